@@ -0,0 +1,164 @@
+// Package events carries domain events of the charging network between services.
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// DefaultStream is the stream all services publish to and read from.
+const DefaultStream = "drivepower:events"
+
+// Event types.
+const (
+	StationBooted              = "StationBooted"
+	StationConnected           = "StationConnected"
+	StationDisconnected        = "StationDisconnected"
+	StationAvailabilityChanged = "StationAvailabilityChanged"
+	StatusChanged              = "StatusChanged"
+	TransactionStarted         = "TransactionStarted"
+	MeterSampled               = "MeterSampled"
+	TransactionStopped         = "TransactionStopped"
+	ReservationNoShow          = "ReservationNoShow"
+)
+
+// Event is envelope of a domain event. Station, transaction and session IDs are
+// kept on the envelope so consumers can route and deduplicate without decoding data.
+type Event struct {
+	ID            string          `json:"id,omitempty"`
+	Type          string          `json:"type"`
+	StationID     string          `json:"station_id"`
+	TransactionID string          `json:"transaction_id,omitempty"`
+	SessionID     int64           `json:"session_id"`
+	OccurredAt    time.Time       `json:"occurred_at"`
+	Data          json.RawMessage `json:"data"`
+}
+
+// StationBootedData is payload of StationBooted.
+type StationBootedData struct {
+	Vendor          string `json:"vendor"`
+	Model           string `json:"model"`
+	SerialNumber    string `json:"serial_number,omitempty"`
+	FirmwareVersion string `json:"firmware_version,omitempty"`
+	Status          string `json:"status"`
+}
+
+// StationConnectionData is payload of StationConnected and StationDisconnected;
+// reason (closed or replaced) is set on disconnect.
+type StationConnectionData struct {
+	RemoteAddr  string    `json:"remote_addr"`
+	Subprotocol string    `json:"subprotocol"`
+	Reason      string    `json:"reason,omitempty"`
+	At          time.Time `json:"at"`
+}
+
+// StationAvailabilityData is payload of StationAvailabilityChanged: station
+// went offline after missed heartbeats or came back online.
+type StationAvailabilityData struct {
+	Online   bool      `json:"online"`
+	LastSeen time.Time `json:"last_seen"`
+	At       time.Time `json:"at"`
+}
+
+// StatusChangedData is payload of StatusChanged; connector 0 is the station itself.
+type StatusChangedData struct {
+	ConnectorID int    `json:"connector_id"`
+	Status      string `json:"status"`
+	ErrorCode   string `json:"error_code,omitempty"`
+}
+
+// TransactionStartedData is payload of TransactionStarted.
+type TransactionStartedData struct {
+	ConnectorID int       `json:"connector_id"`
+	IdTag       string    `json:"id_tag,omitempty"`
+	UserID      int64     `json:"user_id"`
+	MeterStart  int64     `json:"meter_start"`
+	StartedAt   time.Time `json:"started_at"`
+}
+
+// MeterSampledData is payload of MeterSampled; value is energy register reading.
+type MeterSampledData struct {
+	ConnectorID int       `json:"connector_id"`
+	Value       float64   `json:"value"`
+	Unit        string    `json:"unit"`
+	SampledAt   time.Time `json:"sampled_at"`
+}
+
+// TransactionStoppedData is payload of TransactionStopped.
+type TransactionStoppedData struct {
+	ConnectorID int       `json:"connector_id"`
+	UserID      int64     `json:"user_id"`
+	MeterStop   int64     `json:"meter_stop"`
+	EnergyKWh   float64   `json:"energy_kwh"`
+	Reason      string    `json:"reason,omitempty"`
+	StoppedAt   time.Time `json:"stopped_at"`
+}
+
+// ReservationNoShowData is payload of ReservationNoShow: reservation expired
+// without a transaction started on it.
+type ReservationNoShowData struct {
+	ReservationID int64     `json:"reservation_id"`
+	ConnectorID   int       `json:"connector_id"`
+	UserID        int64     `json:"user_id"`
+	IdTag         string    `json:"id_tag,omitempty"`
+	StartAt       time.Time `json:"start_at"`
+	ExpiredAt     time.Time `json:"expired_at"`
+}
+
+// New builds event of type with encoded data.
+func New(eventType, stationID string, data interface{}) (Event, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return Event{}, fmt.Errorf("events: encode %s: %w", eventType, err)
+	}
+	return Event{
+		Type:       eventType,
+		StationID:  stationID,
+		OccurredAt: time.Now().UTC(),
+		Data:       raw,
+	}, nil
+}
+
+// Decode unmarshals event data into v.
+func (e Event) Decode(v interface{}) error {
+	if err := json.Unmarshal(e.Data, v); err != nil {
+		return fmt.Errorf("events: decode %s: %w", e.Type, err)
+	}
+	return nil
+}
+
+// Handler processes one event. Returning error leaves event unacknowledged so
+// it is delivered again; handlers must therefore be idempotent.
+type Handler func(ctx context.Context, event Event) error
+
+// Publisher appends events to the bus.
+type Publisher interface {
+	Publish(ctx context.Context, event Event) error
+}
+
+// Subscriber consumes events as member of a consumer group. Each group receives
+// every event once; instances sharing a group split the load. Subscribe blocks
+// until ctx is cancelled. With no types given all events are passed to handler.
+type Subscriber interface {
+	Subscribe(ctx context.Context, group string, handler Handler, types ...string) error
+}
+
+// Bus both publishes and consumes events.
+type Bus interface {
+	Publisher
+	Subscriber
+}
+
+func accepts(types []string, eventType string) bool {
+	if len(types) == 0 {
+		return true
+	}
+	for _, t := range types {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,87 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const memoryRetryDelay = 10 * time.Millisecond
+
+// MemoryBus is in-process Bus for tests and single-binary setups. It keeps
+// every published event and mirrors consumer group semantics of RedisBus:
+// a group starts at events published after its first subscription and failed
+// events are retried up to maxDeliveries times.
+type MemoryBus struct {
+	mu     sync.Mutex
+	events []Event
+	groups map[string]int
+	wake   chan struct{}
+}
+
+// NewMemoryBus returns empty bus.
+func NewMemoryBus() *MemoryBus {
+	return &MemoryBus{
+		groups: make(map[string]int),
+		wake:   make(chan struct{}),
+	}
+}
+
+// Publish stores event and wakes subscribers.
+func (b *MemoryBus) Publish(_ context.Context, event Event) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	event.ID = fmt.Sprintf("%d-0", len(b.events)+1)
+	b.events = append(b.events, event)
+	close(b.wake)
+	b.wake = make(chan struct{})
+	return nil
+}
+
+// Subscribe delivers events to handler until ctx is cancelled.
+func (b *MemoryBus) Subscribe(ctx context.Context, group string, handler Handler, types ...string) error {
+	b.mu.Lock()
+	if _, ok := b.groups[group]; !ok {
+		b.groups[group] = len(b.events)
+	}
+	b.mu.Unlock()
+
+	for {
+		b.mu.Lock()
+		if offset := b.groups[group]; offset < len(b.events) {
+			event := b.events[offset]
+			b.groups[group] = offset + 1
+			b.mu.Unlock()
+			b.deliver(ctx, event, handler, types)
+			continue
+		}
+		wake := b.wake
+		b.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-wake:
+		}
+	}
+}
+
+// Published returns copy of all events published so far.
+func (b *MemoryBus) Published() []Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]Event(nil), b.events...)
+}
+
+func (b *MemoryBus) deliver(ctx context.Context, event Event, handler Handler, types []string) {
+	if !accepts(types, event.Type) {
+		return
+	}
+	for attempt := 1; ; attempt++ {
+		if err := handler(ctx, event); err == nil || attempt >= maxDeliveries || ctx.Err() != nil {
+			return
+		}
+		sleep(ctx, memoryRetryDelay)
+	}
+}
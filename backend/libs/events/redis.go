@@ -0,0 +1,228 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+const (
+	streamMaxLen  = 1_000_000
+	readBatch     = 50
+	readBlock     = 5 * time.Second
+	claimIdle     = time.Minute
+	retryDelay    = time.Second
+	maxDeliveries = 10
+)
+
+// RedisBus is Bus on top of a Redis stream and its consumer groups. Events
+// are acknowledged after handler succeeds; unacknowledged events are claimed
+// again by any group member once idle for claimIdle and dropped after
+// maxDeliveries attempts.
+type RedisBus struct {
+	client   *redis.Client
+	stream   string
+	consumer string
+	logger   *zap.Logger
+}
+
+// NewRedisBus returns bus over stream (DefaultStream when empty).
+func NewRedisBus(client *redis.Client, stream string, logger *zap.Logger) *RedisBus {
+	stream = strings.TrimSpace(stream)
+	if stream == "" {
+		stream = DefaultStream
+	}
+	return &RedisBus{
+		client:   client,
+		stream:   stream,
+		consumer: consumerName(),
+		logger:   logger,
+	}
+}
+
+// Publish appends event to the stream; old entries are trimmed approximately.
+func (b *RedisBus) Publish(ctx context.Context, event Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("events: encode %s: %w", event.Type, err)
+	}
+	return b.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: b.stream,
+		MaxLen: streamMaxLen,
+		Approx: true,
+		Values: map[string]interface{}{"type": event.Type, "event": data},
+	}).Err()
+}
+
+// Subscribe reads events for group until ctx is cancelled. The group is
+// created on first use and starts with events published after that moment.
+func (b *RedisBus) Subscribe(ctx context.Context, group string, handler Handler, types ...string) error {
+	if err := b.ensureGroup(ctx, group); err != nil {
+		return err
+	}
+	b.logger.Info("event subscription started", zap.String("stream", b.stream), zap.String("group", group), zap.String("consumer", b.consumer))
+
+	var lastClaim time.Time
+	for ctx.Err() == nil {
+		if time.Since(lastClaim) >= claimIdle/2 {
+			b.reclaim(ctx, group, handler, types)
+			lastClaim = time.Now()
+		}
+
+		streams, err := b.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    group,
+			Consumer: b.consumer,
+			Streams:  []string{b.stream, ">"},
+			Count:    readBatch,
+			Block:    readBlock,
+		}).Result()
+		if err != nil {
+			if errors.Is(err, redis.Nil) || ctx.Err() != nil {
+				continue
+			}
+			if strings.HasPrefix(err.Error(), "NOGROUP") {
+				// Stream was deleted or trimmed away together with the group.
+				if err := b.ensureGroup(ctx, group); err != nil {
+					b.logger.Warn("event group recreate failed", zap.String("group", group), zap.Error(err))
+				}
+			} else {
+				b.logger.Warn("event read failed", zap.String("group", group), zap.Error(err))
+			}
+			sleep(ctx, retryDelay)
+			continue
+		}
+		for _, stream := range streams {
+			for _, msg := range stream.Messages {
+				b.handle(ctx, group, msg, handler, types)
+			}
+		}
+	}
+	return nil
+}
+
+func (b *RedisBus) ensureGroup(ctx context.Context, group string) error {
+	err := b.client.XGroupCreateMkStream(ctx, b.stream, group, "$").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return fmt.Errorf("events: create group %s: %w", group, err)
+	}
+	return nil
+}
+
+// reclaim takes over events left unacknowledged by failed handlers or dead consumers.
+func (b *RedisBus) reclaim(ctx context.Context, group string, handler Handler, types []string) {
+	msgs, _, err := b.client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+		Stream:   b.stream,
+		Group:    group,
+		Consumer: b.consumer,
+		MinIdle:  claimIdle,
+		Start:    "0-0",
+		Count:    readBatch,
+	}).Result()
+	if err != nil {
+		if ctx.Err() == nil && !errors.Is(err, redis.Nil) {
+			b.logger.Warn("event reclaim failed", zap.String("group", group), zap.Error(err))
+		}
+		return
+	}
+	for _, msg := range msgs {
+		b.handle(ctx, group, msg, handler, types)
+	}
+}
+
+func (b *RedisBus) handle(ctx context.Context, group string, msg redis.XMessage, handler Handler, types []string) {
+	event, err := decodeMessage(msg)
+	if err != nil {
+		// Malformed entry will never succeed.
+		b.logger.Error("dropping malformed event", zap.String("group", group), zap.String("message_id", msg.ID), zap.Error(err))
+		b.ack(ctx, group, msg.ID)
+		return
+	}
+	if !accepts(types, event.Type) {
+		b.ack(ctx, group, msg.ID)
+		return
+	}
+
+	if err := handler(ctx, event); err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+		if b.deliveries(ctx, group, msg.ID) >= maxDeliveries {
+			b.logger.Error("dropping event after repeated failures",
+				zap.String("group", group),
+				zap.String("message_id", msg.ID),
+				zap.String("event_type", event.Type),
+				zap.String("transaction_id", event.TransactionID),
+				zap.Error(err),
+			)
+			b.ack(ctx, group, msg.ID)
+			return
+		}
+		b.logger.Warn("event handler failed, will retry",
+			zap.String("group", group),
+			zap.String("message_id", msg.ID),
+			zap.String("event_type", event.Type),
+			zap.Error(err),
+		)
+		return
+	}
+	b.ack(ctx, group, msg.ID)
+}
+
+func (b *RedisBus) ack(ctx context.Context, group, id string) {
+	if err := b.client.XAck(ctx, b.stream, group, id).Err(); err != nil && ctx.Err() == nil {
+		b.logger.Warn("event ack failed", zap.String("group", group), zap.String("message_id", id), zap.Error(err))
+	}
+}
+
+// deliveries returns how many times message was handed to group consumers.
+func (b *RedisBus) deliveries(ctx context.Context, group, id string) int64 {
+	pending, err := b.client.XPendingExt(ctx, &redis.XPendingExtArgs{
+		Stream: b.stream,
+		Group:  group,
+		Start:  id,
+		End:    id,
+		Count:  1,
+	}).Result()
+	if err != nil || len(pending) == 0 {
+		return 0
+	}
+	return pending[0].RetryCount
+}
+
+func decodeMessage(msg redis.XMessage) (Event, error) {
+	raw, ok := msg.Values["event"].(string)
+	if !ok {
+		return Event{}, errors.New("events: message has no event field")
+	}
+	var event Event
+	if err := json.Unmarshal([]byte(raw), &event); err != nil {
+		return Event{}, fmt.Errorf("events: decode message: %w", err)
+	}
+	event.ID = msg.ID
+	return event, nil
+}
+
+// consumerName identifies this process within consumer group.
+func consumerName() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s-%d", host, os.Getpid())
+}
+
+func sleep(ctx context.Context, d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
@@ -26,18 +26,26 @@ func New(cfg *config.Config, logger *zap.Logger) (*App, error) {
 	sessionsClient := clients.NewSessionsClient(cfg.Services.SessionsURL, httpClient)
 	billingClient := clients.NewBillingClient(cfg.Services.BillingURL, httpClient)
 	stationsClient := clients.NewStationsClient(cfg.Services.StationsURL, httpClient)
+	// Commands wait for the station answer, which takes longer than other
+	// calls; file transfers are streamed without timeout.
+	commandsClient := clients.NewCommandsClient(cfg.Services.OCPPURL, clients.NewDefaultHTTPClient(cfg.CommandTimeout()), clients.NewDefaultHTTPClient(0))
 
 	authHandlers := handlers.NewAuthHandlers(authClient, logger)
-	sessionsHandlers := handlers.NewSessionsHandlers(sessionsClient, logger)
+	sessionsHandlers := handlers.NewSessionsHandlers(sessionsClient, commandsClient, logger)
+	reservationsHandlers := handlers.NewReservationsHandlers(commandsClient, logger)
 	billingHandlers := handlers.NewBillingHandlers(billingClient, logger)
 	stationsHandlers := handlers.NewStationsHandlers(stationsClient, logger)
+	adminHandlers := handlers.NewAdminHandlers(commandsClient, logger)
 
 	router := httpserver.NewRouter(httpserver.RouterDeps{
 		AuthHandlers:     authHandlers,
 		StationsHandlers: stationsHandlers,
 		SessionsHandlers: sessionsHandlers,
+		Reservations:     reservationsHandlers,
 		BillingHandlers:  billingHandlers,
+		AdminHandlers:    adminHandlers,
 		HealthHandler:    handlers.NewHealthHandler(),
+		CommandTimeout:   cfg.CommandTimeout(),
 	}, middleware.AuthMiddleware(cfg.JWT.Secret))
 
 	server := httpserver.NewServer(
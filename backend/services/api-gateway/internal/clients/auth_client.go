@@ -3,6 +3,7 @@ package clients
 import (
 	"context"
 	"net/http"
+	"strconv"
 )
 
 // AuthClient proxies auth-service endpoints.
@@ -24,3 +25,19 @@ func (c *AuthClient) Signup(ctx context.Context, body []byte) (int, []byte, erro
 func (c *AuthClient) Login(ctx context.Context, body []byte) (int, []byte, error) {
 	return c.base.Do(ctx, http.MethodPost, "/auth/login", body, nil)
 }
+
+// RegisterIdTag binds RFID/app token to user.
+func (c *AuthClient) RegisterIdTag(ctx context.Context, userID int64, body []byte) (int, []byte, error) {
+	headers := map[string]string{
+		"X-User-ID": strconv.FormatInt(userID, 10),
+	}
+	return c.base.Do(ctx, http.MethodPost, "/auth/id-tags", body, headers)
+}
+
+// GetIdTagsForUser lists id tags of user.
+func (c *AuthClient) GetIdTagsForUser(ctx context.Context, userID int64) (int, []byte, error) {
+	headers := map[string]string{
+		"X-User-ID": strconv.FormatInt(userID, 10),
+	}
+	return c.base.Do(ctx, http.MethodGet, "/auth/id-tags/me", nil, headers)
+}
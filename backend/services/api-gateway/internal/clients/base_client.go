@@ -54,7 +54,36 @@ func (c *BaseClient) Do(ctx context.Context, method, path string, body []byte, h
 	if body != nil && req.Header.Get("Content-Type") == "" {
 		req.Header.Set("Content-Type", "application/json")
 	}
+	return c.send(req)
+}
+
+// Stream executes HTTP request with body read from reader, e.g. file upload,
+// and returns status/body.
+func (c *BaseClient) Stream(ctx context.Context, method, path string, body io.Reader, headers map[string]string) (int, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.buildURL(path), body)
+	if err != nil {
+		return 0, nil, err
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	return c.send(req)
+}
+
+// Open executes HTTP request and returns response with unread body, e.g. file
+// download; caller closes the body.
+func (c *BaseClient) Open(ctx context.Context, method, path string, headers map[string]string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.buildURL(path), nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	return c.client.Do(req)
+}
 
+func (c *BaseClient) send(req *http.Request) (int, []byte, error) {
 	resp, err := c.client.Do(req)
 	if err != nil {
 		return 0, nil, err
@@ -67,7 +96,7 @@ func (c *BaseClient) Do(ctx context.Context, method, path string, body []byte, h
 	return resp.StatusCode, respBody, nil
 }
 
-// NewDefaultHTTPClient returns *http.Client with timeout.
+// NewDefaultHTTPClient returns *http.Client with timeout; zero means none.
 func NewDefaultHTTPClient(timeout time.Duration) *http.Client {
 	return &http.Client{Timeout: timeout}
 }
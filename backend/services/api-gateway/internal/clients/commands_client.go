@@ -0,0 +1,114 @@
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// CommandsClient sends station commands through ocpp-server internal API.
+// File transfers use stream client, which must have no timeout.
+type CommandsClient struct {
+	base   *BaseClient
+	stream *BaseClient
+}
+
+// RemoteStartRequest payload for remote start command.
+type RemoteStartRequest struct {
+	StationID   string `json:"station_id"`
+	ConnectorID int    `json:"connector_id"`
+	IdTag       string `json:"id_tag"`
+}
+
+// NewCommandsClient returns client.
+func NewCommandsClient(baseURL string, httpClient, streamClient HTTPDoer) *CommandsClient {
+	return &CommandsClient{
+		base:   NewBaseClient(baseURL, httpClient),
+		stream: NewBaseClient(baseURL, streamClient),
+	}
+}
+
+// RemoteStart asks ocpp-server to send RemoteStartTransaction.
+func (c *CommandsClient) RemoteStart(ctx context.Context, userID int64, req RemoteStartRequest) (int, []byte, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	headers := map[string]string{
+		"X-User-ID": strconv.FormatInt(userID, 10),
+	}
+	return c.base.Do(ctx, http.MethodPost, "/internal/commands/remote-start", body, headers)
+}
+
+// RemoteStop asks ocpp-server to send RemoteStopTransaction.
+func (c *CommandsClient) RemoteStop(ctx context.Context, userID int64, transactionID string) (int, []byte, error) {
+	body, err := json.Marshal(map[string]string{"transaction_id": transactionID})
+	if err != nil {
+		return 0, nil, err
+	}
+	headers := map[string]string{
+		"X-User-ID": strconv.FormatInt(userID, 10),
+	}
+	return c.base.Do(ctx, http.MethodPost, "/internal/commands/remote-stop", body, headers)
+}
+
+// StationCommand asks ocpp-server to send management command (reset,
+// change-availability, unlock-connector, trigger-message, clear-cache).
+func (c *CommandsClient) StationCommand(ctx context.Context, userID int64, command string, body []byte) (int, []byte, error) {
+	headers := map[string]string{
+		"X-User-ID": strconv.FormatInt(userID, 10),
+	}
+	return c.base.Do(ctx, http.MethodPost, "/internal/commands/"+command, body, headers)
+}
+
+// Audit lists commands sent to stations; query is forwarded as is.
+func (c *CommandsClient) Audit(ctx context.Context, query string) (int, []byte, error) {
+	path := "/internal/commands/audit"
+	if query != "" {
+		path += "?" + query
+	}
+	return c.base.Do(ctx, http.MethodGet, path, nil, nil)
+}
+
+// CompositeSchedule asks ocpp-server to read composite schedule of station.
+func (c *CommandsClient) CompositeSchedule(ctx context.Context, userID int64, stationID, query string) (int, []byte, error) {
+	path := "/internal/stations/" + url.PathEscape(stationID) + "/composite-schedule"
+	if query != "" {
+		path += "?" + query
+	}
+	headers := map[string]string{
+		"X-User-ID": strconv.FormatInt(userID, 10),
+	}
+	return c.base.Do(ctx, http.MethodGet, path, nil, headers)
+}
+
+// Forward sends administration request to ocpp-server internal API; path
+// starts with /internal.
+func (c *CommandsClient) Forward(ctx context.Context, userID int64, method, path string, body []byte) (int, []byte, error) {
+	headers := map[string]string{
+		"X-User-ID": strconv.FormatInt(userID, 10),
+	}
+	return c.base.Do(ctx, method, path, body, headers)
+}
+
+// UploadFirmware streams firmware image to ocpp-server; query carries version
+// and file_name.
+func (c *CommandsClient) UploadFirmware(ctx context.Context, userID int64, query, contentType string, body io.Reader) (int, []byte, error) {
+	headers := map[string]string{
+		"X-User-ID":    strconv.FormatInt(userID, 10),
+		"Content-Type": contentType,
+	}
+	return c.stream.Stream(ctx, http.MethodPost, "/internal/firmware/artifacts?"+query, body, headers)
+}
+
+// DownloadDiagnostics opens diagnostics bundle stored by ocpp-server; caller
+// closes the body.
+func (c *CommandsClient) DownloadDiagnostics(ctx context.Context, userID int64, id string) (*http.Response, error) {
+	headers := map[string]string{
+		"X-User-ID": strconv.FormatInt(userID, 10),
+	}
+	return c.stream.Open(ctx, http.MethodGet, "/internal/diagnostics/"+url.PathEscape(id)+"/file", headers)
+}
@@ -22,9 +22,11 @@ type Config struct {
 		SessionsURL string `yaml:"sessionsUrl" env:"SESSIONS_SERVICE_URL"`
 		BillingURL  string `yaml:"billingUrl" env:"BILLING_SERVICE_URL"`
 		StationsURL string `yaml:"stationsUrl" env:"STATIONS_SERVICE_URL"`
+		OCPPURL     string `yaml:"ocppUrl" env:"OCPP_SERVER_URL"`
 	} `yaml:"services"`
 	HTTPClient struct {
-		TimeoutSeconds int `yaml:"timeoutSeconds" env:"API_GATEWAY_HTTP_TIMEOUT"`
+		TimeoutSeconds        int `yaml:"timeoutSeconds" env:"API_GATEWAY_HTTP_TIMEOUT"`
+		CommandTimeoutSeconds int `yaml:"commandTimeoutSeconds" env:"API_GATEWAY_COMMAND_TIMEOUT"`
 	} `yaml:"httpClient"`
 }
 
@@ -37,9 +39,11 @@ func Load() (*Config, error) {
 			Port: "8080",
 		},
 		HTTPClient: struct {
-			TimeoutSeconds int `yaml:"timeoutSeconds" env:"API_GATEWAY_HTTP_TIMEOUT"`
+			TimeoutSeconds        int `yaml:"timeoutSeconds" env:"API_GATEWAY_HTTP_TIMEOUT"`
+			CommandTimeoutSeconds int `yaml:"commandTimeoutSeconds" env:"API_GATEWAY_COMMAND_TIMEOUT"`
 		}{
-			TimeoutSeconds: 5,
+			TimeoutSeconds:        5,
+			CommandTimeoutSeconds: 40,
 		},
 	}
 
@@ -73,3 +77,11 @@ func (c *Config) HTTPTimeout() time.Duration {
 	return time.Duration(c.HTTPClient.TimeoutSeconds) * time.Second
 }
 
+// CommandTimeout returns timeout of requests to ocpp-server that wait for a
+// station answer; it must exceed OCPP_CALL_TIMEOUT of ocpp-server.
+func (c *Config) CommandTimeout() time.Duration {
+	if c.HTTPClient.CommandTimeoutSeconds <= 0 {
+		return 40 * time.Second
+	}
+	return time.Duration(c.HTTPClient.CommandTimeoutSeconds) * time.Second
+}
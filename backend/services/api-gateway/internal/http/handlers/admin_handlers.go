@@ -0,0 +1,323 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"drivepower/backend/services/api-gateway/internal/clients"
+	"drivepower/backend/services/api-gateway/internal/http/middleware"
+)
+
+// Station management commands proxied to ocpp-server.
+const (
+	CommandReset              = "reset"
+	CommandChangeAvailability = "change-availability"
+	CommandUnlockConnector    = "unlock-connector"
+	CommandTriggerMessage     = "trigger-message"
+	CommandClearCache         = "clear-cache"
+)
+
+// AdminHandlers proxies station management endpoints for operators.
+type AdminHandlers struct {
+	commands *clients.CommandsClient
+	logger   *zap.Logger
+}
+
+// NewAdminHandlers returns handler.
+func NewAdminHandlers(commands *clients.CommandsClient, logger *zap.Logger) *AdminHandlers {
+	return &AdminHandlers{commands: commands, logger: logger}
+}
+
+// StationCommand returns handler of POST /api/admin/stations/{id}/<command>.
+// Body fields are passed to ocpp-server as is, station_id is taken from path.
+func (h *AdminHandlers) StationCommand(command string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := middleware.UserIDFromContext(r.Context())
+		if !ok {
+			writeError(w, http.StatusUnauthorized, "unauthorized")
+			return
+		}
+		stationID := strings.TrimSpace(r.PathValue("id"))
+		if stationID == "" {
+			writeError(w, http.StatusBadRequest, "station id is required")
+			return
+		}
+
+		fields := make(map[string]interface{})
+		if err := json.NewDecoder(r.Body).Decode(&fields); err != nil && !errors.Is(err, io.EOF) {
+			writeError(w, http.StatusBadRequest, "invalid body")
+			return
+		}
+		fields["station_id"] = stationID
+		body, err := json.Marshal(fields)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid body")
+			return
+		}
+
+		status, respBody, err := h.commands.StationCommand(r.Context(), userID, command, body)
+		if err != nil {
+			h.logger.Error("station command proxy failed", zap.String("command", command), zap.Error(err))
+			writeError(w, http.StatusBadGateway, "ocpp server unavailable")
+			return
+		}
+		writeRaw(w, status, respBody)
+	}
+}
+
+// CommandAudit handles GET /api/admin/commands?station_id=&limit=.
+func (h *AdminHandlers) CommandAudit(w http.ResponseWriter, r *http.Request) {
+	query := url.Values{}
+	for _, key := range []string{"station_id", "limit"} {
+		if value := r.URL.Query().Get(key); value != "" {
+			query.Set(key, value)
+		}
+	}
+	status, respBody, err := h.commands.Audit(r.Context(), query.Encode())
+	if err != nil {
+		h.logger.Error("command audit proxy failed", zap.Error(err))
+		writeError(w, http.StatusBadGateway, "ocpp server unavailable")
+		return
+	}
+	writeRaw(w, status, respBody)
+}
+
+// maxAdminBody limits body forwarded to ocpp-server.
+const maxAdminBody = 1 << 20
+
+// ChargingProfiles handles GET /api/admin/charging-profiles?station_id= and
+// POST /api/admin/charging-profiles.
+func (h *AdminHandlers) ChargingProfiles(w http.ResponseWriter, r *http.Request) {
+	query := url.Values{}
+	if stationID := r.URL.Query().Get("station_id"); stationID != "" {
+		query.Set("station_id", stationID)
+	}
+	path := "/internal/charging-profiles"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	h.forward(w, r, path)
+}
+
+// ChargingProfile handles GET, PUT and DELETE /api/admin/charging-profiles/{id}.
+func (h *AdminHandlers) ChargingProfile(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimSpace(r.PathValue("id"))
+	if id == "" {
+		writeError(w, http.StatusBadRequest, "profile id is required")
+		return
+	}
+	h.forward(w, r, "/internal/charging-profiles/"+url.PathEscape(id))
+}
+
+// CompositeSchedule handles GET /api/admin/stations/{id}/composite-schedule?connector_id=&duration=&charging_rate_unit=.
+func (h *AdminHandlers) CompositeSchedule(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	stationID := strings.TrimSpace(r.PathValue("id"))
+	if stationID == "" {
+		writeError(w, http.StatusBadRequest, "station id is required")
+		return
+	}
+	query := url.Values{}
+	for _, key := range []string{"connector_id", "duration", "charging_rate_unit"} {
+		if value := r.URL.Query().Get(key); value != "" {
+			query.Set(key, value)
+		}
+	}
+
+	status, respBody, err := h.commands.CompositeSchedule(r.Context(), userID, stationID, query.Encode())
+	if err != nil {
+		h.logger.Error("composite schedule proxy failed", zap.String("station_id", stationID), zap.Error(err))
+		writeError(w, http.StatusBadGateway, "ocpp server unavailable")
+		return
+	}
+	writeRaw(w, status, respBody)
+}
+
+// Sites handles GET /api/admin/sites.
+func (h *AdminHandlers) Sites(w http.ResponseWriter, r *http.Request) {
+	h.forward(w, r, "/internal/sites")
+}
+
+// Site handles GET, PUT and DELETE /api/admin/sites/{id}.
+func (h *AdminHandlers) Site(w http.ResponseWriter, r *http.Request) {
+	h.forward(w, r, "/internal/sites/"+url.PathEscape(r.PathValue("id")))
+}
+
+// SiteLoad handles GET /api/admin/sites/{id}/load.
+func (h *AdminHandlers) SiteLoad(w http.ResponseWriter, r *http.Request) {
+	h.forward(w, r, "/internal/sites/"+url.PathEscape(r.PathValue("id"))+"/load")
+}
+
+// UserTier handles PUT /api/admin/users/{id}/tier.
+func (h *AdminHandlers) UserTier(w http.ResponseWriter, r *http.Request) {
+	h.forward(w, r, "/internal/user-tiers/"+url.PathEscape(r.PathValue("id")))
+}
+
+// FirmwareArtifacts handles GET /api/admin/firmware/artifacts and POST with
+// JSON {version, url} or with the image itself and ?version=&file_name=.
+func (h *AdminHandlers) FirmwareArtifacts(w http.ResponseWriter, r *http.Request) {
+	contentType := r.Header.Get("Content-Type")
+	if mediaType, _, _ := mime.ParseMediaType(contentType); r.Method != http.MethodPost || mediaType == "application/json" {
+		h.forward(w, r, "/internal/firmware/artifacts")
+		return
+	}
+
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	query := url.Values{}
+	for _, key := range []string{"version", "file_name"} {
+		query.Set(key, r.URL.Query().Get(key))
+	}
+	// Image may take longer to arrive than the server timeouts allow; the
+	// answer is written only after the whole image was forwarded.
+	rc := http.NewResponseController(w)
+	_ = rc.SetReadDeadline(time.Time{})
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	status, respBody, err := h.commands.UploadFirmware(r.Context(), userID, query.Encode(), contentType, r.Body)
+	if err != nil {
+		h.logger.Error("firmware upload proxy failed", zap.Error(err))
+		writeError(w, http.StatusBadGateway, "ocpp server unavailable")
+		return
+	}
+	writeRaw(w, status, respBody)
+}
+
+// FirmwareCampaigns handles GET and POST /api/admin/firmware/campaigns.
+func (h *AdminHandlers) FirmwareCampaigns(w http.ResponseWriter, r *http.Request) {
+	h.forward(w, r, "/internal/firmware/campaigns")
+}
+
+// FirmwareCampaign handles GET /api/admin/firmware/campaigns/{id}, the campaign dashboard.
+func (h *AdminHandlers) FirmwareCampaign(w http.ResponseWriter, r *http.Request) {
+	h.forward(w, r, "/internal/firmware/campaigns/"+url.PathEscape(r.PathValue("id")))
+}
+
+// FirmwareCampaignAction returns handler of POST
+// /api/admin/firmware/campaigns/{id}/<action> (pause, resume).
+func (h *AdminHandlers) FirmwareCampaignAction(action string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		h.forward(w, r, "/internal/firmware/campaigns/"+url.PathEscape(r.PathValue("id"))+"/"+action)
+	}
+}
+
+// Diagnostics handles GET /api/admin/diagnostics?station_id=&limit= and POST
+// /api/admin/diagnostics, which asks station to upload its logs.
+func (h *AdminHandlers) Diagnostics(w http.ResponseWriter, r *http.Request) {
+	query := url.Values{}
+	for _, key := range []string{"station_id", "limit"} {
+		if value := r.URL.Query().Get(key); value != "" {
+			query.Set(key, value)
+		}
+	}
+	path := "/internal/diagnostics"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	h.forward(w, r, path)
+}
+
+// DiagnosticsEntry handles GET /api/admin/diagnostics/{id}.
+func (h *AdminHandlers) DiagnosticsEntry(w http.ResponseWriter, r *http.Request) {
+	h.forward(w, r, "/internal/diagnostics/"+url.PathEscape(r.PathValue("id")))
+}
+
+// DiagnosticsFile handles GET /api/admin/diagnostics/{id}/file and streams the
+// uploaded bundle.
+func (h *AdminHandlers) DiagnosticsFile(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	resp, err := h.commands.DownloadDiagnostics(r.Context(), userID, r.PathValue("id"))
+	if err != nil {
+		h.logger.Error("diagnostics download proxy failed", zap.Error(err))
+		writeError(w, http.StatusBadGateway, "ocpp server unavailable")
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxAdminBody))
+		writeRaw(w, resp.StatusCode, respBody)
+		return
+	}
+	for _, key := range []string{"Content-Type", "Content-Disposition", "Content-Length", "Last-Modified"} {
+		if value := resp.Header.Get(key); value != "" {
+			w.Header().Set(key, value)
+		}
+	}
+	// Bundle may take longer to send than the server write timeout allows.
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+	w.WriteHeader(http.StatusOK)
+	_, _ = io.Copy(w, resp.Body)
+}
+
+// Reservations handles GET /api/admin/reservations?station_id=&user_id=&limit=.
+func (h *AdminHandlers) Reservations(w http.ResponseWriter, r *http.Request) {
+	query := url.Values{}
+	for _, key := range []string{"station_id", "user_id", "limit"} {
+		if value := r.URL.Query().Get(key); value != "" {
+			query.Set(key, value)
+		}
+	}
+	path := "/internal/reservations"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	h.forward(w, r, path)
+}
+
+// ReservationCancel handles POST /api/admin/reservations/{id}/cancel and
+// cancels reservation of any user.
+func (h *AdminHandlers) ReservationCancel(w http.ResponseWriter, r *http.Request) {
+	h.forward(w, r, "/internal/reservations/"+url.PathEscape(r.PathValue("id"))+"/cancel")
+}
+
+// forward proxies request with its body to ocpp-server path.
+func (h *AdminHandlers) forward(w http.ResponseWriter, r *http.Request, path string) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	var body []byte
+	if r.Method == http.MethodPost || r.Method == http.MethodPut {
+		var err error
+		if body, err = io.ReadAll(http.MaxBytesReader(w, r.Body, maxAdminBody)); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid body")
+			return
+		}
+	}
+
+	status, respBody, err := h.commands.Forward(r.Context(), userID, r.Method, path, body)
+	if err != nil {
+		h.logger.Error("admin proxy failed", zap.String("path", path), zap.Error(err))
+		writeError(w, http.StatusBadGateway, "ocpp server unavailable")
+		return
+	}
+	if status == http.StatusNoContent {
+		w.WriteHeader(status)
+		return
+	}
+	writeRaw(w, status, respBody)
+}
@@ -7,6 +7,7 @@ import (
 	"go.uber.org/zap"
 
 	"drivepower/backend/services/api-gateway/internal/clients"
+	"drivepower/backend/services/api-gateway/internal/http/middleware"
 )
 
 // AuthHandlers proxies auth-service endpoints.
@@ -52,3 +53,40 @@ func (h *AuthHandlers) Login(w http.ResponseWriter, r *http.Request) {
 	writeRaw(w, status, respBody)
 }
 
+
+// RegisterIdTag handles POST /api/auth/id-tags.
+func (h *AuthHandlers) RegisterIdTag(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid body")
+		return
+	}
+	status, respBody, err := h.client.RegisterIdTag(r.Context(), userID, body)
+	if err != nil {
+		h.logger.Error("register id tag proxy failed", zap.Error(err))
+		writeError(w, http.StatusBadGateway, "auth service unavailable")
+		return
+	}
+	writeRaw(w, status, respBody)
+}
+
+// IdTagsMe handles GET /api/auth/id-tags/me.
+func (h *AuthHandlers) IdTagsMe(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	status, respBody, err := h.client.GetIdTagsForUser(r.Context(), userID)
+	if err != nil {
+		h.logger.Error("id tags proxy failed", zap.Error(err))
+		writeError(w, http.StatusBadGateway, "auth service unavailable")
+		return
+	}
+	writeRaw(w, status, respBody)
+}
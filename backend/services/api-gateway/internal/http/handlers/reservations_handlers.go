@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"go.uber.org/zap"
+
+	"drivepower/backend/services/api-gateway/internal/clients"
+	"drivepower/backend/services/api-gateway/internal/http/middleware"
+)
+
+// ReservationsHandlers proxies connector bookings of the current user to
+// ocpp-server; every request is limited to reservations of that user.
+type ReservationsHandlers struct {
+	commands *clients.CommandsClient
+	logger   *zap.Logger
+}
+
+// NewReservationsHandlers returns handler.
+func NewReservationsHandlers(commands *clients.CommandsClient, logger *zap.Logger) *ReservationsHandlers {
+	return &ReservationsHandlers{commands: commands, logger: logger}
+}
+
+// Book handles POST /api/reservations with {station_id, connector_id, id_tag,
+// start_time, end_time}; start_time may be omitted to book from now.
+func (h *ReservationsHandlers) Book(w http.ResponseWriter, r *http.Request) {
+	h.forward(w, r, func(int64) string { return "/internal/reservations" })
+}
+
+// Me handles GET /api/reservations/me?limit=.
+func (h *ReservationsHandlers) Me(w http.ResponseWriter, r *http.Request) {
+	h.forward(w, r, func(userID int64) string {
+		query := url.Values{"user_id": {strconv.FormatInt(userID, 10)}}
+		if limit := r.URL.Query().Get("limit"); limit != "" {
+			query.Set("limit", limit)
+		}
+		return "/internal/reservations?" + query.Encode()
+	})
+}
+
+// Cancel handles POST /api/reservations/{id}/cancel.
+func (h *ReservationsHandlers) Cancel(w http.ResponseWriter, r *http.Request) {
+	h.forward(w, r, func(userID int64) string {
+		return "/internal/reservations/" + url.PathEscape(r.PathValue("id")) + "/cancel?user_id=" + strconv.FormatInt(userID, 10)
+	})
+}
+
+// forward proxies request with its body to ocpp-server path built for the
+// current user.
+func (h *ReservationsHandlers) forward(w http.ResponseWriter, r *http.Request, path func(userID int64) string) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	var body []byte
+	if r.Method == http.MethodPost {
+		var err error
+		if body, err = io.ReadAll(http.MaxBytesReader(w, r.Body, maxAdminBody)); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid body")
+			return
+		}
+	}
+
+	status, respBody, err := h.commands.Forward(r.Context(), userID, r.Method, path(userID), body)
+	if err != nil {
+		h.logger.Error("reservations proxy failed", zap.Error(err))
+		writeError(w, http.StatusBadGateway, "ocpp server unavailable")
+		return
+	}
+	writeRaw(w, status, respBody)
+}
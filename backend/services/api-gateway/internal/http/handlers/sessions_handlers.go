@@ -1,7 +1,9 @@
 package handlers
 
 import (
+	"encoding/json"
 	"net/http"
+	"strings"
 
 	"go.uber.org/zap"
 
@@ -11,13 +13,14 @@ import (
 
 // SessionsHandlers proxies sessions-service endpoints.
 type SessionsHandlers struct {
-	client *clients.SessionsClient
-	logger *zap.Logger
+	client   *clients.SessionsClient
+	commands *clients.CommandsClient
+	logger   *zap.Logger
 }
 
 // NewSessionsHandlers returns handler.
-func NewSessionsHandlers(client *clients.SessionsClient, logger *zap.Logger) *SessionsHandlers {
-	return &SessionsHandlers{client: client, logger: logger}
+func NewSessionsHandlers(client *clients.SessionsClient, commands *clients.CommandsClient, logger *zap.Logger) *SessionsHandlers {
+	return &SessionsHandlers{client: client, commands: commands, logger: logger}
 }
 
 // Me handles GET /api/sessions/me.
@@ -36,3 +39,53 @@ func (h *SessionsHandlers) Me(w http.ResponseWriter, r *http.Request) {
 	writeRaw(w, status, respBody)
 }
 
+
+// Start handles POST /api/sessions/start.
+func (h *SessionsHandlers) Start(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	var req clients.RemoteStartRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid body")
+		return
+	}
+	req.StationID = strings.TrimSpace(req.StationID)
+	req.IdTag = strings.TrimSpace(req.IdTag)
+	if req.StationID == "" || req.IdTag == "" {
+		writeError(w, http.StatusBadRequest, "station_id and id_tag are required")
+		return
+	}
+
+	status, respBody, err := h.commands.RemoteStart(r.Context(), userID, req)
+	if err != nil {
+		h.logger.Error("remote start proxy failed", zap.Error(err))
+		writeError(w, http.StatusBadGateway, "ocpp server unavailable")
+		return
+	}
+	writeRaw(w, status, respBody)
+}
+
+// Stop handles POST /api/sessions/{id}/stop where id is OCPP transaction id.
+func (h *SessionsHandlers) Stop(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.UserIDFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	transactionID := strings.TrimSpace(r.PathValue("id"))
+	if transactionID == "" {
+		writeError(w, http.StatusBadRequest, "transaction id is required")
+		return
+	}
+
+	status, respBody, err := h.commands.RemoteStop(r.Context(), userID, transactionID)
+	if err != nil {
+		h.logger.Error("remote stop proxy failed", zap.Error(err))
+		writeError(w, http.StatusBadGateway, "ocpp server unavailable")
+		return
+	}
+	writeRaw(w, status, respBody)
+}
@@ -12,9 +12,15 @@ import (
 
 type contextKey string
 
-const userIDKey contextKey = "userID"
+const (
+	userIDKey contextKey = "userID"
+	roleKey   contextKey = "role"
+)
+
+// RoleAdmin is role of operators allowed to manage stations.
+const RoleAdmin = "admin"
 
-// AuthMiddleware validates JWT tokens and extracts user ID.
+// AuthMiddleware validates JWT tokens and extracts user ID and role.
 func AuthMiddleware(secret string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -52,7 +58,10 @@ func AuthMiddleware(secret string) func(http.Handler) http.Handler {
 				return
 			}
 
+			role, _ := claims["role"].(string)
+
 			ctx := context.WithValue(r.Context(), userIDKey, userID)
+			ctx = context.WithValue(ctx, roleKey, role)
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
@@ -82,3 +91,23 @@ func UserIDFromContext(ctx context.Context) (int64, bool) {
 	id, ok := val.(int64)
 	return id, ok
 }
+
+// RoleFromContext retrieves role from request context.
+func RoleFromContext(ctx context.Context) string {
+	role, _ := ctx.Value(roleKey).(string)
+	return role
+}
+
+// RequireRole rejects requests whose token role differs from role; must run
+// after AuthMiddleware.
+func RequireRole(role string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if RoleFromContext(r.Context()) != role {
+				http.Error(w, "forbidden", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -2,6 +2,8 @@ package httpserver
 
 import (
 	"net/http"
+	"strings"
+	"time"
 
 	"drivepower/backend/services/api-gateway/internal/http/handlers"
 	"drivepower/backend/services/api-gateway/internal/http/middleware"
@@ -12,8 +14,13 @@ type RouterDeps struct {
 	AuthHandlers     *handlers.AuthHandlers
 	StationsHandlers *handlers.StationsHandlers
 	SessionsHandlers *handlers.SessionsHandlers
+	Reservations     *handlers.ReservationsHandlers
 	BillingHandlers  *handlers.BillingHandlers
+	AdminHandlers    *handlers.AdminHandlers
 	HealthHandler    http.HandlerFunc
+	// CommandTimeout is how long ocpp-server may take to answer a request
+	// that waits for a station.
+	CommandTimeout time.Duration
 }
 
 // NewRouter wires HTTP routes with middleware.
@@ -30,10 +37,56 @@ func NewRouter(deps RouterDeps, authMiddleware func(http.Handler) http.Handler)
 	authenticated := func(handler http.HandlerFunc) http.Handler {
 		return middleware.Chain(handler, authMiddleware)
 	}
+	// command extends write deadline of route proxied to ocpp-server past
+	// the server write timeout: the answer waits for the station.
+	command := func(handler http.Handler) http.Handler {
+		return writeTimeout(deps.CommandTimeout+5*time.Second, handler)
+	}
 
+	mux.Handle("/api/auth/id-tags", method(http.MethodPost, authenticated(http.HandlerFunc(deps.AuthHandlers.RegisterIdTag))))
+	mux.Handle("/api/auth/id-tags/me", method(http.MethodGet, authenticated(http.HandlerFunc(deps.AuthHandlers.IdTagsMe))))
 	mux.Handle("/api/sessions/me", method(http.MethodGet, authenticated(http.HandlerFunc(deps.SessionsHandlers.Me))))
+	mux.Handle("/api/sessions/start", command(method(http.MethodPost, authenticated(http.HandlerFunc(deps.SessionsHandlers.Start)))))
+	mux.Handle("/api/sessions/{id}/stop", command(method(http.MethodPost, authenticated(http.HandlerFunc(deps.SessionsHandlers.Stop)))))
+	mux.Handle("/api/reservations", command(method(http.MethodPost, authenticated(http.HandlerFunc(deps.Reservations.Book)))))
+	mux.Handle("/api/reservations/me", method(http.MethodGet, authenticated(http.HandlerFunc(deps.Reservations.Me))))
+	mux.Handle("/api/reservations/{id}/cancel", command(method(http.MethodPost, authenticated(http.HandlerFunc(deps.Reservations.Cancel)))))
 	mux.Handle("/api/billing/me/transactions", method(http.MethodGet, authenticated(http.HandlerFunc(deps.BillingHandlers.TransactionsMe))))
 
+	// Admin routes are proxied to ocpp-server and may wait for a station.
+	admin := func(handler http.HandlerFunc) http.Handler {
+		return command(middleware.Chain(handler, authMiddleware, middleware.RequireRole(middleware.RoleAdmin)))
+	}
+
+	for _, command := range []string{
+		handlers.CommandReset,
+		handlers.CommandChangeAvailability,
+		handlers.CommandUnlockConnector,
+		handlers.CommandTriggerMessage,
+		handlers.CommandClearCache,
+	} {
+		mux.Handle("/api/admin/stations/{id}/"+command, method(http.MethodPost, admin(deps.AdminHandlers.StationCommand(command))))
+	}
+	mux.Handle("/api/admin/commands", method(http.MethodGet, admin(deps.AdminHandlers.CommandAudit)))
+	mux.Handle("/api/admin/charging-profiles", methods([]string{http.MethodGet, http.MethodPost}, admin(deps.AdminHandlers.ChargingProfiles)))
+	mux.Handle("/api/admin/charging-profiles/{id}", methods([]string{http.MethodGet, http.MethodPut, http.MethodDelete}, admin(deps.AdminHandlers.ChargingProfile)))
+	mux.Handle("/api/admin/stations/{id}/composite-schedule", method(http.MethodGet, admin(deps.AdminHandlers.CompositeSchedule)))
+	mux.Handle("/api/admin/sites", method(http.MethodGet, admin(deps.AdminHandlers.Sites)))
+	mux.Handle("/api/admin/sites/{id}", methods([]string{http.MethodGet, http.MethodPut, http.MethodDelete}, admin(deps.AdminHandlers.Site)))
+	mux.Handle("/api/admin/sites/{id}/load", method(http.MethodGet, admin(deps.AdminHandlers.SiteLoad)))
+	mux.Handle("/api/admin/users/{id}/tier", method(http.MethodPut, admin(deps.AdminHandlers.UserTier)))
+	mux.Handle("/api/admin/firmware/artifacts", methods([]string{http.MethodGet, http.MethodPost}, admin(deps.AdminHandlers.FirmwareArtifacts)))
+	mux.Handle("/api/admin/firmware/campaigns", methods([]string{http.MethodGet, http.MethodPost}, admin(deps.AdminHandlers.FirmwareCampaigns)))
+	mux.Handle("/api/admin/firmware/campaigns/{id}", method(http.MethodGet, admin(deps.AdminHandlers.FirmwareCampaign)))
+	for _, action := range []string{"pause", "resume"} {
+		mux.Handle("/api/admin/firmware/campaigns/{id}/"+action, method(http.MethodPost, admin(deps.AdminHandlers.FirmwareCampaignAction(action))))
+	}
+	mux.Handle("/api/admin/diagnostics", methods([]string{http.MethodGet, http.MethodPost}, admin(deps.AdminHandlers.Diagnostics)))
+	mux.Handle("/api/admin/diagnostics/{id}", method(http.MethodGet, admin(deps.AdminHandlers.DiagnosticsEntry)))
+	mux.Handle("/api/admin/diagnostics/{id}/file", method(http.MethodGet, admin(deps.AdminHandlers.DiagnosticsFile)))
+	mux.Handle("/api/admin/reservations", method(http.MethodGet, admin(deps.AdminHandlers.Reservations)))
+	mux.Handle("/api/admin/reservations/{id}/cancel", method(http.MethodPost, admin(deps.AdminHandlers.ReservationCancel)))
+
 	return mux
 }
 
@@ -48,3 +101,26 @@ func method(expected string, handler http.Handler) http.Handler {
 	})
 }
 
+// writeTimeout sets write deadline of request to timeout from now, replacing
+// the server-wide write timeout.
+func writeTimeout(timeout time.Duration, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = http.NewResponseController(w).SetWriteDeadline(time.Now().Add(timeout))
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// methods is method for path that accepts several methods.
+func methods(allowed []string, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, m := range allowed {
+			if r.Method == m {
+				handler.ServeHTTP(w, r)
+				return
+			}
+		}
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	})
+}
+
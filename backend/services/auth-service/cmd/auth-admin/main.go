@@ -0,0 +1,53 @@
+// Command auth-admin assigns role to an existing user, e.g. to provision
+// administrators, which public signup never creates:
+//
+//	auth-admin -email ops@example.com -role admin
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"drivepower/backend/services/auth-service/internal/config"
+	"drivepower/backend/services/auth-service/internal/db"
+	"drivepower/backend/services/auth-service/internal/models"
+	"drivepower/backend/services/auth-service/internal/repository"
+)
+
+func main() {
+	email := flag.String("email", "", "email of registered user")
+	role := flag.String("role", models.RoleAdmin, "role to assign (admin or user)")
+	flag.Parse()
+
+	if *email == "" {
+		fail("email is required")
+	}
+	if *role != models.RoleAdmin && *role != models.RoleUser {
+		fail("role must be admin or user")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fail(err.Error())
+	}
+	sqlDB, err := db.NewPostgres(cfg.Database.DSN)
+	if err != nil {
+		fail(err.Error())
+	}
+	defer sqlDB.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := repository.NewUserRepository(sqlDB).SetRole(ctx, *email, *role); err != nil {
+		fail(err.Error())
+	}
+	fmt.Printf("user %s now has role %s\n", *email, *role)
+}
+
+func fail(message string) {
+	fmt.Fprintln(os.Stderr, "auth-admin:", message)
+	os.Exit(1)
+}
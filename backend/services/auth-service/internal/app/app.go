@@ -33,11 +33,16 @@ func New(cfg *appconfig.Config, logger *zap.Logger) (*App, error) {
 	hasher := password.NewBcryptHasher(0)
 	tokenSvc := service.NewTokenService(cfg.JWT.Secret, cfg.JWTExpiration())
 	authSvc := service.NewAuthService(userRepo, hasher, tokenSvc, logger)
+	idTagRepo := repository.NewIdTagRepository(sqlDB)
+	idTagSvc := service.NewIdTagService(idTagRepo, logger)
 
 	routes := httpserver.Routes{
-		Signup: handlers.NewSignupHandler(authSvc),
-		Login:  handlers.NewLoginHandler(authSvc),
-		Health: handlers.NewHealthHandler(),
+		Signup:         handlers.NewSignupHandler(authSvc),
+		Login:          handlers.NewLoginHandler(authSvc),
+		RegisterIdTag:  handlers.NewRegisterIdTagHandler(idTagSvc),
+		IdTagsMe:       handlers.NewIdTagsMeHandler(idTagSvc),
+		AuthorizeIdTag: handlers.NewAuthorizeIdTagHandler(idTagSvc),
+		Health:         handlers.NewHealthHandler(),
 	}
 
 	router := httpserver.NewRouter(routes)
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"drivepower/backend/services/auth-service/internal/service"
+)
+
+const userIDHeader = "X-User-ID"
+
+// NewRegisterIdTagHandler handles POST /auth/id-tags.
+func NewRegisterIdTagHandler(svc *service.IdTagService) http.HandlerFunc {
+	type request struct {
+		IdTag string `json:"id_tag"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := userIDFromHeader(w, r)
+		if !ok {
+			return
+		}
+		var req request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid JSON body")
+			return
+		}
+
+		tag, err := svc.Register(r.Context(), userID, req.IdTag)
+		if err != nil {
+			switch {
+			case errors.Is(err, service.ErrIdTagInvalid):
+				writeError(w, http.StatusBadRequest, "id_tag must be 1-20 characters")
+			case errors.Is(err, service.ErrIdTagTaken):
+				writeError(w, http.StatusConflict, "id tag already registered")
+			default:
+				writeError(w, http.StatusInternalServerError, "failed to register id tag")
+			}
+			return
+		}
+		writeJSON(w, http.StatusCreated, tag)
+	}
+}
+
+// NewIdTagsMeHandler handles GET /auth/id-tags/me.
+func NewIdTagsMeHandler(svc *service.IdTagService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := userIDFromHeader(w, r)
+		if !ok {
+			return
+		}
+		tags, err := svc.ListForUser(r.Context(), userID)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "failed to load id tags")
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"id_tags": tags,
+		})
+	}
+}
+
+// NewAuthorizeIdTagHandler handles GET /internal/id-tags/{idTag} used by ocpp-server.
+func NewAuthorizeIdTagHandler(svc *service.IdTagService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		idTag := r.PathValue("idTag")
+		if idTag == "" {
+			writeError(w, http.StatusBadRequest, "id tag required")
+			return
+		}
+		info, err := svc.Authorize(r.Context(), idTag)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "failed to authorize id tag")
+			return
+		}
+		writeJSON(w, http.StatusOK, info)
+	}
+}
+
+func userIDFromHeader(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	userIDStr := r.Header.Get(userIDHeader)
+	if userIDStr == "" {
+		writeError(w, http.StatusUnauthorized, "missing user id header")
+		return 0, false
+	}
+	userID, err := strconv.ParseInt(userIDStr, 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid user id header")
+		return 0, false
+	}
+	return userID, true
+}
@@ -14,7 +14,6 @@ func NewSignupHandler(authService *service.AuthService) http.HandlerFunc {
 	type request struct {
 		Email    string `json:"email"`
 		Password string `json:"password"`
-		Role     string `json:"role"`
 	}
 	type response struct {
 		ID    int64  `json:"id"`
@@ -36,7 +35,7 @@ func NewSignupHandler(authService *service.AuthService) http.HandlerFunc {
 			return
 		}
 
-		user, err := authService.Signup(r.Context(), req.Email, req.Password, req.Role)
+		user, err := authService.Signup(r.Context(), req.Email, req.Password)
 		if err != nil {
 			switch {
 			case errors.Is(err, service.ErrEmailInUse):
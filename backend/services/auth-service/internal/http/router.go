@@ -4,9 +4,12 @@ import "net/http"
 
 // Routes aggregates handlers for HTTP server.
 type Routes struct {
-	Signup http.HandlerFunc
-	Login  http.HandlerFunc
-	Health http.HandlerFunc
+	Signup         http.HandlerFunc
+	Login          http.HandlerFunc
+	RegisterIdTag  http.HandlerFunc
+	IdTagsMe       http.HandlerFunc
+	AuthorizeIdTag http.HandlerFunc
+	Health         http.HandlerFunc
 }
 
 // NewRouter wires all HTTP routes.
@@ -18,6 +21,15 @@ func NewRouter(routes Routes) http.Handler {
 	if routes.Login != nil {
 		mux.Handle("/auth/login", method(http.MethodPost, routes.Login))
 	}
+	if routes.RegisterIdTag != nil {
+		mux.Handle("/auth/id-tags", method(http.MethodPost, routes.RegisterIdTag))
+	}
+	if routes.IdTagsMe != nil {
+		mux.Handle("/auth/id-tags/me", method(http.MethodGet, routes.IdTagsMe))
+	}
+	if routes.AuthorizeIdTag != nil {
+		mux.Handle("/internal/id-tags/{idTag}", method(http.MethodGet, routes.AuthorizeIdTag))
+	}
 	if routes.Health != nil {
 		mux.Handle("/health", method(http.MethodGet, routes.Health))
 	}
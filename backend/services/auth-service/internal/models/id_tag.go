@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// IdTag represents RFID card or app token bound to a user.
+type IdTag struct {
+	IdTag       string     `db:"id_tag" json:"id_tag"`
+	UserID      int64      `db:"user_id" json:"user_id"`
+	Status      string     `db:"status" json:"status"`
+	ExpiryDate  *time.Time `db:"expiry_date" json:"expiry_date,omitempty"`
+	ParentIdTag string     `db:"parent_id_tag" json:"parent_id_tag,omitempty"`
+	CreatedAt   time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time  `db:"updated_at" json:"updated_at"`
+}
@@ -2,6 +2,12 @@ package models
 
 import "time"
 
+// User roles. Signup creates users only; admins are assigned with auth-admin.
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
 // User represents a system account.
 type User struct {
 	ID           int64     `db:"id" json:"id"`
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"drivepower/backend/services/auth-service/internal/models"
+)
+
+// ErrIdTagNotFound represents unknown id tag.
+var ErrIdTagNotFound = errors.New("id tag not found")
+
+// IdTagRepository handles id_tags table.
+type IdTagRepository struct {
+	db *sql.DB
+}
+
+// NewIdTagRepository returns repository instance.
+func NewIdTagRepository(db *sql.DB) *IdTagRepository {
+	return &IdTagRepository{db: db}
+}
+
+// Upsert stores id tag or rebinds existing one.
+func (r *IdTagRepository) Upsert(ctx context.Context, tag *models.IdTag) error {
+	tag.IdTag = strings.TrimSpace(tag.IdTag)
+	const query = `
+		INSERT INTO id_tags (id_tag, user_id, status, expiry_date, parent_id_tag, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), NOW(), NOW())
+		ON CONFLICT (id_tag) DO UPDATE SET
+			user_id = EXCLUDED.user_id,
+			status = EXCLUDED.status,
+			expiry_date = EXCLUDED.expiry_date,
+			parent_id_tag = EXCLUDED.parent_id_tag,
+			updated_at = NOW()
+		RETURNING created_at, updated_at
+	`
+	return r.db.QueryRowContext(ctx, query,
+		tag.IdTag,
+		tag.UserID,
+		tag.Status,
+		tag.ExpiryDate,
+		tag.ParentIdTag,
+	).Scan(&tag.CreatedAt, &tag.UpdatedAt)
+}
+
+// GetByTag fetches id tag.
+func (r *IdTagRepository) GetByTag(ctx context.Context, idTag string) (*models.IdTag, error) {
+	const query = `
+		SELECT id_tag, user_id, status, expiry_date, COALESCE(parent_id_tag, ''), created_at, updated_at
+		FROM id_tags
+		WHERE id_tag = $1
+	`
+	var tag models.IdTag
+	err := r.db.QueryRowContext(ctx, query, strings.TrimSpace(idTag)).Scan(
+		&tag.IdTag,
+		&tag.UserID,
+		&tag.Status,
+		&tag.ExpiryDate,
+		&tag.ParentIdTag,
+		&tag.CreatedAt,
+		&tag.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrIdTagNotFound
+		}
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// ListByUser returns id tags bound to user.
+func (r *IdTagRepository) ListByUser(ctx context.Context, userID int64) ([]models.IdTag, error) {
+	const query = `
+		SELECT id_tag, user_id, status, expiry_date, COALESCE(parent_id_tag, ''), created_at, updated_at
+		FROM id_tags
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tags []models.IdTag
+	for rows.Next() {
+		var tag models.IdTag
+		if err := rows.Scan(
+			&tag.IdTag,
+			&tag.UserID,
+			&tag.Status,
+			&tag.ExpiryDate,
+			&tag.ParentIdTag,
+			&tag.CreatedAt,
+			&tag.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
@@ -34,6 +34,22 @@ func (r *UserRepository) Create(ctx context.Context, user *models.User) error {
 		Scan(&user.ID, &user.CreatedAt)
 }
 
+// SetRole changes role of user with email.
+func (r *UserRepository) SetRole(ctx context.Context, email, role string) error {
+	result, err := r.db.ExecContext(ctx, `UPDATE users SET role = $2 WHERE email = $1`, strings.ToLower(strings.TrimSpace(email)), role)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
 // GetByEmail fetches a user by email.
 func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
 	const query = `
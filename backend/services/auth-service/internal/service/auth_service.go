@@ -43,8 +43,8 @@ func NewAuthService(repo UserRepository, hasher password.Hasher, tokenizer *Toke
 	}
 }
 
-// Signup registers a new user.
-func (s *AuthService) Signup(ctx context.Context, email, password string, role string) (*models.User, error) {
+// Signup registers a new user with role user; role cannot be chosen at signup.
+func (s *AuthService) Signup(ctx context.Context, email, password string) (*models.User, error) {
 	email = strings.ToLower(strings.TrimSpace(email))
 	if email == "" {
 		return nil, errors.New("auth: email required")
@@ -52,10 +52,6 @@ func (s *AuthService) Signup(ctx context.Context, email, password string, role s
 	if password == "" {
 		return nil, errors.New("auth: password required")
 	}
-	if role == "" {
-		role = "user"
-	}
-
 	if _, err := s.repo.GetByEmail(ctx, email); err == nil {
 		return nil, ErrEmailInUse
 	} else if !errors.Is(err, repository.ErrUserNotFound) {
@@ -70,7 +66,7 @@ func (s *AuthService) Signup(ctx context.Context, email, password string, role s
 	user := &models.User{
 		Email:        email,
 		PasswordHash: hash,
-		Role:         role,
+		Role:         models.RoleUser,
 	}
 
 	if err := s.repo.Create(ctx, user); err != nil {
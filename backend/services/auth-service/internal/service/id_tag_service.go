@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"drivepower/backend/services/auth-service/internal/models"
+	"drivepower/backend/services/auth-service/internal/repository"
+)
+
+// OCPP authorization status values.
+const (
+	IdTagStatusAccepted = "Accepted"
+	IdTagStatusBlocked  = "Blocked"
+	IdTagStatusExpired  = "Expired"
+	IdTagStatusInvalid  = "Invalid"
+)
+
+var (
+	// ErrIdTagInvalid is returned for empty or too long id tags.
+	ErrIdTagInvalid = errors.New("auth: invalid id tag")
+	// ErrIdTagTaken is returned when id tag belongs to another user.
+	ErrIdTagTaken = errors.New("auth: id tag belongs to another user")
+)
+
+// maxIdTagLength is OCPP 1.6 IdToken (CiString20Type) limit.
+const maxIdTagLength = 20
+
+// IdTagRepository defines storage contract for id tags.
+type IdTagRepository interface {
+	Upsert(ctx context.Context, tag *models.IdTag) error
+	GetByTag(ctx context.Context, idTag string) (*models.IdTag, error)
+	ListByUser(ctx context.Context, userID int64) ([]models.IdTag, error)
+}
+
+// IdTagInfo is authorization decision for an id tag.
+type IdTagInfo struct {
+	IdTag       string     `json:"id_tag"`
+	UserID      int64      `json:"user_id"`
+	Status      string     `json:"status"`
+	ExpiryDate  *time.Time `json:"expiry_date,omitempty"`
+	ParentIdTag string     `json:"parent_id_tag,omitempty"`
+}
+
+// IdTagService manages RFID/app tokens of users.
+type IdTagService struct {
+	repo   IdTagRepository
+	logger *zap.Logger
+}
+
+// NewIdTagService builds IdTagService.
+func NewIdTagService(repo IdTagRepository, logger *zap.Logger) *IdTagService {
+	return &IdTagService{repo: repo, logger: logger}
+}
+
+// Register binds id tag to user.
+func (s *IdTagService) Register(ctx context.Context, userID int64, idTag string) (*models.IdTag, error) {
+	idTag = strings.TrimSpace(idTag)
+	if idTag == "" || len(idTag) > maxIdTagLength || userID == 0 {
+		return nil, ErrIdTagInvalid
+	}
+
+	existing, err := s.repo.GetByTag(ctx, idTag)
+	switch {
+	case err == nil && existing.UserID != userID:
+		return nil, ErrIdTagTaken
+	case err == nil:
+		return existing, nil
+	case !errors.Is(err, repository.ErrIdTagNotFound):
+		return nil, err
+	}
+
+	tag := &models.IdTag{
+		IdTag:  idTag,
+		UserID: userID,
+		Status: IdTagStatusAccepted,
+	}
+	if err := s.repo.Upsert(ctx, tag); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("id tag registered", zap.Int64("user_id", userID), zap.String("id_tag", idTag))
+	return tag, nil
+}
+
+// ListForUser returns id tags of user.
+func (s *IdTagService) ListForUser(ctx context.Context, userID int64) ([]models.IdTag, error) {
+	return s.repo.ListByUser(ctx, userID)
+}
+
+// Authorize resolves id tag into OCPP status and owner.
+func (s *IdTagService) Authorize(ctx context.Context, idTag string) (*IdTagInfo, error) {
+	tag, err := s.repo.GetByTag(ctx, idTag)
+	if err != nil {
+		if errors.Is(err, repository.ErrIdTagNotFound) {
+			return &IdTagInfo{IdTag: idTag, Status: IdTagStatusInvalid}, nil
+		}
+		return nil, err
+	}
+
+	info := &IdTagInfo{
+		IdTag:       tag.IdTag,
+		UserID:      tag.UserID,
+		Status:      tag.Status,
+		ExpiryDate:  tag.ExpiryDate,
+		ParentIdTag: tag.ParentIdTag,
+	}
+	if info.Status == IdTagStatusAccepted && tag.ExpiryDate != nil && tag.ExpiryDate.Before(time.Now()) {
+		info.Status = IdTagStatusExpired
+	}
+	return info, nil
+}
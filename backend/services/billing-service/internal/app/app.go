@@ -4,9 +4,14 @@ import (
 	"context"
 	"database/sql"
 
+	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 
+	"drivepower/backend/libs/events"
+	libredis "drivepower/backend/libs/redis"
+	"drivepower/backend/services/billing-service/internal/clients"
 	"drivepower/backend/services/billing-service/internal/config"
+	"drivepower/backend/services/billing-service/internal/consumer"
 	"drivepower/backend/services/billing-service/internal/db"
 	httpserver "drivepower/backend/services/billing-service/internal/http"
 	"drivepower/backend/services/billing-service/internal/http/handlers"
@@ -16,9 +21,11 @@ import (
 
 // App wires billing service dependencies.
 type App struct {
-	server *httpserver.Server
-	db     *sql.DB
-	logger *zap.Logger
+	server   *httpserver.Server
+	consumer *consumer.Consumer
+	db       *sql.DB
+	redis    *redis.Client
+	logger   *zap.Logger
 }
 
 // New constructs application graph.
@@ -31,12 +38,13 @@ func New(cfg *config.Config, logger *zap.Logger) (*App, error) {
 	txRepo := repository.NewTransactionRepository(sqlDB)
 	tariffRepo := repository.NewTariffRepository(sqlDB)
 	tariffService := service.NewTariffService(tariffRepo, 7.0) // default price
-	billingService := service.NewBillingService(txRepo, tariffService, logger)
+	billingService := service.NewBillingService(txRepo, tariffService, cfg.Reservations.NoShowFee, logger)
 
 	sessionStoppedHandler := handlers.NewOCPPStopHandler(billingService, logger)
 
 	routes := httpserver.Routes{
 		SessionStopped: sessionStoppedHandler,
+		NoShow:         handlers.NewNoShowHandler(billingService, logger),
 		TransactionsMe: handlers.NewTransactionsMeHandler(billingService),
 		Health:         handlers.NewHealthHandler(),
 	}
@@ -44,15 +52,38 @@ func New(cfg *config.Config, logger *zap.Logger) (*App, error) {
 	router := httpserver.NewRouter(routes)
 	server := httpserver.NewServer(cfg.HTTPAddress(), router, logger)
 
+	var (
+		redisClient   *redis.Client
+		eventConsumer *consumer.Consumer
+	)
+	if cfg.Events.Enabled {
+		redisClient, err = libredis.NewRedisClient(cfg.Redis.Addr, cfg.Redis.Password)
+		if err != nil {
+			sqlDB.Close()
+			return nil, err
+		}
+		sessionsClient := clients.NewSessionsClient(cfg.Services.SessionsURL)
+		eventConsumer = consumer.NewConsumer(events.NewRedisBus(redisClient, cfg.Events.Stream, logger), billingService, sessionsClient, logger)
+	}
+
 	return &App{
-		server: server,
-		db:     sqlDB,
-		logger: logger,
+		server:   server,
+		consumer: eventConsumer,
+		db:       sqlDB,
+		redis:    redisClient,
+		logger:   logger,
 	}, nil
 }
 
-// Run starts HTTP server.
+// Run starts event consumer (when enabled) and HTTP server.
 func (a *App) Run(ctx context.Context) error {
+	if a.consumer != nil {
+		go func() {
+			if err := a.consumer.Run(ctx); err != nil {
+				a.logger.Error("event consumer stopped", zap.Error(err))
+			}
+		}()
+	}
 	return a.server.Run(ctx)
 }
 
@@ -63,5 +94,10 @@ func (a *App) Close() {
 			a.logger.Warn("failed to close db", zap.Error(err))
 		}
 	}
+	if a.redis != nil {
+		if err := a.redis.Close(); err != nil {
+			a.logger.Warn("failed to close redis", zap.Error(err))
+		}
+	}
 }
 
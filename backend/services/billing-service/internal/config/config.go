@@ -16,6 +16,20 @@ type Config struct {
 	Database struct {
 		DSN string `yaml:"dsn" env:"BILLING_POSTGRES_DSN"`
 	} `yaml:"database"`
+	Redis struct {
+		Addr     string `yaml:"addr" env:"BILLING_REDIS_ADDR"`
+		Password string `yaml:"password" env:"BILLING_REDIS_PASSWORD"`
+	} `yaml:"redis"`
+	Events struct {
+		Enabled bool   `yaml:"enabled" env:"BILLING_EVENTS_ENABLED"`
+		Stream  string `yaml:"stream" env:"BILLING_EVENTS_STREAM"`
+	} `yaml:"events"`
+	Services struct {
+		SessionsURL string `yaml:"sessions_url" env:"SESSIONS_SERVICE_URL"`
+	} `yaml:"services"`
+	Reservations struct {
+		NoShowFee float64 `yaml:"no_show_fee" env:"BILLING_NO_SHOW_FEE"`
+	} `yaml:"reservations"`
 }
 
 // Load configuration from file/env.
@@ -35,6 +49,12 @@ func Load() (*Config, error) {
 	if strings.TrimSpace(cfg.Database.DSN) == "" {
 		return nil, errors.New("config: database dsn required")
 	}
+	if cfg.Events.Enabled && strings.TrimSpace(cfg.Redis.Addr) == "" {
+		return nil, errors.New("config: redis addr required when events are enabled")
+	}
+	if cfg.Events.Enabled && strings.TrimSpace(cfg.Services.SessionsURL) == "" {
+		return nil, errors.New("config: sessions url required when events are enabled")
+	}
 	return cfg, nil
 }
 
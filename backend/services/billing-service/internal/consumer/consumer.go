@@ -0,0 +1,93 @@
+// Package consumer subscribes billing-service to domain events of the charging network.
+package consumer
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"drivepower/backend/libs/events"
+	"drivepower/backend/services/billing-service/internal/clients"
+	"drivepower/backend/services/billing-service/internal/service"
+)
+
+// Group is consumer group of billing-service on the event bus.
+const Group = "billing-service"
+
+// Consumer bills finished transactions and reservation no-shows.
+type Consumer struct {
+	bus      events.Subscriber
+	service  *service.BillingService
+	sessions *clients.SessionsClient
+	logger   *zap.Logger
+}
+
+// NewConsumer builds consumer.
+func NewConsumer(bus events.Subscriber, service *service.BillingService, sessions *clients.SessionsClient, logger *zap.Logger) *Consumer {
+	return &Consumer{
+		bus:      bus,
+		service:  service,
+		sessions: sessions,
+		logger:   logger,
+	}
+}
+
+// Run consumes events until ctx is cancelled.
+func (c *Consumer) Run(ctx context.Context) error {
+	return c.bus.Subscribe(ctx, Group, c.handle, events.TransactionStopped, events.ReservationNoShow)
+}
+
+func (c *Consumer) handle(ctx context.Context, event events.Event) error {
+	if event.Type == events.ReservationNoShow {
+		return c.handleNoShow(ctx, event)
+	}
+	var data events.TransactionStoppedData
+	if err := event.Decode(&data); err != nil {
+		return err
+	}
+	sessionID, err := c.sessionID(ctx, event)
+	if err != nil {
+		return err
+	}
+	// Billing transaction is unique per session, so redelivery is harmless.
+	_, err = c.service.CalculateAndCreateTransaction(ctx, service.CreateTransactionInput{
+		SessionID: sessionID,
+		UserID:    data.UserID,
+		EnergyKWh: data.EnergyKWh,
+	})
+	return err
+}
+
+// handleNoShow charges no-show fee; fee is unique per reservation, so
+// redelivery and the HTTP callback for the same reservation are harmless.
+func (c *Consumer) handleNoShow(ctx context.Context, event events.Event) error {
+	var data events.ReservationNoShowData
+	if err := event.Decode(&data); err != nil {
+		return err
+	}
+	_, err := c.service.ChargeNoShow(ctx, service.NoShowInput{
+		ReservationID: data.ReservationID,
+		UserID:        data.UserID,
+	})
+	return err
+}
+
+// sessionID returns session of transaction event. Events published while the
+// session start was still in flight carry none, so it is looked up in
+// sessions-service; an error makes the bus retry the event.
+func (c *Consumer) sessionID(ctx context.Context, event events.Event) (int64, error) {
+	if event.SessionID != 0 {
+		return event.SessionID, nil
+	}
+	sessionID, err := c.sessions.SessionIDByTransaction(ctx, event.TransactionID)
+	if err != nil {
+		c.logger.Warn("failed to resolve session of event",
+			zap.String("event_id", event.ID),
+			zap.String("event_type", event.Type),
+			zap.String("transaction_id", event.TransactionID),
+			zap.Error(err),
+		)
+		return 0, err
+	}
+	return sessionID, nil
+}
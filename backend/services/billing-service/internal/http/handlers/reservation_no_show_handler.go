@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"drivepower/backend/services/billing-service/internal/service"
+)
+
+// NoShowHandler charges reservations that expired without transaction.
+type NoShowHandler struct {
+	service *service.BillingService
+	logger  *zap.Logger
+}
+
+// NewNoShowHandler builds handler.
+func NewNoShowHandler(service *service.BillingService, logger *zap.Logger) *NoShowHandler {
+	return &NoShowHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+type noShowRequest struct {
+	ReservationID int64  `json:"reservation_id"`
+	UserID        int64  `json:"user_id"`
+	StationID     string `json:"station_id"`
+}
+
+// ServeHTTP handles POST /internal/ocpp/reservation-no-show; answers 204 when
+// no-show fees are disabled.
+func (h *NoShowHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req noShowRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	if req.ReservationID == 0 {
+		writeError(w, http.StatusBadRequest, "reservation_id required")
+		return
+	}
+
+	tx, err := h.service.ChargeNoShow(r.Context(), service.NoShowInput{
+		ReservationID: req.ReservationID,
+		UserID:        req.UserID,
+	})
+	if err != nil {
+		h.logger.Error("failed to charge no-show fee", zap.Int64("reservation_id", req.ReservationID), zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "no-show charge failed")
+		return
+	}
+	if tx == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeJSON(w, http.StatusCreated, tx)
+}
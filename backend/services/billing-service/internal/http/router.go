@@ -5,6 +5,7 @@ import "net/http"
 // Routes groups HTTP handlers.
 type Routes struct {
 	SessionStopped http.Handler
+	NoShow         http.Handler
 	TransactionsMe http.HandlerFunc
 	Health         http.HandlerFunc
 }
@@ -15,6 +16,9 @@ func NewRouter(routes Routes) http.Handler {
 	if routes.SessionStopped != nil {
 		mux.Handle("/internal/ocpp/session-stopped", method(http.MethodPost, routes.SessionStopped.ServeHTTP))
 	}
+	if routes.NoShow != nil {
+		mux.Handle("/internal/ocpp/reservation-no-show", method(http.MethodPost, routes.NoShow.ServeHTTP))
+	}
 	if routes.TransactionsMe != nil {
 		mux.Handle("/billing/me/transactions", method(http.MethodGet, routes.TransactionsMe))
 	}
@@ -2,15 +2,23 @@ package models
 
 import "time"
 
-// Transaction represents billing entry for completed session.
+// Transaction kinds.
+const (
+	TransactionCharging = "charging"
+	TransactionNoShow   = "no_show"
+)
+
+// Transaction represents billing entry for completed session or for
+// reservation that expired without one.
 type Transaction struct {
-	ID          int64     `db:"id" json:"id"`
-	SessionID   int64     `db:"session_id" json:"session_id"`
-	UserID      int64     `db:"user_id" json:"user_id"`
-	EnergyKWh   float64   `db:"energy_kwh" json:"energy_kwh"`
-	PricePerKWh float64   `db:"price_per_kwh" json:"price_per_kwh"`
-	Amount      float64   `db:"amount" json:"amount"`
-	Status      string    `db:"status" json:"status"`
-	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+	ID            int64     `db:"id" json:"id"`
+	Kind          string    `db:"kind" json:"kind"`
+	SessionID     int64     `db:"session_id" json:"session_id"`
+	ReservationID int64     `db:"reservation_id" json:"reservation_id,omitempty"`
+	UserID        int64     `db:"user_id" json:"user_id"`
+	EnergyKWh     float64   `db:"energy_kwh" json:"energy_kwh"`
+	PricePerKWh   float64   `db:"price_per_kwh" json:"price_per_kwh"`
+	Amount        float64   `db:"amount" json:"amount"`
+	Status        string    `db:"status" json:"status"`
+	CreatedAt     time.Time `db:"created_at" json:"created_at"`
 }
-
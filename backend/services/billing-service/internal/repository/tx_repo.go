@@ -17,12 +17,14 @@ func NewTransactionRepository(db *sql.DB) *TransactionRepository {
 	return &TransactionRepository{db: db}
 }
 
-// Create inserts a new transaction.
+// Create inserts a new transaction. Repeated call for the same session keeps
+// the first entry and returns it.
 func (r *TransactionRepository) Create(ctx context.Context, tx *models.Transaction) error {
 	const query = `
 		INSERT INTO billing_transactions (session_id, user_id, energy_kwh, price_per_kwh, amount, status, created_at)
 		VALUES ($1, $2, $3, $4, $5, $6, NOW())
-		RETURNING id, created_at
+		ON CONFLICT (session_id) DO UPDATE SET session_id = billing_transactions.session_id
+		RETURNING id, kind, COALESCE(user_id, 0), energy_kwh, price_per_kwh, amount, status, created_at
 	`
 	return r.db.QueryRowContext(ctx, query,
 		tx.SessionID,
@@ -31,7 +33,25 @@ func (r *TransactionRepository) Create(ctx context.Context, tx *models.Transacti
 		tx.PricePerKWh,
 		tx.Amount,
 		tx.Status,
-	).Scan(&tx.ID, &tx.CreatedAt)
+	).Scan(&tx.ID, &tx.Kind, &tx.UserID, &tx.EnergyKWh, &tx.PricePerKWh, &tx.Amount, &tx.Status, &tx.CreatedAt)
+}
+
+// CreateNoShowFee inserts fee for reservation. Repeated call for the same
+// reservation keeps the first entry and returns it.
+func (r *TransactionRepository) CreateNoShowFee(ctx context.Context, tx *models.Transaction) error {
+	const query = `
+		INSERT INTO billing_transactions (kind, reservation_id, user_id, energy_kwh, price_per_kwh, amount, status, created_at)
+		VALUES ($1, $2, $3, 0, 0, $4, $5, NOW())
+		ON CONFLICT (reservation_id) DO UPDATE SET reservation_id = billing_transactions.reservation_id
+		RETURNING id, kind, COALESCE(user_id, 0), amount, status, created_at
+	`
+	return r.db.QueryRowContext(ctx, query,
+		models.TransactionNoShow,
+		tx.ReservationID,
+		tx.UserID,
+		tx.Amount,
+		tx.Status,
+	).Scan(&tx.ID, &tx.Kind, &tx.UserID, &tx.Amount, &tx.Status, &tx.CreatedAt)
 }
 
 // ListByUser returns latest transactions for user.
@@ -40,7 +60,7 @@ func (r *TransactionRepository) ListByUser(ctx context.Context, userID int64, li
 		limit = 50
 	}
 	const query = `
-		SELECT id, session_id, user_id, energy_kwh, price_per_kwh, amount, status, created_at
+		SELECT id, kind, COALESCE(session_id, 0), COALESCE(reservation_id, 0), user_id, energy_kwh, price_per_kwh, amount, status, created_at
 		FROM billing_transactions
 		WHERE user_id = $1
 		ORDER BY created_at DESC
@@ -57,7 +77,9 @@ func (r *TransactionRepository) ListByUser(ctx context.Context, userID int64, li
 		var tx models.Transaction
 		if err := rows.Scan(
 			&tx.ID,
+			&tx.Kind,
 			&tx.SessionID,
+			&tx.ReservationID,
 			&tx.UserID,
 			&tx.EnergyKWh,
 			&tx.PricePerKWh,
@@ -74,4 +96,3 @@ func (r *TransactionRepository) ListByUser(ctx context.Context, userID int64, li
 	}
 	return txs, nil
 }
-
@@ -14,14 +14,17 @@ import (
 type BillingService struct {
 	txRepo        *repository.TransactionRepository
 	tariffService *TariffService
+	noShowFee     float64
 	logger        *zap.Logger
 }
 
-// NewBillingService builds service.
-func NewBillingService(txRepo *repository.TransactionRepository, tariffSvc *TariffService, logger *zap.Logger) *BillingService {
+// NewBillingService builds service. Reservations that expire without
+// transaction are charged noShowFee; zero disables no-show fees.
+func NewBillingService(txRepo *repository.TransactionRepository, tariffSvc *TariffService, noShowFee float64, logger *zap.Logger) *BillingService {
 	return &BillingService{
 		txRepo:        txRepo,
 		tariffService: tariffSvc,
+		noShowFee:     noShowFee,
 		logger:        logger,
 	}
 }
@@ -70,6 +73,40 @@ func (s *BillingService) CalculateAndCreateTransaction(ctx context.Context, inpu
 	return tx, nil
 }
 
+// NoShowInput represents reservation that expired without transaction.
+type NoShowInput struct {
+	ReservationID int64
+	UserID        int64
+}
+
+// ChargeNoShow stores no-show fee for reservation. It returns nil transaction
+// when no-show fees are disabled.
+func (s *BillingService) ChargeNoShow(ctx context.Context, input NoShowInput) (*models.Transaction, error) {
+	if input.ReservationID == 0 {
+		return nil, errors.New("billing: reservation id required")
+	}
+	if s.noShowFee <= 0 {
+		return nil, nil
+	}
+
+	tx := &models.Transaction{
+		ReservationID: input.ReservationID,
+		UserID:        input.UserID,
+		Amount:        s.noShowFee,
+		Status:        "completed",
+	}
+	if err := s.txRepo.CreateNoShowFee(ctx, tx); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("no-show fee charged",
+		zap.Int64("reservation_id", input.ReservationID),
+		zap.Int64("user_id", input.UserID),
+		zap.Float64("amount", tx.Amount),
+	)
+	return tx, nil
+}
+
 // TransactionsForUser returns history for given user.
 func (s *BillingService) TransactionsForUser(ctx context.Context, userID int64, limit int) ([]models.Transaction, error) {
 	return s.txRepo.ListByUser(ctx, userID, limit)
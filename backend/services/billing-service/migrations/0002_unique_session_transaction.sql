@@ -0,0 +1,3 @@
+-- One billing transaction per session: OCPP server redelivers stop events after failures.
+DROP INDEX IF EXISTS idx_transactions_session_id;
+CREATE UNIQUE INDEX IF NOT EXISTS uq_transactions_session_id ON billing_transactions(session_id);
@@ -0,0 +1,8 @@
+-- No-show fees: charged for connector reservation that expired without
+-- transaction, so such entries have reservation instead of session.
+ALTER TABLE billing_transactions ALTER COLUMN session_id DROP NOT NULL;
+ALTER TABLE billing_transactions ADD COLUMN IF NOT EXISTS reservation_id BIGINT;
+ALTER TABLE billing_transactions ADD COLUMN IF NOT EXISTS kind TEXT NOT NULL DEFAULT 'charging';
+
+-- One fee per reservation: OCPP server redelivers no-show notifications after failures.
+CREATE UNIQUE INDEX IF NOT EXISTS uq_transactions_reservation_id ON billing_transactions(reservation_id);
@@ -2,89 +2,351 @@ package app
 
 import (
 	"context"
+	"crypto/tls"
 	"database/sql"
+	"fmt"
 	"net/http"
 	"time"
 
+	"github.com/gorilla/websocket"
+	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 
+	"drivepower/backend/libs/events"
+	libredis "drivepower/backend/libs/redis"
 	"drivepower/backend/services/ocpp-server/internal/clients"
+	"drivepower/backend/services/ocpp-server/internal/cluster"
 	"drivepower/backend/services/ocpp-server/internal/config"
 	"drivepower/backend/services/ocpp-server/internal/db"
 	"drivepower/backend/services/ocpp-server/internal/handlers"
+	httpserver "drivepower/backend/services/ocpp-server/internal/http"
+	apihandlers "drivepower/backend/services/ocpp-server/internal/http/handlers"
+	"drivepower/backend/services/ocpp-server/internal/models"
 	"drivepower/backend/services/ocpp-server/internal/ocpp"
 	"drivepower/backend/services/ocpp-server/internal/ocpp/protocol"
+	"drivepower/backend/services/ocpp-server/internal/ocpp/protocol/v201"
 	"drivepower/backend/services/ocpp-server/internal/repository"
+	"drivepower/backend/services/ocpp-server/internal/security"
 	"drivepower/backend/services/ocpp-server/internal/service"
 	"drivepower/backend/services/ocpp-server/internal/ws"
 )
 
 // App wires all dependencies for the OCPP server.
 type App struct {
-	httpServer *http.Server
-	db         *sql.DB
-	manager    *ws.Manager
-	logger     *zap.Logger
+	httpServer     *http.Server
+	tlsServer      *http.Server
+	internalServer *http.Server
+	db             *sql.DB
+	redis          *redis.Client
+	manager        *ws.Manager
+	node           *cluster.Node
+	liveness       *service.LivenessMonitor
+	stationEvents  *service.StationEvents
+	txStore        *service.TransactionStore
+	outbox         *service.Outbox
+	configSync     *service.ConfigSync
+	profiles       *service.ChargingProfiles
+	sitePower      *service.SitePowerManager
+	firmware       *service.FirmwareService
+	reservations   *service.ReservationService
+	messageLog     *service.MessageLog
+	drain          time.Duration
+	logger         *zap.Logger
 }
 
 // New builds the application graph.
 func New(cfg *config.Config, logger *zap.Logger) (*App, error) {
+	stationCreds := make(map[string]security.StationCredentials, len(cfg.Security.Stations))
+	for _, st := range cfg.Security.Stations {
+		stationCreds[st.ID] = security.StationCredentials{Profile: st.Profile, PasswordHash: st.PasswordHash}
+	}
+	authenticator, err := security.NewAuthenticator(cfg.Security.DefaultProfile, stationCreds, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	var tlsConfig *tls.Config
+	if cfg.TLSEnabled() {
+		var err error
+		tlsConfig, err = security.TLSConfig(cfg.Security.TLSCertFile, cfg.Security.TLSKeyFile, cfg.Security.ClientCAFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	sqlDB, err := db.NewPostgres(cfg.Database.DSN)
 	if err != nil {
 		return nil, err
 	}
 
 	stationRepo := repository.NewStationRepository(sqlDB)
-	logRepo := repository.NewOCPPLogRepository(sqlDB)
+	messageLog := service.NewMessageLog(repository.NewOCPPLogRepository(sqlDB), cfg.MessageLog.BufferSize, cfg.MessageLog.BatchSize, cfg.MessageLogFlushInterval(), cfg.MessageLog.RetentionDays, logger)
+	txIDRepo := repository.NewTransactionIDRepository(sqlDB)
 	stationState := service.NewStationState()
-	txStore := service.NewTransactionStore()
+	txStore := service.NewTransactionStore(repository.NewTransactionRepository(sqlDB), cfg.TransactionTTL(), cfg.Cluster.Enabled, logger)
 
 	sessionsClient := clients.NewSessionsClient(cfg.Services.SessionsURL, logger)
+
+	restoreCtx, cancelRestore := context.WithTimeout(context.Background(), 30*time.Second)
+	err = txStore.Restore(restoreCtx, sessionsClient)
+	cancelRestore()
+	if err != nil {
+		_ = sqlDB.Close()
+		return nil, fmt.Errorf("restore transactions: %w", err)
+	}
+
+	var (
+		redisClient *redis.Client
+		publisher   events.Publisher
+	)
+	if cfg.Events.Enabled || cfg.Cluster.Enabled {
+		redisClient, err = libredis.NewRedisClient(cfg.Redis.Addr, cfg.Redis.Password)
+		if err != nil {
+			_ = sqlDB.Close()
+			return nil, fmt.Errorf("connect redis: %w", err)
+		}
+	}
+	if cfg.Events.Enabled {
+		publisher = events.NewRedisBus(redisClient, cfg.Events.Stream, logger)
+	}
 	billingClient := clients.NewBillingClient(cfg.Services.BillingURL, logger)
 	telemetryClient := clients.NewTelemetryClient(cfg.Services.TelemetryURL, logger)
+	authClient := clients.NewAuthClient(cfg.Services.AuthURL, logger)
+	outbox := service.NewOutbox(repository.NewOutboxRepository(sqlDB), sessionsClient, billingClient, telemetryClient, publisher, txStore, cfg.Outbox.MaxAttempts, cfg.OutboxMaxBackoff(), logger)
+	authorizer := service.NewAuthorizer(authClient, txStore, logger)
+	liveness := service.NewLivenessMonitor(stationRepo, stationState, cfg.HeartbeatInterval(), cfg.Liveness.MissedHeartbeats, cfg.OfflineCheckInterval(), logger)
+	registry := service.NewStationRegistry(stationRepo, cfg.Registration.UnknownStationPolicy, cfg.HeartbeatInterval(), cfg.PendingRetryInterval(), logger)
+
+	manager := ws.NewManager(cfg.PingInterval(), logger)
+	stationEvents := service.NewStationEvents(outbox, logger)
+	manager.Subscribe(stationEvents.OnConnection)
+	liveness.Subscribe(stationEvents.OnAvailability)
 
-	router := ocpp.NewRouter()
 	parser := ocpp.NewParser()
-	processor := ocpp.NewProcessor(parser, router, logRepo, logger)
-
-	router.Register(protocol.ActionBootNotification, handlers.NewBootNotificationHandler(stationRepo, stationState, logger))
-	router.Register(protocol.ActionStatusNotification, handlers.NewStatusNotificationHandler(stationRepo, stationState, logger))
-	router.Register(protocol.ActionStartTransaction, handlers.NewStartTransactionHandler(sessionsClient, billingClient, stationState, txStore, logger))
-	router.Register(protocol.ActionStopTransaction, handlers.NewStopTransactionHandler(sessionsClient, billingClient, stationState, txStore, logger))
-	router.Register(protocol.ActionHeartbeat, handlers.NewHeartbeatHandler())
-	router.Register(protocol.ActionMeterValues, handlers.NewMeterValuesHandler(telemetryClient, txStore, logger))
-
-	manager := ws.NewManager(cfg.PingInterval())
-	wsServer := ws.NewServer(manager, processor, cfg.WriteTimeout(), logger)
-
-	mux := http.NewServeMux()
-	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		_, _ = w.Write([]byte(`{"status":"ok"}`))
+	caller := ocpp.NewCaller(manager, cfg.CallTimeout(), messageLog, logger)
+	manager.Subscribe(func(event ws.ConnectionEvent) {
+		logger.Info("station "+event.Type,
+			zap.String("station_id", event.StationID),
+			zap.String("remote_addr", event.RemoteAddr),
+			zap.String("subprotocol", event.Subprotocol),
+			zap.String("reason", event.Reason),
+		)
+		if event.Type == ws.EventDisconnected {
+			// A call sent over the dropped socket will never be answered.
+			caller.Abort(event.StationID)
+		}
+	})
+	registry.Subscribe(func(_ context.Context, event service.RegistrationEvent) {
+		if event.RegistrationStatus == models.RegistrationDecommissioned {
+			manager.Disconnect(event.StationID, websocket.ClosePolicyViolation, "station decommissioned")
+		}
+	})
+
+	// In a cluster commands reach stations connected to other replicas as well.
+	var (
+		node          *cluster.Node
+		stationCaller service.StationCaller = caller
+	)
+	if cfg.Cluster.Enabled {
+		node = cluster.NewNode(cfg.NodeID(), redisClient, cfg.ClusterLease(), cfg.CallTimeout(), manager, caller, txStore, registry, logger)
+		manager.Subscribe(node.OnConnection)
+		registry.Subscribe(node.RegistrationChanged)
+		stationCaller = node
+	}
+
+	commandService := service.NewCommandService(stationCaller, txStore, stationState, stationRepo, authorizer, repository.NewCommandAuditRepository(sqlDB), logger)
+	configSync := service.NewConfigSync(repository.NewConfigRepository(sqlDB), commandService, cfg.ConfigSyncDelay(), logger)
+	chargingProfiles := service.NewChargingProfiles(repository.NewChargingProfileRepository(sqlDB), commandService, txStore, cfg.ChargingProfileResyncDelay(), logger)
+	sitePower := service.NewSitePowerManager(repository.NewSiteRepository(sqlDB), commandService, stationState, cfg.LoadBalancingDebounce(), logger)
+	firmware := service.NewFirmwareService(repository.NewFirmwareRepository(sqlDB), commandService, cfg.Firmware.Dir, cfg.Firmware.PublicURL,
+		cfg.FirmwareMaxUpload(), cfg.FirmwareStationTimeout(), cfg.FirmwareCheckInterval(), logger)
+	diagnostics := service.NewDiagnosticsService(repository.NewDiagnosticsRepository(sqlDB), stationRepo, commandService, cfg.Diagnostics.Dir,
+		cfg.DiagnosticsPublicURL(), cfg.DiagnosticsMaxUpload(), logger)
+	reservations := service.NewReservationService(repository.NewReservationRepository(sqlDB), stationRepo, commandService, authorizer, outbox,
+		cfg.ReservationMaxDuration(), cfg.ReservationMaxAdvance(), cfg.ReservationCheckInterval(), logger)
+	bootObservers := []handlers.BootObserver{chargingProfiles, firmware}
+	if cfg.ConfigSync.OnBoot {
+		bootObservers = append(bootObservers, configSync)
+	}
+
+	ocppRouter, ocpp201Router := NewOCPPRouters(OCPPDeps{
+		Registry:     registry,
+		Stations:     stationRepo,
+		State:        stationState,
+		Liveness:     liveness,
+		Authorizer:   authorizer,
+		Sessions:     sessionsClient,
+		Outbox:       outbox,
+		TxIDs:        txIDRepo,
+		TxStore:      txStore,
+		Boot:         bootObservers,
+		Load:         sitePower,
+		Firmware:     firmware,
+		Diagnostics:  diagnostics,
+		Reservations: reservations,
+		Logger:       logger,
+	})
+
+	wsServer := ws.NewServer(manager, []ws.Subprotocol{
+		{Name: v201.Subprotocol, Processor: ocpp.NewProcessor(parser, ocpp201Router, caller, registry, liveness, messageLog, logger)},
+		{Name: protocol.Subprotocol, Processor: ocpp.NewProcessor(parser, ocppRouter, caller, registry, liveness, messageLog, logger)},
+	}, protocol.Subprotocol, authenticator, cfg.WriteTimeout(), logger)
+
+	commandsHandler := apihandlers.NewCommandsHandler(commandService, logger)
+	stationsHandler := apihandlers.NewStationsHandler(registry, liveness, logger)
+	outboxHandler := apihandlers.NewOutboxHandler(outbox, logger)
+	configHandler := apihandlers.NewConfigHandler(configSync, logger)
+	chargingProfilesHandler := apihandlers.NewChargingProfilesHandler(chargingProfiles, logger)
+	sitesHandler := apihandlers.NewSitesHandler(sitePower, logger)
+	messagesHandler := apihandlers.NewMessagesHandler(messageLog, logger)
+	firmwareHandler := apihandlers.NewFirmwareHandler(firmware, logger)
+	diagnosticsHandler := apihandlers.NewDiagnosticsHandler(diagnostics, logger)
+	reservationsHandler := apihandlers.NewReservationsHandler(reservations, logger)
+
+	// Station-facing listeners serve only what stations need: the WebSocket
+	// endpoint, firmware downloads and diagnostics uploads.
+	stationRoutes := httpserver.Routes{
+		Health:            apihandlers.NewHealthHandler(),
+		OCPP:              wsServer.HandleWS,
+		FirmwareDownload:  firmwareHandler.HandleDownload,
+		DiagnosticsUpload: diagnosticsHandler.HandleUpload,
+	}
+
+	// Internal API drives the whole CSMS and has no authentication of its
+	// own, so it listens on a separate port that must not be exposed.
+	internalRouter := httpserver.NewRouter(httpserver.Routes{
+		Health:      apihandlers.NewHealthHandler(),
+		RemoteStart: commandsHandler.HandleRemoteStart,
+		RemoteStop:  commandsHandler.HandleRemoteStop,
+
+		Reset:              commandsHandler.HandleReset,
+		ChangeAvailability: commandsHandler.HandleChangeAvailability,
+		UnlockConnector:    commandsHandler.HandleUnlockConnector,
+		TriggerMessage:     commandsHandler.HandleTriggerMessage,
+		ClearCache:         commandsHandler.HandleClearCache,
+		CommandAudit:       commandsHandler.HandleAudit,
+
+		ListStations:        stationsHandler.HandleList,
+		OfflineStations:     stationsHandler.HandleOffline,
+		ApproveStation:      stationsHandler.HandleApprove,
+		DecommissionStation: stationsHandler.HandleDecommission,
+
+		ListOutbox:      outboxHandler.HandleList,
+		ReplayOutbox:    outboxHandler.HandleReplay,
+		ReplayAllOutbox: outboxHandler.HandleReplayAll,
+
+		SearchMessages:  messagesHandler.HandleSearch,
+		MessageExchange: messagesHandler.HandleExchange,
+
+		GetDesiredConfig: configHandler.HandleGetDesired,
+		SetDesiredConfig: configHandler.HandleSetDesired,
+		SetConfigGroup:   configHandler.HandleSetGroup,
+		SyncConfig:       configHandler.HandleSync,
+		ConfigDrift:      configHandler.HandleDrift,
+
+		CreateChargingProfile: chargingProfilesHandler.HandleCreate,
+		ListChargingProfiles:  chargingProfilesHandler.HandleList,
+		GetChargingProfile:    chargingProfilesHandler.HandleGet,
+		UpdateChargingProfile: chargingProfilesHandler.HandleUpdate,
+		DeleteChargingProfile: chargingProfilesHandler.HandleDelete,
+		CompositeSchedule:     chargingProfilesHandler.HandleCompositeSchedule,
+
+		ListSites:   sitesHandler.HandleList,
+		GetSite:     sitesHandler.HandleGet,
+		SaveSite:    sitesHandler.HandleSave,
+		DeleteSite:  sitesHandler.HandleDelete,
+		SiteLoad:    sitesHandler.HandleLoad,
+		SetUserTier: sitesHandler.HandleSetUserTier,
+
+		CreateFirmwareArtifact: firmwareHandler.HandleCreateArtifact,
+		ListFirmwareArtifacts:  firmwareHandler.HandleListArtifacts,
+		CreateFirmwareCampaign: firmwareHandler.HandleCreateCampaign,
+		ListFirmwareCampaigns:  firmwareHandler.HandleListCampaigns,
+		FirmwareCampaign:       firmwareHandler.HandleCampaign,
+		PauseFirmwareCampaign:  firmwareHandler.HandlePause,
+		ResumeFirmwareCampaign: firmwareHandler.HandleResume,
+
+		RequestDiagnostics: diagnosticsHandler.HandleRequest,
+		ListDiagnostics:    diagnosticsHandler.HandleList,
+		GetDiagnostics:     diagnosticsHandler.HandleGet,
+		DiagnosticsFile:    diagnosticsHandler.HandleFile,
+
+		BookReservation:   reservationsHandler.HandleBook,
+		ListReservations:  reservationsHandler.HandleList,
+		GetReservation:    reservationsHandler.HandleGet,
+		CancelReservation: reservationsHandler.HandleCancel,
 	})
-	mux.HandleFunc("/ocpp/ws", wsServer.HandleWS)
 
 	httpServer := &http.Server{
 		Addr:         cfg.HTTPAddress(),
-		Handler:      mux,
+		Handler:      httpserver.NewRouter(stationRoutes),
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
 	}
+	internalServer := &http.Server{
+		Addr:         cfg.InternalAddress(),
+		Handler:      internalRouter,
+		ReadTimeout:  15 * time.Second,
+		WriteTimeout: cfg.CallTimeout() + 5*time.Second,
+		IdleTimeout:  60 * time.Second,
+	}
+
+	// TLS listener serves stations with security profiles 2 and 3.
+	var tlsServer *http.Server
+	if tlsConfig != nil {
+		tlsServer = &http.Server{
+			Addr:         cfg.TLSAddress(),
+			Handler:      httpserver.NewRouter(stationRoutes),
+			TLSConfig:    tlsConfig,
+			ReadTimeout:  15 * time.Second,
+			WriteTimeout: 15 * time.Second,
+			IdleTimeout:  60 * time.Second,
+		}
+	}
 
 	return &App{
-		httpServer: httpServer,
-		db:         sqlDB,
-		manager:    manager,
-		logger:     logger,
+		httpServer:     httpServer,
+		tlsServer:      tlsServer,
+		internalServer: internalServer,
+		db:             sqlDB,
+		redis:          redisClient,
+		manager:        manager,
+		node:           node,
+		liveness:       liveness,
+		stationEvents:  stationEvents,
+		txStore:        txStore,
+		outbox:         outbox,
+		configSync:     configSync,
+		profiles:       chargingProfiles,
+		sitePower:      sitePower,
+		firmware:       firmware,
+		reservations:   reservations,
+		messageLog:     messageLog,
+		drain:          cfg.DrainTimeout(),
+		logger:         logger,
 	}, nil
 }
 
-// Run starts manager and HTTP server.
+// Run starts manager, background monitors and HTTP server(s).
 func (a *App) Run(ctx context.Context) error {
-	errCh := make(chan error, 1)
+	errCh := make(chan error, 3)
 
 	go a.manager.Start(ctx)
+	go a.liveness.Start(ctx)
+	go a.stationEvents.Start(ctx)
+	go a.txStore.StartExpiry(ctx, a.outbox)
+	go a.outbox.Start(ctx)
+	go a.configSync.Start(ctx)
+	go a.profiles.Start(ctx)
+	go a.sitePower.Start(ctx)
+	go a.firmware.Start(ctx)
+	go a.reservations.Start(ctx)
+	go a.messageLog.Start(ctx)
+	go a.messageLog.StartMaintenance(ctx)
+	if a.node != nil {
+		go a.node.Start(ctx)
+	}
 
 	go func() {
 		a.logger.Info("starting ocpp http server", zap.String("addr", a.httpServer.Addr))
@@ -95,11 +357,23 @@ func (a *App) Run(ctx context.Context) error {
 		errCh <- nil
 	}()
 
+	go func() {
+		a.logger.Info("starting internal api server", zap.String("addr", a.internalServer.Addr))
+		errCh <- a.internalServer.ListenAndServe()
+	}()
+
+	if a.tlsServer != nil {
+		go func() {
+			a.logger.Info("starting ocpp tls server", zap.String("addr", a.tlsServer.Addr))
+			errCh <- a.tlsServer.ListenAndServeTLS("", "")
+		}()
+	}
+
 	select {
 	case <-ctx.Done():
-		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), a.drain)
 		defer cancel()
-		return a.httpServer.Shutdown(shutdownCtx)
+		return a.shutdown(shutdownCtx)
 	case err := <-errCh:
 		if err == http.ErrServerClosed {
 			return nil
@@ -108,6 +382,40 @@ func (a *App) Run(ctx context.Context) error {
 	}
 }
 
+// shutdown drains stations before stopping HTTP servers: hijacked WebSocket
+// connections are not tracked by http.Server, so messages being processed are
+// let finish and stations are asked to reconnect before notifications queued
+// for them are flushed.
+func (a *App) shutdown(ctx context.Context) error {
+	start := time.Now()
+	a.logger.Info("shutdown started", zap.Duration("timeout", a.drain))
+	if err := a.manager.Drain(ctx); err != nil {
+		a.logger.Warn("drain incomplete", zap.Error(err))
+	}
+	if a.node != nil {
+		a.node.Leave(ctx)
+	}
+	if err := a.manager.CloseAll(ctx, websocket.CloseServiceRestart, "service restart"); err != nil {
+		a.logger.Warn("failed to close all station connections", zap.Error(err))
+	}
+
+	if a.tlsServer != nil {
+		if err := a.tlsServer.Shutdown(ctx); err != nil {
+			a.logger.Warn("failed to shutdown tls server", zap.Error(err))
+		}
+	}
+	err := a.httpServer.Shutdown(ctx)
+	if internalErr := a.internalServer.Shutdown(ctx); internalErr != nil {
+		a.logger.Warn("failed to shutdown internal api server", zap.Error(internalErr))
+	}
+
+	a.stationEvents.Flush(ctx)
+	a.outbox.Flush(ctx)
+	a.messageLog.Flush(ctx)
+	a.logger.Info("shutdown finished", zap.Duration("took", time.Since(start)))
+	return err
+}
+
 // Close releases resources.
 func (a *App) Close() {
 	if a.db != nil {
@@ -115,4 +423,9 @@ func (a *App) Close() {
 			a.logger.Warn("failed to close db", zap.Error(err))
 		}
 	}
+	if a.redis != nil {
+		if err := a.redis.Close(); err != nil {
+			a.logger.Warn("failed to close redis", zap.Error(err))
+		}
+	}
 }
@@ -0,0 +1,62 @@
+package app
+
+import (
+	"go.uber.org/zap"
+
+	"drivepower/backend/services/ocpp-server/internal/clients"
+	"drivepower/backend/services/ocpp-server/internal/handlers"
+	handlers201 "drivepower/backend/services/ocpp-server/internal/handlers/v201"
+	"drivepower/backend/services/ocpp-server/internal/ocpp"
+	"drivepower/backend/services/ocpp-server/internal/ocpp/protocol"
+	"drivepower/backend/services/ocpp-server/internal/ocpp/protocol/v201"
+	"drivepower/backend/services/ocpp-server/internal/service"
+)
+
+// OCPPDeps are dependencies of station-initiated message handlers. Shared by
+// the server and the replay tool, which wires them to in-memory fakes.
+type OCPPDeps struct {
+	Registry   *service.StationRegistry
+	Stations   service.StationBackend
+	State      *service.StationState
+	Liveness   *service.LivenessMonitor
+	Authorizer *service.Authorizer
+	Sessions   *clients.SessionsClient
+	Outbox     *service.Outbox
+	TxIDs      handlers.TransactionIDs
+	TxStore    *service.TransactionStore
+	// Boot are notified about OCPP 1.6 stations accepted by BootNotification; optional.
+	Boot []handlers.BootObserver
+	// Load is notified about transactions and measured current of stations; optional.
+	Load handlers.LoadObserver
+	// Firmware records FirmwareStatusNotification of OCPP 1.6 stations; optional.
+	Firmware handlers.FirmwareObserver
+	// Diagnostics records DiagnosticsStatusNotification and LogStatusNotification; optional.
+	Diagnostics handlers.DiagnosticsObserver
+	// Reservations are consumed by StartTransaction of OCPP 1.6 stations; optional.
+	Reservations handlers.ReservationObserver
+	Logger       *zap.Logger
+}
+
+// NewOCPPRouters registers handlers of OCPP 1.6 and 2.0.1 actions.
+func NewOCPPRouters(d OCPPDeps) (ocpp16, ocpp201 *ocpp.Router) {
+	ocpp16 = ocpp.NewRouter(ocpp.Spec{Actions: protocol.StationActions, ErrorCodes: protocol.ErrorCodes})
+	ocpp16.Register(protocol.ActionBootNotification, handlers.NewBootNotificationHandler(d.Registry, d.State, d.Outbox, d.Boot, d.Logger))
+	ocpp16.Register(protocol.ActionStatusNotification, handlers.NewStatusNotificationHandler(d.Stations, d.State, d.Outbox, d.Logger))
+	ocpp16.Register(protocol.ActionStartTransaction, handlers.NewStartTransactionHandler(d.Sessions, d.Outbox, d.Authorizer, d.TxIDs, d.State, d.TxStore, d.Load, d.Reservations, d.Logger))
+	ocpp16.Register(protocol.ActionStopTransaction, handlers.NewStopTransactionHandler(d.Outbox, d.Authorizer, d.State, d.TxStore, d.Load, d.Logger))
+	ocpp16.Register(protocol.ActionAuthorize, handlers.NewAuthorizeHandler(d.Authorizer, d.Logger))
+	ocpp16.Register(protocol.ActionHeartbeat, handlers.NewHeartbeatHandler(d.Liveness))
+	ocpp16.Register(protocol.ActionMeterValues, handlers.NewMeterValuesHandler(d.Outbox, d.TxStore, d.Load, d.Logger))
+	ocpp16.Register(protocol.ActionFirmwareStatusNotification, handlers.NewFirmwareStatusNotificationHandler(d.Firmware, d.Logger))
+	ocpp16.Register(protocol.ActionDiagnosticsStatusNotification, handlers.NewDiagnosticsStatusNotificationHandler(d.Diagnostics, d.Logger))
+
+	ocpp201 = ocpp.NewRouter(ocpp.Spec{Actions: v201.StationActions, ErrorCodes: v201.ErrorCodes})
+	ocpp201.Register(v201.ActionBootNotification, handlers201.NewBootNotificationHandler(d.Registry, d.State, d.Outbox, d.Logger))
+	ocpp201.Register(v201.ActionStatusNotification, handlers201.NewStatusNotificationHandler(d.Stations, d.State, d.Outbox, d.Logger))
+	ocpp201.Register(v201.ActionHeartbeat, handlers201.NewHeartbeatHandler(d.Liveness))
+	ocpp201.Register(v201.ActionAuthorize, handlers201.NewAuthorizeHandler(d.Authorizer, d.Logger))
+	ocpp201.Register(v201.ActionTransactionEvent, handlers201.NewTransactionEventHandler(d.Sessions, d.Outbox, d.Authorizer, d.State, d.TxStore, d.Load, d.Logger))
+	ocpp201.Register(v201.ActionMeterValues, handlers201.NewMeterValuesHandler(d.Outbox, d.TxStore, d.Load, d.Logger))
+	ocpp201.Register(v201.ActionLogStatusNotification, handlers201.NewLogStatusNotificationHandler(d.Diagnostics, d.Logger))
+	return ocpp16, ocpp201
+}
@@ -0,0 +1,71 @@
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// AuthClient resolves id tags through auth-service registry.
+type AuthClient struct {
+	baseURL string
+	client  *http.Client
+	logger  *zap.Logger
+}
+
+// IdTagAuthorization mirrors auth-service id tag decision.
+type IdTagAuthorization struct {
+	IdTag       string     `json:"id_tag"`
+	UserID      int64      `json:"user_id"`
+	Status      string     `json:"status"`
+	ExpiryDate  *time.Time `json:"expiry_date,omitempty"`
+	ParentIdTag string     `json:"parent_id_tag,omitempty"`
+}
+
+// NewAuthClient builds HTTP client wrapper.
+func NewAuthClient(baseURL string, logger *zap.Logger) *AuthClient {
+	return &AuthClient{
+		baseURL: baseURL,
+		client: &http.Client{
+			Timeout: 5 * time.Second,
+		},
+		logger: logger,
+	}
+}
+
+// Enabled reports whether registry lookups are configured.
+func (c *AuthClient) Enabled() bool {
+	return c.baseURL != ""
+}
+
+// Authorize looks up id tag in auth-service.
+func (c *AuthClient) Authorize(ctx context.Context, idTag string) (*IdTagAuthorization, error) {
+	endpoint := fmt.Sprintf("%s/internal/id-tags/%s", c.baseURL, url.PathEscape(idTag))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		c.logger.Warn("auth client request failed", zap.Error(err))
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		c.logger.Warn("auth client returned non-success", zap.Int("status", resp.StatusCode))
+		return nil, fmt.Errorf("auth id tag lookup non-success status %d", resp.StatusCode)
+	}
+
+	var result IdTagAuthorization
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
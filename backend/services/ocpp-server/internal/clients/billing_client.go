@@ -25,6 +25,13 @@ type BillingStopRequest struct {
 	EnergyKWh float64 `json:"energy_kwh"`
 }
 
+// BillingNoShowRequest payload for reservation that expired without transaction.
+type BillingNoShowRequest struct {
+	ReservationID int64  `json:"reservation_id"`
+	UserID        int64  `json:"user_id"`
+	StationID     string `json:"station_id"`
+}
+
 // NewBillingClient returns HTTP client wrapper.
 func NewBillingClient(baseURL string, logger *zap.Logger) *BillingClient {
 	return &BillingClient{
@@ -36,6 +43,11 @@ func NewBillingClient(baseURL string, logger *zap.Logger) *BillingClient {
 	}
 }
 
+// Enabled reports whether billing-service URL is configured.
+func (c *BillingClient) Enabled() bool {
+	return c.baseURL != ""
+}
+
 // NotifySessionStop best-effort call.
 func (c *BillingClient) NotifySessionStop(ctx context.Context, req BillingStopRequest) error {
 	if c.baseURL == "" {
@@ -45,6 +57,16 @@ func (c *BillingClient) NotifySessionStop(ctx context.Context, req BillingStopRe
 	return c.post(ctx, "/internal/ocpp/session-stopped", req)
 }
 
+// NotifyReservationNoShow best-effort call; billing-service decides whether
+// no-show is charged.
+func (c *BillingClient) NotifyReservationNoShow(ctx context.Context, req BillingNoShowRequest) error {
+	if c.baseURL == "" {
+		c.logger.Debug("billing client disabled, skip no-show notification")
+		return nil
+	}
+	return c.post(ctx, "/internal/ocpp/reservation-no-show", req)
+}
+
 func (c *BillingClient) post(ctx context.Context, path string, body interface{}) error {
 	data, err := json.Marshal(body)
 	if err != nil {
@@ -64,6 +86,7 @@ func (c *BillingClient) post(ctx context.Context, path string, body interface{})
 
 	if resp.StatusCode >= 300 {
 		c.logger.Warn("billing client returned non-success", zap.Int("status", resp.StatusCode))
+		return fmt.Errorf("billing non-success status %d", resp.StatusCode)
 	}
 	return nil
 }
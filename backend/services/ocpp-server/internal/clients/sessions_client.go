@@ -24,6 +24,7 @@ type StartSessionRequest struct {
 	ConnectorID  int    `json:"connector_id"`
 	TransactionID string `json:"transaction_id"`
 	MeterStart   int64  `json:"meter_start"`
+	UserID       int64  `json:"user_id,omitempty"`
 }
 
 // StopSessionRequest minimal payload when transaction ends.
@@ -35,6 +36,17 @@ type StopSessionRequest struct {
 	EndTime       time.Time `json:"end_time"`
 }
 
+// ActiveSession is running session known to sessions-service.
+type ActiveSession struct {
+	ID            int64     `json:"id"`
+	UserID        int64     `json:"user_id"`
+	StationID     string    `json:"station_id"`
+	ConnectorID   int       `json:"connector_id"`
+	MeterStart    int64     `json:"meter_start"`
+	TransactionID string    `json:"transaction_id"`
+	StartTime     time.Time `json:"start_time"`
+}
+
 // NewSessionsClient builds HTTP client wrapper.
 func NewSessionsClient(baseURL string, logger *zap.Logger) *SessionsClient {
 	return &SessionsClient{
@@ -46,6 +58,11 @@ func NewSessionsClient(baseURL string, logger *zap.Logger) *SessionsClient {
 	}
 }
 
+// Enabled reports whether sessions-service URL is configured.
+func (c *SessionsClient) Enabled() bool {
+	return c.baseURL != ""
+}
+
 // CreateFromOCPP notifies about session start (best-effort).
 func (c *SessionsClient) CreateFromOCPP(ctx context.Context, req StartSessionRequest) (int64, error) {
 	if c.baseURL == "" {
@@ -64,6 +81,36 @@ func (c *SessionsClient) CompleteFromOCPP(ctx context.Context, req StopSessionRe
 	return c.post(ctx, "/internal/ocpp/session-stop", req)
 }
 
+// ListActive returns sessions that are still running.
+func (c *SessionsClient) ListActive(ctx context.Context) ([]ActiveSession, error) {
+	if c.baseURL == "" {
+		return nil, nil
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s%s", c.baseURL, "/internal/ocpp/active-sessions"), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		c.logger.Warn("sessions client request failed", zap.Error(err))
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("sessions active list non-success status %d", resp.StatusCode)
+	}
+
+	var payload struct {
+		Sessions []ActiveSession `json:"sessions"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return nil, err
+	}
+	return payload.Sessions, nil
+}
+
 func (c *SessionsClient) post(ctx context.Context, path string, body interface{}) error {
 	data, err := json.Marshal(body)
 	if err != nil {
@@ -84,6 +131,7 @@ func (c *SessionsClient) post(ctx context.Context, path string, body interface{}
 
 	if resp.StatusCode >= 300 {
 		c.logger.Warn("sessions client returned non-success", zap.Int("status", resp.StatusCode))
+		return fmt.Errorf("sessions non-success status %d", resp.StatusCode)
 	}
 	return nil
 }
@@ -39,6 +39,11 @@ func NewTelemetryClient(baseURL string, logger *zap.Logger) *TelemetryClient {
 	}
 }
 
+// Enabled reports whether telemetry-service URL is configured.
+func (c *TelemetryClient) Enabled() bool {
+	return c.baseURL != ""
+}
+
 // NotifyMeterValue sends meter value to telemetry-service.
 func (c *TelemetryClient) NotifyMeterValue(ctx context.Context, req MeterValueRequest) error {
 	if c.baseURL == "" {
@@ -64,6 +69,7 @@ func (c *TelemetryClient) NotifyMeterValue(ctx context.Context, req MeterValueRe
 
 	if resp.StatusCode >= 300 {
 		c.logger.Warn("telemetry client returned non-success", zap.Int("status", resp.StatusCode))
+		return fmt.Errorf("telemetry non-success status %d", resp.StatusCode)
 	}
 	return nil
 }
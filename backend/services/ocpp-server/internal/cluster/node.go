@@ -0,0 +1,306 @@
+// Package cluster lets several ocpp-server replicas share the station fleet:
+// station ownership is leased in Redis, commands for a station connected to
+// another replica are forwarded to it over pub/sub and registration changes
+// are broadcast to all replicas.
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"drivepower/backend/services/ocpp-server/internal/models"
+	"drivepower/backend/services/ocpp-server/internal/ocpp"
+	"drivepower/backend/services/ocpp-server/internal/service"
+	"drivepower/backend/services/ocpp-server/internal/ws"
+)
+
+const (
+	ownershipQueue = 4096
+	rebalanceBatch = 50
+	rebalancePause = 200 * time.Millisecond
+)
+
+// claimScript refreshes lease held by node or takes over expired one; returns
+// 0 when station is owned by another node.
+var claimScript = redis.NewScript(`
+local owner = redis.call('GET', KEYS[1])
+if owner == false or owner == ARGV[1] then
+	redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+	return 1
+end
+return 0
+`)
+
+// releaseScript deletes lease only when it belongs to node.
+var releaseScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// Node is this replica as cluster member. It leases ownership of locally
+// connected stations, serves commands forwarded by other replicas and forwards
+// commands for stations it does not hold.
+type Node struct {
+	id          string
+	client      *redis.Client
+	lease       time.Duration
+	callTimeout time.Duration
+	manager     *ws.Manager
+	caller      *ocpp.Caller
+	txStore     *service.TransactionStore
+	registry    *service.StationRegistry
+	logger      *zap.Logger
+
+	changes chan ws.ConnectionEvent
+	leaving atomic.Bool
+
+	mu      sync.Mutex
+	waiting map[string]chan envelope
+}
+
+// NewNode builds cluster member with given ID. Ownership lease is refreshed
+// every third of lease; callTimeout is how long station may take to answer.
+func NewNode(
+	id string,
+	client *redis.Client,
+	lease time.Duration,
+	callTimeout time.Duration,
+	manager *ws.Manager,
+	caller *ocpp.Caller,
+	txStore *service.TransactionStore,
+	registry *service.StationRegistry,
+	logger *zap.Logger,
+) *Node {
+	if lease <= 0 {
+		lease = 30 * time.Second
+	}
+	return &Node{
+		id:          id,
+		client:      client,
+		lease:       lease,
+		callTimeout: callTimeout,
+		manager:     manager,
+		caller:      caller,
+		txStore:     txStore,
+		registry:    registry,
+		logger:      logger.With(zap.String("node_id", id)),
+		changes:     make(chan ws.ConnectionEvent, ownershipQueue),
+		waiting:     make(map[string]chan envelope),
+	}
+}
+
+// ID returns node identifier.
+func (n *Node) ID() string {
+	return n.id
+}
+
+// OnConnection is ws.ConnectionListener; ownership changes are applied in order
+// by a background worker so the connection path never waits for Redis.
+func (n *Node) OnConnection(event ws.ConnectionEvent) {
+	if n.leaving.Load() {
+		return
+	}
+	select {
+	case n.changes <- event:
+	default:
+		// Lease refresh reclaims connected stations, lost releases expire.
+		n.logger.Warn("ownership queue full, change dropped", zap.String("station_id", event.StationID), zap.String("event", event.Type))
+	}
+}
+
+// RegistrationChanged is StationRegistry listener telling other replicas to
+// drop cached registration status of station; decommissioned station is
+// disconnected by the replica holding it.
+func (n *Node) RegistrationChanged(ctx context.Context, event service.RegistrationEvent) {
+	n.publishTo(ctx, registryChannel, envelope{Kind: kindRegistry, From: n.id, StationID: event.StationID, Status: event.RegistrationStatus, At: time.Now()})
+}
+
+// Start serves commands of other nodes and keeps leases until ctx is cancelled.
+func (n *Node) Start(ctx context.Context) {
+	pubsub := n.client.Subscribe(ctx, nodeChannel(n.id), registryChannel)
+	defer pubsub.Close()
+	messages := pubsub.Channel()
+
+	ticker := time.NewTicker(n.lease / 3)
+	defer ticker.Stop()
+
+	n.logger.Info("cluster node started", zap.Duration("lease", n.lease))
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-n.changes:
+			n.applyChange(ctx, event)
+		case <-ticker.C:
+			n.refresh(ctx)
+		case msg, ok := <-messages:
+			if !ok {
+				return
+			}
+			n.receive(ctx, msg.Payload)
+		}
+	}
+}
+
+// Leave hands stations over to other replicas on shutdown: leases are
+// released and stations are asked in batches to reconnect ("service restart"),
+// so the load balancer spreads them over remaining nodes without a reconnect storm.
+func (n *Node) Leave(ctx context.Context) {
+	n.leaving.Store(true)
+	stations := n.manager.Stations()
+	if len(stations) == 0 {
+		return
+	}
+	n.logger.Info("rebalancing stations to other nodes", zap.Int("stations", len(stations)))
+
+	pipe := n.client.Pipeline()
+	for _, id := range stations {
+		releaseScript.Eval(ctx, pipe, []string{ownerKey(id)}, n.id)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		n.logger.Warn("failed to release station leases", zap.Error(err))
+	}
+
+	for i, id := range stations {
+		n.manager.Disconnect(id, websocket.CloseServiceRestart, "node shutting down")
+		if (i+1)%rebalanceBatch == 0 && i+1 < len(stations) {
+			select {
+			case <-ctx.Done():
+				n.logger.Warn("rebalancing interrupted", zap.Int("disconnected", i+1), zap.Int("stations", len(stations)))
+				return
+			case <-time.After(rebalancePause):
+			}
+		}
+	}
+	n.logger.Info("stations released", zap.Int("stations", len(stations)))
+}
+
+// Owner returns node holding station, empty when station is not connected anywhere.
+func (n *Node) Owner(ctx context.Context, stationID string) (string, error) {
+	owner, err := n.client.Get(ctx, ownerKey(stationID)).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	return owner, err
+}
+
+func (n *Node) applyChange(ctx context.Context, event ws.ConnectionEvent) {
+	switch {
+	case event.Type == ws.EventConnected:
+		n.claim(ctx, event.StationID, event.At)
+	case event.Type == ws.EventDisconnected && event.Reason != ws.ReasonReplaced:
+		if err := releaseScript.Run(ctx, n.client, []string{ownerKey(event.StationID)}, n.id).Err(); err != nil {
+			n.logger.Warn("failed to release station lease", zap.String("station_id", event.StationID), zap.Error(err))
+		}
+		// Transactions stay persisted; the next owner loads them.
+		n.txStore.EvictStation(event.StationID)
+	}
+}
+
+// claim takes station lease and tells previous owner to drop its stale socket.
+func (n *Node) claim(ctx context.Context, stationID string, connectedAt time.Time) {
+	previous, err := n.client.SetArgs(ctx, ownerKey(stationID), n.id, redis.SetArgs{TTL: n.lease, Get: true}).Result()
+	if err != nil && err != redis.Nil {
+		n.logger.Warn("failed to claim station", zap.String("station_id", stationID), zap.Error(err))
+	}
+	if previous != "" && previous != n.id {
+		n.logger.Info("station moved from another node", zap.String("station_id", stationID), zap.String("previous_node", previous))
+		n.publish(ctx, previous, envelope{Kind: kindDisconnect, From: n.id, StationID: stationID, At: connectedAt})
+	}
+	if err := n.txStore.LoadStation(ctx, stationID); err != nil {
+		n.logger.Warn("failed to load station transactions", zap.String("station_id", stationID), zap.Error(err))
+	}
+}
+
+// refresh extends leases of local stations and closes sockets of stations
+// that meanwhile connected to another node.
+func (n *Node) refresh(ctx context.Context) {
+	stations := n.manager.Stations()
+	if len(stations) == 0 {
+		return
+	}
+	pipe := n.client.Pipeline()
+	results := make([]*redis.Cmd, len(stations))
+	for i, id := range stations {
+		results[i] = claimScript.Eval(ctx, pipe, []string{ownerKey(id)}, n.id, n.lease.Milliseconds())
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		n.logger.Warn("failed to refresh station leases", zap.Error(err))
+		return
+	}
+	for i, res := range results {
+		if owned, err := res.Int(); err == nil && owned == 0 {
+			n.logger.Info("station owned by another node, closing local connection", zap.String("station_id", stations[i]))
+			n.manager.Disconnect(stations[i], websocket.ClosePolicyViolation, "connected to another node")
+		}
+	}
+}
+
+func (n *Node) receive(ctx context.Context, payload string) {
+	var env envelope
+	if err := json.Unmarshal([]byte(payload), &env); err != nil {
+		n.logger.Warn("malformed cluster message", zap.Error(err))
+		return
+	}
+	switch env.Kind {
+	case kindCall:
+		go n.serve(ctx, env)
+	case kindReply:
+		n.mu.Lock()
+		wait, ok := n.waiting[env.ID]
+		n.mu.Unlock()
+		if ok {
+			wait <- env
+		}
+	case kindDisconnect:
+		// Station may have come back here after the other node took it.
+		if conn, ok := n.manager.Get(env.StationID); ok && conn.ConnectedAt().Before(env.At) {
+			n.manager.Disconnect(env.StationID, websocket.ClosePolicyViolation, "replaced by connection on another node")
+		}
+	case kindRegistry:
+		if env.From == n.id {
+			return
+		}
+		n.registry.Forget(env.StationID)
+		if env.Status == models.RegistrationDecommissioned {
+			n.manager.Disconnect(env.StationID, websocket.ClosePolicyViolation, "station decommissioned")
+		}
+	}
+}
+
+func (n *Node) publish(ctx context.Context, nodeID string, env envelope) (int64, error) {
+	return n.publishTo(ctx, nodeChannel(nodeID), env)
+}
+
+func (n *Node) publishTo(ctx context.Context, channel string, env envelope) (int64, error) {
+	data, err := json.Marshal(env)
+	if err != nil {
+		return 0, err
+	}
+	receivers, err := n.client.Publish(ctx, channel, data).Result()
+	if err != nil {
+		n.logger.Warn("failed to publish cluster message", zap.String("channel", channel), zap.String("kind", env.Kind), zap.Error(err))
+	}
+	return receivers, err
+}
+
+func ownerKey(stationID string) string {
+	return fmt.Sprintf("ocpp:station:%s:owner", stationID)
+}
+
+// registryChannel carries registration changes to all nodes.
+const registryChannel = "ocpp:registry"
+
+func nodeChannel(nodeID string) string {
+	return fmt.Sprintf("ocpp:node:%s", nodeID)
+}
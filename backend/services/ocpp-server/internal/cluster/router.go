@@ -0,0 +1,177 @@
+package cluster
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"drivepower/backend/services/ocpp-server/internal/ocpp"
+	"drivepower/backend/services/ocpp-server/internal/ws"
+)
+
+// Cluster message kinds.
+const (
+	kindCall       = "call"
+	kindReply      = "reply"
+	kindDisconnect = "disconnect"
+	kindRegistry   = "registry"
+)
+
+// Failure kinds of forwarded call, mapped back to local errors.
+const (
+	failureNotConnected = "not_connected"
+	failureTimeout      = "timeout"
+	failureCallError    = "call_error"
+	failureOther        = "failed"
+)
+
+// replyMargin is added to call timeout to cover the pub/sub round trip.
+const replyMargin = 5 * time.Second
+
+// envelope is message exchanged between nodes over their pub/sub channels.
+type envelope struct {
+	Kind      string          `json:"kind"`
+	ID        string          `json:"id,omitempty"`
+	From      string          `json:"from"`
+	StationID string          `json:"station_id"`
+	Action    string          `json:"action,omitempty"`
+	Payload   json.RawMessage `json:"payload,omitempty"`
+	Failure   *failure        `json:"failure,omitempty"`
+	Status    string          `json:"status,omitempty"`
+	At        time.Time       `json:"at,omitempty"`
+}
+
+type failure struct {
+	Kind        string `json:"kind"`
+	Code        string `json:"code,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// Call sends action to station wherever in the cluster it is connected. Local
+// stations are called directly, others through their owner node.
+func (n *Node) Call(ctx context.Context, stationID, action string, request, response interface{}) error {
+	if _, ok := n.manager.Get(stationID); ok {
+		return n.caller.Call(ctx, stationID, action, request, response)
+	}
+
+	owner, err := n.Owner(ctx, stationID)
+	if err != nil {
+		return fmt.Errorf("cluster: lookup owner: %w", err)
+	}
+	if owner == "" || owner == n.id {
+		return ws.ErrStationNotConnected
+	}
+
+	payload, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	id, err := newRequestID()
+	if err != nil {
+		return err
+	}
+
+	wait := make(chan envelope, 1)
+	n.mu.Lock()
+	n.waiting[id] = wait
+	n.mu.Unlock()
+	defer func() {
+		n.mu.Lock()
+		delete(n.waiting, id)
+		n.mu.Unlock()
+	}()
+
+	receivers, err := n.publish(ctx, owner, envelope{
+		Kind:      kindCall,
+		ID:        id,
+		From:      n.id,
+		StationID: stationID,
+		Action:    action,
+		Payload:   payload,
+	})
+	if err != nil {
+		return err
+	}
+	if receivers == 0 {
+		// Owner is gone and its lease has not expired yet.
+		return ws.ErrStationNotConnected
+	}
+
+	timer := time.NewTimer(n.callTimeout + replyMargin)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		n.logger.Warn("forwarded call not answered", zap.String("station_id", stationID), zap.String("owner", owner), zap.String("action", action))
+		return ocpp.ErrCallTimeout
+	case reply := <-wait:
+		if reply.Failure != nil {
+			return reply.Failure.err()
+		}
+		if response == nil {
+			return nil
+		}
+		if err := json.Unmarshal(reply.Payload, response); err != nil {
+			return fmt.Errorf("cluster: decode %s response: %w", action, err)
+		}
+		return nil
+	}
+}
+
+// serve executes call forwarded by another node and sends back the answer.
+func (n *Node) serve(ctx context.Context, req envelope) {
+	var result json.RawMessage
+	err := n.caller.Call(ctx, req.StationID, req.Action, req.Payload, &result)
+
+	reply := envelope{Kind: kindReply, ID: req.ID, From: n.id, StationID: req.StationID, Action: req.Action}
+	if err != nil {
+		reply.Failure = failureOf(err)
+	} else {
+		reply.Payload = result
+	}
+	if _, err := n.publish(ctx, req.From, reply); err == nil {
+		n.logger.Debug("forwarded call served", zap.String("station_id", req.StationID), zap.String("from_node", req.From), zap.String("action", req.Action))
+	}
+}
+
+func failureOf(err error) *failure {
+	var callErr *ocpp.CallError
+	switch {
+	case errors.Is(err, ws.ErrStationNotConnected), errors.Is(err, ocpp.ErrCallAborted):
+		return &failure{Kind: failureNotConnected}
+	case errors.Is(err, ocpp.ErrCallTimeout):
+		return &failure{Kind: failureTimeout}
+	case errors.As(err, &callErr):
+		return &failure{Kind: failureCallError, Code: callErr.Code, Description: callErr.Description}
+	default:
+		return &failure{Kind: failureOther, Description: err.Error()}
+	}
+}
+
+func (f *failure) err() error {
+	switch f.Kind {
+	case failureNotConnected:
+		return ws.ErrStationNotConnected
+	case failureTimeout:
+		return ocpp.ErrCallTimeout
+	case failureCallError:
+		return &ocpp.CallError{Code: f.Code, Description: f.Description}
+	default:
+		return fmt.Errorf("cluster: remote call failed: %s", f.Description)
+	}
+}
+
+func newRequestID() (string, error) {
+	var b [12]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b[:]), nil
+}
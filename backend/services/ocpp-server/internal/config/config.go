@@ -3,6 +3,7 @@ package config
 import (
 	"errors"
 	"fmt"
+	"os"
 	"strings"
 	"time"
 
@@ -12,7 +13,8 @@ import (
 // Config defines OCPP server configuration.
 type Config struct {
 	HTTP struct {
-		Port string `yaml:"port" env:"OCPP_HTTP_PORT"`
+		Port         string `yaml:"port" env:"OCPP_HTTP_PORT"`
+		InternalPort string `yaml:"internalPort" env:"OCPP_INTERNAL_HTTP_PORT"`
 	} `yaml:"http"`
 	Database struct {
 		DSN string `yaml:"dsn" env:"OCPP_POSTGRES_DSN"`
@@ -21,27 +23,205 @@ type Config struct {
 		SessionsURL string `yaml:"sessionsUrl" env:"SESSIONS_SERVICE_URL"`
 		BillingURL  string `yaml:"billingUrl" env:"BILLING_SERVICE_URL"`
 		TelemetryURL string `yaml:"telemetryUrl" env:"TELEMETRY_SERVICE_URL"`
+		AuthURL      string `yaml:"authUrl" env:"AUTH_SERVICE_URL"`
 	} `yaml:"services"`
 	WebSocket struct {
 		PingIntervalSeconds int `yaml:"pingIntervalSeconds" env:"OCPP_PING_INTERVAL"`
 		WriteTimeoutSeconds int `yaml:"writeTimeoutSeconds" env:"OCPP_WRITE_TIMEOUT"`
+		CallTimeoutSeconds  int `yaml:"callTimeoutSeconds" env:"OCPP_CALL_TIMEOUT"`
+		DrainTimeoutSeconds int `yaml:"drainTimeoutSeconds" env:"OCPP_DRAIN_TIMEOUT"`
 	} `yaml:"websocket"`
+	Registration struct {
+		UnknownStationPolicy     string `yaml:"unknownStationPolicy" env:"OCPP_UNKNOWN_STATION_POLICY"`
+		HeartbeatIntervalSeconds int    `yaml:"heartbeatIntervalSeconds" env:"OCPP_HEARTBEAT_INTERVAL"`
+		PendingRetrySeconds      int    `yaml:"pendingRetrySeconds" env:"OCPP_PENDING_RETRY_INTERVAL"`
+	} `yaml:"registration"`
+	Liveness struct {
+		MissedHeartbeats     int `yaml:"missedHeartbeats" env:"OCPP_OFFLINE_MISSED_HEARTBEATS"`
+		CheckIntervalSeconds int `yaml:"checkIntervalSeconds" env:"OCPP_OFFLINE_CHECK_INTERVAL"`
+	} `yaml:"liveness"`
+	Transactions struct {
+		TTLHours int `yaml:"ttlHours" env:"OCPP_TRANSACTION_TTL_HOURS"`
+	} `yaml:"transactions"`
+	Outbox struct {
+		MaxAttempts       int `yaml:"maxAttempts" env:"OCPP_OUTBOX_MAX_ATTEMPTS"`
+		MaxBackoffSeconds int `yaml:"maxBackoffSeconds" env:"OCPP_OUTBOX_MAX_BACKOFF"`
+	} `yaml:"outbox"`
+	MessageLog struct {
+		BufferSize      int `yaml:"bufferSize" env:"OCPP_MESSAGE_LOG_BUFFER"`
+		BatchSize       int `yaml:"batchSize" env:"OCPP_MESSAGE_LOG_BATCH"`
+		FlushIntervalMs int `yaml:"flushIntervalMs" env:"OCPP_MESSAGE_LOG_FLUSH_MS"`
+		RetentionDays   int `yaml:"retentionDays" env:"OCPP_MESSAGE_LOG_RETENTION_DAYS"`
+	} `yaml:"messageLog"`
+	ConfigSync struct {
+		OnBoot       bool `yaml:"onBoot" env:"OCPP_CONFIG_SYNC_ON_BOOT"`
+		DelaySeconds int  `yaml:"delaySeconds" env:"OCPP_CONFIG_SYNC_DELAY"`
+	} `yaml:"configSync"`
+	SmartCharging struct {
+		ResyncDelaySeconds int `yaml:"resyncDelaySeconds" env:"OCPP_SMART_CHARGING_RESYNC_DELAY"`
+	} `yaml:"smartCharging"`
+	LoadBalancing struct {
+		DebounceSeconds int `yaml:"debounceSeconds" env:"OCPP_LOAD_BALANCING_DEBOUNCE"`
+	} `yaml:"loadBalancing"`
+	Firmware struct {
+		Dir                   string `yaml:"dir" env:"OCPP_FIRMWARE_DIR"`
+		PublicURL             string `yaml:"publicUrl" env:"OCPP_FIRMWARE_PUBLIC_URL"`
+		MaxUploadMB           int    `yaml:"maxUploadMb" env:"OCPP_FIRMWARE_MAX_UPLOAD_MB"`
+		StationTimeoutMinutes int    `yaml:"stationTimeoutMinutes" env:"OCPP_FIRMWARE_STATION_TIMEOUT"`
+		CheckIntervalSeconds  int    `yaml:"checkIntervalSeconds" env:"OCPP_FIRMWARE_CHECK_INTERVAL"`
+	} `yaml:"firmware"`
+	Diagnostics struct {
+		Dir         string `yaml:"dir" env:"OCPP_DIAGNOSTICS_DIR"`
+		PublicURL   string `yaml:"publicUrl" env:"OCPP_DIAGNOSTICS_PUBLIC_URL"`
+		MaxUploadMB int    `yaml:"maxUploadMb" env:"OCPP_DIAGNOSTICS_MAX_UPLOAD_MB"`
+	} `yaml:"diagnostics"`
+	Reservations struct {
+		MaxDurationMinutes   int `yaml:"maxDurationMinutes" env:"OCPP_RESERVATION_MAX_DURATION"`
+		MaxAdvanceHours      int `yaml:"maxAdvanceHours" env:"OCPP_RESERVATION_MAX_ADVANCE"`
+		CheckIntervalSeconds int `yaml:"checkIntervalSeconds" env:"OCPP_RESERVATION_CHECK_INTERVAL"`
+	} `yaml:"reservations"`
+	Redis struct {
+		Addr     string `yaml:"addr" env:"OCPP_REDIS_ADDR"`
+		Password string `yaml:"password" env:"OCPP_REDIS_PASSWORD"`
+	} `yaml:"redis"`
+	Events struct {
+		Enabled bool   `yaml:"enabled" env:"OCPP_EVENTS_ENABLED"`
+		Stream  string `yaml:"stream" env:"OCPP_EVENTS_STREAM"`
+	} `yaml:"events"`
+	Cluster struct {
+		Enabled      bool   `yaml:"enabled" env:"OCPP_CLUSTER_ENABLED"`
+		NodeID       string `yaml:"nodeId" env:"OCPP_NODE_ID"`
+		LeaseSeconds int    `yaml:"leaseSeconds" env:"OCPP_CLUSTER_LEASE"`
+	} `yaml:"cluster"`
+	Security struct {
+		DefaultProfile int               `yaml:"defaultProfile" env:"OCPP_SECURITY_PROFILE"`
+		TLSPort        string            `yaml:"tlsPort" env:"OCPP_TLS_PORT"`
+		TLSCertFile    string            `yaml:"tlsCertFile" env:"OCPP_TLS_CERT_FILE"`
+		TLSKeyFile     string            `yaml:"tlsKeyFile" env:"OCPP_TLS_KEY_FILE"`
+		ClientCAFile   string            `yaml:"clientCaFile" env:"OCPP_TLS_CLIENT_CA_FILE"`
+		Stations       []StationSecurity `yaml:"stations" env:"-"`
+	} `yaml:"security"`
+}
+
+// StationSecurity overrides security profile for single station.
+type StationSecurity struct {
+	ID           string `yaml:"id"`
+	Profile      int    `yaml:"profile"`
+	PasswordHash string `yaml:"passwordHash"` // bcrypt hash of Basic auth password
 }
 
 // Load uses shared config loader and validates required fields.
 func Load() (*Config, error) {
 	cfg := &Config{
 		HTTP: struct {
-			Port string `yaml:"port" env:"OCPP_HTTP_PORT"`
+			Port         string `yaml:"port" env:"OCPP_HTTP_PORT"`
+			InternalPort string `yaml:"internalPort" env:"OCPP_INTERNAL_HTTP_PORT"`
 		}{
-			Port: "8081",
+			Port:         "8081",
+			InternalPort: "8091",
 		},
 		WebSocket: struct {
 			PingIntervalSeconds int `yaml:"pingIntervalSeconds" env:"OCPP_PING_INTERVAL"`
 			WriteTimeoutSeconds int `yaml:"writeTimeoutSeconds" env:"OCPP_WRITE_TIMEOUT"`
+			CallTimeoutSeconds  int `yaml:"callTimeoutSeconds" env:"OCPP_CALL_TIMEOUT"`
+			DrainTimeoutSeconds int `yaml:"drainTimeoutSeconds" env:"OCPP_DRAIN_TIMEOUT"`
 		}{
 			PingIntervalSeconds: 30,
 			WriteTimeoutSeconds: 15,
+			CallTimeoutSeconds:  30,
+			DrainTimeoutSeconds: 30,
+		},
+		Registration: struct {
+			UnknownStationPolicy     string `yaml:"unknownStationPolicy" env:"OCPP_UNKNOWN_STATION_POLICY"`
+			HeartbeatIntervalSeconds int    `yaml:"heartbeatIntervalSeconds" env:"OCPP_HEARTBEAT_INTERVAL"`
+			PendingRetrySeconds      int    `yaml:"pendingRetrySeconds" env:"OCPP_PENDING_RETRY_INTERVAL"`
+		}{
+			UnknownStationPolicy:     "pending",
+			HeartbeatIntervalSeconds: 300,
+			PendingRetrySeconds:      60,
+		},
+		Liveness: struct {
+			MissedHeartbeats     int `yaml:"missedHeartbeats" env:"OCPP_OFFLINE_MISSED_HEARTBEATS"`
+			CheckIntervalSeconds int `yaml:"checkIntervalSeconds" env:"OCPP_OFFLINE_CHECK_INTERVAL"`
+		}{
+			MissedHeartbeats:     3,
+			CheckIntervalSeconds: 30,
+		},
+		Transactions: struct {
+			TTLHours int `yaml:"ttlHours" env:"OCPP_TRANSACTION_TTL_HOURS"`
+		}{
+			TTLHours: 72,
+		},
+		Cluster: struct {
+			Enabled      bool   `yaml:"enabled" env:"OCPP_CLUSTER_ENABLED"`
+			NodeID       string `yaml:"nodeId" env:"OCPP_NODE_ID"`
+			LeaseSeconds int    `yaml:"leaseSeconds" env:"OCPP_CLUSTER_LEASE"`
+		}{
+			LeaseSeconds: 30,
+		},
+		MessageLog: struct {
+			BufferSize      int `yaml:"bufferSize" env:"OCPP_MESSAGE_LOG_BUFFER"`
+			BatchSize       int `yaml:"batchSize" env:"OCPP_MESSAGE_LOG_BATCH"`
+			FlushIntervalMs int `yaml:"flushIntervalMs" env:"OCPP_MESSAGE_LOG_FLUSH_MS"`
+			RetentionDays   int `yaml:"retentionDays" env:"OCPP_MESSAGE_LOG_RETENTION_DAYS"`
+		}{
+			BufferSize:      10000,
+			BatchSize:       500,
+			FlushIntervalMs: 500,
+			RetentionDays:   30,
+		},
+		ConfigSync: struct {
+			OnBoot       bool `yaml:"onBoot" env:"OCPP_CONFIG_SYNC_ON_BOOT"`
+			DelaySeconds int  `yaml:"delaySeconds" env:"OCPP_CONFIG_SYNC_DELAY"`
+		}{
+			OnBoot:       true,
+			DelaySeconds: 5,
+		},
+		SmartCharging: struct {
+			ResyncDelaySeconds int `yaml:"resyncDelaySeconds" env:"OCPP_SMART_CHARGING_RESYNC_DELAY"`
+		}{
+			ResyncDelaySeconds: 5,
+		},
+		LoadBalancing: struct {
+			DebounceSeconds int `yaml:"debounceSeconds" env:"OCPP_LOAD_BALANCING_DEBOUNCE"`
+		}{
+			DebounceSeconds: 2,
+		},
+		Firmware: struct {
+			Dir                   string `yaml:"dir" env:"OCPP_FIRMWARE_DIR"`
+			PublicURL             string `yaml:"publicUrl" env:"OCPP_FIRMWARE_PUBLIC_URL"`
+			MaxUploadMB           int    `yaml:"maxUploadMb" env:"OCPP_FIRMWARE_MAX_UPLOAD_MB"`
+			StationTimeoutMinutes int    `yaml:"stationTimeoutMinutes" env:"OCPP_FIRMWARE_STATION_TIMEOUT"`
+			CheckIntervalSeconds  int    `yaml:"checkIntervalSeconds" env:"OCPP_FIRMWARE_CHECK_INTERVAL"`
+		}{
+			Dir:                   "data/firmware",
+			MaxUploadMB:           200,
+			StationTimeoutMinutes: 60,
+			CheckIntervalSeconds:  15,
+		},
+		Diagnostics: struct {
+			Dir         string `yaml:"dir" env:"OCPP_DIAGNOSTICS_DIR"`
+			PublicURL   string `yaml:"publicUrl" env:"OCPP_DIAGNOSTICS_PUBLIC_URL"`
+			MaxUploadMB int    `yaml:"maxUploadMb" env:"OCPP_DIAGNOSTICS_MAX_UPLOAD_MB"`
+		}{
+			Dir:         "data/diagnostics",
+			MaxUploadMB: 100,
+		},
+		Reservations: struct {
+			MaxDurationMinutes   int `yaml:"maxDurationMinutes" env:"OCPP_RESERVATION_MAX_DURATION"`
+			MaxAdvanceHours      int `yaml:"maxAdvanceHours" env:"OCPP_RESERVATION_MAX_ADVANCE"`
+			CheckIntervalSeconds int `yaml:"checkIntervalSeconds" env:"OCPP_RESERVATION_CHECK_INTERVAL"`
+		}{
+			MaxDurationMinutes:   120,
+			MaxAdvanceHours:      168,
+			CheckIntervalSeconds: 15,
+		},
+		Outbox: struct {
+			MaxAttempts       int `yaml:"maxAttempts" env:"OCPP_OUTBOX_MAX_ATTEMPTS"`
+			MaxBackoffSeconds int `yaml:"maxBackoffSeconds" env:"OCPP_OUTBOX_MAX_BACKOFF"`
+		}{
+			MaxAttempts:       12,
+			MaxBackoffSeconds: 600,
 		},
 	}
 
@@ -53,6 +233,24 @@ func Load() (*Config, error) {
 		return nil, errors.New("config: database DSN is required")
 	}
 
+	if (cfg.Events.Enabled || cfg.Cluster.Enabled) && strings.TrimSpace(cfg.Redis.Addr) == "" {
+		return nil, errors.New("config: redis addr is required when events or cluster are enabled")
+	}
+
+	if cfg.InternalAddress() == cfg.HTTPAddress() || cfg.InternalAddress() == cfg.TLSAddress() {
+		return nil, errors.New("config: internal API port must differ from station ports")
+	}
+
+	if (cfg.Security.TLSCertFile == "") != (cfg.Security.TLSKeyFile == "") {
+		return nil, errors.New("config: both TLS cert and key files are required")
+	}
+
+	switch cfg.Registration.UnknownStationPolicy {
+	case "reject", "pending", "accept":
+	default:
+		return nil, fmt.Errorf("config: unknown station policy %q (want reject, pending or accept)", cfg.Registration.UnknownStationPolicy)
+	}
+
 	return cfg, nil
 }
 
@@ -68,6 +266,19 @@ func (c *Config) HTTPAddress() string {
 	return fmt.Sprintf(":%s", port)
 }
 
+// InternalAddress returns address of the internal API listener. Port alone
+// listens on all interfaces; host:port (e.g. 127.0.0.1:8091) binds one.
+func (c *Config) InternalAddress() string {
+	port := strings.TrimSpace(c.HTTP.InternalPort)
+	if port == "" {
+		port = "8091"
+	}
+	if strings.Contains(port, ":") {
+		return port
+	}
+	return fmt.Sprintf(":%s", port)
+}
+
 // PingInterval returns websocket ping interval.
 func (c *Config) PingInterval() time.Duration {
 	if c.WebSocket.PingIntervalSeconds <= 0 {
@@ -83,3 +294,196 @@ func (c *Config) WriteTimeout() time.Duration {
 	}
 	return time.Duration(c.WebSocket.WriteTimeoutSeconds) * time.Second
 }
+
+// CallTimeout returns how long CSMS-initiated calls wait for station reply.
+func (c *Config) CallTimeout() time.Duration {
+	if c.WebSocket.CallTimeoutSeconds <= 0 {
+		return 30 * time.Second
+	}
+	return time.Duration(c.WebSocket.CallTimeoutSeconds) * time.Second
+}
+
+// DrainTimeout returns how long shutdown may take to drain stations and flush the outbox.
+func (c *Config) DrainTimeout() time.Duration {
+	if c.WebSocket.DrainTimeoutSeconds <= 0 {
+		return 30 * time.Second
+	}
+	return time.Duration(c.WebSocket.DrainTimeoutSeconds) * time.Second
+}
+
+// MessageLogFlushInterval returns how often buffered OCPP log entries are written.
+func (c *Config) MessageLogFlushInterval() time.Duration {
+	if c.MessageLog.FlushIntervalMs <= 0 {
+		return 500 * time.Millisecond
+	}
+	return time.Duration(c.MessageLog.FlushIntervalMs) * time.Millisecond
+}
+
+// HeartbeatInterval returns default heartbeat interval sent in BootNotification.
+func (c *Config) HeartbeatInterval() time.Duration {
+	if c.Registration.HeartbeatIntervalSeconds <= 0 {
+		return 300 * time.Second
+	}
+	return time.Duration(c.Registration.HeartbeatIntervalSeconds) * time.Second
+}
+
+// PendingRetryInterval returns BootNotification retry interval for pending/rejected stations.
+func (c *Config) PendingRetryInterval() time.Duration {
+	if c.Registration.PendingRetrySeconds <= 0 {
+		return 60 * time.Second
+	}
+	return time.Duration(c.Registration.PendingRetrySeconds) * time.Second
+}
+
+// OfflineCheckInterval returns how often silent stations are looked for.
+func (c *Config) OfflineCheckInterval() time.Duration {
+	if c.Liveness.CheckIntervalSeconds <= 0 {
+		return 30 * time.Second
+	}
+	return time.Duration(c.Liveness.CheckIntervalSeconds) * time.Second
+}
+
+// TransactionTTL returns idle time after which ongoing transaction is considered abandoned.
+func (c *Config) TransactionTTL() time.Duration {
+	if c.Transactions.TTLHours <= 0 {
+		return 72 * time.Hour
+	}
+	return time.Duration(c.Transactions.TTLHours) * time.Hour
+}
+
+// OutboxMaxBackoff returns upper bound of delay between delivery attempts.
+func (c *Config) OutboxMaxBackoff() time.Duration {
+	if c.Outbox.MaxBackoffSeconds <= 0 {
+		return 10 * time.Minute
+	}
+	return time.Duration(c.Outbox.MaxBackoffSeconds) * time.Second
+}
+
+// ConfigSyncDelay returns pause between BootNotification and configuration sync.
+func (c *Config) ConfigSyncDelay() time.Duration {
+	if c.ConfigSync.DelaySeconds < 0 {
+		return 0
+	}
+	return time.Duration(c.ConfigSync.DelaySeconds) * time.Second
+}
+
+// ChargingProfileResyncDelay returns pause between BootNotification and
+// delivery of charging profiles the station has not installed yet.
+func (c *Config) ChargingProfileResyncDelay() time.Duration {
+	if c.SmartCharging.ResyncDelaySeconds < 0 {
+		return 0
+	}
+	return time.Duration(c.SmartCharging.ResyncDelaySeconds) * time.Second
+}
+
+// LoadBalancingDebounce returns pause before site limits are recalculated, so
+// that station receives StartTransaction answer first and bursts are merged.
+func (c *Config) LoadBalancingDebounce() time.Duration {
+	if c.LoadBalancing.DebounceSeconds < 0 {
+		return 0
+	}
+	return time.Duration(c.LoadBalancing.DebounceSeconds) * time.Second
+}
+
+// FirmwareMaxUpload returns size limit of uploaded firmware image in bytes.
+func (c *Config) FirmwareMaxUpload() int64 {
+	if c.Firmware.MaxUploadMB <= 0 {
+		return 200 << 20
+	}
+	return int64(c.Firmware.MaxUploadMB) << 20
+}
+
+// FirmwareStationTimeout returns how long station may report no firmware
+// update progress before it is counted as failed; 0 disables the timeout.
+func (c *Config) FirmwareStationTimeout() time.Duration {
+	if c.Firmware.StationTimeoutMinutes < 0 {
+		return 0
+	}
+	return time.Duration(c.Firmware.StationTimeoutMinutes) * time.Minute
+}
+
+// FirmwareCheckInterval returns how often firmware campaigns are advanced.
+func (c *Config) FirmwareCheckInterval() time.Duration {
+	if c.Firmware.CheckIntervalSeconds <= 0 {
+		return 15 * time.Second
+	}
+	return time.Duration(c.Firmware.CheckIntervalSeconds) * time.Second
+}
+
+// DiagnosticsPublicURL returns base URL stations upload diagnostics to;
+// defaults to firmware public URL.
+func (c *Config) DiagnosticsPublicURL() string {
+	if c.Diagnostics.PublicURL != "" {
+		return c.Diagnostics.PublicURL
+	}
+	return c.Firmware.PublicURL
+}
+
+// DiagnosticsMaxUpload returns size limit of uploaded diagnostics bundle in bytes.
+func (c *Config) DiagnosticsMaxUpload() int64 {
+	if c.Diagnostics.MaxUploadMB <= 0 {
+		return 100 << 20
+	}
+	return int64(c.Diagnostics.MaxUploadMB) << 20
+}
+
+// ReservationMaxDuration returns longest allowed booking; 0 means unlimited.
+func (c *Config) ReservationMaxDuration() time.Duration {
+	if c.Reservations.MaxDurationMinutes <= 0 {
+		return 0
+	}
+	return time.Duration(c.Reservations.MaxDurationMinutes) * time.Minute
+}
+
+// ReservationMaxAdvance returns how far ahead booking may start; 0 means unlimited.
+func (c *Config) ReservationMaxAdvance() time.Duration {
+	if c.Reservations.MaxAdvanceHours <= 0 {
+		return 0
+	}
+	return time.Duration(c.Reservations.MaxAdvanceHours) * time.Hour
+}
+
+// ReservationCheckInterval returns how often reservations are delivered and expired.
+func (c *Config) ReservationCheckInterval() time.Duration {
+	if c.Reservations.CheckIntervalSeconds <= 0 {
+		return 15 * time.Second
+	}
+	return time.Duration(c.Reservations.CheckIntervalSeconds) * time.Second
+}
+
+// ClusterLease returns how long station ownership survives without refresh.
+func (c *Config) ClusterLease() time.Duration {
+	if c.Cluster.LeaseSeconds <= 0 {
+		return 30 * time.Second
+	}
+	return time.Duration(c.Cluster.LeaseSeconds) * time.Second
+}
+
+// NodeID returns cluster node identifier, hostname and pid by default.
+func (c *Config) NodeID() string {
+	if id := strings.TrimSpace(c.Cluster.NodeID); id != "" {
+		return id
+	}
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "ocpp"
+	}
+	return fmt.Sprintf("%s-%d", host, os.Getpid())
+}
+
+// TLSEnabled reports whether TLS listener (security profiles 2 and 3) is configured.
+func (c *Config) TLSEnabled() bool {
+	return c.Security.TLSCertFile != "" && c.Security.TLSKeyFile != ""
+}
+
+// TLSAddress returns :port style address of TLS listener.
+func (c *Config) TLSAddress() string {
+	port := strings.TrimSpace(c.Security.TLSPort)
+	if port == "" {
+		port = "8443"
+	}
+	if strings.HasPrefix(port, ":") {
+		return port
+	}
+	return fmt.Sprintf(":%s", port)
+}
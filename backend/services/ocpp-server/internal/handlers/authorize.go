@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+
+	"go.uber.org/zap"
+
+	"drivepower/backend/services/ocpp-server/internal/ocpp"
+	"drivepower/backend/services/ocpp-server/internal/ocpp/protocol"
+	"drivepower/backend/services/ocpp-server/internal/service"
+)
+
+// NewAuthorizeHandler validates id tag against registry.
+func NewAuthorizeHandler(authorizer *service.Authorizer, logger *zap.Logger) ocpp.HandlerFunc {
+	return func(ctx context.Context, stationID string, payload json.RawMessage) (interface{}, error) {
+		req, err := ocpp.Decode[protocol.AuthorizeRequest](payload)
+		if err != nil {
+			return nil, err
+		}
+
+		result, err := authorizer.Authorize(ctx, req.IdTag)
+		if err != nil {
+			logger.Warn("id tag lookup failed", zap.String("station_id", stationID), zap.Error(err))
+			return nil, err
+		}
+
+		logger.Info("id tag authorized",
+			zap.String("station_id", stationID),
+			zap.String("id_tag", req.IdTag),
+			zap.String("status", result.Status),
+		)
+		return protocol.AuthorizeResponse{
+			IdTagInfo: result.IdTagInfo(),
+		}, nil
+	}
+}
@@ -7,15 +7,21 @@ import (
 
 	"go.uber.org/zap"
 
+	"drivepower/backend/libs/events"
 	"drivepower/backend/services/ocpp-server/internal/models"
 	"drivepower/backend/services/ocpp-server/internal/ocpp"
 	"drivepower/backend/services/ocpp-server/internal/ocpp/protocol"
-	"drivepower/backend/services/ocpp-server/internal/repository"
 	"drivepower/backend/services/ocpp-server/internal/service"
 )
 
-// NewBootNotificationHandler registers handler.
-func NewBootNotificationHandler(repo *repository.StationRepository, state *service.StationState, logger *zap.Logger) ocpp.HandlerFunc {
+// BootObserver is notified about stations accepted by BootNotification. It is
+// called from the station read loop and must not wait for the station.
+type BootObserver interface {
+	StationBooted(stationID string)
+}
+
+// NewBootNotificationHandler registers handler; observers may be empty.
+func NewBootNotificationHandler(registry *service.StationRegistry, state *service.StationState, outbox *service.Outbox, observers []BootObserver, logger *zap.Logger) ocpp.HandlerFunc {
 	return func(ctx context.Context, stationID string, payload json.RawMessage) (interface{}, error) {
 		req, err := ocpp.Decode[protocol.BootNotificationRequest](payload)
 		if err != nil {
@@ -29,19 +35,41 @@ func NewBootNotificationHandler(repo *repository.StationRepository, state *servi
 			FirmwareVersion: req.FirmwareVersion,
 			Status:          protocol.ConnectorAvailable,
 			LastHeartbeat:   time.Now().UTC(),
+			OCPPVersion:     protocol.Subprotocol,
 		}
 
-		if err := repo.Upsert(ctx, station); err != nil {
-			logger.Error("failed to upsert station", zap.String("station_id", stationID), zap.Error(err))
+		decision, err := registry.Boot(ctx, station)
+		if err != nil {
+			logger.Error("failed to register station", zap.String("station_id", stationID), zap.Error(err))
 			return nil, err
 		}
 
-		state.UpdateStation(stationID, protocol.ConnectorAvailable)
+		if decision.Status == protocol.RegistrationAccepted {
+			state.UpdateStation(stationID, protocol.ConnectorAvailable)
+			for _, observer := range observers {
+				observer.StationBooted(stationID)
+			}
+		}
+
+		booted := outbox.Domain(service.DomainEvent{
+			Type:      events.StationBooted,
+			StationID: stationID,
+			Data: events.StationBootedData{
+				Vendor:          station.Vendor,
+				Model:           station.Model,
+				SerialNumber:    req.ChargePointSerialNumber,
+				FirmwareVersion: station.FirmwareVersion,
+				Status:          decision.Status,
+			},
+		})
+		if err := outbox.Enqueue(ctx, booted...); err != nil {
+			logger.Warn("failed to queue station booted event", zap.String("station_id", stationID), zap.Error(err))
+		}
 
 		resp := protocol.BootNotificationResponse{
 			CurrentTime: time.Now().UTC(),
-			Interval:    30,
-			Status:      protocol.RegistrationAccepted,
+			Interval:    decision.Interval,
+			Status:      decision.Status,
 		}
 		return resp, nil
 	}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+
+	"go.uber.org/zap"
+
+	"drivepower/backend/services/ocpp-server/internal/ocpp"
+	"drivepower/backend/services/ocpp-server/internal/ocpp/protocol"
+)
+
+// DiagnosticsObserver records log upload progress reported by station;
+// requestID is nil for OCPP 1.6 DiagnosticsStatusNotification.
+type DiagnosticsObserver interface {
+	DiagnosticsStatusChanged(ctx context.Context, stationID string, requestID *int, status string) error
+}
+
+// NewDiagnosticsStatusNotificationHandler registers handler; diagnostics may be nil.
+func NewDiagnosticsStatusNotificationHandler(diagnostics DiagnosticsObserver, logger *zap.Logger) ocpp.HandlerFunc {
+	return func(ctx context.Context, stationID string, payload json.RawMessage) (interface{}, error) {
+		req, err := ocpp.Decode[protocol.DiagnosticsStatusNotificationRequest](payload)
+		if err != nil {
+			return nil, err
+		}
+
+		logger.Info("diagnostics status", zap.String("station_id", stationID), zap.String("status", req.Status))
+		if diagnostics != nil {
+			if err := diagnostics.DiagnosticsStatusChanged(ctx, stationID, nil, req.Status); err != nil {
+				logger.Warn("failed to record diagnostics status", zap.String("station_id", stationID), zap.Error(err))
+			}
+		}
+		return protocol.DiagnosticsStatusNotificationResponse{}, nil
+	}
+}
@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+
+	"go.uber.org/zap"
+
+	"drivepower/backend/services/ocpp-server/internal/ocpp"
+	"drivepower/backend/services/ocpp-server/internal/ocpp/protocol"
+)
+
+// FirmwareObserver records firmware update progress reported by station.
+type FirmwareObserver interface {
+	FirmwareStatusChanged(ctx context.Context, stationID, status string) error
+}
+
+// NewFirmwareStatusNotificationHandler registers handler; firmware may be nil.
+func NewFirmwareStatusNotificationHandler(firmware FirmwareObserver, logger *zap.Logger) ocpp.HandlerFunc {
+	return func(ctx context.Context, stationID string, payload json.RawMessage) (interface{}, error) {
+		req, err := ocpp.Decode[protocol.FirmwareStatusNotificationRequest](payload)
+		if err != nil {
+			return nil, err
+		}
+
+		logger.Info("firmware status", zap.String("station_id", stationID), zap.String("status", req.Status))
+		if firmware != nil {
+			if err := firmware.FirmwareStatusChanged(ctx, stationID, req.Status); err != nil {
+				logger.Warn("failed to record firmware status", zap.String("station_id", stationID), zap.Error(err))
+			}
+		}
+		return protocol.FirmwareStatusNotificationResponse{}, nil
+	}
+}
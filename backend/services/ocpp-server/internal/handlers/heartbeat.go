@@ -7,13 +7,13 @@ import (
 
 	"drivepower/backend/services/ocpp-server/internal/ocpp"
 	"drivepower/backend/services/ocpp-server/internal/ocpp/protocol"
+	"drivepower/backend/services/ocpp-server/internal/service"
 )
 
-// NewHeartbeatHandler returns ack with current time.
-func NewHeartbeatHandler() ocpp.HandlerFunc {
+// NewHeartbeatHandler records station liveness and returns ack with current time.
+func NewHeartbeatHandler(liveness *service.LivenessMonitor) ocpp.HandlerFunc {
 	return func(ctx context.Context, stationID string, payload json.RawMessage) (interface{}, error) {
-		_ = ctx // unused
-		_ = stationID
+		liveness.Heartbeat(ctx, stationID)
 		return protocol.HeartbeatResponse{
 			CurrentTime: time.Now().UTC(),
 		}, nil
@@ -3,47 +3,101 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"math"
+	"strconv"
 	"time"
 
 	"go.uber.org/zap"
 
+	"drivepower/backend/libs/events"
 	"drivepower/backend/services/ocpp-server/internal/clients"
 	"drivepower/backend/services/ocpp-server/internal/ocpp"
 	"drivepower/backend/services/ocpp-server/internal/ocpp/protocol"
 	"drivepower/backend/services/ocpp-server/internal/service"
 )
 
-// NewMeterValuesHandler routes meter values to telemetry-service.
-func NewMeterValuesHandler(telemetry *clients.TelemetryClient, txStore *service.TransactionStore, logger *zap.Logger) ocpp.HandlerFunc {
+// NewMeterValuesHandler queues meter values for telemetry-service; load may be nil.
+func NewMeterValuesHandler(outbox *service.Outbox, txStore *service.TransactionStore, load LoadObserver, logger *zap.Logger) ocpp.HandlerFunc {
 	return func(ctx context.Context, stationID string, payload json.RawMessage) (interface{}, error) {
 		req, err := ocpp.Decode[protocol.MeterValuesRequest](payload)
 		if err != nil {
 			return nil, err
 		}
 
-		// find session id from transaction context
-		txCtx, ok := txStore.Get(req.TransactionID)
-		if !ok || txCtx.SessionID == 0 {
-			logger.Warn("meter values without session context", zap.String("transaction_id", req.TransactionID))
-			return nil, nil
+		// connector-level samples outside of transaction are not stored
+		if req.TransactionID == nil {
+			return protocol.MeterValuesResponse{}, nil
 		}
 
-		if telemetry != nil {
-			_ = telemetry.NotifyMeterValue(ctx, clients.MeterValueRequest{
-				SessionID:   txCtx.SessionID,
-				StationID:   req.StationID,
-				ConnectorID: req.ConnectorID,
-				MeterValue:  req.MeterValue,
-				Unit:        "kWh",
-				Timestamp:   req.Timestamp.UTC(),
-			})
+		// find session id from transaction context
+		transactionID := strconv.Itoa(*req.TransactionID)
+		txCtx, ok, err := txStore.Lookup(ctx, transactionID)
+		if err != nil {
+			logger.Error("failed to load transaction", zap.String("transaction_id", transactionID), zap.Error(err))
+			return nil, err
+		}
+		if !ok || !txCtx.Authorized {
+			logger.Warn("meter values without session context", zap.String("transaction_id", transactionID))
+			return protocol.MeterValuesResponse{}, nil
+		}
+		var meterWh int64
+		if kwh, ok := protocol.LastEnergyImportKWh(req.MeterValue); ok {
+			meterWh = int64(math.Round(kwh * 1000))
+		}
+		if err := txStore.Touch(ctx, transactionID, meterWh); err != nil {
+			logger.Warn("failed to record transaction activity", zap.String("transaction_id", transactionID), zap.Error(err))
+		}
+		if load != nil {
+			load.MeterSampled(stationID, transactionID, req.MeterValue)
 		}
 
-		// update station state heartbeat-like timestamp if needed
-		if req.Timestamp.IsZero() {
-			req.Timestamp = time.Now().UTC()
+		if err := outbox.Enqueue(ctx, meterValueMessages(outbox, transactionID, txCtx.SessionID, stationID, req.ConnectorID, req.MeterValue)...); err != nil {
+			logger.Error("failed to queue meter values", zap.String("transaction_id", transactionID), zap.Error(err))
+			return nil, err
 		}
 
-		return nil, nil
+		return protocol.MeterValuesResponse{}, nil
+	}
+}
+
+// meterValueMessages turns energy register readings into telemetry callbacks and
+// MeterSampled events. Session ID may still be 0 while session start is being
+// retried; outbox fills it in.
+func meterValueMessages(outbox *service.Outbox, transactionID string, sessionID int64, stationID string, connectorID int, values []protocol.MeterValue) []service.OutboxMessage {
+	var messages []service.OutboxMessage
+	for _, mv := range values {
+		energy, ok := mv.EnergyImportKWh()
+		if !ok {
+			continue
+		}
+		timestamp := mv.Timestamp
+		if timestamp.IsZero() {
+			timestamp = time.Now()
+		}
+		messages = append(messages, service.OutboxMessage{
+			EventType:   service.EventMeterValue,
+			AggregateID: transactionID,
+			Payload: clients.MeterValueRequest{
+				SessionID:   sessionID,
+				StationID:   stationID,
+				ConnectorID: connectorID,
+				MeterValue:  energy,
+				Unit:        protocol.UnitKWh,
+				Timestamp:   timestamp.UTC(),
+			},
+		})
+		messages = append(messages, outbox.Domain(service.DomainEvent{
+			Type:          events.MeterSampled,
+			StationID:     stationID,
+			TransactionID: transactionID,
+			SessionID:     sessionID,
+			Data: events.MeterSampledData{
+				ConnectorID: connectorID,
+				Value:       energy,
+				Unit:        protocol.UnitKWh,
+				SampledAt:   timestamp.UTC(),
+			},
+		})...)
 	}
+	return messages
 }
@@ -3,23 +3,45 @@ package handlers
 import (
 	"context"
 	"encoding/json"
-	"fmt"
-	"time"
+	"strconv"
 
 	"go.uber.org/zap"
 
+	"drivepower/backend/libs/events"
 	"drivepower/backend/services/ocpp-server/internal/clients"
 	"drivepower/backend/services/ocpp-server/internal/ocpp"
 	"drivepower/backend/services/ocpp-server/internal/ocpp/protocol"
 	"drivepower/backend/services/ocpp-server/internal/service"
 )
 
-// NewStartTransactionHandler notifies dependent services about start event.
+// TransactionIDs allocates OCPP 1.6 transaction IDs.
+type TransactionIDs interface {
+	Next(ctx context.Context) (int, error)
+}
+
+// LoadObserver is notified about changes of current drawn by stations. It is
+// called from the station read loop and must not wait for the station.
+type LoadObserver interface {
+	TransactionsChanged(stationID string)
+	MeterSampled(stationID, transactionID string, values []protocol.MeterValue)
+}
+
+// ReservationObserver is notified about transactions started on reserved
+// connectors; reservationID is nil when station did not report one.
+type ReservationObserver interface {
+	ReservationUsed(ctx context.Context, stationID string, connectorID int, idTag string, reservationID *int, transactionID string) error
+}
+
+// NewStartTransactionHandler assigns transaction ID and notifies dependent services about start event.
 func NewStartTransactionHandler(
 	sessions *clients.SessionsClient,
-	billing *clients.BillingClient,
+	outbox *service.Outbox,
+	authorizer *service.Authorizer,
+	txIDs TransactionIDs,
 	state *service.StationState,
 	txStore *service.TransactionStore,
+	load LoadObserver,
+	reservations ReservationObserver,
 	logger *zap.Logger,
 ) ocpp.HandlerFunc {
 	return func(ctx context.Context, stationID string, payload json.RawMessage) (interface{}, error) {
@@ -28,37 +50,91 @@ func NewStartTransactionHandler(
 			return nil, err
 		}
 
-		transactionID := req.TransactionID
-		if transactionID == "" {
-			transactionID = fmt.Sprintf("%s-%d", stationID, time.Now().UnixNano())
+		txID, err := txIDs.Next(ctx)
+		if err != nil {
+			logger.Error("failed to allocate transaction id", zap.String("station_id", stationID), zap.Error(err))
+			return nil, err
+		}
+		transactionID := strconv.Itoa(txID)
+
+		authz, err := authorizer.AuthorizeStart(ctx, req.IdTag)
+		if err != nil {
+			// Station already started charging; do not lose the transaction because registry is down.
+			logger.Warn("id tag lookup failed, accepting without owner", zap.String("station_id", stationID), zap.Error(err))
+			authz = service.Authorization{Status: protocol.AuthorizationAccepted}
 		}
 
 		var sessionID int64
-		if sessions != nil {
-			sessionID, err = sessions.CreateFromOCPP(ctx, clients.StartSessionRequest{
+		if sessions != nil && authz.Accepted() {
+			start := clients.StartSessionRequest{
 				StationID:     stationID,
 				ConnectorID:   req.ConnectorID,
 				TransactionID: transactionID,
 				MeterStart:    req.MeterStart,
-			})
+				UserID:        authz.UserID,
+			}
+			var messages []service.OutboxMessage
+			sessionID, err = sessions.CreateFromOCPP(ctx, start)
 			if err != nil {
-				logger.Warn("sessions start notification failed", zap.String("station_id", stationID), zap.Error(err))
+				// Session ID is needed right away; on failure the outbox retries the start.
+				logger.Warn("sessions start notification failed, queued for retry", zap.String("station_id", stationID), zap.Error(err))
+				messages = append(messages, service.OutboxMessage{EventType: service.EventSessionStart, AggregateID: transactionID, Payload: start})
+			}
+			messages = append(messages, outbox.Domain(service.DomainEvent{
+				Type:          events.TransactionStarted,
+				StationID:     stationID,
+				TransactionID: transactionID,
+				SessionID:     sessionID,
+				Data: events.TransactionStartedData{
+					ConnectorID: req.ConnectorID,
+					IdTag:       req.IdTag,
+					UserID:      authz.UserID,
+					MeterStart:  req.MeterStart,
+					StartedAt:   req.Timestamp.UTC(),
+				},
+			})...)
+			if err := outbox.Enqueue(ctx, messages...); err != nil {
+				logger.Error("failed to queue start notifications", zap.String("transaction_id", transactionID), zap.Error(err))
+				return nil, err
 			}
 		}
 
+		if !authz.Accepted() {
+			logger.Info("start transaction rejected",
+				zap.String("station_id", stationID),
+				zap.String("id_tag", req.IdTag),
+				zap.String("status", authz.Status),
+			)
+		}
+
 		if req.ConnectorID > 0 {
 			state.UpdateConnector(stationID, req.ConnectorID, protocol.ConnectorCharging)
 		}
 
-		txStore.Set(transactionID, service.TransactionContext{
-			SessionID: sessionID,
-			MeterStart: req.MeterStart,
+		err = txStore.Set(ctx, transactionID, service.TransactionContext{
+			SessionID:   sessionID,
+			MeterStart:  req.MeterStart,
+			StationID:   stationID,
+			ConnectorID: req.ConnectorID,
+			IdTag:       req.IdTag,
+			UserID:      authz.UserID,
+			Authorized:  authz.Accepted(),
 		})
-
-		resp := protocol.StartTransactionResponse{
-			TransactionID: transactionID,
+		if err != nil {
+			logger.Warn("failed to persist transaction", zap.String("transaction_id", transactionID), zap.Error(err))
+		}
+		if load != nil {
+			load.TransactionsChanged(stationID)
 		}
-		resp.IdTagInfo.Status = "Accepted"
-		return resp, nil
+		if reservations != nil && authz.Accepted() {
+			if err := reservations.ReservationUsed(ctx, stationID, req.ConnectorID, req.IdTag, req.ReservationID, transactionID); err != nil {
+				logger.Warn("failed to record reservation use", zap.String("transaction_id", transactionID), zap.Error(err))
+			}
+		}
+
+		return protocol.StartTransactionResponse{
+			TransactionID: txID,
+			IdTagInfo:     authz.IdTagInfo(),
+		}, nil
 	}
 }
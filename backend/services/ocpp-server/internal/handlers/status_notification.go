@@ -6,30 +6,43 @@ import (
 
 	"go.uber.org/zap"
 
+	"drivepower/backend/libs/events"
 	"drivepower/backend/services/ocpp-server/internal/ocpp"
 	"drivepower/backend/services/ocpp-server/internal/ocpp/protocol"
-	"drivepower/backend/services/ocpp-server/internal/repository"
 	"drivepower/backend/services/ocpp-server/internal/service"
 )
 
 // NewStatusNotificationHandler updates station/connector status.
-func NewStatusNotificationHandler(repo *repository.StationRepository, state *service.StationState, logger *zap.Logger) ocpp.HandlerFunc {
+func NewStatusNotificationHandler(repo service.StationBackend, state *service.StationState, outbox *service.Outbox, logger *zap.Logger) ocpp.HandlerFunc {
 	return func(ctx context.Context, stationID string, payload json.RawMessage) (interface{}, error) {
 		req, err := ocpp.Decode[protocol.StatusNotificationRequest](payload)
 		if err != nil {
 			return nil, err
 		}
 
-		if req.ConnectorStatus == "" {
-			req.ConnectorStatus = protocol.ConnectorAvailable
+		if req.Status == "" {
+			req.Status = protocol.ConnectorAvailable
 		}
 
-		if err := repo.UpdateStatus(ctx, stationID, req.ConnectorStatus); err != nil {
+		if err := repo.UpdateStatus(ctx, stationID, req.Status); err != nil {
 			logger.Warn("failed to update station status", zap.String("station_id", stationID), zap.Error(err))
 		}
-		state.UpdateStation(stationID, req.ConnectorStatus)
+		state.UpdateStation(stationID, req.Status)
 		if req.ConnectorID > 0 {
-			state.UpdateConnector(stationID, req.ConnectorID, req.ConnectorStatus)
+			state.UpdateConnector(stationID, req.ConnectorID, req.Status)
+		}
+
+		changed := outbox.Domain(service.DomainEvent{
+			Type:      events.StatusChanged,
+			StationID: stationID,
+			Data: events.StatusChangedData{
+				ConnectorID: req.ConnectorID,
+				Status:      req.Status,
+				ErrorCode:   req.ErrorCode,
+			},
+		})
+		if err := outbox.Enqueue(ctx, changed...); err != nil {
+			logger.Warn("failed to queue status changed event", zap.String("station_id", stationID), zap.Error(err))
 		}
 
 		return protocol.StatusNotificationResponse{}, nil
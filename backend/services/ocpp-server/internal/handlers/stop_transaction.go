@@ -3,22 +3,25 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"strconv"
 	"time"
 
 	"go.uber.org/zap"
 
+	"drivepower/backend/libs/events"
 	"drivepower/backend/services/ocpp-server/internal/clients"
 	"drivepower/backend/services/ocpp-server/internal/ocpp"
 	"drivepower/backend/services/ocpp-server/internal/ocpp/protocol"
 	"drivepower/backend/services/ocpp-server/internal/service"
 )
 
-// NewStopTransactionHandler notifies dependent services about stop event.
+// NewStopTransactionHandler queues stop notifications for dependent services.
 func NewStopTransactionHandler(
-	sessions *clients.SessionsClient,
-	billing *clients.BillingClient,
+	outbox *service.Outbox,
+	authorizer *service.Authorizer,
 	state *service.StationState,
 	txStore *service.TransactionStore,
+	load LoadObserver,
 	logger *zap.Logger,
 ) ocpp.HandlerFunc {
 	return func(ctx context.Context, stationID string, payload json.RawMessage) (interface{}, error) {
@@ -26,43 +29,110 @@ func NewStopTransactionHandler(
 		if err != nil {
 			return nil, err
 		}
+		transactionID := strconv.Itoa(req.TransactionID)
 
 		var energyKWh float64
 		var sessionID int64
-		if ctxInfo, ok := txStore.Get(req.TransactionID); ok {
+		var userID int64
+		connectorID := 0
+		authorized := true
+		ctxInfo, known, err := txStore.Lookup(ctx, transactionID)
+		if err != nil {
+			logger.Error("failed to load transaction", zap.String("transaction_id", transactionID), zap.Error(err))
+			return nil, err
+		}
+		if known {
 			sessionID = ctxInfo.SessionID
+			userID = ctxInfo.UserID
+			connectorID = ctxInfo.ConnectorID
+			authorized = ctxInfo.Authorized
 			if req.MeterStop > ctxInfo.MeterStart {
 				energyKWh = float64(req.MeterStop-ctxInfo.MeterStart) / 1000.0
 			}
-			txStore.Delete(req.TransactionID)
-		}
-
-		if sessions != nil {
-			if err := sessions.CompleteFromOCPP(ctx, clients.StopSessionRequest{
-				TransactionID: req.TransactionID,
-				MeterStop:     req.MeterStop,
-				Reason:        req.Reason,
-				EnergyKWh:     energyKWh,
-				EndTime:       time.Now().UTC(),
-			}); err != nil {
-				logger.Warn("sessions stop notification failed", zap.String("station_id", stationID), zap.Error(err))
+		} else {
+			// Late stop of transaction closed on expiry: its session, billing and
+			// TransactionStopped were already sent with the last known reading.
+			expired, err := txStore.Expired(ctx, transactionID)
+			if err != nil {
+				logger.Error("failed to load transaction", zap.String("transaction_id", transactionID), zap.Error(err))
+				return nil, err
+			}
+			if expired {
+				logger.Info("stop of expired transaction ignored", zap.String("station_id", stationID), zap.String("transaction_id", transactionID))
+				authorized = false
 			}
 		}
 
-		if billing != nil {
-			if sessionID > 0 {
-				if err := billing.NotifySessionStop(ctx, clients.BillingStopRequest{
+		var messages []service.OutboxMessage
+		if known && authorized {
+			messages = append(messages, meterValueMessages(outbox, transactionID, sessionID, stationID, connectorID, req.TransactionData)...)
+		}
+		if authorized {
+			messages = append(messages, service.OutboxMessage{
+				EventType:   service.EventSessionStop,
+				AggregateID: transactionID,
+				Payload: clients.StopSessionRequest{
+					TransactionID: transactionID,
+					MeterStop:     req.MeterStop,
+					Reason:        req.Reason,
+					EnergyKWh:     energyKWh,
+					EndTime:       time.Now().UTC(),
+				},
+			})
+		}
+		if authorized {
+			messages = append(messages, outbox.Domain(service.DomainEvent{
+				Type:          events.TransactionStopped,
+				StationID:     stationID,
+				TransactionID: transactionID,
+				SessionID:     sessionID,
+				Data: events.TransactionStoppedData{
+					ConnectorID: connectorID,
+					UserID:      userID,
+					MeterStop:   req.MeterStop,
+					EnergyKWh:   energyKWh,
+					Reason:      req.Reason,
+					StoppedAt:   req.Timestamp.UTC(),
+				},
+			})...)
+		}
+		if known && authorized {
+			messages = append(messages, service.OutboxMessage{
+				EventType:   service.EventBillingStopped,
+				AggregateID: transactionID,
+				Payload: clients.BillingStopRequest{
 					SessionID: sessionID,
-					UserID:    0,
+					UserID:    userID,
 					EnergyKWh: energyKWh,
-				}); err != nil {
-					logger.Warn("billing stop notification failed", zap.String("station_id", stationID), zap.Error(err))
-				}
+				},
+			})
+		}
+		// Station gets its answer only after notifications are stored; otherwise it retries.
+		if err := outbox.Enqueue(ctx, messages...); err != nil {
+			logger.Error("failed to queue stop notifications", zap.String("transaction_id", transactionID), zap.Error(err))
+			return nil, err
+		}
+
+		if known {
+			if err := txStore.Delete(ctx, transactionID); err != nil {
+				logger.Warn("failed to delete transaction", zap.String("transaction_id", transactionID), zap.Error(err))
 			}
 		}
 
 		state.UpdateStation(stationID, protocol.ConnectorAvailable)
+		if load != nil {
+			load.TransactionsChanged(stationID)
+		}
 
-		return protocol.StopTransactionResponse{}, nil
+		resp := protocol.StopTransactionResponse{}
+		if req.IdTag != "" {
+			if authz, err := authorizer.Authorize(ctx, req.IdTag); err == nil {
+				info := authz.IdTagInfo()
+				resp.IdTagInfo = &info
+			} else {
+				logger.Warn("id tag lookup failed on stop", zap.String("station_id", stationID), zap.Error(err))
+			}
+		}
+		return resp, nil
 	}
 }
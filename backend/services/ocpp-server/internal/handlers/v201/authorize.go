@@ -0,0 +1,49 @@
+package v201
+
+import (
+	"context"
+	"encoding/json"
+
+	"go.uber.org/zap"
+
+	"drivepower/backend/services/ocpp-server/internal/ocpp"
+	protocol "drivepower/backend/services/ocpp-server/internal/ocpp/protocol/v201"
+	"drivepower/backend/services/ocpp-server/internal/service"
+)
+
+// NewAuthorizeHandler validates id token against registry.
+func NewAuthorizeHandler(authorizer *service.Authorizer, logger *zap.Logger) ocpp.HandlerFunc {
+	return func(ctx context.Context, stationID string, payload json.RawMessage) (interface{}, error) {
+		req, err := ocpp.Decode[protocol.AuthorizeRequest](payload)
+		if err != nil {
+			return nil, err
+		}
+
+		result, err := authorizer.Authorize(ctx, req.IdToken.IdToken)
+		if err != nil {
+			logger.Warn("id token lookup failed", zap.String("station_id", stationID), zap.Error(err))
+			return nil, err
+		}
+
+		logger.Info("id token authorized",
+			zap.String("station_id", stationID),
+			zap.String("id_token", req.IdToken.IdToken),
+			zap.String("status", result.Status),
+		)
+		return protocol.AuthorizeResponse{
+			IdTokenInfo: idTokenInfo(result),
+		}, nil
+	}
+}
+
+// idTokenInfo converts registry decision to OCPP 2.0.1 idTokenInfo.
+func idTokenInfo(a service.Authorization) protocol.IdTokenInfo {
+	info := protocol.IdTokenInfo{
+		Status:              a.Status,
+		CacheExpiryDateTime: a.ExpiryDate,
+	}
+	if a.ParentIdTag != "" {
+		info.GroupIdToken = &protocol.IdToken{IdToken: a.ParentIdTag, Type: "Central"}
+	}
+	return info
+}
@@ -0,0 +1,66 @@
+// Package v201 holds OCPP 2.0.1 action handlers.
+package v201
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"go.uber.org/zap"
+
+	"drivepower/backend/libs/events"
+	"drivepower/backend/services/ocpp-server/internal/models"
+	"drivepower/backend/services/ocpp-server/internal/ocpp"
+	protocol "drivepower/backend/services/ocpp-server/internal/ocpp/protocol/v201"
+	"drivepower/backend/services/ocpp-server/internal/service"
+)
+
+// NewBootNotificationHandler registers station.
+func NewBootNotificationHandler(registry *service.StationRegistry, state *service.StationState, outbox *service.Outbox, logger *zap.Logger) ocpp.HandlerFunc {
+	return func(ctx context.Context, stationID string, payload json.RawMessage) (interface{}, error) {
+		req, err := ocpp.Decode[protocol.BootNotificationRequest](payload)
+		if err != nil {
+			return nil, err
+		}
+
+		station := &models.Station{
+			ID:              stationID,
+			Vendor:          req.ChargingStation.VendorName,
+			Model:           req.ChargingStation.Model,
+			FirmwareVersion: req.ChargingStation.FirmwareVersion,
+			Status:          protocol.ConnectorAvailable,
+			LastHeartbeat:   time.Now().UTC(),
+			OCPPVersion:     protocol.Subprotocol,
+		}
+		decision, err := registry.Boot(ctx, station)
+		if err != nil {
+			logger.Error("failed to register station", zap.String("station_id", stationID), zap.Error(err))
+			return nil, err
+		}
+
+		if decision.Status == protocol.RegistrationAccepted {
+			state.UpdateStation(stationID, protocol.ConnectorAvailable)
+		}
+
+		booted := outbox.Domain(service.DomainEvent{
+			Type:      events.StationBooted,
+			StationID: stationID,
+			Data: events.StationBootedData{
+				Vendor:          station.Vendor,
+				Model:           station.Model,
+				SerialNumber:    req.ChargingStation.SerialNumber,
+				FirmwareVersion: station.FirmwareVersion,
+				Status:          decision.Status,
+			},
+		})
+		if err := outbox.Enqueue(ctx, booted...); err != nil {
+			logger.Warn("failed to queue station booted event", zap.String("station_id", stationID), zap.Error(err))
+		}
+
+		return protocol.BootNotificationResponse{
+			CurrentTime: time.Now().UTC(),
+			Interval:    decision.Interval,
+			Status:      decision.Status,
+		}, nil
+	}
+}
@@ -0,0 +1,21 @@
+package v201
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"drivepower/backend/services/ocpp-server/internal/ocpp"
+	protocol "drivepower/backend/services/ocpp-server/internal/ocpp/protocol/v201"
+	"drivepower/backend/services/ocpp-server/internal/service"
+)
+
+// NewHeartbeatHandler records station liveness and returns ack with current time.
+func NewHeartbeatHandler(liveness *service.LivenessMonitor) ocpp.HandlerFunc {
+	return func(ctx context.Context, stationID string, payload json.RawMessage) (interface{}, error) {
+		liveness.Heartbeat(ctx, stationID)
+		return protocol.HeartbeatResponse{
+			CurrentTime: time.Now().UTC(),
+		}, nil
+	}
+}
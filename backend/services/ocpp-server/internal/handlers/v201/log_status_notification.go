@@ -0,0 +1,34 @@
+package v201
+
+import (
+	"context"
+	"encoding/json"
+
+	"go.uber.org/zap"
+
+	"drivepower/backend/services/ocpp-server/internal/ocpp"
+	protocol "drivepower/backend/services/ocpp-server/internal/ocpp/protocol/v201"
+)
+
+// LogObserver records log upload progress of GetLog requestID.
+type LogObserver interface {
+	DiagnosticsStatusChanged(ctx context.Context, stationID string, requestID *int, status string) error
+}
+
+// NewLogStatusNotificationHandler registers handler; logs may be nil.
+func NewLogStatusNotificationHandler(logs LogObserver, logger *zap.Logger) ocpp.HandlerFunc {
+	return func(ctx context.Context, stationID string, payload json.RawMessage) (interface{}, error) {
+		req, err := ocpp.Decode[protocol.LogStatusNotificationRequest](payload)
+		if err != nil {
+			return nil, err
+		}
+
+		logger.Info("log status", zap.String("station_id", stationID), zap.String("status", req.Status))
+		if logs != nil {
+			if err := logs.DiagnosticsStatusChanged(ctx, stationID, req.RequestID, req.Status); err != nil {
+				logger.Warn("failed to record log status", zap.String("station_id", stationID), zap.Error(err))
+			}
+		}
+		return protocol.LogStatusNotificationResponse{}, nil
+	}
+}
@@ -0,0 +1,130 @@
+package v201
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+
+	"drivepower/backend/libs/events"
+	"drivepower/backend/services/ocpp-server/internal/clients"
+	"drivepower/backend/services/ocpp-server/internal/handlers"
+	"drivepower/backend/services/ocpp-server/internal/ocpp"
+	ocpp16 "drivepower/backend/services/ocpp-server/internal/ocpp/protocol"
+	protocol "drivepower/backend/services/ocpp-server/internal/ocpp/protocol/v201"
+	"drivepower/backend/services/ocpp-server/internal/service"
+)
+
+// NewMeterValuesHandler queues EVSE meter values for telemetry-service.
+// In 2.0.1 transaction samples come with TransactionEvent, so only samples of
+// EVSE with ongoing transaction are forwarded here; load may be nil.
+func NewMeterValuesHandler(outbox *service.Outbox, txStore *service.TransactionStore, load handlers.LoadObserver, logger *zap.Logger) ocpp.HandlerFunc {
+	return func(ctx context.Context, stationID string, payload json.RawMessage) (interface{}, error) {
+		req, err := ocpp.Decode[protocol.MeterValuesRequest](payload)
+		if err != nil {
+			return nil, err
+		}
+
+		transactionID, _, ok := txStore.FindByConnector(stationID, req.EvseID)
+		if !ok {
+			return protocol.MeterValuesResponse{}, nil
+		}
+		txCtx, ok, err := txStore.Lookup(ctx, transactionID)
+		if err != nil {
+			logger.Error("failed to load transaction", zap.String("transaction_id", transactionID), zap.Error(err))
+			return nil, err
+		}
+		if !ok || !txCtx.Authorized {
+			return protocol.MeterValuesResponse{}, nil
+		}
+		meterWh, _ := protocol.LastEnergyImportWh(req.MeterValue)
+		if err := txStore.Touch(ctx, transactionID, int64(meterWh)); err != nil {
+			logger.Warn("failed to record transaction activity", zap.String("transaction_id", transactionID), zap.Error(err))
+		}
+		if load != nil {
+			load.MeterSampled(stationID, transactionID, loadSamples(req.MeterValue))
+		}
+
+		if err := outbox.Enqueue(ctx, meterValueMessages(outbox, transactionID, txCtx.SessionID, stationID, req.EvseID, req.MeterValue)...); err != nil {
+			logger.Error("failed to queue meter values", zap.String("transaction_id", transactionID), zap.Error(err))
+			return nil, err
+		}
+
+		return protocol.MeterValuesResponse{}, nil
+	}
+}
+
+// meterValueMessages turns energy register readings into telemetry callbacks and
+// MeterSampled events. Session ID may still be 0 while session start is being
+// retried; outbox fills it in.
+func meterValueMessages(outbox *service.Outbox, transactionID string, sessionID int64, stationID string, evseID int, values []protocol.MeterValue) []service.OutboxMessage {
+	var messages []service.OutboxMessage
+	for _, mv := range values {
+		wh, ok := mv.EnergyImportWh()
+		if !ok {
+			continue
+		}
+		timestamp := mv.Timestamp
+		if timestamp.IsZero() {
+			timestamp = time.Now()
+		}
+		messages = append(messages, service.OutboxMessage{
+			EventType:   service.EventMeterValue,
+			AggregateID: transactionID,
+			Payload: clients.MeterValueRequest{
+				SessionID:   sessionID,
+				StationID:   stationID,
+				ConnectorID: evseID,
+				MeterValue:  wh / 1000.0,
+				Unit:        protocol.UnitKWh,
+				Timestamp:   timestamp.UTC(),
+			},
+		})
+		messages = append(messages, outbox.Domain(service.DomainEvent{
+			Type:          events.MeterSampled,
+			StationID:     stationID,
+			TransactionID: transactionID,
+			SessionID:     sessionID,
+			Data: events.MeterSampledData{
+				ConnectorID: evseID,
+				Value:       wh / 1000.0,
+				Unit:        protocol.UnitKWh,
+				SampledAt:   timestamp.UTC(),
+			},
+		})...)
+	}
+	return messages
+}
+
+// loadSamples converts Current.Import and Power.Active.Import samples to OCPP
+// 1.6 form used by load balancing, with unit multiplier applied.
+func loadSamples(values []protocol.MeterValue) []ocpp16.MeterValue {
+	var converted []ocpp16.MeterValue
+	for _, mv := range values {
+		var samples []ocpp16.SampledValue
+		for _, sv := range mv.SampledValue {
+			if sv.Measurand != ocpp16.MeasurandCurrentImport && sv.Measurand != ocpp16.MeasurandPowerActiveImport {
+				continue
+			}
+			value, unit := sv.Value, ""
+			if sv.UnitOfMeasure != nil {
+				value *= math.Pow10(sv.UnitOfMeasure.Multiplier)
+				unit = sv.UnitOfMeasure.Unit
+			}
+			samples = append(samples, ocpp16.SampledValue{
+				Value:     strconv.FormatFloat(value, 'f', -1, 64),
+				Measurand: sv.Measurand,
+				Phase:     sv.Phase,
+				Location:  sv.Location,
+				Unit:      unit,
+			})
+		}
+		if len(samples) > 0 {
+			converted = append(converted, ocpp16.MeterValue{Timestamp: mv.Timestamp, SampledValue: samples})
+		}
+	}
+	return converted
+}
@@ -0,0 +1,50 @@
+package v201
+
+import (
+	"context"
+	"encoding/json"
+
+	"go.uber.org/zap"
+
+	"drivepower/backend/libs/events"
+	"drivepower/backend/services/ocpp-server/internal/ocpp"
+	protocol "drivepower/backend/services/ocpp-server/internal/ocpp/protocol/v201"
+	"drivepower/backend/services/ocpp-server/internal/service"
+)
+
+// NewStatusNotificationHandler updates station/EVSE status.
+func NewStatusNotificationHandler(repo service.StationBackend, state *service.StationState, outbox *service.Outbox, logger *zap.Logger) ocpp.HandlerFunc {
+	return func(ctx context.Context, stationID string, payload json.RawMessage) (interface{}, error) {
+		req, err := ocpp.Decode[protocol.StatusNotificationRequest](payload)
+		if err != nil {
+			return nil, err
+		}
+
+		if req.ConnectorStatus == "" {
+			req.ConnectorStatus = protocol.ConnectorAvailable
+		}
+
+		if err := repo.UpdateStatus(ctx, stationID, req.ConnectorStatus); err != nil {
+			logger.Warn("failed to update station status", zap.String("station_id", stationID), zap.Error(err))
+		}
+
+		state.UpdateStation(stationID, req.ConnectorStatus)
+		if req.EvseID > 0 {
+			state.UpdateConnector(stationID, req.EvseID, req.ConnectorStatus)
+		}
+
+		changed := outbox.Domain(service.DomainEvent{
+			Type:      events.StatusChanged,
+			StationID: stationID,
+			Data: events.StatusChangedData{
+				ConnectorID: req.EvseID,
+				Status:      req.ConnectorStatus,
+			},
+		})
+		if err := outbox.Enqueue(ctx, changed...); err != nil {
+			logger.Warn("failed to queue status changed event", zap.String("station_id", stationID), zap.Error(err))
+		}
+
+		return protocol.StatusNotificationResponse{}, nil
+	}
+}
@@ -0,0 +1,243 @@
+package v201
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"go.uber.org/zap"
+
+	"drivepower/backend/libs/events"
+	"drivepower/backend/services/ocpp-server/internal/clients"
+	"drivepower/backend/services/ocpp-server/internal/handlers"
+	"drivepower/backend/services/ocpp-server/internal/ocpp"
+	protocol "drivepower/backend/services/ocpp-server/internal/ocpp/protocol/v201"
+	"drivepower/backend/services/ocpp-server/internal/service"
+)
+
+// transactionEvents holds dependencies of TransactionEvent processing.
+type transactionEvents struct {
+	sessions   *clients.SessionsClient
+	outbox     *service.Outbox
+	authorizer *service.Authorizer
+	state      *service.StationState
+	txStore    *service.TransactionStore
+	load       handlers.LoadObserver
+	logger     *zap.Logger
+}
+
+// NewTransactionEventHandler maps Started/Updated/Ended events onto sessions,
+// billing and telemetry; load may be nil.
+func NewTransactionEventHandler(
+	sessions *clients.SessionsClient,
+	outbox *service.Outbox,
+	authorizer *service.Authorizer,
+	state *service.StationState,
+	txStore *service.TransactionStore,
+	load handlers.LoadObserver,
+	logger *zap.Logger,
+) ocpp.HandlerFunc {
+	h := &transactionEvents{
+		sessions:   sessions,
+		outbox:     outbox,
+		authorizer: authorizer,
+		state:      state,
+		txStore:    txStore,
+		load:       load,
+		logger:     logger,
+	}
+	return func(ctx context.Context, stationID string, payload json.RawMessage) (interface{}, error) {
+		req, err := ocpp.Decode[protocol.TransactionEventRequest](payload)
+		if err != nil {
+			return nil, err
+		}
+		return h.handle(ctx, stationID, req)
+	}
+}
+
+func (h *transactionEvents) handle(ctx context.Context, stationID string, req protocol.TransactionEventRequest) (interface{}, error) {
+	transactionID := req.TransactionInfo.TransactionID
+	evseID := 0
+	if req.Evse != nil {
+		evseID = req.Evse.ID
+	}
+
+	txCtx, known, err := h.txStore.Lookup(ctx, transactionID)
+	if err != nil {
+		h.logger.Error("failed to load transaction", zap.String("transaction_id", transactionID), zap.Error(err))
+		return nil, err
+	}
+	if !known {
+		// Started may be lost or arrive after station reconnect; open context on first event.
+		meterStart, _ := protocol.LastEnergyImportWh(req.MeterValue)
+		txCtx = service.TransactionContext{
+			MeterStart:  int64(meterStart),
+			StationID:   stationID,
+			ConnectorID: evseID,
+		}
+		if evseID > 0 {
+			h.state.UpdateConnector(stationID, evseID, protocol.ConnectorOccupied)
+		}
+	}
+
+	resp := protocol.TransactionEventResponse{}
+	if req.IdToken != nil && req.IdToken.IdToken != "" && txCtx.IdTag == "" {
+		authz := h.authorize(ctx, stationID, req.IdToken.IdToken)
+		txCtx.IdTag = req.IdToken.IdToken
+		txCtx.UserID = authz.UserID
+		txCtx.Authorized = authz.Accepted()
+		info := idTokenInfo(authz)
+		resp.IdTokenInfo = &info
+
+		if txCtx.Authorized && txCtx.SessionID == 0 && h.sessions != nil {
+			start := clients.StartSessionRequest{
+				StationID:     stationID,
+				ConnectorID:   txCtx.ConnectorID,
+				TransactionID: transactionID,
+				MeterStart:    txCtx.MeterStart,
+				UserID:        txCtx.UserID,
+			}
+			var messages []service.OutboxMessage
+			sessionID, err := h.sessions.CreateFromOCPP(ctx, start)
+			if err != nil {
+				// Session ID is needed right away; on failure the outbox retries the start.
+				h.logger.Warn("sessions start notification failed, queued for retry", zap.String("station_id", stationID), zap.Error(err))
+				messages = append(messages, service.OutboxMessage{EventType: service.EventSessionStart, AggregateID: transactionID, Payload: start})
+			}
+			txCtx.SessionID = sessionID
+			messages = append(messages, h.outbox.Domain(service.DomainEvent{
+				Type:          events.TransactionStarted,
+				StationID:     stationID,
+				TransactionID: transactionID,
+				SessionID:     sessionID,
+				Data: events.TransactionStartedData{
+					ConnectorID: txCtx.ConnectorID,
+					IdTag:       txCtx.IdTag,
+					UserID:      txCtx.UserID,
+					MeterStart:  txCtx.MeterStart,
+					StartedAt:   req.Timestamp.UTC(),
+				},
+			})...)
+			if err := h.outbox.Enqueue(ctx, messages...); err != nil {
+				h.logger.Error("failed to queue start notifications", zap.String("transaction_id", transactionID), zap.Error(err))
+				return nil, err
+			}
+		}
+	}
+
+	if txCtx.Authorized {
+		if err := h.outbox.Enqueue(ctx, meterValueMessages(h.outbox, transactionID, txCtx.SessionID, stationID, txCtx.ConnectorID, req.MeterValue)...); err != nil {
+			h.logger.Error("failed to queue meter values", zap.String("transaction_id", transactionID), zap.Error(err))
+			return nil, err
+		}
+	}
+
+	if req.EventType != protocol.TransactionEventEnded {
+		if wh, ok := protocol.LastEnergyImportWh(req.MeterValue); ok && int64(wh) > txCtx.MeterLast {
+			txCtx.MeterLast = int64(wh)
+		}
+		if err := h.txStore.Set(ctx, transactionID, txCtx); err != nil {
+			h.logger.Warn("failed to persist transaction", zap.String("transaction_id", transactionID), zap.Error(err))
+		}
+		if h.load != nil {
+			if !known {
+				h.load.TransactionsChanged(stationID)
+			}
+			if samples := loadSamples(req.MeterValue); len(samples) > 0 {
+				h.load.MeterSampled(stationID, transactionID, samples)
+			}
+		}
+		return resp, nil
+	}
+
+	if err := h.end(ctx, stationID, transactionID, txCtx, known, req); err != nil {
+		return nil, err
+	}
+	if h.load != nil {
+		h.load.TransactionsChanged(stationID)
+	}
+	return resp, nil
+}
+
+func (h *transactionEvents) authorize(ctx context.Context, stationID, idToken string) service.Authorization {
+	authz, err := h.authorizer.AuthorizeStart(ctx, idToken)
+	if err != nil {
+		// Station already started charging; do not lose the transaction because registry is down.
+		h.logger.Warn("id token lookup failed, accepting without owner", zap.String("station_id", stationID), zap.Error(err))
+		return service.Authorization{Status: protocol.AuthorizationAccepted}
+	}
+	if !authz.Accepted() {
+		h.logger.Info("transaction id token rejected",
+			zap.String("station_id", stationID),
+			zap.String("id_token", idToken),
+			zap.String("status", authz.Status),
+		)
+	}
+	return authz
+}
+
+func (h *transactionEvents) end(ctx context.Context, stationID, transactionID string, txCtx service.TransactionContext, known bool, req protocol.TransactionEventRequest) error {
+	var energyKWh float64
+	meterStop := txCtx.MeterStart
+	if wh, ok := protocol.LastEnergyImportWh(req.MeterValue); ok {
+		meterStop = int64(wh)
+		if meterStop > txCtx.MeterStart {
+			energyKWh = float64(meterStop-txCtx.MeterStart) / 1000.0
+		}
+	}
+
+	if txCtx.Authorized {
+		messages := []service.OutboxMessage{
+			{
+				EventType:   service.EventSessionStop,
+				AggregateID: transactionID,
+				Payload: clients.StopSessionRequest{
+					TransactionID: transactionID,
+					MeterStop:     meterStop,
+					Reason:        req.TransactionInfo.StoppedReason,
+					EnergyKWh:     energyKWh,
+					EndTime:       time.Now().UTC(),
+				},
+			},
+			{
+				EventType:   service.EventBillingStopped,
+				AggregateID: transactionID,
+				Payload: clients.BillingStopRequest{
+					SessionID: txCtx.SessionID,
+					UserID:    txCtx.UserID,
+					EnergyKWh: energyKWh,
+				},
+			},
+		}
+		messages = append(messages, h.outbox.Domain(service.DomainEvent{
+			Type:          events.TransactionStopped,
+			StationID:     stationID,
+			TransactionID: transactionID,
+			SessionID:     txCtx.SessionID,
+			Data: events.TransactionStoppedData{
+				ConnectorID: txCtx.ConnectorID,
+				UserID:      txCtx.UserID,
+				MeterStop:   meterStop,
+				EnergyKWh:   energyKWh,
+				Reason:      req.TransactionInfo.StoppedReason,
+				StoppedAt:   req.Timestamp.UTC(),
+			},
+		})...)
+		// Station gets its answer only after notifications are stored; otherwise it retries.
+		if err := h.outbox.Enqueue(ctx, messages...); err != nil {
+			h.logger.Error("failed to queue stop notifications", zap.String("transaction_id", transactionID), zap.Error(err))
+			return err
+		}
+	}
+
+	if known {
+		if err := h.txStore.Delete(ctx, transactionID); err != nil {
+			h.logger.Warn("failed to delete transaction", zap.String("transaction_id", transactionID), zap.Error(err))
+		}
+	}
+
+	if txCtx.ConnectorID > 0 {
+		h.state.UpdateConnector(stationID, txCtx.ConnectorID, protocol.ConnectorAvailable)
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"go.uber.org/zap"
+
+	"drivepower/backend/services/ocpp-server/internal/models"
+	"drivepower/backend/services/ocpp-server/internal/ocpp"
+	"drivepower/backend/services/ocpp-server/internal/ocpp/protocol"
+	"drivepower/backend/services/ocpp-server/internal/repository"
+	"drivepower/backend/services/ocpp-server/internal/service"
+	"drivepower/backend/services/ocpp-server/internal/ws"
+)
+
+// ChargingProfilesHandler exposes smart charging profile administration.
+type ChargingProfilesHandler struct {
+	profiles *service.ChargingProfiles
+	logger   *zap.Logger
+}
+
+// NewChargingProfilesHandler builds handler set.
+func NewChargingProfilesHandler(profiles *service.ChargingProfiles, logger *zap.Logger) *ChargingProfilesHandler {
+	return &ChargingProfilesHandler{
+		profiles: profiles,
+		logger:   logger,
+	}
+}
+
+// chargingProfileRequest carries profile in OCPP 1.6 form; chargingProfileId
+// is assigned by the server.
+type chargingProfileRequest struct {
+	StationID   string                   `json:"station_id"`
+	ConnectorID int                      `json:"connector_id"`
+	Profile     protocol.ChargingProfile `json:"profile"`
+}
+
+// HandleCreate handles POST /internal/charging-profiles.
+func (h *ChargingProfilesHandler) HandleCreate(w http.ResponseWriter, r *http.Request) {
+	var req chargingProfileRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+
+	profile, err := h.profiles.Create(r.Context(), service.ChargingProfileInput{
+		StationID:   req.StationID,
+		ConnectorID: req.ConnectorID,
+		Profile:     req.Profile,
+		RequestedBy: requestedBy(r),
+	})
+	if err != nil {
+		h.writeProfileError(w, "create charging profile", err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, profile)
+}
+
+// HandleList handles GET /internal/charging-profiles?station_id=.
+func (h *ChargingProfilesHandler) HandleList(w http.ResponseWriter, r *http.Request) {
+	profiles, err := h.profiles.List(r.Context(), r.URL.Query().Get("station_id"))
+	if err != nil {
+		h.writeProfileError(w, "list charging profiles", err)
+		return
+	}
+	if profiles == nil {
+		profiles = []models.ChargingProfile{}
+	}
+	writeJSON(w, http.StatusOK, profiles)
+}
+
+// HandleGet handles GET /internal/charging-profiles/{id}.
+func (h *ChargingProfilesHandler) HandleGet(w http.ResponseWriter, r *http.Request) {
+	id, ok := profileID(w, r)
+	if !ok {
+		return
+	}
+	profile, err := h.profiles.Get(r.Context(), id)
+	if err != nil {
+		h.writeProfileError(w, "load charging profile", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, profile)
+}
+
+// HandleUpdate handles PUT /internal/charging-profiles/{id}.
+func (h *ChargingProfilesHandler) HandleUpdate(w http.ResponseWriter, r *http.Request) {
+	id, ok := profileID(w, r)
+	if !ok {
+		return
+	}
+	var req chargingProfileRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+
+	profile, err := h.profiles.Update(r.Context(), id, service.ChargingProfileInput{
+		StationID:   req.StationID,
+		ConnectorID: req.ConnectorID,
+		Profile:     req.Profile,
+		RequestedBy: requestedBy(r),
+	})
+	if err != nil {
+		h.writeProfileError(w, "update charging profile", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, profile)
+}
+
+// HandleDelete handles DELETE /internal/charging-profiles/{id}. Answers 204
+// when the profile is cleared and 202 when it is kept until the station
+// can be reached.
+func (h *ChargingProfilesHandler) HandleDelete(w http.ResponseWriter, r *http.Request) {
+	id, ok := profileID(w, r)
+	if !ok {
+		return
+	}
+	profile, err := h.profiles.Delete(r.Context(), id, requestedBy(r))
+	switch {
+	case err != nil:
+		h.writeProfileError(w, "delete charging profile", err)
+	case profile != nil:
+		writeJSON(w, http.StatusAccepted, profile)
+	default:
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// HandleCompositeSchedule handles GET /internal/stations/{id}/composite-schedule?connector_id=&duration=&charging_rate_unit=.
+func (h *ChargingProfilesHandler) HandleCompositeSchedule(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	connectorID, err := strconv.Atoi(query.Get("connector_id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "connector_id must be a number")
+		return
+	}
+	duration, err := strconv.Atoi(query.Get("duration"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "duration must be a number of seconds")
+		return
+	}
+
+	schedule, err := h.profiles.CompositeSchedule(r.Context(), r.PathValue("id"), protocol.GetCompositeScheduleRequest{
+		ConnectorID:      connectorID,
+		Duration:         duration,
+		ChargingRateUnit: query.Get("charging_rate_unit"),
+	}, requestedBy(r))
+	if err != nil {
+		h.writeProfileError(w, "get composite schedule", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, schedule)
+}
+
+func (h *ChargingProfilesHandler) writeProfileError(w http.ResponseWriter, operation string, err error) {
+	var callErr *ocpp.CallError
+	switch {
+	case errors.Is(err, service.ErrInvalidProfile), errors.Is(err, service.ErrInvalidCommand):
+		writeError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrUnsupportedVersion):
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+	case errors.Is(err, repository.ErrChargingProfileNotFound):
+		writeError(w, http.StatusNotFound, "charging profile not found")
+	case errors.Is(err, repository.ErrStationNotFound):
+		writeError(w, http.StatusNotFound, "station not found")
+	case errors.Is(err, repository.ErrChargingProfileConflict):
+		writeError(w, http.StatusConflict, "profile with the same purpose and stack level already exists on connector")
+	case errors.Is(err, ws.ErrStationNotConnected):
+		writeError(w, http.StatusConflict, "station not connected")
+	case errors.Is(err, ocpp.ErrCallTimeout):
+		writeError(w, http.StatusGatewayTimeout, "station did not respond")
+	case errors.As(err, &callErr):
+		writeError(w, http.StatusBadGateway, callErr.Error())
+	default:
+		h.logger.Error(operation+" failed", zap.Error(err))
+		writeError(w, http.StatusInternalServerError, operation+" failed")
+	}
+}
+
+func profileID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || id <= 0 {
+		writeError(w, http.StatusBadRequest, "invalid profile id")
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"go.uber.org/zap"
+
+	"drivepower/backend/services/ocpp-server/internal/models"
+	"drivepower/backend/services/ocpp-server/internal/ocpp"
+	"drivepower/backend/services/ocpp-server/internal/repository"
+	"drivepower/backend/services/ocpp-server/internal/service"
+	"drivepower/backend/services/ocpp-server/internal/ws"
+)
+
+// userIDHeader carries ID of the user on whose behalf api-gateway sends command.
+const userIDHeader = "X-User-ID"
+
+// CommandsHandler exposes internal API for CSMS-initiated commands.
+type CommandsHandler struct {
+	svc    *service.CommandService
+	logger *zap.Logger
+}
+
+// NewCommandsHandler builds handler set.
+func NewCommandsHandler(svc *service.CommandService, logger *zap.Logger) *CommandsHandler {
+	return &CommandsHandler{
+		svc:    svc,
+		logger: logger,
+	}
+}
+
+type remoteStartRequest struct {
+	StationID   string `json:"station_id"`
+	ConnectorID int    `json:"connector_id"`
+	IdTag       string `json:"id_tag"`
+}
+
+type remoteStopRequest struct {
+	TransactionID string `json:"transaction_id"`
+}
+
+type resetRequest struct {
+	StationID string `json:"station_id"`
+	Type      string `json:"type"`
+}
+
+type changeAvailabilityRequest struct {
+	StationID   string `json:"station_id"`
+	ConnectorID int    `json:"connector_id"`
+	Type        string `json:"type"`
+}
+
+type unlockConnectorRequest struct {
+	StationID   string `json:"station_id"`
+	ConnectorID int    `json:"connector_id"`
+}
+
+type triggerMessageRequest struct {
+	StationID        string `json:"station_id"`
+	RequestedMessage string `json:"requested_message"`
+	ConnectorID      *int   `json:"connector_id"`
+}
+
+type clearCacheRequest struct {
+	StationID string `json:"station_id"`
+}
+
+// HandleRemoteStart handles POST /internal/commands/remote-start.
+func (h *CommandsHandler) HandleRemoteStart(w http.ResponseWriter, r *http.Request) {
+	var req remoteStartRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+
+	status, err := h.svc.RemoteStart(r.Context(), service.RemoteStartInput{
+		StationID:   req.StationID,
+		ConnectorID: req.ConnectorID,
+		IdTag:       req.IdTag,
+		RequestedBy: requestedBy(r),
+	})
+	if err != nil {
+		h.writeCommandError(w, "remote start", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": status})
+}
+
+// HandleRemoteStop handles POST /internal/commands/remote-stop.
+func (h *CommandsHandler) HandleRemoteStop(w http.ResponseWriter, r *http.Request) {
+	var req remoteStopRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+
+	status, err := h.svc.RemoteStop(r.Context(), req.TransactionID, requestedBy(r))
+	if err != nil {
+		h.writeCommandError(w, "remote stop", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": status})
+}
+
+// HandleReset handles POST /internal/commands/reset.
+func (h *CommandsHandler) HandleReset(w http.ResponseWriter, r *http.Request) {
+	var req resetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+
+	status, err := h.svc.Reset(r.Context(), service.ResetInput{
+		StationID:   req.StationID,
+		Type:        req.Type,
+		RequestedBy: requestedBy(r),
+	})
+	if err != nil {
+		h.writeCommandError(w, "reset", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": status})
+}
+
+// HandleChangeAvailability handles POST /internal/commands/change-availability.
+func (h *CommandsHandler) HandleChangeAvailability(w http.ResponseWriter, r *http.Request) {
+	var req changeAvailabilityRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+
+	status, err := h.svc.ChangeAvailability(r.Context(), service.ChangeAvailabilityInput{
+		StationID:   req.StationID,
+		ConnectorID: req.ConnectorID,
+		Type:        req.Type,
+		RequestedBy: requestedBy(r),
+	})
+	if err != nil {
+		h.writeCommandError(w, "change availability", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": status})
+}
+
+// HandleUnlockConnector handles POST /internal/commands/unlock-connector.
+func (h *CommandsHandler) HandleUnlockConnector(w http.ResponseWriter, r *http.Request) {
+	var req unlockConnectorRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+
+	status, err := h.svc.UnlockConnector(r.Context(), service.UnlockConnectorInput{
+		StationID:   req.StationID,
+		ConnectorID: req.ConnectorID,
+		RequestedBy: requestedBy(r),
+	})
+	if err != nil {
+		h.writeCommandError(w, "unlock connector", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": status})
+}
+
+// HandleTriggerMessage handles POST /internal/commands/trigger-message.
+func (h *CommandsHandler) HandleTriggerMessage(w http.ResponseWriter, r *http.Request) {
+	var req triggerMessageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+
+	status, err := h.svc.TriggerMessage(r.Context(), service.TriggerMessageInput{
+		StationID:        req.StationID,
+		RequestedMessage: req.RequestedMessage,
+		ConnectorID:      req.ConnectorID,
+		RequestedBy:      requestedBy(r),
+	})
+	if err != nil {
+		h.writeCommandError(w, "trigger message", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": status})
+}
+
+// HandleClearCache handles POST /internal/commands/clear-cache.
+func (h *CommandsHandler) HandleClearCache(w http.ResponseWriter, r *http.Request) {
+	var req clearCacheRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+
+	status, err := h.svc.ClearCache(r.Context(), req.StationID, requestedBy(r))
+	if err != nil {
+		h.writeCommandError(w, "clear cache", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": status})
+}
+
+// HandleAudit handles GET /internal/commands/audit?station_id=&limit=.
+func (h *CommandsHandler) HandleAudit(w http.ResponseWriter, r *http.Request) {
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	entries, err := h.svc.Audit(r.Context(), r.URL.Query().Get("station_id"), limit)
+	if err != nil {
+		h.logger.Error("list command audit failed", zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to list command audit")
+		return
+	}
+	if entries == nil {
+		entries = []models.CommandAudit{}
+	}
+	writeJSON(w, http.StatusOK, entries)
+}
+
+func (h *CommandsHandler) writeCommandError(w http.ResponseWriter, command string, err error) {
+	var callErr *ocpp.CallError
+	switch {
+	case errors.Is(err, service.ErrInvalidCommand):
+		writeError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrTransactionNotFound):
+		writeError(w, http.StatusNotFound, "transaction not found")
+	case errors.Is(err, repository.ErrStationNotFound):
+		writeError(w, http.StatusNotFound, "station not found")
+	case errors.Is(err, service.ErrNotTransactionOwner):
+		writeError(w, http.StatusForbidden, "transaction belongs to another user")
+	case errors.Is(err, service.ErrOwnerUnknown):
+		writeError(w, http.StatusConflict, "transaction owner is unknown")
+	case errors.Is(err, service.ErrIdTagNotAllowed):
+		writeError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, service.ErrUnsupportedVersion):
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+	case errors.Is(err, ws.ErrStationNotConnected):
+		writeError(w, http.StatusConflict, "station not connected")
+	case errors.Is(err, ocpp.ErrCallTimeout):
+		writeError(w, http.StatusGatewayTimeout, "station did not respond")
+	case errors.As(err, &callErr):
+		writeError(w, http.StatusBadGateway, callErr.Error())
+	default:
+		h.logger.Error(command+" failed", zap.Error(err))
+		writeError(w, http.StatusInternalServerError, command+" failed")
+	}
+}
+
+// requestedBy returns user ID forwarded by api-gateway, 0 when absent.
+func requestedBy(r *http.Request) int64 {
+	userID, _ := strconv.ParseInt(r.Header.Get(userIDHeader), 10, 64)
+	return userID
+}
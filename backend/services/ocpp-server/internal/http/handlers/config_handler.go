@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"drivepower/backend/services/ocpp-server/internal/models"
+	"drivepower/backend/services/ocpp-server/internal/ocpp"
+	"drivepower/backend/services/ocpp-server/internal/repository"
+	"drivepower/backend/services/ocpp-server/internal/service"
+	"drivepower/backend/services/ocpp-server/internal/ws"
+)
+
+// ConfigHandler exposes desired station configuration administration.
+type ConfigHandler struct {
+	sync   *service.ConfigSync
+	logger *zap.Logger
+}
+
+// NewConfigHandler builds handler set.
+func NewConfigHandler(sync *service.ConfigSync, logger *zap.Logger) *ConfigHandler {
+	return &ConfigHandler{
+		sync:   sync,
+		logger: logger,
+	}
+}
+
+type desiredConfigRequest struct {
+	Keys map[string]string `json:"keys"`
+}
+
+type configGroupRequest struct {
+	Group string `json:"group"`
+}
+
+// HandleGetDesired handles GET /internal/config/{scope}/{target} where scope is station or group.
+func (h *ConfigHandler) HandleGetDesired(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.sync.Desired(r.Context(), r.PathValue("scope"), r.PathValue("target"))
+	switch {
+	case errors.Is(err, service.ErrInvalidConfig):
+		writeError(w, http.StatusBadRequest, "scope must be station or group")
+	case err != nil:
+		h.logger.Error("load desired configuration failed", zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to load desired configuration")
+	default:
+		writeJSON(w, http.StatusOK, desiredConfigRequest{Keys: keys})
+	}
+}
+
+// HandleSetDesired handles PUT /internal/config/{scope}/{target}; body keys replace stored ones.
+func (h *ConfigHandler) HandleSetDesired(w http.ResponseWriter, r *http.Request) {
+	var req desiredConfigRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	if req.Keys == nil {
+		req.Keys = map[string]string{}
+	}
+
+	err := h.sync.SetDesired(r.Context(), r.PathValue("scope"), r.PathValue("target"), req.Keys)
+	switch {
+	case errors.Is(err, service.ErrInvalidConfig):
+		writeError(w, http.StatusBadRequest, "scope must be station or group; keys up to 50 and values up to 500 characters")
+	case errors.Is(err, service.ErrUnsupportedVersion):
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+	case err != nil:
+		h.logger.Error("store desired configuration failed", zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to store desired configuration")
+	default:
+		writeJSON(w, http.StatusOK, req)
+	}
+}
+
+// HandleSetGroup handles PUT /internal/stations/{id}/config-group; empty group removes station from its group.
+func (h *ConfigHandler) HandleSetGroup(w http.ResponseWriter, r *http.Request) {
+	var req configGroupRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+
+	stationID := r.PathValue("id")
+	if err := h.sync.SetGroup(r.Context(), stationID, req.Group); err != nil {
+		if errors.Is(err, service.ErrInvalidConfig) {
+			writeError(w, http.StatusBadRequest, "station id is required")
+			return
+		}
+		h.logger.Error("set configuration group failed", zap.String("station_id", stationID), zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to set configuration group")
+		return
+	}
+	writeJSON(w, http.StatusOK, req)
+}
+
+// HandleSync handles POST /internal/stations/{id}/config-sync and returns per-key result.
+func (h *ConfigHandler) HandleSync(w http.ResponseWriter, r *http.Request) {
+	stationID := r.PathValue("id")
+	states, err := h.sync.Sync(r.Context(), stationID)
+	var callErr *ocpp.CallError
+	switch {
+	case errors.Is(err, service.ErrUnsupportedVersion):
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+	case errors.Is(err, repository.ErrStationNotFound):
+		writeError(w, http.StatusNotFound, "station not found")
+	case errors.Is(err, ws.ErrStationNotConnected):
+		writeError(w, http.StatusConflict, "station not connected")
+	case errors.Is(err, ocpp.ErrCallTimeout):
+		writeError(w, http.StatusGatewayTimeout, "station did not respond")
+	case errors.As(err, &callErr):
+		writeError(w, http.StatusBadGateway, callErr.Error())
+	case err != nil:
+		h.logger.Error("configuration sync failed", zap.String("station_id", stationID), zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "configuration sync failed")
+	default:
+		if states == nil {
+			states = []models.ConfigKeyState{}
+		}
+		writeJSON(w, http.StatusOK, states)
+	}
+}
+
+// HandleDrift handles GET /internal/config-drift?station_id=.
+func (h *ConfigHandler) HandleDrift(w http.ResponseWriter, r *http.Request) {
+	report, err := h.sync.Drift(r.Context(), r.URL.Query().Get("station_id"))
+	if err != nil {
+		h.logger.Error("configuration drift report failed", zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to build drift report")
+		return
+	}
+	if report == nil {
+		report = []models.ConfigDrift{}
+	}
+	writeJSON(w, http.StatusOK, report)
+}
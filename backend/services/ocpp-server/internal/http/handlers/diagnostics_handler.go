@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+
+	"drivepower/backend/services/ocpp-server/internal/models"
+	"drivepower/backend/services/ocpp-server/internal/ocpp"
+	"drivepower/backend/services/ocpp-server/internal/repository"
+	"drivepower/backend/services/ocpp-server/internal/service"
+	"drivepower/backend/services/ocpp-server/internal/ws"
+)
+
+// DiagnosticsHandler exposes log requests and receives bundles uploaded by stations.
+type DiagnosticsHandler struct {
+	diagnostics *service.DiagnosticsService
+	logger      *zap.Logger
+}
+
+// NewDiagnosticsHandler builds handler set.
+func NewDiagnosticsHandler(diagnostics *service.DiagnosticsService, logger *zap.Logger) *DiagnosticsHandler {
+	return &DiagnosticsHandler{
+		diagnostics: diagnostics,
+		logger:      logger,
+	}
+}
+
+type diagnosticsRequest struct {
+	StationID string     `json:"station_id"`
+	LogType   string     `json:"log_type"`
+	StartTime *time.Time `json:"start_time"`
+	StopTime  *time.Time `json:"stop_time"`
+}
+
+// HandleRequest handles POST /internal/diagnostics and asks station for logs.
+func (h *DiagnosticsHandler) HandleRequest(w http.ResponseWriter, r *http.Request) {
+	var req diagnosticsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+
+	d, err := h.diagnostics.Request(r.Context(), service.DiagnosticsInput{
+		StationID:   req.StationID,
+		LogType:     req.LogType,
+		StartTime:   req.StartTime,
+		StopTime:    req.StopTime,
+		RequestedBy: requestedBy(r),
+	})
+	if err != nil {
+		h.writeDiagnosticsError(w, "request diagnostics", err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, d)
+}
+
+// HandleList handles GET /internal/diagnostics?station_id=&limit=.
+func (h *DiagnosticsHandler) HandleList(w http.ResponseWriter, r *http.Request) {
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	list, err := h.diagnostics.List(r.Context(), r.URL.Query().Get("station_id"), limit)
+	if err != nil {
+		h.writeDiagnosticsError(w, "list diagnostics", err)
+		return
+	}
+	if list == nil {
+		list = []models.Diagnostics{}
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// HandleGet handles GET /internal/diagnostics/{id}.
+func (h *DiagnosticsHandler) HandleGet(w http.ResponseWriter, r *http.Request) {
+	id, ok := diagnosticsID(w, r)
+	if !ok {
+		return
+	}
+	d, err := h.diagnostics.Get(r.Context(), id)
+	if err != nil {
+		h.writeDiagnosticsError(w, "load diagnostics", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, d)
+}
+
+// HandleFile handles GET /internal/diagnostics/{id}/file and returns the
+// uploaded bundle.
+func (h *DiagnosticsHandler) HandleFile(w http.ResponseWriter, r *http.Request) {
+	id, ok := diagnosticsID(w, r)
+	if !ok {
+		return
+	}
+	file, d, err := h.diagnostics.OpenFile(r.Context(), id)
+	if err != nil {
+		h.writeDiagnosticsError(w, "open diagnostics file", err)
+		return
+	}
+	defer file.Close()
+
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": d.FileName}))
+	http.ServeContent(w, r, d.FileName, *d.UploadedAt, file)
+}
+
+// HandleUpload handles PUT /diagnostics/{token}[/{name}]; stations upload
+// bundles here. POST with multipart/form-data body takes the first file part.
+func (h *DiagnosticsHandler) HandleUpload(w http.ResponseWriter, r *http.Request) {
+	// Bundle may take longer to arrive than the server read timeout allows.
+	_ = http.NewResponseController(w).SetReadDeadline(time.Time{})
+
+	var (
+		body     io.Reader = r.Body
+		fileName           = r.PathValue("name")
+	)
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); r.Method == http.MethodPost && mediaType == "multipart/form-data" {
+		reader, err := r.MultipartReader()
+		if err != nil {
+			http.Error(w, "invalid multipart body", http.StatusBadRequest)
+			return
+		}
+		for {
+			part, err := reader.NextPart()
+			if err != nil {
+				http.Error(w, "no file in multipart body", http.StatusBadRequest)
+				return
+			}
+			if part.FileName() != "" {
+				body, fileName = part, part.FileName()
+				break
+			}
+		}
+	}
+
+	_, err := h.diagnostics.ReceiveUpload(r.Context(), r.PathValue("token"), fileName, body)
+	switch {
+	case err == nil:
+		w.WriteHeader(http.StatusCreated)
+	case errors.Is(err, repository.ErrDiagnosticsNotFound):
+		http.NotFound(w, r)
+	case errors.Is(err, repository.ErrDiagnosticsUploaded):
+		http.Error(w, "already uploaded", http.StatusConflict)
+	case errors.Is(err, service.ErrDiagnosticsTooLarge):
+		http.Error(w, "bundle too large", http.StatusRequestEntityTooLarge)
+	case errors.Is(err, service.ErrInvalidDiagnostics):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		h.logger.Error("store diagnostics upload failed", zap.Error(err))
+		http.Error(w, "store upload failed", http.StatusInternalServerError)
+	}
+}
+
+func (h *DiagnosticsHandler) writeDiagnosticsError(w http.ResponseWriter, operation string, err error) {
+	var callErr *ocpp.CallError
+	switch {
+	case errors.Is(err, service.ErrInvalidDiagnostics):
+		writeError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, repository.ErrDiagnosticsNotFound):
+		writeError(w, http.StatusNotFound, "diagnostics not found")
+	case errors.Is(err, repository.ErrStationNotFound):
+		writeError(w, http.StatusNotFound, "station not found")
+	case errors.Is(err, ws.ErrStationNotConnected):
+		writeError(w, http.StatusConflict, "station not connected")
+	case errors.Is(err, ocpp.ErrCallTimeout):
+		writeError(w, http.StatusGatewayTimeout, "station did not respond")
+	case errors.As(err, &callErr):
+		writeError(w, http.StatusBadGateway, callErr.Error())
+	default:
+		h.logger.Error(operation+" failed", zap.Error(err))
+		writeError(w, http.StatusInternalServerError, operation+" failed")
+	}
+}
+
+func diagnosticsID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || id <= 0 {
+		writeError(w, http.StatusBadRequest, "invalid diagnostics id")
+		return 0, false
+	}
+	return id, true
+}
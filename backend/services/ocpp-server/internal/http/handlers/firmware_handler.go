@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+
+	"drivepower/backend/services/ocpp-server/internal/models"
+	"drivepower/backend/services/ocpp-server/internal/repository"
+	"drivepower/backend/services/ocpp-server/internal/service"
+)
+
+// FirmwareHandler exposes firmware artifacts and update campaigns.
+type FirmwareHandler struct {
+	firmware *service.FirmwareService
+	logger   *zap.Logger
+}
+
+// NewFirmwareHandler builds handler set.
+func NewFirmwareHandler(firmware *service.FirmwareService, logger *zap.Logger) *FirmwareHandler {
+	return &FirmwareHandler{
+		firmware: firmware,
+		logger:   logger,
+	}
+}
+
+type registerArtifactRequest struct {
+	Version string `json:"version"`
+	URL     string `json:"url"`
+}
+
+type campaignRequest struct {
+	Name             string `json:"name"`
+	ArtifactID       int64  `json:"artifact_id"`
+	Vendor           string `json:"vendor"`
+	Model            string `json:"model"`
+	BatchSize        int    `json:"batch_size"`
+	FailureThreshold *int   `json:"failure_threshold"`
+}
+
+// HandleCreateArtifact handles POST /internal/firmware/artifacts. JSON body
+// {version, url} registers image hosted elsewhere; any other body is the image
+// itself, named by ?version=&file_name=.
+func (h *FirmwareHandler) HandleCreateArtifact(w http.ResponseWriter, r *http.Request) {
+	var (
+		artifact *models.FirmwareArtifact
+		err      error
+	)
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "application/json" {
+		var req registerArtifactRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid json")
+			return
+		}
+		artifact, err = h.firmware.Register(r.Context(), req.Version, req.URL)
+	} else {
+		// Image may take longer to arrive than the server timeouts allow; the
+		// answer is written only after the whole image was stored.
+		rc := http.NewResponseController(w)
+		_ = rc.SetReadDeadline(time.Time{})
+		_ = rc.SetWriteDeadline(time.Time{})
+		query := r.URL.Query()
+		artifact, err = h.firmware.Upload(r.Context(), query.Get("version"), query.Get("file_name"), r.Body)
+	}
+	if err != nil {
+		h.writeFirmwareError(w, "store firmware artifact", err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, artifact)
+}
+
+// HandleListArtifacts handles GET /internal/firmware/artifacts.
+func (h *FirmwareHandler) HandleListArtifacts(w http.ResponseWriter, r *http.Request) {
+	artifacts, err := h.firmware.Artifacts(r.Context())
+	if err != nil {
+		h.writeFirmwareError(w, "list firmware artifacts", err)
+		return
+	}
+	if artifacts == nil {
+		artifacts = []models.FirmwareArtifact{}
+	}
+	writeJSON(w, http.StatusOK, artifacts)
+}
+
+// HandleDownload handles GET /firmware/{id}/{name}; stations download
+// uploaded images from here.
+func (h *FirmwareHandler) HandleDownload(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	file, artifact, err := h.firmware.OpenArtifact(r.Context(), id, r.PathValue("name"))
+	if errors.Is(err, repository.ErrFirmwareArtifactNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		h.logger.Error("open firmware artifact failed", zap.Int64("artifact_id", id), zap.Error(err))
+		http.Error(w, "open firmware failed", http.StatusInternalServerError)
+		return
+	}
+	defer file.Close()
+
+	// Stations on slow links need longer than the server write timeout.
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+	h.logger.Info("firmware download", zap.Int64("artifact_id", id), zap.String("remote_addr", r.RemoteAddr))
+	w.Header().Set("Content-Type", "application/octet-stream")
+	http.ServeContent(w, r, artifact.FileName, artifact.CreatedAt, file)
+}
+
+// HandleCreateCampaign handles POST /internal/firmware/campaigns.
+func (h *FirmwareHandler) HandleCreateCampaign(w http.ResponseWriter, r *http.Request) {
+	var req campaignRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+
+	campaign, err := h.firmware.CreateCampaign(r.Context(), service.CampaignInput{
+		Name:             req.Name,
+		ArtifactID:       req.ArtifactID,
+		Vendor:           req.Vendor,
+		Model:            req.Model,
+		BatchSize:        req.BatchSize,
+		FailureThreshold: req.FailureThreshold,
+		RequestedBy:      requestedBy(r),
+	})
+	if err != nil {
+		h.writeFirmwareError(w, "create firmware campaign", err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, campaign)
+}
+
+// HandleListCampaigns handles GET /internal/firmware/campaigns.
+func (h *FirmwareHandler) HandleListCampaigns(w http.ResponseWriter, r *http.Request) {
+	campaigns, err := h.firmware.Campaigns(r.Context())
+	if err != nil {
+		h.writeFirmwareError(w, "list firmware campaigns", err)
+		return
+	}
+	if campaigns == nil {
+		campaigns = []models.FirmwareCampaign{}
+	}
+	writeJSON(w, http.StatusOK, campaigns)
+}
+
+// HandleCampaign handles GET /internal/firmware/campaigns/{id} and returns
+// campaign dashboard: station counts by state and progress of each station.
+func (h *FirmwareHandler) HandleCampaign(w http.ResponseWriter, r *http.Request) {
+	id, ok := campaignID(w, r)
+	if !ok {
+		return
+	}
+	report, err := h.firmware.Report(r.Context(), id)
+	if err != nil {
+		h.writeFirmwareError(w, "load firmware campaign", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+// HandlePause handles POST /internal/firmware/campaigns/{id}/pause.
+func (h *FirmwareHandler) HandlePause(w http.ResponseWriter, r *http.Request) {
+	id, ok := campaignID(w, r)
+	if !ok {
+		return
+	}
+	campaign, err := h.firmware.Pause(r.Context(), id)
+	if err != nil {
+		h.writeFirmwareError(w, "pause firmware campaign", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, campaign)
+}
+
+// HandleResume handles POST /internal/firmware/campaigns/{id}/resume.
+func (h *FirmwareHandler) HandleResume(w http.ResponseWriter, r *http.Request) {
+	id, ok := campaignID(w, r)
+	if !ok {
+		return
+	}
+	campaign, err := h.firmware.Resume(r.Context(), id)
+	if err != nil {
+		h.writeFirmwareError(w, "resume firmware campaign", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, campaign)
+}
+
+func (h *FirmwareHandler) writeFirmwareError(w http.ResponseWriter, operation string, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidFirmware):
+		writeError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, repository.ErrFirmwareArtifactNotFound):
+		writeError(w, http.StatusNotFound, "firmware artifact not found")
+	case errors.Is(err, repository.ErrFirmwareCampaignNotFound):
+		writeError(w, http.StatusNotFound, "firmware campaign not found")
+	case errors.Is(err, service.ErrCampaignState):
+		writeError(w, http.StatusConflict, err.Error())
+	default:
+		h.logger.Error(operation+" failed", zap.Error(err))
+		writeError(w, http.StatusInternalServerError, operation+" failed")
+	}
+}
+
+func campaignID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || id <= 0 {
+		writeError(w, http.StatusBadRequest, "invalid campaign id")
+		return 0, false
+	}
+	return id, true
+}
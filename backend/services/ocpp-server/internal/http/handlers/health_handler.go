@@ -0,0 +1,10 @@
+package handlers
+
+import "net/http"
+
+// NewHealthHandler returns GET /health handler.
+func NewHealthHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	}
+}
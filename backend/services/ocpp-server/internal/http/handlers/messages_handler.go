@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+
+	"drivepower/backend/services/ocpp-server/internal/models"
+	"drivepower/backend/services/ocpp-server/internal/repository"
+	"drivepower/backend/services/ocpp-server/internal/service"
+)
+
+// MessagesHandler exposes search over the OCPP message log.
+type MessagesHandler struct {
+	log    *service.MessageLog
+	logger *zap.Logger
+}
+
+// NewMessagesHandler builds handler set.
+func NewMessagesHandler(log *service.MessageLog, logger *zap.Logger) *MessagesHandler {
+	return &MessagesHandler{
+		log:    log,
+		logger: logger,
+	}
+}
+
+// HandleSearch handles GET /internal/ocpp-messages?station_id=&from=&to=&action=&direction=&frame_type=&unique_id=&limit=.
+// Times are RFC 3339; the range is [from, to).
+func (h *MessagesHandler) HandleSearch(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := repository.MessageFilter{
+		StationID: query.Get("station_id"),
+		Action:    query.Get("action"),
+		Direction: query.Get("direction"),
+		FrameType: query.Get("frame_type"),
+		UniqueID:  query.Get("unique_id"),
+	}
+	switch filter.Direction {
+	case "", models.DirectionIncoming, models.DirectionOutgoing:
+	default:
+		writeError(w, http.StatusBadRequest, "invalid direction")
+		return
+	}
+	switch filter.FrameType {
+	case "", models.FrameCall, models.FrameCallResult, models.FrameCallError:
+	default:
+		writeError(w, http.StatusBadRequest, "invalid frame_type")
+		return
+	}
+	var err error
+	if filter.From, err = parseTime(query.Get("from")); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid from")
+		return
+	}
+	if filter.To, err = parseTime(query.Get("to")); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid to")
+		return
+	}
+	filter.Limit, _ = strconv.Atoi(query.Get("limit"))
+
+	messages, err := h.log.Search(r.Context(), filter)
+	if err != nil {
+		h.logger.Error("search ocpp messages failed", zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to search messages")
+		return
+	}
+	if messages == nil {
+		messages = []models.OCPPMessage{}
+	}
+	writeJSON(w, http.StatusOK, messages)
+}
+
+// HandleExchange handles GET /internal/ocpp-messages/{stationId}/{uniqueId}.
+func (h *MessagesHandler) HandleExchange(w http.ResponseWriter, r *http.Request) {
+	stationID, uniqueID := r.PathValue("stationId"), r.PathValue("uniqueId")
+	exchange, err := h.log.Exchange(r.Context(), stationID, uniqueID)
+	switch {
+	case errors.Is(err, repository.ErrMessageNotFound):
+		writeError(w, http.StatusNotFound, "call not found")
+	case err != nil:
+		h.logger.Error("load ocpp exchange failed", zap.String("station_id", stationID), zap.String("unique_id", uniqueID), zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to load exchange")
+	default:
+		writeJSON(w, http.StatusOK, exchange)
+	}
+}
+
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
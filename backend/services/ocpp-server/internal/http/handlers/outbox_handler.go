@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"go.uber.org/zap"
+
+	"drivepower/backend/services/ocpp-server/internal/models"
+	"drivepower/backend/services/ocpp-server/internal/repository"
+	"drivepower/backend/services/ocpp-server/internal/service"
+)
+
+// OutboxHandler exposes inspection and replay of queued notifications.
+type OutboxHandler struct {
+	outbox *service.Outbox
+	logger *zap.Logger
+}
+
+// NewOutboxHandler builds handler set.
+func NewOutboxHandler(outbox *service.Outbox, logger *zap.Logger) *OutboxHandler {
+	return &OutboxHandler{
+		outbox: outbox,
+		logger: logger,
+	}
+}
+
+// HandleList handles GET /internal/outbox?status=dead&limit=100.
+func (h *OutboxHandler) HandleList(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "":
+		status = models.OutboxDead
+	case models.OutboxPending, models.OutboxDelivered, models.OutboxDead:
+	default:
+		writeError(w, http.StatusBadRequest, "invalid status")
+		return
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+	events, err := h.outbox.List(r.Context(), status, limit)
+	if err != nil {
+		h.logger.Error("list outbox events failed", zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to list outbox events")
+		return
+	}
+	if events == nil {
+		events = []models.OutboxEvent{}
+	}
+	writeJSON(w, http.StatusOK, events)
+}
+
+// HandleReplay handles POST /internal/outbox/{id}/replay.
+func (h *OutboxHandler) HandleReplay(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+	err = h.outbox.Replay(r.Context(), id)
+	switch {
+	case errors.Is(err, repository.ErrOutboxEventNotFound):
+		writeError(w, http.StatusNotFound, "dead event not found")
+	case err != nil:
+		h.logger.Error("replay outbox event failed", zap.Int64("event_id", id), zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to replay event")
+	default:
+		writeJSON(w, http.StatusOK, map[string]string{"status": models.OutboxPending})
+	}
+}
+
+// HandleReplayAll handles POST /internal/outbox/replay.
+func (h *OutboxHandler) HandleReplayAll(w http.ResponseWriter, r *http.Request) {
+	count, err := h.outbox.ReplayAll(r.Context())
+	if err != nil {
+		h.logger.Error("replay dead outbox events failed", zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to replay events")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]int64{"replayed": count})
+}
@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+
+	"drivepower/backend/services/ocpp-server/internal/models"
+	"drivepower/backend/services/ocpp-server/internal/ocpp"
+	"drivepower/backend/services/ocpp-server/internal/repository"
+	"drivepower/backend/services/ocpp-server/internal/service"
+	"drivepower/backend/services/ocpp-server/internal/ws"
+)
+
+// ReservationsHandler exposes connector bookings. Optional ?user_id= limits
+// lookups and cancellation to reservations of that user.
+type ReservationsHandler struct {
+	reservations *service.ReservationService
+	logger       *zap.Logger
+}
+
+// NewReservationsHandler builds handler set.
+func NewReservationsHandler(reservations *service.ReservationService, logger *zap.Logger) *ReservationsHandler {
+	return &ReservationsHandler{
+		reservations: reservations,
+		logger:       logger,
+	}
+}
+
+type bookingRequest struct {
+	StationID   string     `json:"station_id"`
+	ConnectorID int        `json:"connector_id"`
+	IdTag       string     `json:"id_tag"`
+	StartTime   *time.Time `json:"start_time"`
+	EndTime     time.Time  `json:"end_time"`
+}
+
+// HandleBook handles POST /internal/reservations; the reservation belongs to
+// the requesting user.
+func (h *ReservationsHandler) HandleBook(w http.ResponseWriter, r *http.Request) {
+	var req bookingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+
+	reservation, err := h.reservations.Book(r.Context(), service.BookingInput{
+		StationID:   req.StationID,
+		ConnectorID: req.ConnectorID,
+		IdTag:       req.IdTag,
+		StartAt:     req.StartTime,
+		EndAt:       req.EndTime,
+		UserID:      requestedBy(r),
+	})
+	if err != nil {
+		h.writeReservationError(w, "book reservation", err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, reservation)
+}
+
+// HandleList handles GET /internal/reservations?station_id=&user_id=&limit=.
+func (h *ReservationsHandler) HandleList(w http.ResponseWriter, r *http.Request) {
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	reservations, err := h.reservations.List(r.Context(), repository.ReservationFilter{
+		StationID: r.URL.Query().Get("station_id"),
+		UserID:    scopeUserID(r),
+		Limit:     limit,
+	})
+	if err != nil {
+		h.writeReservationError(w, "list reservations", err)
+		return
+	}
+	if reservations == nil {
+		reservations = []models.Reservation{}
+	}
+	writeJSON(w, http.StatusOK, reservations)
+}
+
+// HandleGet handles GET /internal/reservations/{id}.
+func (h *ReservationsHandler) HandleGet(w http.ResponseWriter, r *http.Request) {
+	id, ok := reservationID(w, r)
+	if !ok {
+		return
+	}
+	reservation, err := h.reservations.Get(r.Context(), id, scopeUserID(r))
+	if err != nil {
+		h.writeReservationError(w, "load reservation", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, reservation)
+}
+
+// HandleCancel handles POST /internal/reservations/{id}/cancel.
+func (h *ReservationsHandler) HandleCancel(w http.ResponseWriter, r *http.Request) {
+	id, ok := reservationID(w, r)
+	if !ok {
+		return
+	}
+	reservation, err := h.reservations.Cancel(r.Context(), id, scopeUserID(r), requestedBy(r))
+	if err != nil {
+		h.writeReservationError(w, "cancel reservation", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, reservation)
+}
+
+func (h *ReservationsHandler) writeReservationError(w http.ResponseWriter, operation string, err error) {
+	var callErr *ocpp.CallError
+	switch {
+	case errors.Is(err, service.ErrInvalidReservation):
+		writeError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrIdTagNotAllowed):
+		writeError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, repository.ErrReservationNotFound):
+		writeError(w, http.StatusNotFound, "reservation not found")
+	case errors.Is(err, repository.ErrStationNotFound):
+		writeError(w, http.StatusNotFound, "station not found")
+	case errors.Is(err, repository.ErrReservationConflict):
+		writeError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, service.ErrReservationState):
+		writeError(w, http.StatusConflict, "reservation cannot be cancelled in its state")
+	case errors.Is(err, service.ErrReservationRejected):
+		writeError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, ws.ErrStationNotConnected):
+		writeError(w, http.StatusConflict, "station not connected")
+	case errors.Is(err, ocpp.ErrCallTimeout):
+		writeError(w, http.StatusGatewayTimeout, "station did not respond")
+	case errors.As(err, &callErr):
+		writeError(w, http.StatusBadGateway, callErr.Error())
+	default:
+		h.logger.Error(operation+" failed", zap.Error(err))
+		writeError(w, http.StatusInternalServerError, operation+" failed")
+	}
+}
+
+func reservationID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || id <= 0 {
+		writeError(w, http.StatusBadRequest, "invalid reservation id")
+		return 0, false
+	}
+	return id, true
+}
+
+// scopeUserID returns ?user_id= that limits request to reservations of the
+// user, 0 when absent.
+func scopeUserID(r *http.Request) int64 {
+	userID, _ := strconv.ParseInt(r.URL.Query().Get("user_id"), 10, 64)
+	return userID
+}
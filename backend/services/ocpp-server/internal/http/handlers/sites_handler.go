@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"go.uber.org/zap"
+
+	"drivepower/backend/services/ocpp-server/internal/models"
+	"drivepower/backend/services/ocpp-server/internal/repository"
+	"drivepower/backend/services/ocpp-server/internal/service"
+)
+
+// SitesHandler exposes site load balancing administration.
+type SitesHandler struct {
+	power  *service.SitePowerManager
+	logger *zap.Logger
+}
+
+// NewSitesHandler builds handler set.
+func NewSitesHandler(power *service.SitePowerManager, logger *zap.Logger) *SitesHandler {
+	return &SitesHandler{
+		power:  power,
+		logger: logger,
+	}
+}
+
+type siteRequest struct {
+	Name                string   `json:"name"`
+	MaxCurrent          float64  `json:"max_current"`
+	MinCurrent          *float64 `json:"min_current"`
+	ConnectorMaxCurrent *float64 `json:"connector_max_current"`
+	Strategy            string   `json:"strategy"`
+	Stations            []string `json:"stations"`
+}
+
+type userTierRequest struct {
+	Tier int `json:"tier"`
+}
+
+// HandleList handles GET /internal/sites.
+func (h *SitesHandler) HandleList(w http.ResponseWriter, r *http.Request) {
+	sites, err := h.power.Sites(r.Context())
+	if err != nil {
+		h.writeSiteError(w, "list sites", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, sites)
+}
+
+// HandleGet handles GET /internal/sites/{id}.
+func (h *SitesHandler) HandleGet(w http.ResponseWriter, r *http.Request) {
+	site, err := h.power.Site(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.writeSiteError(w, "load site", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, site)
+}
+
+// HandleSave handles PUT /internal/sites/{id}; body replaces site and its stations.
+func (h *SitesHandler) HandleSave(w http.ResponseWriter, r *http.Request) {
+	var req siteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+
+	site, err := h.power.SaveSite(r.Context(), service.SiteInput{
+		ID:                  r.PathValue("id"),
+		Name:                req.Name,
+		MaxCurrent:          req.MaxCurrent,
+		MinCurrent:          req.MinCurrent,
+		ConnectorMaxCurrent: req.ConnectorMaxCurrent,
+		Strategy:            req.Strategy,
+		Stations:            req.Stations,
+	})
+	if err != nil {
+		h.writeSiteError(w, "save site", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, site)
+}
+
+// HandleDelete handles DELETE /internal/sites/{id}.
+func (h *SitesHandler) HandleDelete(w http.ResponseWriter, r *http.Request) {
+	if err := h.power.DeleteSite(r.Context(), r.PathValue("id")); err != nil {
+		h.writeSiteError(w, "delete site", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleLoad handles GET /internal/sites/{id}/load and returns limits of active transactions.
+func (h *SitesHandler) HandleLoad(w http.ResponseWriter, r *http.Request) {
+	allocations, err := h.power.Allocations(r.Context(), r.PathValue("id"))
+	if err != nil {
+		h.writeSiteError(w, "load site allocations", err)
+		return
+	}
+	if allocations == nil {
+		allocations = []models.SiteAllocation{}
+	}
+	writeJSON(w, http.StatusOK, allocations)
+}
+
+// HandleSetUserTier handles PUT /internal/user-tiers/{userId}.
+func (h *SitesHandler) HandleSetUserTier(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseInt(r.PathValue("userId"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid user id")
+		return
+	}
+	var req userTierRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	if err := h.power.SetUserTier(r.Context(), userID, req.Tier); err != nil {
+		h.writeSiteError(w, "set user tier", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, req)
+}
+
+func (h *SitesHandler) writeSiteError(w http.ResponseWriter, operation string, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidSite):
+		writeError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, repository.ErrSiteNotFound):
+		writeError(w, http.StatusNotFound, "site not found")
+	default:
+		h.logger.Error(operation+" failed", zap.Error(err))
+		writeError(w, http.StatusInternalServerError, operation+" failed")
+	}
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"drivepower/backend/services/ocpp-server/internal/models"
+	"drivepower/backend/services/ocpp-server/internal/repository"
+	"drivepower/backend/services/ocpp-server/internal/service"
+)
+
+// StationsHandler exposes internal station registry administration.
+type StationsHandler struct {
+	registry *service.StationRegistry
+	liveness *service.LivenessMonitor
+	logger   *zap.Logger
+}
+
+// NewStationsHandler builds handler set.
+func NewStationsHandler(registry *service.StationRegistry, liveness *service.LivenessMonitor, logger *zap.Logger) *StationsHandler {
+	return &StationsHandler{
+		registry: registry,
+		liveness: liveness,
+		logger:   logger,
+	}
+}
+
+type approveStationRequest struct {
+	HeartbeatInterval int `json:"heartbeat_interval"`
+}
+
+// HandleList handles GET /internal/stations?registration_status=pending.
+func (h *StationsHandler) HandleList(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("registration_status")
+	switch status {
+	case "", models.RegistrationPending, models.RegistrationAccepted, models.RegistrationDecommissioned:
+	default:
+		writeError(w, http.StatusBadRequest, "invalid registration_status")
+		return
+	}
+
+	stations, err := h.registry.List(r.Context(), status)
+	if err != nil {
+		h.logger.Error("list stations failed", zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to list stations")
+		return
+	}
+	if stations == nil {
+		stations = []models.Station{}
+	}
+	writeJSON(w, http.StatusOK, stations)
+}
+
+// HandleOffline handles GET /internal/stations/offline.
+func (h *StationsHandler) HandleOffline(w http.ResponseWriter, r *http.Request) {
+	stations, err := h.liveness.Offline(r.Context())
+	if err != nil {
+		h.logger.Error("list offline stations failed", zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to list offline stations")
+		return
+	}
+	if stations == nil {
+		stations = []models.Station{}
+	}
+	writeJSON(w, http.StatusOK, stations)
+}
+
+// HandleApprove handles POST /internal/stations/{id}/approve.
+func (h *StationsHandler) HandleApprove(w http.ResponseWriter, r *http.Request) {
+	var req approveStationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, "invalid json")
+		return
+	}
+	if req.HeartbeatInterval < 0 {
+		writeError(w, http.StatusBadRequest, "heartbeat_interval must not be negative")
+		return
+	}
+
+	stationID := r.PathValue("id")
+	if err := h.registry.Approve(r.Context(), stationID, req.HeartbeatInterval); err != nil {
+		h.logger.Error("approve station failed", zap.String("station_id", stationID), zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to approve station")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"registration_status": models.RegistrationAccepted})
+}
+
+// HandleDecommission handles POST /internal/stations/{id}/decommission.
+func (h *StationsHandler) HandleDecommission(w http.ResponseWriter, r *http.Request) {
+	stationID := r.PathValue("id")
+	err := h.registry.Decommission(r.Context(), stationID)
+	switch {
+	case errors.Is(err, repository.ErrStationNotFound):
+		writeError(w, http.StatusNotFound, "station not found")
+	case err != nil:
+		h.logger.Error("decommission station failed", zap.String("station_id", stationID), zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to decommission station")
+	default:
+		writeJSON(w, http.StatusOK, map[string]string{"registration_status": models.RegistrationDecommissioned})
+	}
+}
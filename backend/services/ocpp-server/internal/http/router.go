@@ -0,0 +1,271 @@
+package httpserver
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// Routes groups handlers.
+type Routes struct {
+	Health      http.HandlerFunc
+	OCPP        http.HandlerFunc
+	RemoteStart http.HandlerFunc
+	RemoteStop  http.HandlerFunc
+
+	Reset              http.HandlerFunc
+	ChangeAvailability http.HandlerFunc
+	UnlockConnector    http.HandlerFunc
+	TriggerMessage     http.HandlerFunc
+	ClearCache         http.HandlerFunc
+	CommandAudit       http.HandlerFunc
+
+	ListStations        http.HandlerFunc
+	OfflineStations     http.HandlerFunc
+	ApproveStation      http.HandlerFunc
+	DecommissionStation http.HandlerFunc
+
+	ListOutbox      http.HandlerFunc
+	ReplayOutbox    http.HandlerFunc
+	ReplayAllOutbox http.HandlerFunc
+
+	SearchMessages  http.HandlerFunc
+	MessageExchange http.HandlerFunc
+
+	GetDesiredConfig http.HandlerFunc
+	SetDesiredConfig http.HandlerFunc
+	SetConfigGroup   http.HandlerFunc
+	SyncConfig       http.HandlerFunc
+	ConfigDrift      http.HandlerFunc
+
+	CreateChargingProfile http.HandlerFunc
+	ListChargingProfiles  http.HandlerFunc
+	GetChargingProfile    http.HandlerFunc
+	UpdateChargingProfile http.HandlerFunc
+	DeleteChargingProfile http.HandlerFunc
+	CompositeSchedule     http.HandlerFunc
+
+	ListSites   http.HandlerFunc
+	GetSite     http.HandlerFunc
+	SaveSite    http.HandlerFunc
+	DeleteSite  http.HandlerFunc
+	SiteLoad    http.HandlerFunc
+	SetUserTier http.HandlerFunc
+
+	FirmwareDownload       http.HandlerFunc
+	CreateFirmwareArtifact http.HandlerFunc
+	ListFirmwareArtifacts  http.HandlerFunc
+	CreateFirmwareCampaign http.HandlerFunc
+	ListFirmwareCampaigns  http.HandlerFunc
+	FirmwareCampaign       http.HandlerFunc
+	PauseFirmwareCampaign  http.HandlerFunc
+	ResumeFirmwareCampaign http.HandlerFunc
+
+	DiagnosticsUpload  http.HandlerFunc
+	RequestDiagnostics http.HandlerFunc
+	ListDiagnostics    http.HandlerFunc
+	GetDiagnostics     http.HandlerFunc
+	DiagnosticsFile    http.HandlerFunc
+
+	BookReservation   http.HandlerFunc
+	ListReservations  http.HandlerFunc
+	GetReservation    http.HandlerFunc
+	CancelReservation http.HandlerFunc
+}
+
+// NewRouter registers endpoints.
+func NewRouter(routes Routes) http.Handler {
+	mux := http.NewServeMux()
+	if routes.Health != nil {
+		mux.Handle("/health", method(http.MethodGet, routes.Health))
+	}
+	if routes.OCPP != nil {
+		mux.Handle("/ocpp/ws", routes.OCPP)
+		mux.Handle("/ocpp/{id}", routes.OCPP)
+	}
+	if routes.RemoteStart != nil {
+		mux.Handle("/internal/commands/remote-start", method(http.MethodPost, routes.RemoteStart))
+	}
+	if routes.RemoteStop != nil {
+		mux.Handle("/internal/commands/remote-stop", method(http.MethodPost, routes.RemoteStop))
+	}
+	if routes.Reset != nil {
+		mux.Handle("/internal/commands/reset", method(http.MethodPost, routes.Reset))
+	}
+	if routes.ChangeAvailability != nil {
+		mux.Handle("/internal/commands/change-availability", method(http.MethodPost, routes.ChangeAvailability))
+	}
+	if routes.UnlockConnector != nil {
+		mux.Handle("/internal/commands/unlock-connector", method(http.MethodPost, routes.UnlockConnector))
+	}
+	if routes.TriggerMessage != nil {
+		mux.Handle("/internal/commands/trigger-message", method(http.MethodPost, routes.TriggerMessage))
+	}
+	if routes.ClearCache != nil {
+		mux.Handle("/internal/commands/clear-cache", method(http.MethodPost, routes.ClearCache))
+	}
+	if routes.CommandAudit != nil {
+		mux.Handle("/internal/commands/audit", method(http.MethodGet, routes.CommandAudit))
+	}
+	if routes.ListStations != nil {
+		mux.Handle("/internal/stations", method(http.MethodGet, routes.ListStations))
+	}
+	if routes.OfflineStations != nil {
+		mux.Handle("/internal/stations/offline", method(http.MethodGet, routes.OfflineStations))
+	}
+	if routes.ApproveStation != nil {
+		mux.Handle("/internal/stations/{id}/approve", method(http.MethodPost, routes.ApproveStation))
+	}
+	if routes.DecommissionStation != nil {
+		mux.Handle("/internal/stations/{id}/decommission", method(http.MethodPost, routes.DecommissionStation))
+	}
+	if routes.ListOutbox != nil {
+		mux.Handle("/internal/outbox", method(http.MethodGet, routes.ListOutbox))
+	}
+	if routes.ReplayOutbox != nil {
+		mux.Handle("/internal/outbox/{id}/replay", method(http.MethodPost, routes.ReplayOutbox))
+	}
+	if routes.ReplayAllOutbox != nil {
+		mux.Handle("/internal/outbox/replay", method(http.MethodPost, routes.ReplayAllOutbox))
+	}
+	if routes.SearchMessages != nil {
+		mux.Handle("/internal/ocpp-messages", method(http.MethodGet, routes.SearchMessages))
+	}
+	if routes.MessageExchange != nil {
+		mux.Handle("/internal/ocpp-messages/{stationId}/{uniqueId}", method(http.MethodGet, routes.MessageExchange))
+	}
+	if routes.GetDesiredConfig != nil && routes.SetDesiredConfig != nil {
+		mux.Handle("/internal/config/{scope}/{target}", byMethod(map[string]http.HandlerFunc{
+			http.MethodGet: routes.GetDesiredConfig,
+			http.MethodPut: routes.SetDesiredConfig,
+		}))
+	}
+	if routes.SetConfigGroup != nil {
+		mux.Handle("/internal/stations/{id}/config-group", method(http.MethodPut, routes.SetConfigGroup))
+	}
+	if routes.SyncConfig != nil {
+		mux.Handle("/internal/stations/{id}/config-sync", method(http.MethodPost, routes.SyncConfig))
+	}
+	if routes.ConfigDrift != nil {
+		mux.Handle("/internal/config-drift", method(http.MethodGet, routes.ConfigDrift))
+	}
+	if routes.CreateChargingProfile != nil && routes.ListChargingProfiles != nil {
+		mux.Handle("/internal/charging-profiles", byMethod(map[string]http.HandlerFunc{
+			http.MethodGet:  routes.ListChargingProfiles,
+			http.MethodPost: routes.CreateChargingProfile,
+		}))
+	}
+	if routes.GetChargingProfile != nil && routes.UpdateChargingProfile != nil && routes.DeleteChargingProfile != nil {
+		mux.Handle("/internal/charging-profiles/{id}", byMethod(map[string]http.HandlerFunc{
+			http.MethodGet:    routes.GetChargingProfile,
+			http.MethodPut:    routes.UpdateChargingProfile,
+			http.MethodDelete: routes.DeleteChargingProfile,
+		}))
+	}
+	if routes.CompositeSchedule != nil {
+		mux.Handle("/internal/stations/{id}/composite-schedule", method(http.MethodGet, routes.CompositeSchedule))
+	}
+	if routes.ListSites != nil {
+		mux.Handle("/internal/sites", method(http.MethodGet, routes.ListSites))
+	}
+	if routes.GetSite != nil && routes.SaveSite != nil && routes.DeleteSite != nil {
+		mux.Handle("/internal/sites/{id}", byMethod(map[string]http.HandlerFunc{
+			http.MethodGet:    routes.GetSite,
+			http.MethodPut:    routes.SaveSite,
+			http.MethodDelete: routes.DeleteSite,
+		}))
+	}
+	if routes.SiteLoad != nil {
+		mux.Handle("/internal/sites/{id}/load", method(http.MethodGet, routes.SiteLoad))
+	}
+	if routes.SetUserTier != nil {
+		mux.Handle("/internal/user-tiers/{userId}", method(http.MethodPut, routes.SetUserTier))
+	}
+	if routes.FirmwareDownload != nil {
+		mux.Handle("/firmware/{id}/{name}", method(http.MethodGet, routes.FirmwareDownload))
+	}
+	if routes.CreateFirmwareArtifact != nil && routes.ListFirmwareArtifacts != nil {
+		mux.Handle("/internal/firmware/artifacts", byMethod(map[string]http.HandlerFunc{
+			http.MethodGet:  routes.ListFirmwareArtifacts,
+			http.MethodPost: routes.CreateFirmwareArtifact,
+		}))
+	}
+	if routes.CreateFirmwareCampaign != nil && routes.ListFirmwareCampaigns != nil {
+		mux.Handle("/internal/firmware/campaigns", byMethod(map[string]http.HandlerFunc{
+			http.MethodGet:  routes.ListFirmwareCampaigns,
+			http.MethodPost: routes.CreateFirmwareCampaign,
+		}))
+	}
+	if routes.FirmwareCampaign != nil {
+		mux.Handle("/internal/firmware/campaigns/{id}", method(http.MethodGet, routes.FirmwareCampaign))
+	}
+	if routes.PauseFirmwareCampaign != nil {
+		mux.Handle("/internal/firmware/campaigns/{id}/pause", method(http.MethodPost, routes.PauseFirmwareCampaign))
+	}
+	if routes.ResumeFirmwareCampaign != nil {
+		mux.Handle("/internal/firmware/campaigns/{id}/resume", method(http.MethodPost, routes.ResumeFirmwareCampaign))
+	}
+	if routes.DiagnosticsUpload != nil {
+		upload := byMethod(map[string]http.HandlerFunc{
+			http.MethodPut:  routes.DiagnosticsUpload,
+			http.MethodPost: routes.DiagnosticsUpload,
+		})
+		mux.Handle("/diagnostics/{token}", upload)
+		mux.Handle("/diagnostics/{token}/{$}", upload)
+		mux.Handle("/diagnostics/{token}/{name}", upload)
+	}
+	if routes.RequestDiagnostics != nil && routes.ListDiagnostics != nil {
+		mux.Handle("/internal/diagnostics", byMethod(map[string]http.HandlerFunc{
+			http.MethodGet:  routes.ListDiagnostics,
+			http.MethodPost: routes.RequestDiagnostics,
+		}))
+	}
+	if routes.GetDiagnostics != nil {
+		mux.Handle("/internal/diagnostics/{id}", method(http.MethodGet, routes.GetDiagnostics))
+	}
+	if routes.DiagnosticsFile != nil {
+		mux.Handle("/internal/diagnostics/{id}/file", method(http.MethodGet, routes.DiagnosticsFile))
+	}
+	if routes.BookReservation != nil && routes.ListReservations != nil {
+		mux.Handle("/internal/reservations", byMethod(map[string]http.HandlerFunc{
+			http.MethodGet:  routes.ListReservations,
+			http.MethodPost: routes.BookReservation,
+		}))
+	}
+	if routes.GetReservation != nil {
+		mux.Handle("/internal/reservations/{id}", method(http.MethodGet, routes.GetReservation))
+	}
+	if routes.CancelReservation != nil {
+		mux.Handle("/internal/reservations/{id}/cancel", method(http.MethodPost, routes.CancelReservation))
+	}
+	return mux
+}
+
+func method(expected string, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != expected {
+			w.Header().Set("Allow", expected)
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		handler(w, r)
+	}
+}
+
+// byMethod serves path that accepts several methods.
+func byMethod(handlers map[string]http.HandlerFunc) http.HandlerFunc {
+	allowed := make([]string, 0, len(handlers))
+	for m := range handlers {
+		allowed = append(allowed, m)
+	}
+	sort.Strings(allowed)
+	return func(w http.ResponseWriter, r *http.Request) {
+		handler, ok := handlers[r.Method]
+		if !ok {
+			w.Header().Set("Allow", strings.Join(allowed, ", "))
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		handler(w, r)
+	}
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"drivepower/backend/services/ocpp-server/internal/ocpp/protocol"
+)
+
+// Charging profile states on station.
+const (
+	ProfilePending   = "pending"
+	ProfileInstalled = "installed"
+	ProfileRejected  = "rejected"
+	ProfileFailed    = "failed"
+	ProfileClearing  = "clearing"
+)
+
+// ChargingProfile is smart charging profile managed by CSMS. ID is sent to
+// station as chargingProfileId.
+type ChargingProfile struct {
+	ID          int64                    `db:"id" json:"id"`
+	StationID   string                   `db:"station_id" json:"stationId"`
+	ConnectorID int                      `db:"connector_id" json:"connectorId"`
+	Profile     protocol.ChargingProfile `db:"profile" json:"profile"`
+	Status      string                   `db:"status" json:"status"`
+	LastError   string                   `db:"last_error" json:"lastError,omitempty"`
+	CreatedAt   time.Time                `db:"created_at" json:"createdAt"`
+	UpdatedAt   time.Time                `db:"updated_at" json:"updatedAt"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// CommandAudit is CSMS-initiated command sent to station with its outcome.
+// Response is nil and Error is set when the station gave no CALLRESULT.
+type CommandAudit struct {
+	ID          int64           `db:"id" json:"id"`
+	StationID   string          `db:"station_id" json:"stationId"`
+	Action      string          `db:"action" json:"action"`
+	Request     json.RawMessage `db:"request" json:"request"`
+	Response    json.RawMessage `db:"response" json:"response,omitempty"`
+	Status      string          `db:"status" json:"status,omitempty"`
+	Error       string          `db:"error" json:"error,omitempty"`
+	RequestedBy int64           `db:"requested_by" json:"requestedBy,omitempty"`
+	CreatedAt   time.Time       `db:"created_at" json:"createdAt"`
+	CompletedAt time.Time       `db:"completed_at" json:"completedAt"`
+}
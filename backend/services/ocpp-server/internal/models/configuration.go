@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// Scopes of desired configuration; station keys override keys of its group.
+const (
+	ConfigScopeStation = "station"
+	ConfigScopeGroup   = "group"
+)
+
+// Sync states of configuration key.
+const (
+	ConfigInSync         = "in_sync"
+	ConfigPending        = "pending"
+	ConfigRebootRequired = "reboot_required"
+	ConfigRejected       = "rejected"
+	ConfigReadOnly       = "read_only"
+	ConfigNotSupported   = "not_supported"
+	ConfigFailed         = "failed"
+)
+
+// ConfigKeyState is result of last sync of desired configuration key.
+// Reported is nil when station did not report the key.
+type ConfigKeyState struct {
+	StationID string    `db:"station_id" json:"stationId"`
+	Key       string    `db:"key" json:"key"`
+	Desired   string    `db:"desired_value" json:"desired"`
+	Reported  *string   `db:"reported_value" json:"reported"`
+	Readonly  bool      `db:"readonly" json:"readonly"`
+	Status    string    `db:"sync_status" json:"status"`
+	Error     string    `db:"error" json:"error,omitempty"`
+	SyncedAt  time.Time `db:"synced_at" json:"syncedAt"`
+}
+
+// ConfigDrift lists keys of station whose configuration differs from desired.
+type ConfigDrift struct {
+	StationID string           `json:"stationId"`
+	Group     string           `json:"group,omitempty"`
+	Keys      []ConfigKeyState `json:"keys"`
+}
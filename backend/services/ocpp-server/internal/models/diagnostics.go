@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// Diagnostics request kinds: GetDiagnostics of OCPP 1.6 and GetLog of OCPP 2.0.1.
+const (
+	DiagnosticsKindDiagnostics = "diagnostics"
+	DiagnosticsKindLog         = "log"
+)
+
+// Diagnostics request states. Uploading to failed follow
+// DiagnosticsStatusNotification and LogStatusNotification of the station.
+const (
+	DiagnosticsRequested = "requested"
+	DiagnosticsRejected  = "rejected"
+	DiagnosticsNoData    = "no_data"
+	DiagnosticsUploading = "uploading"
+	DiagnosticsUploaded  = "uploaded"
+	DiagnosticsFailed    = "failed"
+)
+
+// Diagnostics is request of station logs and the bundle the station uploaded.
+// ID is sent as requestId of GetLog.
+type Diagnostics struct {
+	ID           int64      `db:"id" json:"id"`
+	StationID    string     `db:"station_id" json:"stationId"`
+	Kind         string     `db:"kind" json:"kind"`
+	LogType      string     `db:"log_type" json:"logType,omitempty"`
+	Token        string     `db:"token" json:"-"`
+	Status       string     `db:"status" json:"status"`
+	StatusDetail string     `db:"status_detail" json:"statusDetail,omitempty"`
+	FileName     string     `db:"file_name" json:"fileName,omitempty"`
+	Size         int64      `db:"size" json:"size"`
+	SHA256       string     `db:"sha256" json:"sha256,omitempty"`
+	RequestedBy  int64      `db:"requested_by" json:"requestedBy"`
+	CreatedAt    time.Time  `db:"created_at" json:"createdAt"`
+	UpdatedAt    time.Time  `db:"updated_at" json:"updatedAt"`
+	UploadedAt   *time.Time `db:"uploaded_at" json:"uploadedAt,omitempty"`
+}
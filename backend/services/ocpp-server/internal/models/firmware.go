@@ -0,0 +1,92 @@
+package models
+
+import "time"
+
+// Firmware campaign states.
+const (
+	CampaignRunning   = "running"
+	CampaignPaused    = "paused"
+	CampaignHalted    = "halted"
+	CampaignCompleted = "completed"
+)
+
+// Firmware update states of campaign station. Downloading to installed follow
+// FirmwareStatusNotification of the station.
+const (
+	FirmwarePending     = "pending"
+	FirmwareSending     = "sending"
+	FirmwareSent        = "sent"
+	FirmwareDownloading = "downloading"
+	FirmwareDownloaded  = "downloaded"
+	FirmwareInstalling  = "installing"
+	FirmwareInstalled   = "installed"
+	FirmwareFailed      = "failed"
+	FirmwareUpToDate    = "up_to_date"
+)
+
+// FirmwareInProgress lists states of stations that were sent UpdateFirmware
+// and have not finished yet.
+var FirmwareInProgress = []string{
+	FirmwareSending,
+	FirmwareSent,
+	FirmwareDownloading,
+	FirmwareDownloaded,
+	FirmwareInstalling,
+}
+
+// FirmwareArtifact is firmware image. Uploaded images are served by the
+// server itself; registered ones are downloaded by stations from URL.
+type FirmwareArtifact struct {
+	ID        int64     `db:"id" json:"id"`
+	Version   string    `db:"version" json:"version"`
+	FileName  string    `db:"file_name" json:"fileName"`
+	URL       string    `db:"url" json:"url,omitempty"`
+	Size      int64     `db:"size" json:"size"`
+	SHA256    string    `db:"sha256" json:"sha256,omitempty"`
+	Location  string    `db:"-" json:"location"`
+	CreatedAt time.Time `db:"created_at" json:"createdAt"`
+}
+
+// Uploaded reports whether image is stored by the server.
+func (a *FirmwareArtifact) Uploaded() bool {
+	return a.URL == ""
+}
+
+// FirmwareCampaign rolls artifact out to stations matching vendor and model
+// in batches. Campaign halts when share of failed stations exceeds
+// FailureThreshold percent.
+type FirmwareCampaign struct {
+	ID               int64      `db:"id" json:"id"`
+	Name             string     `db:"name" json:"name"`
+	ArtifactID       int64      `db:"artifact_id" json:"artifactId"`
+	Vendor           string     `db:"vendor" json:"vendor"`
+	Model            string     `db:"model" json:"model"`
+	BatchSize        int        `db:"batch_size" json:"batchSize"`
+	FailureThreshold int        `db:"failure_threshold" json:"failureThreshold"`
+	CurrentBatch     int        `db:"current_batch" json:"currentBatch"`
+	Status           string     `db:"status" json:"status"`
+	CreatedBy        int64      `db:"created_by" json:"createdBy"`
+	CreatedAt        time.Time  `db:"created_at" json:"createdAt"`
+	UpdatedAt        time.Time  `db:"updated_at" json:"updatedAt"`
+	FinishedAt       *time.Time `db:"finished_at" json:"finishedAt,omitempty"`
+}
+
+// CampaignStation is progress of single station of campaign.
+type CampaignStation struct {
+	CampaignID      int64     `db:"campaign_id" json:"campaignId"`
+	StationID       string    `db:"station_id" json:"stationId"`
+	Batch           int       `db:"batch" json:"batch"`
+	Status          string    `db:"status" json:"status"`
+	Error           string    `db:"error" json:"error,omitempty"`
+	FirmwareVersion string    `db:"firmware_version" json:"firmwareVersion"`
+	UpdatedAt       time.Time `db:"updated_at" json:"updatedAt"`
+}
+
+// CampaignReport is campaign dashboard: counts of stations by state and
+// progress of each station.
+type CampaignReport struct {
+	Campaign FirmwareCampaign  `json:"campaign"`
+	Artifact FirmwareArtifact  `json:"artifact"`
+	Counts   map[string]int    `json:"counts"`
+	Stations []CampaignStation `json:"stations"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// OCPP message log directions.
+const (
+	DirectionIncoming = "incoming"
+	DirectionOutgoing = "outgoing"
+)
+
+// OCPP-J frame types as stored in message log.
+const (
+	FrameCall       = "CALL"
+	FrameCallResult = "CALLRESULT"
+	FrameCallError  = "CALLERROR"
+)
+
+// OCPPMessage is raw OCPP frame exchanged with station. Action of replies is
+// taken from the call they answer and is empty when it is unknown.
+type OCPPMessage struct {
+	ID        int64           `db:"id" json:"id"`
+	StationID string          `db:"station_id" json:"stationId"`
+	Direction string          `db:"direction" json:"direction"`
+	FrameType string          `db:"frame_type" json:"frameType"`
+	UniqueID  string          `db:"unique_id" json:"uniqueId"`
+	Action    string          `db:"message_type" json:"action"`
+	Payload   json.RawMessage `db:"payload" json:"payload"`
+	CreatedAt time.Time       `db:"created_at" json:"createdAt"`
+}
+
+// OCPPExchange is call together with its reply; Response is nil while unanswered.
+type OCPPExchange struct {
+	Request  OCPPMessage  `json:"request"`
+	Response *OCPPMessage `json:"response"`
+}
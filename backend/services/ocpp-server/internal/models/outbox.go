@@ -0,0 +1,27 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Outbox event states.
+const (
+	OutboxPending   = "pending"
+	OutboxDelivered = "delivered"
+	OutboxDead      = "dead"
+)
+
+// OutboxEvent is notification for downstream service awaiting delivery.
+type OutboxEvent struct {
+	ID            int64           `db:"id" json:"id"`
+	EventType     string          `db:"event_type" json:"eventType"`
+	AggregateID   string          `db:"aggregate_id" json:"aggregateId"`
+	Payload       json.RawMessage `db:"payload" json:"payload"`
+	Status        string          `db:"status" json:"status"`
+	Attempts      int             `db:"attempts" json:"attempts"`
+	LastError     string          `db:"last_error" json:"lastError,omitempty"`
+	NextAttemptAt time.Time       `db:"next_attempt_at" json:"nextAttemptAt"`
+	CreatedAt     time.Time       `db:"created_at" json:"createdAt"`
+	UpdatedAt     time.Time       `db:"updated_at" json:"updatedAt"`
+}
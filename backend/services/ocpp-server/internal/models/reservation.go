@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// Reservation states. Scheduled reservation is sent to station with ReserveNow
+// when its window starts; active one is consumed by StartTransaction or expires
+// as no-show when the window ends.
+const (
+	ReservationScheduled = "scheduled"
+	ReservationReserving = "reserving"
+	ReservationActive    = "active"
+	ReservationUsed      = "used"
+	ReservationCancelled = "cancelled"
+	ReservationExpired   = "expired"
+	ReservationFailed    = "failed"
+)
+
+// ReservationOpen lists states that hold the connector window.
+var ReservationOpen = []string{ReservationScheduled, ReservationReserving, ReservationActive}
+
+// Reservation is booking of connector by user for time window. ID is sent to
+// station as reservationId.
+type Reservation struct {
+	ID            int64     `db:"id" json:"id"`
+	StationID     string    `db:"station_id" json:"stationId"`
+	ConnectorID   int       `db:"connector_id" json:"connectorId"`
+	UserID        int64     `db:"user_id" json:"userId"`
+	IdTag         string    `db:"id_tag" json:"idTag"`
+	StartAt       time.Time `db:"start_at" json:"startAt"`
+	EndAt         time.Time `db:"end_at" json:"endAt"`
+	Status        string    `db:"status" json:"status"`
+	StatusDetail  string    `db:"status_detail" json:"statusDetail,omitempty"`
+	TransactionID string    `db:"transaction_id" json:"transactionId,omitempty"`
+	CreatedAt     time.Time `db:"created_at" json:"createdAt"`
+	UpdatedAt     time.Time `db:"updated_at" json:"updatedAt"`
+}
@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// Strategies splitting site capacity among active transactions.
+const (
+	SiteStrategyEqual     = "equal"
+	SiteStrategyPriority  = "priority"
+	SiteStrategyFirstCome = "first_come"
+)
+
+// Site is group of stations behind one grid connection. Currents are in A per phase.
+type Site struct {
+	ID                  string    `db:"id" json:"id"`
+	Name                string    `db:"name" json:"name"`
+	MaxCurrent          float64   `db:"max_current" json:"maxCurrent"`
+	MinCurrent          float64   `db:"min_current" json:"minCurrent"`
+	ConnectorMaxCurrent float64   `db:"connector_max_current" json:"connectorMaxCurrent"`
+	Strategy            string    `db:"strategy" json:"strategy"`
+	Stations            []string  `db:"-" json:"stations"`
+	CreatedAt           time.Time `db:"created_at" json:"createdAt"`
+	UpdatedAt           time.Time `db:"updated_at" json:"updatedAt"`
+}
+
+// SiteAllocation is current limit of active transaction of site. Nil values
+// are not known yet: no measurement received or no limit accepted by station.
+// Unmanaged transaction cannot be limited; its limit is current reserved for it.
+type SiteAllocation struct {
+	TransactionID   string    `db:"transaction_id" json:"transactionId"`
+	SiteID          string    `db:"site_id" json:"siteId"`
+	StationID       string    `db:"station_id" json:"stationId"`
+	ConnectorID     int       `db:"connector_id" json:"connectorId"`
+	MeasuredCurrent *float64  `db:"measured_current" json:"measuredCurrent"`
+	LimitCurrent    *float64  `db:"limit_current" json:"limitCurrent"`
+	Unmanaged       bool      `db:"unmanaged" json:"unmanaged"`
+	UpdatedAt       time.Time `db:"updated_at" json:"updatedAt"`
+}
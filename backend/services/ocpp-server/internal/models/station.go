@@ -2,15 +2,27 @@ package models
 
 import "time"
 
+// Station provisioning states.
+const (
+	RegistrationPending        = "pending"
+	RegistrationAccepted       = "accepted"
+	RegistrationDecommissioned = "decommissioned"
+)
+
+// StatusOffline marks station that stopped sending heartbeats.
+const StatusOffline = "Offline"
+
 // Station represents a charging station.
 type Station struct {
-	ID              string    `db:"id" json:"id"`
-	Vendor          string    `db:"vendor" json:"vendor"`
-	Model           string    `db:"model" json:"model"`
-	FirmwareVersion string    `db:"firmware_version" json:"firmwareVersion"`
-	LastHeartbeat   time.Time `db:"last_heartbeat" json:"lastHeartbeat"`
-	Status          string    `db:"status" json:"status"`
-	CreatedAt       time.Time `db:"created_at" json:"createdAt"`
-	UpdatedAt       time.Time `db:"updated_at" json:"updatedAt"`
+	ID                 string    `db:"id" json:"id"`
+	Vendor             string    `db:"vendor" json:"vendor"`
+	Model              string    `db:"model" json:"model"`
+	FirmwareVersion    string    `db:"firmware_version" json:"firmwareVersion"`
+	LastHeartbeat      time.Time `db:"last_heartbeat" json:"lastHeartbeat"`
+	Status             string    `db:"status" json:"status"`
+	RegistrationStatus string    `db:"registration_status" json:"registrationStatus"`
+	HeartbeatInterval  int       `db:"heartbeat_interval" json:"heartbeatInterval"`
+	OCPPVersion        string    `db:"ocpp_version" json:"ocppVersion"` // subprotocol of last BootNotification
+	CreatedAt          time.Time `db:"created_at" json:"createdAt"`
+	UpdatedAt          time.Time `db:"updated_at" json:"updatedAt"`
 }
-
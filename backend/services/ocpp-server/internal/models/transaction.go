@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// Transaction is ongoing OCPP transaction kept across server restarts.
+type Transaction struct {
+	ID          string    `db:"transaction_id" json:"transactionId"`
+	StationID   string    `db:"station_id" json:"stationId"`
+	ConnectorID int       `db:"connector_id" json:"connectorId"`
+	SessionID   int64     `db:"session_id" json:"sessionId"`
+	MeterStart  int64     `db:"meter_start" json:"meterStart"`
+	MeterLast   int64     `db:"meter_last" json:"meterLast"`
+	IdTag       string    `db:"id_tag" json:"idTag"`
+	UserID      int64     `db:"user_id" json:"userId"`
+	Authorized  bool      `db:"authorized" json:"authorized"`
+	StartedAt   time.Time `db:"started_at" json:"startedAt"`
+	UpdatedAt   time.Time `db:"updated_at" json:"updatedAt"`
+}
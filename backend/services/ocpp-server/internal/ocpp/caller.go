@@ -0,0 +1,232 @@
+package ocpp
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"drivepower/backend/services/ocpp-server/internal/models"
+	"drivepower/backend/services/ocpp-server/internal/ocpp/protocol"
+)
+
+var (
+	// ErrCallTimeout is returned when station does not answer in time.
+	ErrCallTimeout = errors.New("ocpp: call timed out")
+	// ErrCallAborted is returned when pending call is dropped (e.g. station disconnected).
+	ErrCallAborted = errors.New("ocpp: call aborted")
+)
+
+// FrameSender delivers raw frames to connected stations.
+type FrameSender interface {
+	SendTo(stationID string, frame []byte) error
+}
+
+// callSlot allows one outstanding call per station; users counts callers
+// holding or waiting for it.
+type callSlot struct {
+	turn  chan struct{}
+	users int
+}
+
+type pendingCall struct {
+	uniqueID string
+	action   string
+	result   chan *Message
+}
+
+// Caller sends CSMS-initiated CALL frames and correlates CALLRESULT/CALLERROR replies.
+// OCPP allows only one outstanding call per station, so callers for the same
+// station are queued until the previous call is answered or times out.
+type Caller struct {
+	sender  FrameSender
+	timeout time.Duration
+	log     MessageLog
+	logger  *zap.Logger
+
+	mu      sync.Mutex
+	slots   map[string]*callSlot
+	pending map[string]*pendingCall
+}
+
+// NewCaller builds Caller.
+func NewCaller(sender FrameSender, timeout time.Duration, log MessageLog, logger *zap.Logger) *Caller {
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	return &Caller{
+		sender:  sender,
+		timeout: timeout,
+		log:     log,
+		logger:  logger,
+		slots:   make(map[string]*callSlot),
+		pending: make(map[string]*pendingCall),
+	}
+}
+
+// Call sends action to station and decodes CALLRESULT payload into response.
+// A CALLERROR answer is returned as *CallError. Must not be invoked from the
+// station read loop (handlers), otherwise the reply can never be read.
+func (c *Caller) Call(ctx context.Context, stationID, action string, request, response interface{}) error {
+	slot, err := c.acquire(ctx, stationID)
+	if err != nil {
+		return err
+	}
+	defer c.release(stationID, slot)
+
+	uniqueID, err := newUniqueID()
+	if err != nil {
+		return err
+	}
+	frame, err := BuildCall(uniqueID, action, request)
+	if err != nil {
+		return err
+	}
+
+	call := &pendingCall{
+		uniqueID: uniqueID,
+		action:   action,
+		result:   make(chan *Message, 1),
+	}
+	c.mu.Lock()
+	c.pending[stationID] = call
+	c.mu.Unlock()
+	defer c.forget(stationID, call)
+
+	if err := c.sender.SendTo(stationID, frame); err != nil {
+		return err
+	}
+	if c.log != nil {
+		c.log.Record(logEntry(stationID, models.DirectionOutgoing, protocol.MessageTypeCall, uniqueID, action, frame))
+	}
+
+	timer := time.NewTimer(c.timeout)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		if c.logger != nil {
+			c.logger.Warn("ocpp call timed out",
+				zap.String("station_id", stationID),
+				zap.String("action", action),
+				zap.String("unique_id", uniqueID),
+			)
+		}
+		return ErrCallTimeout
+	case msg, ok := <-call.result:
+		if !ok {
+			return ErrCallAborted
+		}
+		if msg.MessageType == protocol.MessageTypeCallError {
+			return &CallError{
+				Code:        msg.ErrorCode,
+				Description: msg.ErrorDescription,
+				Details:     msg.ErrorDetails,
+			}
+		}
+		if response == nil {
+			return nil
+		}
+		if err := json.Unmarshal(msg.Payload, response); err != nil {
+			return fmt.Errorf("ocpp: decode %s response: %w", action, err)
+		}
+		return nil
+	}
+}
+
+// Resolve hands CALLRESULT/CALLERROR frame to the waiting caller.
+// Returns action of the matched call and false when nothing was waiting for it.
+func (c *Caller) Resolve(stationID string, msg *Message) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	call, ok := c.pending[stationID]
+	if !ok || call.uniqueID != msg.UniqueID {
+		return "", false
+	}
+	delete(c.pending, stationID)
+	call.result <- msg
+	return call.action, true
+}
+
+// Abort fails the outstanding call of station, used when connection goes away.
+// Its slot is removed once the aborted caller and the queued ones are done.
+func (c *Caller) Abort(stationID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	call, ok := c.pending[stationID]
+	if !ok {
+		return
+	}
+	delete(c.pending, stationID)
+	close(call.result)
+}
+
+// acquire waits for the call slot of station, at most the call timeout, so
+// that callers with a long-lived ctx do not wait forever behind a stuck call.
+func (c *Caller) acquire(ctx context.Context, stationID string) (*callSlot, error) {
+	c.mu.Lock()
+	slot, ok := c.slots[stationID]
+	if !ok {
+		slot = &callSlot{turn: make(chan struct{}, 1)}
+		c.slots[stationID] = slot
+	}
+	slot.users++
+	c.mu.Unlock()
+
+	timer := time.NewTimer(c.timeout)
+	defer timer.Stop()
+	select {
+	case slot.turn <- struct{}{}:
+		return slot, nil
+	case <-ctx.Done():
+		c.leave(stationID, slot)
+		return nil, ctx.Err()
+	case <-timer.C:
+		c.leave(stationID, slot)
+		return nil, ErrCallTimeout
+	}
+}
+
+func (c *Caller) release(stationID string, slot *callSlot) {
+	<-slot.turn
+	c.leave(stationID, slot)
+}
+
+// leave drops caller from slot; slot nobody uses is removed, so stations that
+// went away do not stay in the map.
+func (c *Caller) leave(stationID string, slot *callSlot) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	slot.users--
+	if slot.users == 0 && c.slots[stationID] == slot {
+		delete(c.slots, stationID)
+	}
+}
+
+func (c *Caller) forget(stationID string, call *pendingCall) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if current, ok := c.pending[stationID]; ok && current == call {
+		delete(c.pending, stationID)
+	}
+}
+
+// newUniqueID returns random UUIDv4 string used as message ID.
+func newUniqueID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	s := hex.EncodeToString(b[:])
+	return fmt.Sprintf("%s-%s-%s-%s-%s", s[0:8], s[8:12], s[12:16], s[16:20], s[20:32]), nil
+}
@@ -0,0 +1,224 @@
+package ocpp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"drivepower/backend/services/ocpp-server/internal/ocpp/protocol"
+)
+
+// fakeSender hands sent frames to the test; block makes SendTo hang until
+// the test closes it.
+type fakeSender struct {
+	frames chan *Message
+	block  chan struct{}
+	err    error
+}
+
+func newFakeSender() *fakeSender {
+	return &fakeSender{frames: make(chan *Message, 4)}
+}
+
+func (s *fakeSender) SendTo(stationID string, frame []byte) error {
+	if s.block != nil {
+		<-s.block
+	}
+	if s.err != nil {
+		return s.err
+	}
+	msg, err := NewParser().Parse(frame)
+	if err != nil {
+		return err
+	}
+	s.frames <- msg
+	return nil
+}
+
+func (s *fakeSender) next(t *testing.T) *Message {
+	t.Helper()
+	select {
+	case msg := <-s.frames:
+		return msg
+	case <-time.After(time.Second):
+		t.Fatal("no frame sent")
+		return nil
+	}
+}
+
+type callOutcome struct {
+	resp protocol.ResetResponse
+	err  error
+}
+
+func startCall(c *Caller, ctx context.Context, stationID string) <-chan callOutcome {
+	done := make(chan callOutcome, 1)
+	go func() {
+		var out callOutcome
+		out.err = c.Call(ctx, stationID, protocol.ActionReset, protocol.ResetRequest{Type: protocol.ResetSoft}, &out.resp)
+		done <- out
+	}()
+	return done
+}
+
+func waitOutcome(t *testing.T, done <-chan callOutcome) callOutcome {
+	t.Helper()
+	select {
+	case out := <-done:
+		return out
+	case <-time.After(2 * time.Second):
+		t.Fatal("call did not return")
+		return callOutcome{}
+	}
+}
+
+func TestCallerCorrelatesReplies(t *testing.T) {
+	tests := []struct {
+		name       string
+		reply      func(uniqueID string) *Message
+		wantStatus string
+		wantCode   string
+	}{
+		{
+			name: "CALLRESULT",
+			reply: func(uniqueID string) *Message {
+				return &Message{MessageType: protocol.MessageTypeCallResult, UniqueID: uniqueID, Payload: json.RawMessage(`{"status":"Accepted"}`)}
+			},
+			wantStatus: protocol.CommandAccepted,
+		},
+		{
+			name: "CALLERROR",
+			reply: func(uniqueID string) *Message {
+				return &Message{MessageType: protocol.MessageTypeCallError, UniqueID: uniqueID, ErrorCode: ErrorCodeNotSupported, ErrorDescription: "no reset"}
+			},
+			wantCode: ErrorCodeNotSupported,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sender := newFakeSender()
+			c := NewCaller(sender, time.Second, nil, nil)
+			done := startCall(c, context.Background(), "CP-1")
+
+			sent := sender.next(t)
+			if sent.MessageType != protocol.MessageTypeCall || sent.Action != protocol.ActionReset {
+				t.Fatalf("sent %d %s, want CALL Reset", sent.MessageType, sent.Action)
+			}
+			if _, ok := c.Resolve("CP-1", tt.reply("other-id")); ok {
+				t.Fatal("reply with foreign unique id resolved the call")
+			}
+			if _, ok := c.Resolve("CP-2", tt.reply(sent.UniqueID)); ok {
+				t.Fatal("reply of other station resolved the call")
+			}
+			action, ok := c.Resolve("CP-1", tt.reply(sent.UniqueID))
+			if !ok || action != protocol.ActionReset {
+				t.Fatalf("Resolve = %q, %v; want Reset, true", action, ok)
+			}
+
+			out := waitOutcome(t, done)
+			var callErr *CallError
+			switch {
+			case tt.wantCode != "":
+				if !errors.As(out.err, &callErr) || callErr.Code != tt.wantCode {
+					t.Fatalf("error = %v, want CallError %s", out.err, tt.wantCode)
+				}
+			case out.err != nil:
+				t.Fatal(out.err)
+			case out.resp.Status != tt.wantStatus:
+				t.Fatalf("status = %q, want %q", out.resp.Status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestCallerTimeout(t *testing.T) {
+	sender := newFakeSender()
+	c := NewCaller(sender, 50*time.Millisecond, nil, nil)
+	done := startCall(c, context.Background(), "CP-1")
+	sent := sender.next(t)
+
+	if out := waitOutcome(t, done); !errors.Is(out.err, ErrCallTimeout) {
+		t.Fatalf("error = %v, want ErrCallTimeout", out.err)
+	}
+	late := &Message{MessageType: protocol.MessageTypeCallResult, UniqueID: sent.UniqueID, Payload: json.RawMessage(`{}`)}
+	if _, ok := c.Resolve("CP-1", late); ok {
+		t.Fatal("late reply resolved a finished call")
+	}
+}
+
+func TestCallerAbort(t *testing.T) {
+	sender := newFakeSender()
+	c := NewCaller(sender, time.Second, nil, nil)
+	done := startCall(c, context.Background(), "CP-1")
+	sender.next(t)
+
+	c.Abort("CP-1")
+	if out := waitOutcome(t, done); !errors.Is(out.err, ErrCallAborted) {
+		t.Fatalf("error = %v, want ErrCallAborted", out.err)
+	}
+}
+
+func TestCallerOneOutstandingCallPerStation(t *testing.T) {
+	sender := newFakeSender()
+	c := NewCaller(sender, time.Second, nil, nil)
+	first := startCall(c, context.Background(), "CP-1")
+	sent := sender.next(t)
+	second := startCall(c, context.Background(), "CP-1")
+
+	// Other stations are not held up.
+	other := startCall(c, context.Background(), "CP-2")
+	otherSent := sender.next(t)
+	c.Resolve("CP-2", &Message{MessageType: protocol.MessageTypeCallResult, UniqueID: otherSent.UniqueID, Payload: json.RawMessage(`{"status":"Accepted"}`)})
+	if out := waitOutcome(t, other); out.err != nil {
+		t.Fatal(out.err)
+	}
+
+	select {
+	case msg := <-sender.frames:
+		t.Fatalf("second call sent %s before the first was answered", msg.UniqueID)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	c.Resolve("CP-1", &Message{MessageType: protocol.MessageTypeCallResult, UniqueID: sent.UniqueID, Payload: json.RawMessage(`{"status":"Accepted"}`)})
+	if out := waitOutcome(t, first); out.err != nil {
+		t.Fatal(out.err)
+	}
+	sent = sender.next(t)
+	c.Resolve("CP-1", &Message{MessageType: protocol.MessageTypeCallResult, UniqueID: sent.UniqueID, Payload: json.RawMessage(`{"status":"Rejected"}`)})
+	if out := waitOutcome(t, second); out.err != nil || out.resp.Status != protocol.CommandRejected {
+		t.Fatalf("second call = %+v", out)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.slots) != 0 || len(c.pending) != 0 {
+		t.Fatalf("slots = %d, pending = %d after all calls finished", len(c.slots), len(c.pending))
+	}
+}
+
+func TestCallerQueueWaitIsBounded(t *testing.T) {
+	sender := newFakeSender()
+	sender.block = make(chan struct{})
+	c := NewCaller(sender, 50*time.Millisecond, nil, nil)
+
+	// First call hangs in SendTo and keeps the slot.
+	first := startCall(c, context.Background(), "CP-1")
+	time.Sleep(10 * time.Millisecond)
+	second := startCall(c, context.Background(), "CP-1")
+	if out := waitOutcome(t, second); !errors.Is(out.err, ErrCallTimeout) {
+		t.Fatalf("queued call error = %v, want ErrCallTimeout", out.err)
+	}
+
+	sender.err = errors.New("connection closed")
+	close(sender.block)
+	if out := waitOutcome(t, first); out.err == nil {
+		t.Fatal("expected send error")
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.slots) != 0 {
+		t.Fatalf("slots = %d after all calls finished", len(c.slots))
+	}
+}
@@ -0,0 +1,136 @@
+package ocpp
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Decode parses payload into T and validates it against `ocpp` struct tags.
+// Failures are returned as *CallError carrying the matching CALLERROR code:
+//
+//	required  field must be present and not null (OccurenceConstraintViolation)
+//	max=N     string length limit (PropertyConstraintViolation)
+//	min=N     minimal number of array items (OccurenceConstraintViolation)
+//	oneof=A B allowed enum values (PropertyConstraintViolation)
+func Decode[T any](payload json.RawMessage) (T, error) {
+	var target T
+
+	var generic interface{}
+	if err := json.Unmarshal(payload, &generic); err != nil {
+		return target, NewCallError(ErrorCodeFormationViolation, "payload is not valid JSON: %v", err)
+	}
+	if _, ok := generic.(map[string]interface{}); !ok {
+		return target, NewCallError(ErrorCodeFormationViolation, "payload must be JSON object")
+	}
+
+	if err := json.Unmarshal(payload, &target); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return target, NewCallError(ErrorCodeTypeConstraintViolation, "%s must be %s", typeErr.Field, typeErr.Type)
+		}
+		return target, NewCallError(ErrorCodeTypeConstraintViolation, "%v", err)
+	}
+
+	if err := validate(generic, reflect.TypeOf(target), ""); err != nil {
+		return target, err
+	}
+	return target, nil
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// validate walks decoded JSON together with target type and checks field rules.
+func validate(value interface{}, t reflect.Type, path string) *CallError {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return nil
+	case t.Kind() == reflect.Struct:
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name := strings.Split(field.Tag.Get("json"), ",")[0]
+			if name == "" || name == "-" {
+				continue
+			}
+			if err := validateField(obj[name], field.Type, joinPath(path, name), field.Tag.Get("ocpp")); err != nil {
+				return err
+			}
+		}
+	case t.Kind() == reflect.Slice:
+		items, ok := value.([]interface{})
+		if !ok {
+			return nil
+		}
+		for i, item := range items {
+			if err := validate(item, t.Elem(), path+"["+strconv.Itoa(i)+"]"); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func validateField(value interface{}, t reflect.Type, path, tag string) *CallError {
+	rules := strings.Split(tag, ",")
+	if value == nil {
+		for _, rule := range rules {
+			if rule == "required" {
+				return NewCallError(ErrorCodeOccurenceConstraintViolation, "%s is required", path)
+			}
+		}
+		return nil
+	}
+
+	for _, rule := range rules {
+		key, arg, _ := strings.Cut(rule, "=")
+		switch key {
+		case "max":
+			limit, _ := strconv.Atoi(arg)
+			if s, ok := value.(string); ok && len(s) > limit {
+				return NewCallError(ErrorCodePropertyConstraintViolation, "%s exceeds %d characters", path, limit)
+			}
+		case "min":
+			limit, _ := strconv.Atoi(arg)
+			if items, ok := value.([]interface{}); ok && len(items) < limit {
+				return NewCallError(ErrorCodeOccurenceConstraintViolation, "%s must contain at least %d item(s)", path, limit)
+			}
+		case "oneof":
+			s, ok := value.(string)
+			if ok && !contains(strings.Fields(arg), s) {
+				return NewCallError(ErrorCodePropertyConstraintViolation, "%s has unsupported value %q", path, s)
+			}
+		}
+	}
+
+	return validate(value, t, path)
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func contains(values []string, v string) bool {
+	for _, candidate := range values {
+		if candidate == v {
+			return true
+		}
+	}
+	return false
+}
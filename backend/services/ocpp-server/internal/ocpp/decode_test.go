@@ -0,0 +1,108 @@
+package ocpp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"drivepower/backend/services/ocpp-server/internal/ocpp/protocol"
+)
+
+type decodeItem struct {
+	Name string `json:"name" ocpp:"required,max=5"`
+}
+
+type decodeRequest struct {
+	IdTag     string       `json:"idTag" ocpp:"required,max=20"`
+	Kind      string       `json:"kind,omitempty" ocpp:"oneof=Hard Soft"`
+	Count     int          `json:"count,omitempty"`
+	Items     []decodeItem `json:"items,omitempty" ocpp:"min=1"`
+	Nested    *decodeItem  `json:"nested,omitempty"`
+	Timestamp time.Time    `json:"timestamp,omitempty"`
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name     string
+		payload  string
+		wantCode string
+	}{
+		{name: "valid", payload: `{"idTag":"TAG","kind":"Hard","count":2,"items":[{"name":"a"}],"nested":{"name":"b"},"timestamp":"2024-01-01T00:00:00Z"}`},
+		{name: "optional fields missing", payload: `{"idTag":"TAG"}`},
+		{name: "not JSON", payload: `{"idTag":`, wantCode: ErrorCodeFormationViolation},
+		{name: "not an object", payload: `["idTag"]`, wantCode: ErrorCodeFormationViolation},
+		{name: "wrong type", payload: `{"idTag":"TAG","count":"two"}`, wantCode: ErrorCodeTypeConstraintViolation},
+		{name: "bad timestamp", payload: `{"idTag":"TAG","timestamp":"yesterday"}`, wantCode: ErrorCodeTypeConstraintViolation},
+		{name: "required missing", payload: `{"kind":"Hard"}`, wantCode: ErrorCodeOccurenceConstraintViolation},
+		{name: "required null", payload: `{"idTag":null}`, wantCode: ErrorCodeOccurenceConstraintViolation},
+		{name: "required missing in nested", payload: `{"idTag":"TAG","nested":{}}`, wantCode: ErrorCodeOccurenceConstraintViolation},
+		{name: "max exceeded", payload: `{"idTag":"TAG-LONGER-THAN-TWENTY"}`, wantCode: ErrorCodePropertyConstraintViolation},
+		{name: "max exceeded in array item", payload: `{"idTag":"TAG","items":[{"name":"abcdef"}]}`, wantCode: ErrorCodePropertyConstraintViolation},
+		{name: "min items", payload: `{"idTag":"TAG","items":[]}`, wantCode: ErrorCodeOccurenceConstraintViolation},
+		{name: "oneof violated", payload: `{"idTag":"TAG","kind":"Medium"}`, wantCode: ErrorCodePropertyConstraintViolation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Decode[decodeRequest](json.RawMessage(tt.payload))
+			if tt.wantCode == "" {
+				if err != nil {
+					t.Fatalf("unexpected error %v", err)
+				}
+				return
+			}
+			var callErr *CallError
+			if !errors.As(err, &callErr) {
+				t.Fatalf("error = %v, want CallError %s", err, tt.wantCode)
+			}
+			if callErr.Code != tt.wantCode {
+				t.Fatalf("code = %s (%s), want %s", callErr.Code, callErr.Description, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestProcessorCallErrorCodes(t *testing.T) {
+	handler := func(_ context.Context, _ string, payload json.RawMessage) (interface{}, error) {
+		if _, err := Decode[decodeRequest](payload); err != nil {
+			return nil, err
+		}
+		return map[string]string{"status": "Accepted"}, nil
+	}
+	spec := Spec{
+		Actions:    []string{"Authorize", "DataTransfer"},
+		ErrorCodes: map[string]string{ErrorCodeOccurenceConstraintViolation: "OccurrenceConstraintViolation"},
+	}
+	router := NewRouter(spec)
+	router.Register("Authorize", handler)
+	processor := NewProcessor(NewParser(), router, nil, nil, nil, nil, nil)
+
+	tests := []struct {
+		name     string
+		frame    string
+		wantType int
+		wantCode string
+	}{
+		{name: "handled", frame: `[2,"1","Authorize",{"idTag":"TAG"}]`, wantType: protocol.MessageTypeCallResult},
+		{name: "unsupported action of the version", frame: `[2,"2","DataTransfer",{}]`, wantType: protocol.MessageTypeCallError, wantCode: ErrorCodeNotSupported},
+		{name: "unknown action", frame: `[2,"3","MakeCoffee",{}]`, wantType: protocol.MessageTypeCallError, wantCode: ErrorCodeNotImplemented},
+		{name: "validation code renamed for version", frame: `[2,"4","Authorize",{}]`, wantType: protocol.MessageTypeCallError, wantCode: "OccurrenceConstraintViolation"},
+		{name: "incomplete CALL", frame: `[2,"5","Authorize"]`, wantType: protocol.MessageTypeCallError, wantCode: ErrorCodeFormationViolation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reply, err := processor.Process(context.Background(), "CP-1", []byte(tt.frame))
+			if err != nil {
+				t.Fatal(err)
+			}
+			msg, err := NewParser().Parse(reply)
+			if err != nil {
+				t.Fatalf("reply %s: %v", reply, err)
+			}
+			if msg.MessageType != tt.wantType || msg.ErrorCode != tt.wantCode {
+				t.Fatalf("reply = %s, want type %d code %q", reply, tt.wantType, tt.wantCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package ocpp
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// CALLERROR codes (OCPP 1.6 spelling; see Spec.ErrorCodes for per-version names).
+const (
+	ErrorCodeNotImplemented               = "NotImplemented"
+	ErrorCodeNotSupported                 = "NotSupported"
+	ErrorCodeInternalError                = "InternalError"
+	ErrorCodeProtocolError                = "ProtocolError"
+	ErrorCodeSecurityError                = "SecurityError"
+	ErrorCodeFormationViolation           = "FormationViolation"
+	ErrorCodePropertyConstraintViolation  = "PropertyConstraintViolation"
+	ErrorCodeOccurenceConstraintViolation = "OccurenceConstraintViolation"
+	ErrorCodeTypeConstraintViolation      = "TypeConstraintViolation"
+	ErrorCodeGenericError                 = "GenericError"
+	ErrorCodeMessageTypeNotSupported      = "MessageTypeNotSupported"
+)
+
+// CallError is CALLERROR content. It is returned by Caller when station answers
+// with CALLERROR, and by Decode/handlers to choose the code sent to station.
+type CallError struct {
+	Code        string
+	Description string
+	Details     json.RawMessage
+}
+
+func (e *CallError) Error() string {
+	return fmt.Sprintf("ocpp: %s: %s", e.Code, e.Description)
+}
+
+// NewCallError builds CallError with formatted description.
+func NewCallError(code, format string, args ...interface{}) *CallError {
+	return &CallError{Code: code, Description: fmt.Sprintf(format, args...)}
+}
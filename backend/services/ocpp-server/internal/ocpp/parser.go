@@ -4,14 +4,19 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+
+	"drivepower/backend/services/ocpp-server/internal/ocpp/protocol"
 )
 
 // Message represents parsed OCPP frame.
 type Message struct {
-	MessageType int
-	UniqueID    string
-	Action      string
-	Payload     json.RawMessage
+	MessageType      int
+	UniqueID         string
+	Action           string
+	Payload          json.RawMessage
+	ErrorCode        string
+	ErrorDescription string
+	ErrorDetails     json.RawMessage
 }
 
 // Parser decodes raw JSON OCPP frames.
@@ -22,14 +27,16 @@ func NewParser() *Parser {
 	return &Parser{}
 }
 
-// Parse decodes []byte into Message struct.
+// Parse decodes []byte into Message struct. When frame is broken but its
+// unique id could be read, the partially filled Message is returned together
+// with *CallError so caller can answer with CALLERROR.
 func (p *Parser) Parse(data []byte) (*Message, error) {
 	var array []json.RawMessage
 	if err := json.Unmarshal(data, &array); err != nil {
 		return nil, err
 	}
 
-	if len(array) < 3 {
+	if len(array) < 2 {
 		return nil, errors.New("ocpp: malformed frame")
 	}
 
@@ -39,26 +46,54 @@ func (p *Parser) Parse(data []byte) (*Message, error) {
 	}
 
 	msg := &Message{MessageType: msgType}
+	if err := json.Unmarshal(array[1], &msg.UniqueID); err != nil {
+		return nil, fmt.Errorf("ocpp: read unique id: %w", err)
+	}
 
 	switch msgType {
-	case 2: // CALL
+	case protocol.MessageTypeCall:
 		if len(array) < 4 {
-			return nil, errors.New("ocpp: incomplete CALL frame")
-		}
-		if err := json.Unmarshal(array[1], &msg.UniqueID); err != nil {
-			return nil, fmt.Errorf("ocpp: read unique id: %w", err)
+			return msg, NewCallError(ErrorCodeFormationViolation, "incomplete CALL frame")
 		}
 		if err := json.Unmarshal(array[2], &msg.Action); err != nil {
-			return nil, fmt.Errorf("ocpp: read action: %w", err)
+			return msg, NewCallError(ErrorCodeFormationViolation, "action must be string")
 		}
 		msg.Payload = array[3]
+	case protocol.MessageTypeCallResult:
+		if len(array) < 3 {
+			return nil, errors.New("ocpp: incomplete CALLRESULT frame")
+		}
+		msg.Payload = array[2]
+	case protocol.MessageTypeCallError:
+		if len(array) < 4 {
+			return nil, errors.New("ocpp: incomplete CALLERROR frame")
+		}
+		if err := json.Unmarshal(array[2], &msg.ErrorCode); err != nil {
+			return nil, fmt.Errorf("ocpp: read error code: %w", err)
+		}
+		if err := json.Unmarshal(array[3], &msg.ErrorDescription); err != nil {
+			return nil, fmt.Errorf("ocpp: read error description: %w", err)
+		}
+		if len(array) > 4 {
+			msg.ErrorDetails = array[4]
+		}
 	default:
-		return nil, fmt.Errorf("ocpp: unsupported message type %d", msgType)
+		return msg, NewCallError(ErrorCodeMessageTypeNotSupported, "unsupported message type %d", msgType)
 	}
 
 	return msg, nil
 }
 
+// BuildCall builds CALL frame for CSMS-initiated requests.
+func BuildCall(uniqueID, action string, payload interface{}) ([]byte, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	frame := []interface{}{protocol.MessageTypeCall, uniqueID, action, json.RawMessage(body)}
+	return json.Marshal(frame)
+}
+
 // BuildCallResult builds standard CALLRESULT payload.
 func BuildCallResult(uniqueID string, payload interface{}) ([]byte, error) {
 	body, err := json.Marshal(payload)
@@ -74,4 +109,3 @@ func BuildCallError(uniqueID, code, description string) ([]byte, error) {
 	frame := []interface{}{4, uniqueID, code, description, map[string]string{}}
 	return json.Marshal(frame)
 }
-
@@ -7,6 +7,9 @@ const (
 	MessageTypeCallError  = 4
 )
 
+// Subprotocol is websocket subprotocol name of OCPP 1.6J.
+const Subprotocol = "ocpp1.6"
+
 // Actions supported by MVP.
 const (
 	ActionBootNotification  = "BootNotification"
@@ -15,11 +18,159 @@ const (
 	ActionStopTransaction    = "StopTransaction"
 	ActionHeartbeat          = "Heartbeat"
 	ActionMeterValues        = "MeterValues"
+	ActionAuthorize          = "Authorize"
+)
+
+// Station-initiated actions reporting progress of UpdateFirmware and GetDiagnostics.
+const (
+	ActionFirmwareStatusNotification    = "FirmwareStatusNotification"
+	ActionDiagnosticsStatusNotification = "DiagnosticsStatusNotification"
+)
+
+// Actions initiated by CSMS.
+const (
+	ActionRemoteStartTransaction = "RemoteStartTransaction"
+	ActionRemoteStopTransaction  = "RemoteStopTransaction"
+	ActionReset                  = "Reset"
+	ActionChangeAvailability     = "ChangeAvailability"
+	ActionUnlockConnector        = "UnlockConnector"
+	ActionTriggerMessage         = "TriggerMessage"
+	ActionClearCache             = "ClearCache"
+	ActionGetConfiguration       = "GetConfiguration"
+	ActionChangeConfiguration    = "ChangeConfiguration"
+	ActionSetChargingProfile     = "SetChargingProfile"
+	ActionClearChargingProfile   = "ClearChargingProfile"
+	ActionGetCompositeSchedule   = "GetCompositeSchedule"
+	ActionUpdateFirmware         = "UpdateFirmware"
+	ActionGetDiagnostics         = "GetDiagnostics"
+	ActionReserveNow             = "ReserveNow"
+	ActionCancelReservation      = "CancelReservation"
+)
+
+// IdTagInfo authorization status values.
+const (
+	AuthorizationAccepted     = "Accepted"
+	AuthorizationBlocked      = "Blocked"
+	AuthorizationExpired      = "Expired"
+	AuthorizationInvalid      = "Invalid"
+	AuthorizationConcurrentTx = "ConcurrentTx"
+)
+
+// Remote start/stop status values.
+const (
+	RemoteStatusAccepted = "Accepted"
+	RemoteStatusRejected = "Rejected"
+)
+
+// Reset types.
+const (
+	ResetHard = "Hard"
+	ResetSoft = "Soft"
+)
+
+// ChangeAvailability types.
+const (
+	AvailabilityInoperative = "Inoperative"
+	AvailabilityOperative   = "Operative"
+)
+
+// Status values of Reset, ChangeAvailability, TriggerMessage and ClearCache answers.
+const (
+	CommandAccepted       = "Accepted"
+	CommandRejected       = "Rejected"
+	CommandScheduled      = "Scheduled"
+	CommandNotImplemented = "NotImplemented"
+)
+
+// ChangeConfiguration status values.
+const (
+	ConfigurationAccepted       = "Accepted"
+	ConfigurationRejected       = "Rejected"
+	ConfigurationRebootRequired = "RebootRequired"
+	ConfigurationNotSupported   = "NotSupported"
+)
+
+// ChargingProfile purposes.
+const (
+	ProfilePurposeChargePointMax = "ChargePointMaxProfile"
+	ProfilePurposeTxDefault      = "TxDefaultProfile"
+	ProfilePurposeTx             = "TxProfile"
+)
+
+// ChargingProfile kinds.
+const (
+	ProfileKindAbsolute  = "Absolute"
+	ProfileKindRecurring = "Recurring"
+	ProfileKindRelative  = "Relative"
+)
+
+// ChargingProfile recurrency kinds.
+const (
+	RecurrencyDaily  = "Daily"
+	RecurrencyWeekly = "Weekly"
+)
+
+// ChargingSchedule rate units.
+const (
+	ChargingRateAmperes = "A"
+	ChargingRateWatts   = "W"
+)
+
+// ClearChargingProfile status values.
+const (
+	ClearProfileAccepted = "Accepted"
+	ClearProfileUnknown  = "Unknown"
+)
+
+// UnlockConnector status values.
+const (
+	UnlockUnlocked     = "Unlocked"
+	UnlockFailed       = "UnlockFailed"
+	UnlockNotSupported = "NotSupported"
+)
+
+// Messages station can be asked to send with TriggerMessage.
+var TriggerableMessages = []string{
+	ActionBootNotification,
+	ActionDiagnosticsStatusNotification,
+	ActionFirmwareStatusNotification,
+	ActionHeartbeat,
+	ActionMeterValues,
+	ActionStatusNotification,
+}
+
+// FirmwareStatusNotification status values.
+const (
+	FirmwareDownloaded         = "Downloaded"
+	FirmwareDownloadFailed     = "DownloadFailed"
+	FirmwareDownloading        = "Downloading"
+	FirmwareIdle               = "Idle"
+	FirmwareInstallationFailed = "InstallationFailed"
+	FirmwareInstalling         = "Installing"
+	FirmwareInstalled          = "Installed"
+)
+
+// DiagnosticsStatusNotification status values.
+const (
+	DiagnosticsIdle         = "Idle"
+	DiagnosticsUploaded     = "Uploaded"
+	DiagnosticsUploadFailed = "UploadFailed"
+	DiagnosticsUploading    = "Uploading"
+)
+
+// ReserveNow response status values.
+const (
+	ReservationAccepted    = "Accepted"
+	ReservationFaulted     = "Faulted"
+	ReservationOccupied    = "Occupied"
+	ReservationRejected    = "Rejected"
+	ReservationUnavailable = "Unavailable"
 )
 
 // Registration status values.
 const (
 	RegistrationAccepted = "Accepted"
+	RegistrationPending  = "Pending"
 	RegistrationRejected = "Rejected"
 )
 
@@ -32,4 +183,49 @@ const (
 	ConnectorPreparing     = "Preparing"
 	ConnectorFaulted       = "Faulted"
 	ConnectorReserved      = "Reserved"
+	ConnectorSuspendedEV   = "SuspendedEV"
+	ConnectorSuspendedEVSE = "SuspendedEVSE"
+)
+
+// SampledValue measurands (subset used by CSMS).
+const (
+	MeasurandEnergyActiveImportRegister = "Energy.Active.Import.Register"
+	MeasurandPowerActiveImport          = "Power.Active.Import"
+	MeasurandCurrentImport              = "Current.Import"
+	MeasurandVoltage                    = "Voltage"
+	MeasurandSoC                        = "SoC"
 )
+
+// SampledValue units of measure (subset).
+const (
+	UnitWh      = "Wh"
+	UnitKWh     = "kWh"
+	UnitW       = "W"
+	UnitKW      = "kW"
+	UnitAmperes = "A"
+)
+
+// SampledValue locations (subset).
+const (
+	LocationOutlet = "Outlet"
+	LocationInlet  = "Inlet"
+)
+
+// StationActions lists every station-initiated action defined by OCPP 1.6J.
+var StationActions = []string{
+	ActionAuthorize,
+	ActionBootNotification,
+	"DataTransfer",
+	ActionDiagnosticsStatusNotification,
+	ActionFirmwareStatusNotification,
+	ActionHeartbeat,
+	ActionMeterValues,
+	ActionStartTransaction,
+	ActionStatusNotification,
+	ActionStopTransaction,
+}
+
+// ErrorCodes maps generic CALLERROR codes missing in OCPP 1.6J.
+var ErrorCodes = map[string]string{
+	"MessageTypeNotSupported": "GenericError",
+}
@@ -2,17 +2,20 @@ package protocol
 
 import "time"
 
-// BootNotificationRequest minimal subset.
+// BootNotificationRequest payload (OCPP 1.6J).
 type BootNotificationRequest struct {
-	ChargePointVendor  string `json:"chargePointVendor"`
-	ChargePointModel   string `json:"chargePointModel"`
-	ChargePointSerial  string `json:"chargePointSerialNumber"`
-	ChargeBoxSerial    string `json:"chargeBoxSerialNumber"`
-	FirmwareVersion    string `json:"firmwareVersion"`
-	StationID          string `json:"stationId"`
+	ChargePointVendor       string `json:"chargePointVendor" ocpp:"required,max=20"`
+	ChargePointModel        string `json:"chargePointModel" ocpp:"required,max=20"`
+	ChargePointSerialNumber string `json:"chargePointSerialNumber,omitempty" ocpp:"max=25"`
+	ChargeBoxSerialNumber   string `json:"chargeBoxSerialNumber,omitempty" ocpp:"max=25"`
+	FirmwareVersion         string `json:"firmwareVersion,omitempty" ocpp:"max=50"`
+	Iccid                   string `json:"iccid,omitempty" ocpp:"max=20"`
+	Imsi                    string `json:"imsi,omitempty" ocpp:"max=20"`
+	MeterType               string `json:"meterType,omitempty" ocpp:"max=25"`
+	MeterSerialNumber       string `json:"meterSerialNumber,omitempty" ocpp:"max=25"`
 }
 
-// BootNotificationResponse minimal response.
+// BootNotificationResponse payload.
 type BootNotificationResponse struct {
 	CurrentTime time.Time `json:"currentTime"`
 	Interval    int       `json:"interval"`
@@ -21,61 +24,336 @@ type BootNotificationResponse struct {
 
 // StatusNotificationRequest payload.
 type StatusNotificationRequest struct {
-	ConnectorID       int       `json:"connectorId"`
-	ConnectorStatus   string    `json:"status"`
-	ErrorCode         string    `json:"errorCode"`
-	Info              string    `json:"info"`
-	Timestamp         time.Time `json:"timestamp"`
-	VendorID          string    `json:"vendorId"`
-	StationID         string    `json:"stationId"`
+	ConnectorID     int        `json:"connectorId" ocpp:"required"`
+	ErrorCode       string     `json:"errorCode" ocpp:"required,oneof=ConnectorLockFailure EVCommunicationError GroundFailure HighTemperature InternalError LocalListConflict NoError OtherError OverCurrentFailure PowerMeterFailure PowerSwitchFailure ReaderFailure ResetFailure UnderVoltage OverVoltage WeakSignal"`
+	Status          string     `json:"status" ocpp:"required,oneof=Available Preparing Charging SuspendedEVSE SuspendedEV Finishing Reserved Unavailable Faulted"`
+	Info            string     `json:"info,omitempty" ocpp:"max=50"`
+	Timestamp       *time.Time `json:"timestamp,omitempty"`
+	VendorID        string     `json:"vendorId,omitempty" ocpp:"max=255"`
+	VendorErrorCode string     `json:"vendorErrorCode,omitempty" ocpp:"max=50"`
 }
 
 // StatusNotificationResponse is empty (ack).
 type StatusNotificationResponse struct{}
 
+// IdTagInfo carries authorization decision for id tag.
+type IdTagInfo struct {
+	Status      string     `json:"status"`
+	ExpiryDate  *time.Time `json:"expiryDate,omitempty"`
+	ParentIdTag string     `json:"parentIdTag,omitempty"`
+}
+
+// AuthorizeRequest payload.
+type AuthorizeRequest struct {
+	IdTag string `json:"idTag" ocpp:"required,max=20"`
+}
+
+// AuthorizeResponse returns id tag decision.
+type AuthorizeResponse struct {
+	IdTagInfo IdTagInfo `json:"idTagInfo"`
+}
+
 // StartTransactionRequest payload.
 type StartTransactionRequest struct {
-	ConnectorID   int       `json:"connectorId"`
-	IdTag         string    `json:"idTag"`
-	MeterStart    int64     `json:"meterStart"`
-	ReservationID int       `json:"reservationId"`
-	Timestamp     time.Time `json:"timestamp"`
-	StationID     string    `json:"stationId"`
-	TransactionID string    `json:"transactionId"`
+	ConnectorID   int       `json:"connectorId" ocpp:"required"`
+	IdTag         string    `json:"idTag" ocpp:"required,max=20"`
+	MeterStart    int64     `json:"meterStart" ocpp:"required"`
+	ReservationID *int      `json:"reservationId,omitempty"`
+	Timestamp     time.Time `json:"timestamp" ocpp:"required"`
 }
 
-// StartTransactionResponse simplified response.
+// StartTransactionResponse carries transaction ID assigned by CSMS.
 type StartTransactionResponse struct {
-	TransactionID string `json:"transactionId"`
-	IdTagInfo     struct {
-		Status string `json:"status"`
-	} `json:"idTagInfo"`
+	TransactionID int       `json:"transactionId"`
+	IdTagInfo     IdTagInfo `json:"idTagInfo"`
 }
 
 // StopTransactionRequest payload.
 type StopTransactionRequest struct {
-	TransactionID string    `json:"transactionId"`
-	IdTag         string    `json:"idTag"`
-	MeterStop     int64     `json:"meterStop"`
-	Timestamp     time.Time `json:"timestamp"`
-	Reason        string    `json:"reason"`
-	StationID     string    `json:"stationId"`
-	MeterStart    int64     `json:"meterStart"` // optional for energy calc
+	TransactionID   int          `json:"transactionId" ocpp:"required"`
+	IdTag           string       `json:"idTag,omitempty" ocpp:"max=20"`
+	MeterStop       int64        `json:"meterStop" ocpp:"required"`
+	Timestamp       time.Time    `json:"timestamp" ocpp:"required"`
+	Reason          string       `json:"reason,omitempty" ocpp:"oneof=EmergencyStop EVDisconnected HardReset Local Other PowerLoss Reboot Remote SoftReset UnlockCommand DeAuthorized"`
+	TransactionData []MeterValue `json:"transactionData,omitempty"`
+}
+
+// StopTransactionResponse returns id tag decision when idTag was sent.
+type StopTransactionResponse struct {
+	IdTagInfo *IdTagInfo `json:"idTagInfo,omitempty"`
+}
+
+// SampledValue is single measurement inside MeterValue.
+type SampledValue struct {
+	Value     string `json:"value" ocpp:"required"`
+	Context   string `json:"context,omitempty" ocpp:"oneof=Interruption.Begin Interruption.End Other Sample.Clock Sample.Periodic Transaction.Begin Transaction.End Trigger"`
+	Format    string `json:"format,omitempty" ocpp:"oneof=Raw SignedData"`
+	Measurand string `json:"measurand,omitempty" ocpp:"oneof=Current.Export Current.Import Current.Offered Energy.Active.Export.Register Energy.Active.Import.Register Energy.Reactive.Export.Register Energy.Reactive.Import.Register Energy.Active.Export.Interval Energy.Active.Import.Interval Energy.Reactive.Export.Interval Energy.Reactive.Import.Interval Frequency Power.Active.Export Power.Active.Import Power.Factor Power.Offered Power.Reactive.Export Power.Reactive.Import RPM SoC Temperature Voltage"`
+	Phase     string `json:"phase,omitempty" ocpp:"oneof=L1 L2 L3 N L1-N L2-N L3-N L1-L2 L2-L3 L3-L1"`
+	Location  string `json:"location,omitempty" ocpp:"oneof=Cable EV Inlet Outlet Body"`
+	Unit      string `json:"unit,omitempty" ocpp:"oneof=Wh kWh varh kvarh W kW VA kVA var kvar A V Celsius Fahrenheit K Percent"`
+}
+
+// MeterValue groups sampled values taken at the same time.
+type MeterValue struct {
+	Timestamp    time.Time      `json:"timestamp" ocpp:"required"`
+	SampledValue []SampledValue `json:"sampledValue" ocpp:"required,min=1"`
 }
 
 // MeterValuesRequest payload for telemetry.
 type MeterValuesRequest struct {
-	StationID     string    `json:"stationId"`
-	ConnectorID   int       `json:"connectorId"`
-	TransactionID string    `json:"transactionId"`
-	MeterValue    float64   `json:"meterValue"`
-	Timestamp     time.Time `json:"timestamp"`
+	ConnectorID   int          `json:"connectorId" ocpp:"required"`
+	TransactionID *int         `json:"transactionId,omitempty"`
+	MeterValue    []MeterValue `json:"meterValue" ocpp:"required,min=1"`
 }
 
-// StopTransactionResponse ack.
-type StopTransactionResponse struct{}
+// MeterValuesResponse is empty (ack).
+type MeterValuesResponse struct{}
+
+// HeartbeatRequest is empty.
+type HeartbeatRequest struct{}
 
 // HeartbeatResponse returns server time.
 type HeartbeatResponse struct {
 	CurrentTime time.Time `json:"currentTime"`
 }
+
+// RemoteStartTransactionRequest asks station to start charging.
+type RemoteStartTransactionRequest struct {
+	ConnectorID int    `json:"connectorId,omitempty"`
+	IdTag       string `json:"idTag"`
+}
+
+// RemoteStartTransactionResponse carries station decision.
+type RemoteStartTransactionResponse struct {
+	Status string `json:"status"`
+}
+
+// RemoteStopTransactionRequest asks station to stop transaction.
+type RemoteStopTransactionRequest struct {
+	TransactionID int `json:"transactionId"`
+}
+
+// RemoteStopTransactionResponse carries station decision.
+type RemoteStopTransactionResponse struct {
+	Status string `json:"status"`
+}
+
+// ResetRequest asks station to reboot.
+type ResetRequest struct {
+	Type string `json:"type" ocpp:"required,oneof=Hard Soft"`
+}
+
+// ResetResponse carries station decision.
+type ResetResponse struct {
+	Status string `json:"status"`
+}
+
+// ChangeAvailabilityRequest switches connector (0 for whole station) between
+// Operative and Inoperative.
+type ChangeAvailabilityRequest struct {
+	ConnectorID int    `json:"connectorId"`
+	Type        string `json:"type" ocpp:"required,oneof=Inoperative Operative"`
+}
+
+// ChangeAvailabilityResponse carries station decision; Scheduled means the
+// change happens once running transactions end.
+type ChangeAvailabilityResponse struct {
+	Status string `json:"status"`
+}
+
+// UnlockConnectorRequest asks station to release cable of connector.
+type UnlockConnectorRequest struct {
+	ConnectorID int `json:"connectorId" ocpp:"required"`
+}
+
+// UnlockConnectorResponse carries unlock result.
+type UnlockConnectorResponse struct {
+	Status string `json:"status"`
+}
+
+// TriggerMessageRequest asks station to send given message.
+type TriggerMessageRequest struct {
+	RequestedMessage string `json:"requestedMessage" ocpp:"required,oneof=BootNotification DiagnosticsStatusNotification FirmwareStatusNotification Heartbeat MeterValues StatusNotification"`
+	ConnectorID      *int   `json:"connectorId,omitempty"`
+}
+
+// TriggerMessageResponse carries station decision.
+type TriggerMessageResponse struct {
+	Status string `json:"status"`
+}
+
+// ClearCacheRequest is empty.
+type ClearCacheRequest struct{}
+
+// ClearCacheResponse carries station decision.
+type ClearCacheResponse struct {
+	Status string `json:"status"`
+}
+
+// GetConfigurationRequest asks station for configuration keys; empty Key
+// requests all keys.
+type GetConfigurationRequest struct {
+	Key []string `json:"key,omitempty"`
+}
+
+// KeyValue is configuration key reported by station.
+type KeyValue struct {
+	Key      string  `json:"key" ocpp:"required,max=50"`
+	Readonly bool    `json:"readonly"`
+	Value    *string `json:"value,omitempty" ocpp:"max=500"`
+}
+
+// GetConfigurationResponse carries known keys and keys the station does not support.
+type GetConfigurationResponse struct {
+	ConfigurationKey []KeyValue `json:"configurationKey,omitempty"`
+	UnknownKey       []string   `json:"unknownKey,omitempty"`
+}
+
+// ChangeConfigurationRequest sets single configuration key.
+type ChangeConfigurationRequest struct {
+	Key   string `json:"key" ocpp:"required,max=50"`
+	Value string `json:"value" ocpp:"required,max=500"`
+}
+
+// ChangeConfigurationResponse carries station decision.
+type ChangeConfigurationResponse struct {
+	Status string `json:"status"`
+}
+
+// ChargingSchedulePeriod is limit applied from StartPeriod seconds after schedule start.
+type ChargingSchedulePeriod struct {
+	StartPeriod  int     `json:"startPeriod"`
+	Limit        float64 `json:"limit"`
+	NumberPhases *int    `json:"numberPhases,omitempty"`
+}
+
+// ChargingSchedule is list of limits in A or W.
+type ChargingSchedule struct {
+	Duration               *int                     `json:"duration,omitempty"`
+	StartSchedule          *time.Time               `json:"startSchedule,omitempty"`
+	ChargingRateUnit       string                   `json:"chargingRateUnit"`
+	ChargingSchedulePeriod []ChargingSchedulePeriod `json:"chargingSchedulePeriod"`
+	MinChargingRate        *float64                 `json:"minChargingRate,omitempty"`
+}
+
+// ChargingProfile limits power or current of connector or whole station.
+type ChargingProfile struct {
+	ChargingProfileID      int              `json:"chargingProfileId"`
+	TransactionID          *int             `json:"transactionId,omitempty"`
+	StackLevel             int              `json:"stackLevel"`
+	ChargingProfilePurpose string           `json:"chargingProfilePurpose"`
+	ChargingProfileKind    string           `json:"chargingProfileKind"`
+	RecurrencyKind         string           `json:"recurrencyKind,omitempty"`
+	ValidFrom              *time.Time       `json:"validFrom,omitempty"`
+	ValidTo                *time.Time       `json:"validTo,omitempty"`
+	ChargingSchedule       ChargingSchedule `json:"chargingSchedule"`
+}
+
+// SetChargingProfileRequest installs profile on connector (0 for whole station).
+type SetChargingProfileRequest struct {
+	ConnectorID        int             `json:"connectorId"`
+	CsChargingProfiles ChargingProfile `json:"csChargingProfiles"`
+}
+
+// SetChargingProfileResponse carries station decision.
+type SetChargingProfileResponse struct {
+	Status string `json:"status"`
+}
+
+// ClearChargingProfileRequest removes profiles matching all given criteria.
+type ClearChargingProfileRequest struct {
+	ID                     *int   `json:"id,omitempty"`
+	ConnectorID            *int   `json:"connectorId,omitempty"`
+	ChargingProfilePurpose string `json:"chargingProfilePurpose,omitempty"`
+	StackLevel             *int   `json:"stackLevel,omitempty"`
+}
+
+// ClearChargingProfileResponse carries station decision.
+type ClearChargingProfileResponse struct {
+	Status string `json:"status"`
+}
+
+// GetCompositeScheduleRequest asks for schedule resulting from installed profiles.
+type GetCompositeScheduleRequest struct {
+	ConnectorID      int    `json:"connectorId"`
+	Duration         int    `json:"duration"`
+	ChargingRateUnit string `json:"chargingRateUnit,omitempty"`
+}
+
+// GetCompositeScheduleResponse carries composite schedule when Accepted.
+type GetCompositeScheduleResponse struct {
+	Status           string            `json:"status"`
+	ConnectorID      *int              `json:"connectorId,omitempty"`
+	ScheduleStart    *time.Time        `json:"scheduleStart,omitempty"`
+	ChargingSchedule *ChargingSchedule `json:"chargingSchedule,omitempty"`
+}
+
+// UpdateFirmwareRequest asks station to download firmware from location after
+// retrieveDate and install it.
+type UpdateFirmwareRequest struct {
+	Location      string    `json:"location"`
+	Retries       *int      `json:"retries,omitempty"`
+	RetrieveDate  time.Time `json:"retrieveDate"`
+	RetryInterval *int      `json:"retryInterval,omitempty"`
+}
+
+// UpdateFirmwareResponse is empty; progress is reported with FirmwareStatusNotification.
+type UpdateFirmwareResponse struct{}
+
+// FirmwareStatusNotificationRequest reports firmware download and installation progress.
+type FirmwareStatusNotificationRequest struct {
+	Status string `json:"status" ocpp:"required,oneof=Downloaded DownloadFailed Downloading Idle InstallationFailed Installing Installed"`
+}
+
+// FirmwareStatusNotificationResponse is empty.
+type FirmwareStatusNotificationResponse struct{}
+
+// GetDiagnosticsRequest asks station to upload diagnostics file to location
+// (directory URI).
+type GetDiagnosticsRequest struct {
+	Location      string     `json:"location"`
+	Retries       *int       `json:"retries,omitempty"`
+	RetryInterval *int       `json:"retryInterval,omitempty"`
+	StartTime     *time.Time `json:"startTime,omitempty"`
+	StopTime      *time.Time `json:"stopTime,omitempty"`
+}
+
+// GetDiagnosticsResponse carries name of file to be uploaded; empty when
+// station has no diagnostics.
+type GetDiagnosticsResponse struct {
+	FileName string `json:"fileName,omitempty"`
+}
+
+// DiagnosticsStatusNotificationRequest reports diagnostics upload progress.
+type DiagnosticsStatusNotificationRequest struct {
+	Status string `json:"status" ocpp:"required,oneof=Idle Uploaded UploadFailed Uploading"`
+}
+
+// DiagnosticsStatusNotificationResponse is empty.
+type DiagnosticsStatusNotificationResponse struct{}
+
+// ReserveNowRequest reserves connector for idTag until expiryDate.
+type ReserveNowRequest struct {
+	ConnectorID   int       `json:"connectorId"`
+	ExpiryDate    time.Time `json:"expiryDate"`
+	IdTag         string    `json:"idTag"`
+	ParentIdTag   string    `json:"parentIdTag,omitempty"`
+	ReservationID int       `json:"reservationId"`
+}
+
+// ReserveNowResponse carries station decision.
+type ReserveNowResponse struct {
+	Status string `json:"status"`
+}
+
+// CancelReservationRequest releases reservation.
+type CancelReservationRequest struct {
+	ReservationID int `json:"reservationId"`
+}
+
+// CancelReservationResponse carries station decision; Rejected when station
+// holds no such reservation.
+type CancelReservationResponse struct {
+	Status string `json:"status"`
+}
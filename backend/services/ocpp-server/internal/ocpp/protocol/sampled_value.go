@@ -0,0 +1,107 @@
+package protocol
+
+import "strconv"
+
+// EnergyImportKWh returns total Energy.Active.Import.Register reading in kWh.
+// Per-phase and inlet samples are ignored; missing measurand and unit fall back
+// to the spec defaults (energy register in Wh).
+func (m MeterValue) EnergyImportKWh() (float64, bool) {
+	for _, sv := range m.SampledValue {
+		measurand := sv.Measurand
+		if measurand == "" {
+			measurand = MeasurandEnergyActiveImportRegister
+		}
+		if measurand != MeasurandEnergyActiveImportRegister || sv.Phase != "" || sv.Format == "SignedData" {
+			continue
+		}
+		if sv.Location != "" && sv.Location != LocationOutlet {
+			continue
+		}
+		value, err := strconv.ParseFloat(sv.Value, 64)
+		if err != nil {
+			continue
+		}
+		switch sv.Unit {
+		case "", UnitWh:
+			return value / 1000.0, true
+		case UnitKWh:
+			return value, true
+		}
+	}
+	return 0, false
+}
+
+// LastEnergyImportKWh returns latest energy register reading among meter values.
+func LastEnergyImportKWh(values []MeterValue) (float64, bool) {
+	for i := len(values) - 1; i >= 0; i-- {
+		if kwh, ok := values[i].EnergyImportKWh(); ok {
+			return kwh, true
+		}
+	}
+	return 0, false
+}
+
+// CurrentImportAmps returns Current.Import at the outlet in A: the highest
+// phase when phases are reported separately.
+func (m MeterValue) CurrentImportAmps() (float64, bool) {
+	var (
+		amps  float64
+		found bool
+	)
+	for _, sv := range m.outletSamples(MeasurandCurrentImport) {
+		if sv.Unit != "" && sv.Unit != UnitAmperes {
+			continue
+		}
+		value, err := strconv.ParseFloat(sv.Value, 64)
+		if err != nil {
+			continue
+		}
+		if !found || value > amps {
+			amps, found = value, true
+		}
+	}
+	return amps, found
+}
+
+// PowerImportWatts returns Power.Active.Import at the outlet in W; phase
+// samples are summed when total is not reported.
+func (m MeterValue) PowerImportWatts() (float64, bool) {
+	var total, phases float64
+	var hasTotal, hasPhases bool
+	for _, sv := range m.outletSamples(MeasurandPowerActiveImport) {
+		value, err := strconv.ParseFloat(sv.Value, 64)
+		if err != nil {
+			continue
+		}
+		switch sv.Unit {
+		case "", UnitW:
+		case UnitKW:
+			value *= 1000
+		default:
+			continue
+		}
+		if sv.Phase == "" {
+			total, hasTotal = value, true
+		} else {
+			phases, hasPhases = phases+value, true
+		}
+	}
+	if hasTotal {
+		return total, true
+	}
+	return phases, hasPhases
+}
+
+func (m MeterValue) outletSamples(measurand string) []SampledValue {
+	var samples []SampledValue
+	for _, sv := range m.SampledValue {
+		if sv.Measurand != measurand || sv.Format == "SignedData" {
+			continue
+		}
+		if sv.Location != "" && sv.Location != LocationOutlet {
+			continue
+		}
+		samples = append(samples, sv)
+	}
+	return samples
+}
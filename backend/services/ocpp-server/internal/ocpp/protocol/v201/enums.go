@@ -0,0 +1,127 @@
+// Package v201 holds OCPP 2.0.1 message schemas.
+package v201
+
+// Subprotocol is websocket subprotocol name of OCPP 2.0.1.
+const Subprotocol = "ocpp2.0.1"
+
+// Actions initiated by charging station.
+const (
+	ActionBootNotification      = "BootNotification"
+	ActionStatusNotification    = "StatusNotification"
+	ActionHeartbeat             = "Heartbeat"
+	ActionAuthorize             = "Authorize"
+	ActionTransactionEvent      = "TransactionEvent"
+	ActionMeterValues           = "MeterValues"
+	ActionLogStatusNotification = "LogStatusNotification"
+)
+
+// Actions initiated by CSMS.
+const (
+	ActionGetLog                  = "GetLog"
+	ActionRequestStartTransaction = "RequestStartTransaction"
+	ActionRequestStopTransaction  = "RequestStopTransaction"
+)
+
+// IdToken type of tags started remotely by CSMS.
+const IdTokenCentral = "Central"
+
+// GetLog log types.
+const (
+	LogDiagnostics = "DiagnosticsLog"
+	LogSecurity    = "SecurityLog"
+)
+
+// GetLog response status values.
+const (
+	LogAccepted         = "Accepted"
+	LogRejected         = "Rejected"
+	LogAcceptedCanceled = "AcceptedCanceled"
+)
+
+// LogStatusNotification status values.
+const (
+	UploadBadMessage            = "BadMessage"
+	UploadIdle                  = "Idle"
+	UploadNotSupportedOperation = "NotSupportedOperation"
+	UploadPermissionDenied      = "PermissionDenied"
+	UploadUploaded              = "Uploaded"
+	UploadFailure               = "UploadFailure"
+	UploadUploading             = "Uploading"
+	UploadAcceptedCanceled      = "AcceptedCanceled"
+)
+
+// Registration status values.
+const (
+	RegistrationAccepted = "Accepted"
+	RegistrationPending  = "Pending"
+	RegistrationRejected = "Rejected"
+)
+
+// IdTokenInfo authorization status values (subset).
+const (
+	AuthorizationAccepted     = "Accepted"
+	AuthorizationBlocked      = "Blocked"
+	AuthorizationExpired      = "Expired"
+	AuthorizationInvalid      = "Invalid"
+	AuthorizationConcurrentTx = "ConcurrentTx"
+	AuthorizationUnknown      = "Unknown"
+)
+
+// Connector status values.
+const (
+	ConnectorAvailable   = "Available"
+	ConnectorOccupied    = "Occupied"
+	ConnectorReserved    = "Reserved"
+	ConnectorUnavailable = "Unavailable"
+	ConnectorFaulted     = "Faulted"
+)
+
+// TransactionEvent event types.
+const (
+	TransactionEventStarted = "Started"
+	TransactionEventUpdated = "Updated"
+	TransactionEventEnded   = "Ended"
+)
+
+// Measurands and units (subset used by CSMS).
+const (
+	MeasurandEnergyActiveImportRegister = "Energy.Active.Import.Register"
+	UnitWh                              = "Wh"
+	UnitKWh                             = "kWh"
+	LocationOutlet                      = "Outlet"
+)
+
+// StationActions lists every station-initiated action defined by OCPP 2.0.1.
+var StationActions = []string{
+	ActionAuthorize,
+	ActionBootNotification,
+	"ClearedChargingLimit",
+	"DataTransfer",
+	"FirmwareStatusNotification",
+	"Get15118EVCertificate",
+	"GetCertificateStatus",
+	ActionHeartbeat,
+	ActionLogStatusNotification,
+	ActionMeterValues,
+	"NotifyChargingLimit",
+	"NotifyCustomerInformation",
+	"NotifyDisplayMessages",
+	"NotifyEVChargingNeeds",
+	"NotifyEVChargingSchedule",
+	"NotifyEvent",
+	"NotifyMonitoringReport",
+	"NotifyReport",
+	"PublishFirmwareStatusNotification",
+	"ReportChargingProfiles",
+	"ReservationStatusUpdate",
+	"SecurityEventNotification",
+	"SignCertificate",
+	ActionStatusNotification,
+	ActionTransactionEvent,
+}
+
+// ErrorCodes maps OCPP 1.6 CALLERROR spelling to OCPP 2.0.1 names.
+var ErrorCodes = map[string]string{
+	"FormationViolation":           "FormatViolation",
+	"OccurenceConstraintViolation": "OccurrenceConstraintViolation",
+}
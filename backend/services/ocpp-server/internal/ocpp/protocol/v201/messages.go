@@ -0,0 +1,197 @@
+package v201
+
+import "time"
+
+// Modem describes wireless module of station.
+type Modem struct {
+	Iccid string `json:"iccid,omitempty" ocpp:"max=20"`
+	Imsi  string `json:"imsi,omitempty" ocpp:"max=20"`
+}
+
+// ChargingStation identifies station hardware.
+type ChargingStation struct {
+	Model           string `json:"model" ocpp:"required,max=20"`
+	VendorName      string `json:"vendorName" ocpp:"required,max=50"`
+	SerialNumber    string `json:"serialNumber,omitempty" ocpp:"max=25"`
+	FirmwareVersion string `json:"firmwareVersion,omitempty" ocpp:"max=50"`
+	Modem           *Modem `json:"modem,omitempty"`
+}
+
+// BootNotificationRequest payload.
+type BootNotificationRequest struct {
+	Reason          string          `json:"reason" ocpp:"required,oneof=ApplicationReset FirmwareUpdate LocalReset PowerUp RemoteReset ScheduledReset Triggered Unknown Watchdog"`
+	ChargingStation ChargingStation `json:"chargingStation" ocpp:"required"`
+}
+
+// BootNotificationResponse payload.
+type BootNotificationResponse struct {
+	CurrentTime time.Time `json:"currentTime"`
+	Interval    int       `json:"interval"`
+	Status      string    `json:"status"`
+}
+
+// StatusNotificationRequest payload.
+type StatusNotificationRequest struct {
+	Timestamp       time.Time `json:"timestamp" ocpp:"required"`
+	ConnectorStatus string    `json:"connectorStatus" ocpp:"required,oneof=Available Occupied Reserved Unavailable Faulted"`
+	EvseID          int       `json:"evseId" ocpp:"required"`
+	ConnectorID     int       `json:"connectorId" ocpp:"required"`
+}
+
+// StatusNotificationResponse is empty (ack).
+type StatusNotificationResponse struct{}
+
+// HeartbeatRequest is empty.
+type HeartbeatRequest struct{}
+
+// HeartbeatResponse returns server time.
+type HeartbeatResponse struct {
+	CurrentTime time.Time `json:"currentTime"`
+}
+
+// IdToken identifies user or vehicle.
+type IdToken struct {
+	IdToken string `json:"idToken" ocpp:"required,max=36"`
+	Type    string `json:"type" ocpp:"required,oneof=Central eMAID ISO14443 ISO15693 KeyCode Local MacAddress NoAuthorization"`
+}
+
+// IdTokenInfo carries authorization decision.
+type IdTokenInfo struct {
+	Status              string     `json:"status"`
+	CacheExpiryDateTime *time.Time `json:"cacheExpiryDateTime,omitempty"`
+	GroupIdToken        *IdToken   `json:"groupIdToken,omitempty"`
+}
+
+// AuthorizeRequest payload.
+type AuthorizeRequest struct {
+	IdToken IdToken `json:"idToken" ocpp:"required"`
+}
+
+// AuthorizeResponse payload.
+type AuthorizeResponse struct {
+	IdTokenInfo IdTokenInfo `json:"idTokenInfo"`
+}
+
+// UnitOfMeasure of sampled value, value is multiplied by 10^Multiplier.
+type UnitOfMeasure struct {
+	Unit       string `json:"unit,omitempty" ocpp:"max=20"`
+	Multiplier int    `json:"multiplier,omitempty"`
+}
+
+// SampledValue is single measurement inside MeterValue.
+type SampledValue struct {
+	Value         float64        `json:"value" ocpp:"required"`
+	Context       string         `json:"context,omitempty" ocpp:"oneof=Interruption.Begin Interruption.End Other Sample.Clock Sample.Periodic Transaction.Begin Transaction.End Trigger"`
+	Measurand     string         `json:"measurand,omitempty" ocpp:"oneof=Current.Export Current.Import Current.Offered Energy.Active.Export.Register Energy.Active.Import.Register Energy.Reactive.Export.Register Energy.Reactive.Import.Register Energy.Active.Export.Interval Energy.Active.Import.Interval Energy.Active.Net Energy.Reactive.Export.Interval Energy.Reactive.Import.Interval Energy.Reactive.Net Energy.Apparent.Net Energy.Apparent.Import Energy.Apparent.Export Frequency Power.Active.Export Power.Active.Import Power.Factor Power.Offered Power.Reactive.Export Power.Reactive.Import SoC Voltage"`
+	Phase         string         `json:"phase,omitempty" ocpp:"oneof=L1 L2 L3 N L1-N L2-N L3-N L1-L2 L2-L3 L3-L1"`
+	Location      string         `json:"location,omitempty" ocpp:"oneof=Body Cable EV Inlet Outlet"`
+	UnitOfMeasure *UnitOfMeasure `json:"unitOfMeasure,omitempty"`
+}
+
+// MeterValue groups sampled values taken at the same time.
+type MeterValue struct {
+	Timestamp    time.Time      `json:"timestamp" ocpp:"required"`
+	SampledValue []SampledValue `json:"sampledValue" ocpp:"required,min=1"`
+}
+
+// EVSE addresses EVSE and optionally connector.
+type EVSE struct {
+	ID          int `json:"id" ocpp:"required"`
+	ConnectorID int `json:"connectorId,omitempty"`
+}
+
+// Transaction describes transaction in TransactionEvent.
+type Transaction struct {
+	TransactionID     string `json:"transactionId" ocpp:"required,max=36"`
+	ChargingState     string `json:"chargingState,omitempty" ocpp:"oneof=Charging EVConnected SuspendedEV SuspendedEVSE Idle"`
+	TimeSpentCharging int    `json:"timeSpentCharging,omitempty"`
+	StoppedReason     string `json:"stoppedReason,omitempty" ocpp:"oneof=DeAuthorized EmergencyStop EnergyLimitReached EVDisconnected GroundFault ImmediateReset Local LocalOutOfCredit MasterPass Other OvercurrentFault PowerLoss PowerQuality Reboot Remote SOCLimitReached StoppedByEV TimeLimitReached Timeout"`
+	RemoteStartID     *int   `json:"remoteStartId,omitempty"`
+}
+
+// TransactionEventRequest payload.
+type TransactionEventRequest struct {
+	EventType          string       `json:"eventType" ocpp:"required,oneof=Started Updated Ended"`
+	Timestamp          time.Time    `json:"timestamp" ocpp:"required"`
+	TriggerReason      string       `json:"triggerReason" ocpp:"required,oneof=Authorized CablePluggedIn ChargingRateChanged ChargingStateChanged Deauthorized EnergyLimitReached EVCommunicationLost EVConnectTimeout MeterValueClock MeterValuePeriodic TimeLimitReached Trigger UnlockCommand StopAuthorized EVDeparted EVDetected RemoteStop RemoteStart AbnormalCondition SignedDataReceived ResetCommand"`
+	SeqNo              int          `json:"seqNo" ocpp:"required"`
+	Offline            bool         `json:"offline,omitempty"`
+	NumberOfPhasesUsed int          `json:"numberOfPhasesUsed,omitempty"`
+	CableMaxCurrent    int          `json:"cableMaxCurrent,omitempty"`
+	ReservationID      *int         `json:"reservationId,omitempty"`
+	TransactionInfo    Transaction  `json:"transactionInfo" ocpp:"required"`
+	IdToken            *IdToken     `json:"idToken,omitempty"`
+	Evse               *EVSE        `json:"evse,omitempty"`
+	MeterValue         []MeterValue `json:"meterValue,omitempty" ocpp:"min=1"`
+}
+
+// TransactionEventResponse payload.
+type TransactionEventResponse struct {
+	TotalCost   *float64     `json:"totalCost,omitempty"`
+	IdTokenInfo *IdTokenInfo `json:"idTokenInfo,omitempty"`
+}
+
+// MeterValuesRequest payload.
+type MeterValuesRequest struct {
+	EvseID     int          `json:"evseId" ocpp:"required"`
+	MeterValue []MeterValue `json:"meterValue" ocpp:"required,min=1"`
+}
+
+// MeterValuesResponse is empty (ack).
+type MeterValuesResponse struct{}
+
+// LogParameters tells where to upload log and which period it covers.
+type LogParameters struct {
+	RemoteLocation  string     `json:"remoteLocation"`
+	OldestTimestamp *time.Time `json:"oldestTimestamp,omitempty"`
+	LatestTimestamp *time.Time `json:"latestTimestamp,omitempty"`
+}
+
+// GetLogRequest asks station to upload log.
+type GetLogRequest struct {
+	Log           LogParameters `json:"log"`
+	LogType       string        `json:"logType"`
+	RequestID     int           `json:"requestId"`
+	Retries       *int          `json:"retries,omitempty"`
+	RetryInterval *int          `json:"retryInterval,omitempty"`
+}
+
+// GetLogResponse carries station decision and name of file to be uploaded.
+type GetLogResponse struct {
+	Status   string `json:"status"`
+	Filename string `json:"filename,omitempty"`
+}
+
+// LogStatusNotificationRequest reports log upload progress of requestId.
+type LogStatusNotificationRequest struct {
+	Status    string `json:"status" ocpp:"required,oneof=BadMessage Idle NotSupportedOperation PermissionDenied Uploaded UploadFailure Uploading AcceptedCanceled"`
+	RequestID *int   `json:"requestId,omitempty"`
+}
+
+// LogStatusNotificationResponse is empty (ack).
+type LogStatusNotificationResponse struct{}
+
+// RequestStartTransactionRequest asks station to start transaction; nil
+// EvseID lets station choose EVSE.
+type RequestStartTransactionRequest struct {
+	EvseID        *int    `json:"evseId,omitempty"`
+	IdToken       IdToken `json:"idToken"`
+	RemoteStartID int     `json:"remoteStartId"`
+}
+
+// RequestStartTransactionResponse carries station decision and ID of
+// transaction already running on the EVSE, if any.
+type RequestStartTransactionResponse struct {
+	Status        string `json:"status"`
+	TransactionID string `json:"transactionId,omitempty"`
+}
+
+// RequestStopTransactionRequest asks station to stop transaction.
+type RequestStopTransactionRequest struct {
+	TransactionID string `json:"transactionId"`
+}
+
+// RequestStopTransactionResponse carries station decision.
+type RequestStopTransactionResponse struct {
+	Status string `json:"status"`
+}
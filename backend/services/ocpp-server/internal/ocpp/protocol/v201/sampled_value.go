@@ -0,0 +1,46 @@
+package v201
+
+import "math"
+
+// EnergyImportWh returns total Energy.Active.Import.Register reading in Wh.
+// Per-phase and non-outlet samples are ignored; missing measurand and unit fall
+// back to the spec defaults (energy register in Wh).
+func (m MeterValue) EnergyImportWh() (float64, bool) {
+	for _, sv := range m.SampledValue {
+		measurand := sv.Measurand
+		if measurand == "" {
+			measurand = MeasurandEnergyActiveImportRegister
+		}
+		if measurand != MeasurandEnergyActiveImportRegister || sv.Phase != "" {
+			continue
+		}
+		if sv.Location != "" && sv.Location != LocationOutlet {
+			continue
+		}
+		value := sv.Value
+		unit := UnitWh
+		if sv.UnitOfMeasure != nil {
+			value *= math.Pow10(sv.UnitOfMeasure.Multiplier)
+			if sv.UnitOfMeasure.Unit != "" {
+				unit = sv.UnitOfMeasure.Unit
+			}
+		}
+		switch unit {
+		case UnitWh:
+			return value, true
+		case UnitKWh:
+			return value * 1000.0, true
+		}
+	}
+	return 0, false
+}
+
+// LastEnergyImportWh returns latest energy register reading among meter values.
+func LastEnergyImportWh(values []MeterValue) (float64, bool) {
+	for i := len(values) - 1; i >= 0; i-- {
+		if wh, ok := values[i].EnergyImportWh(); ok {
+			return wh, true
+		}
+	}
+	return 0, false
+}
@@ -3,22 +3,45 @@ package ocpp
 import (
 	"context"
 	"encoding/json"
-	"fmt"
+	"errors"
+	"time"
 
 	"go.uber.org/zap"
+
+	"drivepower/backend/services/ocpp-server/internal/models"
+	"drivepower/backend/services/ocpp-server/internal/ocpp/protocol"
 )
 
 // HandlerFunc processes message payload and returns response body.
 type HandlerFunc func(ctx context.Context, stationID string, payload json.RawMessage) (interface{}, error)
 
+// Spec describes OCPP version specifics needed to answer stations correctly.
+type Spec struct {
+	// Actions lists every station-initiated action defined by the version;
+	// unregistered ones are answered with NotSupported, unknown with NotImplemented.
+	Actions []string
+	// ErrorCodes renames CALLERROR codes that are spelled differently in the version.
+	ErrorCodes map[string]string
+}
+
 // Router dispatches OCPP actions to handlers.
 type Router struct {
 	handlers map[string]HandlerFunc
+	known    map[string]struct{}
+	codes    map[string]string
 }
 
-// NewRouter returns router.
-func NewRouter() *Router {
-	return &Router{handlers: make(map[string]HandlerFunc)}
+// NewRouter returns router for OCPP version described by spec.
+func NewRouter(spec Spec) *Router {
+	known := make(map[string]struct{}, len(spec.Actions))
+	for _, action := range spec.Actions {
+		known[action] = struct{}{}
+	}
+	return &Router{
+		handlers: make(map[string]HandlerFunc),
+		known:    known,
+		codes:    spec.ErrorCodes,
+	}
 }
 
 // Register attaches handler to action.
@@ -30,51 +53,111 @@ func (r *Router) Register(action string, handler HandlerFunc) {
 func (r *Router) Route(ctx context.Context, stationID string, msg *Message) (interface{}, error) {
 	handler, ok := r.handlers[msg.Action]
 	if !ok {
-		return nil, fmt.Errorf("ocpp: unsupported action %s", msg.Action)
+		if _, known := r.known[msg.Action]; known {
+			return nil, NewCallError(ErrorCodeNotSupported, "action %s is not supported", msg.Action)
+		}
+		return nil, NewCallError(ErrorCodeNotImplemented, "action %s is not known", msg.Action)
 	}
 	return handler(ctx, stationID, msg.Payload)
 }
 
+// ErrorCode returns CALLERROR code spelled for router's OCPP version.
+func (r *Router) ErrorCode(code string) string {
+	if renamed, ok := r.codes[code]; ok {
+		return renamed
+	}
+	return code
+}
+
+// Gate decides whether station may send action, e.g. before its registration is accepted.
+type Gate interface {
+	Allow(ctx context.Context, stationID, action string) bool
+}
+
+// Liveness records that station is alive; every inbound frame counts.
+type Liveness interface {
+	Touch(ctx context.Context, stationID string)
+}
+
 // Processor ties together parsing, routing, and response encoding.
 type Processor struct {
-	parser  *Parser
-	router  *Router
-	logger  *zap.Logger
-	logRepo OCPPLogRepository
+	parser   *Parser
+	router   *Router
+	caller   *Caller
+	gate     Gate
+	liveness Liveness
+	logger   *zap.Logger
+	log      MessageLog
 }
 
-// OCPPLogRepository minimal interface.
-type OCPPLogRepository interface {
-	Save(ctx context.Context, stationID, direction, messageType string, payload []byte) error
+// MessageLog records raw frames; Record must not block the read loop.
+type MessageLog interface {
+	Record(message models.OCPPMessage)
 }
 
 // NewProcessor builds Processor.
-func NewProcessor(parser *Parser, router *Router, logRepo OCPPLogRepository, logger *zap.Logger) *Processor {
+func NewProcessor(parser *Parser, router *Router, caller *Caller, gate Gate, liveness Liveness, log MessageLog, logger *zap.Logger) *Processor {
 	return &Processor{
-		parser:  parser,
-		router:  router,
-		logRepo: logRepo,
-		logger:  logger,
+		parser:   parser,
+		router:   router,
+		caller:   caller,
+		gate:     gate,
+		liveness: liveness,
+		log:      log,
+		logger:   logger,
 	}
 }
 
 // Process handles raw message and returns response frame bytes.
 func (p *Processor) Process(ctx context.Context, stationID string, raw []byte) ([]byte, error) {
+	if p.liveness != nil {
+		p.liveness.Touch(ctx, stationID)
+	}
 	msg, err := p.parser.Parse(raw)
 	if err != nil {
+		var callErr *CallError
+		if msg != nil && errors.As(err, &callErr) {
+			p.record(stationID, models.DirectionIncoming, msg.MessageType, msg.UniqueID, msg.Action, raw)
+			if p.logger != nil {
+				p.logger.Warn("malformed ocpp frame", zap.String("station_id", stationID), zap.Error(err))
+			}
+			frame, buildErr := BuildCallError(msg.UniqueID, p.router.ErrorCode(callErr.Code), callErr.Description)
+			if buildErr == nil {
+				p.record(stationID, models.DirectionOutgoing, protocol.MessageTypeCallError, msg.UniqueID, msg.Action, frame)
+			}
+			return frame, buildErr
+		}
+		p.record(stationID, models.DirectionIncoming, 0, "", "", raw)
 		return nil, err
 	}
 
-	if p.logRepo != nil {
-		_ = p.logRepo.Save(ctx, stationID, "incoming", msg.Action, raw)
+	if msg.MessageType != protocol.MessageTypeCall {
+		p.resolveCall(stationID, msg, raw)
+		return nil, nil
 	}
 
-	responsePayload, err := p.router.Route(ctx, stationID, msg)
+	p.record(stationID, models.DirectionIncoming, msg.MessageType, msg.UniqueID, msg.Action, raw)
+
+	var responsePayload interface{}
+	if p.gate != nil && !p.gate.Allow(ctx, stationID, msg.Action) {
+		err = NewCallError(ErrorCodeSecurityError, "station is not accepted, only BootNotification is allowed")
+	} else {
+		responsePayload, err = p.router.Route(ctx, stationID, msg)
+	}
 	if err != nil {
 		if p.logger != nil {
 			p.logger.Warn("ocpp handler failed", zap.String("action", msg.Action), zap.Error(err))
 		}
-		return BuildCallError(msg.UniqueID, "InternalError", err.Error())
+		code, description := ErrorCodeInternalError, err.Error()
+		var callErr *CallError
+		if errors.As(err, &callErr) {
+			code, description = callErr.Code, callErr.Description
+		}
+		frame, buildErr := BuildCallError(msg.UniqueID, p.router.ErrorCode(code), description)
+		if buildErr == nil {
+			p.record(stationID, models.DirectionOutgoing, protocol.MessageTypeCallError, msg.UniqueID, msg.Action, frame)
+		}
+		return frame, buildErr
 	}
 
 	if responsePayload == nil {
@@ -89,19 +172,53 @@ func (p *Processor) Process(ctx context.Context, stationID string, raw []byte) (
 		return nil, err
 	}
 
-	if p.logRepo != nil {
-		_ = p.logRepo.Save(ctx, stationID, "outgoing", msg.Action, respBytes)
-	}
+	p.record(stationID, models.DirectionOutgoing, protocol.MessageTypeCallResult, msg.UniqueID, msg.Action, respBytes)
 
 	return respBytes, nil
 }
 
-// Decode convenience helper for handlers.
-func Decode[T any](payload json.RawMessage) (T, error) {
-	var target T
-	if err := json.Unmarshal(payload, &target); err != nil {
-		var zero T
-		return zero, err
+// resolveCall passes station reply to the pending CSMS-initiated call.
+func (p *Processor) resolveCall(stationID string, msg *Message, raw []byte) {
+	var (
+		action  string
+		matched bool
+	)
+	if p.caller != nil {
+		action, matched = p.caller.Resolve(stationID, msg)
+	}
+	if !matched && p.logger != nil {
+		p.logger.Warn("ocpp reply without pending call",
+			zap.String("station_id", stationID),
+			zap.String("unique_id", msg.UniqueID),
+		)
+	}
+	p.record(stationID, models.DirectionIncoming, msg.MessageType, msg.UniqueID, action, raw)
+}
+
+func (p *Processor) record(stationID, direction string, messageType int, uniqueID, action string, frame []byte) {
+	if p.log != nil {
+		p.log.Record(logEntry(stationID, direction, messageType, uniqueID, action, frame))
+	}
+}
+
+// logEntry builds message log entry; frame type is empty for unparseable frames.
+func logEntry(stationID, direction string, messageType int, uniqueID, action string, frame []byte) models.OCPPMessage {
+	var frameType string
+	switch messageType {
+	case protocol.MessageTypeCall:
+		frameType = models.FrameCall
+	case protocol.MessageTypeCallResult:
+		frameType = models.FrameCallResult
+	case protocol.MessageTypeCallError:
+		frameType = models.FrameCallError
+	}
+	return models.OCPPMessage{
+		StationID: stationID,
+		Direction: direction,
+		FrameType: frameType,
+		UniqueID:  uniqueID,
+		Action:    action,
+		Payload:   frame,
+		CreatedAt: time.Now().UTC(),
 	}
-	return target, nil
 }
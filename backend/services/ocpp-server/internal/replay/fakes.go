@@ -0,0 +1,289 @@
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"drivepower/backend/services/ocpp-server/internal/models"
+	"drivepower/backend/services/ocpp-server/internal/repository"
+)
+
+// stationBackend is in-memory service.StationBackend.
+type stationBackend struct {
+	mu       sync.Mutex
+	stations map[string]models.Station
+}
+
+func newStationBackend() *stationBackend {
+	return &stationBackend{stations: make(map[string]models.Station)}
+}
+
+func (b *stationBackend) Upsert(_ context.Context, station *models.Station) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	stored, ok := b.stations[station.ID]
+	if !ok {
+		stored = models.Station{ID: station.ID, RegistrationStatus: station.RegistrationStatus, CreatedAt: time.Now().UTC()}
+		if stored.RegistrationStatus == "" {
+			stored.RegistrationStatus = models.RegistrationAccepted
+		}
+	}
+	stored.Vendor, stored.Model, stored.FirmwareVersion = station.Vendor, station.Model, station.FirmwareVersion
+	stored.Status, stored.LastHeartbeat = station.Status, station.LastHeartbeat
+	stored.OCPPVersion = station.OCPPVersion
+	stored.UpdatedAt = time.Now().UTC()
+	b.stations[station.ID] = stored
+	return nil
+}
+
+func (b *stationBackend) GetByID(_ context.Context, stationID string) (*models.Station, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	station, ok := b.stations[stationID]
+	if !ok {
+		return nil, repository.ErrStationNotFound
+	}
+	return &station, nil
+}
+
+func (b *stationBackend) List(_ context.Context, registrationStatus string) ([]models.Station, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var stations []models.Station
+	for _, station := range b.stations {
+		if registrationStatus == "" || station.RegistrationStatus == registrationStatus {
+			stations = append(stations, station)
+		}
+	}
+	return stations, nil
+}
+
+func (b *stationBackend) SetRegistration(_ context.Context, stationID, registrationStatus string, heartbeatInterval int) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	station, ok := b.stations[stationID]
+	if !ok {
+		return repository.ErrStationNotFound
+	}
+	station.RegistrationStatus = registrationStatus
+	if heartbeatInterval > 0 {
+		station.HeartbeatInterval = heartbeatInterval
+	}
+	b.stations[stationID] = station
+	return nil
+}
+
+func (b *stationBackend) TouchHeartbeat(_ context.Context, stationID string, at time.Time, restoreStatus string) (bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	station, ok := b.stations[stationID]
+	if !ok {
+		return false, repository.ErrStationNotFound
+	}
+	wasOffline := station.Status == models.StatusOffline
+	if wasOffline {
+		station.Status = restoreStatus
+	}
+	station.LastHeartbeat = at
+	b.stations[stationID] = station
+	return wasOffline, nil
+}
+
+// MarkStaleOffline never marks stations: replay runs faster than heartbeats.
+func (b *stationBackend) MarkStaleOffline(context.Context, int, int) ([]repository.StaleStation, error) {
+	return nil, nil
+}
+
+func (b *stationBackend) ListOffline(context.Context) ([]models.Station, error) {
+	return nil, nil
+}
+
+func (b *stationBackend) UpdateStatus(_ context.Context, stationID, status string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if station, ok := b.stations[stationID]; ok {
+		station.Status = status
+		b.stations[stationID] = station
+	}
+	return nil
+}
+
+// outboxBackend is service.OutboxBackend that only records enqueued events;
+// nothing is delivered during replay.
+type outboxBackend struct {
+	mu     sync.Mutex
+	events []models.OutboxEvent
+}
+
+func (b *outboxBackend) Enqueue(_ context.Context, events []models.OutboxEvent) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, event := range events {
+		event.ID = int64(len(b.events) + 1)
+		event.Status = models.OutboxPending
+		event.CreatedAt = time.Now().UTC()
+		b.events = append(b.events, event)
+	}
+	return nil
+}
+
+// since returns events enqueued after the first n.
+func (b *outboxBackend) since(n int) (int, []models.OutboxEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.events), append([]models.OutboxEvent(nil), b.events[n:]...)
+}
+
+func (b *outboxBackend) ClaimDue(context.Context, int, time.Duration) ([]models.OutboxEvent, error) {
+	return nil, nil
+}
+
+func (b *outboxBackend) MarkDelivered(context.Context, int64) error { return nil }
+
+func (b *outboxBackend) MarkFailed(context.Context, int64, string, time.Time, string) error {
+	return nil
+}
+
+func (b *outboxBackend) AssignSession(context.Context, string, int64) error { return nil }
+
+func (b *outboxBackend) ListByStatus(context.Context, string, int) ([]models.OutboxEvent, error) {
+	return nil, nil
+}
+
+func (b *outboxBackend) Replay(context.Context, int64) error {
+	return repository.ErrOutboxEventNotFound
+}
+
+func (b *outboxBackend) ReplayAllDead(context.Context) (int64, error) { return 0, nil }
+
+func (b *outboxBackend) DeleteDelivered(context.Context, time.Time) (int64, error) { return 0, nil }
+
+// transactionBackend is in-memory service.TransactionBackend.
+type transactionBackend struct {
+	mu           sync.Mutex
+	transactions map[string]models.Transaction
+}
+
+func newTransactionBackend() *transactionBackend {
+	return &transactionBackend{transactions: make(map[string]models.Transaction)}
+}
+
+func (b *transactionBackend) Save(_ context.Context, tx *models.Transaction) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	stored := *tx
+	stored.UpdatedAt = time.Now().UTC()
+	if existing, ok := b.transactions[tx.ID]; ok {
+		stored.StartedAt = existing.StartedAt
+	} else {
+		stored.StartedAt = stored.UpdatedAt
+	}
+	b.transactions[tx.ID] = stored
+	return nil
+}
+
+func (b *transactionBackend) Touch(context.Context, string, int64) error { return nil }
+
+func (b *transactionBackend) Delete(_ context.Context, transactionID string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.transactions, transactionID)
+	return nil
+}
+
+func (b *transactionBackend) ExpireIdle(context.Context, time.Time) ([]models.Transaction, error) {
+	return nil, nil
+}
+
+func (b *transactionBackend) Unexpire(context.Context, string) error { return nil }
+
+func (b *transactionBackend) DeleteExpired(context.Context, string) error { return nil }
+
+func (b *transactionBackend) IsExpired(context.Context, string) (bool, error) { return false, nil }
+
+func (b *transactionBackend) ListExpired(context.Context) ([]string, error) { return nil, nil }
+
+func (b *transactionBackend) AssignSession(_ context.Context, transactionID string, sessionID int64) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if tx, ok := b.transactions[transactionID]; ok && tx.SessionID == 0 {
+		tx.SessionID = sessionID
+		b.transactions[transactionID] = tx
+	}
+	return nil
+}
+
+func (b *transactionBackend) Get(_ context.Context, transactionID string) (*models.Transaction, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	tx, ok := b.transactions[transactionID]
+	if !ok {
+		return nil, repository.ErrTransactionNotFound
+	}
+	return &tx, nil
+}
+
+func (b *transactionBackend) List(_ context.Context) ([]models.Transaction, error) {
+	return b.ListByStation(context.Background(), "")
+}
+
+func (b *transactionBackend) ListByStation(_ context.Context, stationID string) ([]models.Transaction, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var transactions []models.Transaction
+	for _, tx := range b.transactions {
+		if stationID == "" || tx.StationID == stationID {
+			transactions = append(transactions, tx)
+		}
+	}
+	sort.Slice(transactions, func(i, j int) bool { return transactions[i].StartedAt.Before(transactions[j].StartedAt) })
+	return transactions, nil
+}
+
+// transactionIDs hands out IDs recorded in StartTransaction answers so that
+// later frames of the conversation refer to transactions known to the replay.
+type transactionIDs struct {
+	mu       sync.Mutex
+	expected []int
+	last     int
+}
+
+func (t *transactionIDs) expect(id int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.expected = append(t.expected, id)
+}
+
+func (t *transactionIDs) Next(context.Context) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.expected) > 0 {
+		t.last, t.expected = t.expected[0], t.expected[1:]
+		return t.last, nil
+	}
+	t.last++
+	return t.last, nil
+}
+
+// newServicesStub serves sessions-service start calls with increasing session
+// IDs and accepts any other call, so clients behave as in production.
+func newServicesStub() *httptest.Server {
+	var sessions atomic.Int64
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/internal/ocpp/session-start":
+			_ = json.NewEncoder(w).Encode(map[string]int64{"session_id": sessions.Add(1)})
+		case "/internal/ocpp/active-sessions":
+			_, _ = w.Write([]byte("[]"))
+		default:
+			_, _ = w.Write([]byte("{}"))
+		}
+	}))
+}
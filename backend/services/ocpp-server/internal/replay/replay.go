@@ -0,0 +1,243 @@
+// Package replay feeds a recorded station conversation from the OCPP message
+// log back through the message processor wired to in-memory fakes, to
+// reproduce behaviour reported by field stations without a database or
+// downstream services.
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+
+	"drivepower/backend/libs/events"
+	"drivepower/backend/services/ocpp-server/internal/app"
+	"drivepower/backend/services/ocpp-server/internal/clients"
+	"drivepower/backend/services/ocpp-server/internal/models"
+	"drivepower/backend/services/ocpp-server/internal/ocpp"
+	"drivepower/backend/services/ocpp-server/internal/ocpp/protocol"
+	"drivepower/backend/services/ocpp-server/internal/ocpp/protocol/v201"
+	"drivepower/backend/services/ocpp-server/internal/service"
+)
+
+// volatileFields differ between recording and replay by design and are not compared.
+var volatileFields = map[string]bool{"currentTime": true}
+
+// Step is incoming call of conversation with answer recorded in the log and
+// answer produced by replay.
+type Step struct {
+	Request  models.OCPPMessage
+	Recorded *models.OCPPMessage
+	Replayed json.RawMessage
+	Err      error
+	// Notifications are outbox events enqueued while processing the call.
+	Notifications []models.OutboxEvent
+}
+
+// Match reports whether replayed answer equals recorded one, ignoring volatile fields.
+func (s Step) Match() bool {
+	if s.Recorded == nil {
+		return s.Replayed == nil
+	}
+	if s.Replayed == nil {
+		return false
+	}
+	recorded, err := normalize(s.Recorded.Payload)
+	if err != nil {
+		return false
+	}
+	replayed, err := normalize(s.Replayed)
+	if err != nil {
+		return false
+	}
+	return reflect.DeepEqual(recorded, replayed)
+}
+
+// Replayer runs conversations through processor of one OCPP version.
+type Replayer struct {
+	processor *ocpp.Processor
+	stations  *stationBackend
+	outbox    *outboxBackend
+	txIDs     *transactionIDs
+	stub      *httptest.Server
+}
+
+// New wires processor of subprotocol (ocpp1.6 or ocpp2.0.1) to fakes. Replayed
+// stations are registered as accepted; id tags are accepted without owner.
+func New(subprotocol string, logger *zap.Logger) (*Replayer, error) {
+	if subprotocol != protocol.Subprotocol && subprotocol != v201.Subprotocol {
+		return nil, fmt.Errorf("replay: unsupported subprotocol %q", subprotocol)
+	}
+	r := &Replayer{
+		stations: newStationBackend(),
+		outbox:   &outboxBackend{},
+		txIDs:    &transactionIDs{},
+		stub:     newServicesStub(),
+	}
+
+	heartbeat := 300 * time.Second
+	state := service.NewStationState()
+	txStore := service.NewTransactionStore(newTransactionBackend(), 0, false, logger)
+	sessions := clients.NewSessionsClient(r.stub.URL, logger)
+	outbox := service.NewOutbox(r.outbox, sessions,
+		clients.NewBillingClient(r.stub.URL, logger),
+		clients.NewTelemetryClient(r.stub.URL, logger),
+		events.NewMemoryBus(), txStore, 0, 0, logger)
+	registry := service.NewStationRegistry(r.stations, service.PolicyAccept, heartbeat, heartbeat, logger)
+	liveness := service.NewLivenessMonitor(r.stations, state, heartbeat, 0, 0, logger)
+
+	ocpp16, ocpp201 := app.NewOCPPRouters(app.OCPPDeps{
+		Registry:   registry,
+		Stations:   r.stations,
+		State:      state,
+		Liveness:   liveness,
+		Authorizer: service.NewAuthorizer(clients.NewAuthClient("", logger), txStore, logger),
+		Sessions:   sessions,
+		Outbox:     outbox,
+		TxIDs:      r.txIDs,
+		TxStore:    txStore,
+		Logger:     logger,
+	})
+	router := ocpp16
+	if subprotocol == v201.Subprotocol {
+		router = ocpp201
+	}
+	r.processor = ocpp.NewProcessor(ocpp.NewParser(), router, nil, registry, liveness, nil, logger)
+	return r, nil
+}
+
+// Close stops stub of downstream services.
+func (r *Replayer) Close() {
+	r.stub.Close()
+}
+
+// Run processes incoming calls of conversation in recorded order. Frames sent
+// by the server and station replies to server calls are used only to find
+// recorded answers.
+func (r *Replayer) Run(ctx context.Context, conversation []models.OCPPMessage) []Step {
+	frames := append([]models.OCPPMessage(nil), conversation...)
+	sort.SliceStable(frames, func(i, j int) bool {
+		if frames[i].CreatedAt.Equal(frames[j].CreatedAt) {
+			return frames[i].ID < frames[j].ID
+		}
+		return frames[i].CreatedAt.Before(frames[j].CreatedAt)
+	})
+
+	var (
+		steps    []Step
+		enqueued int
+	)
+	for i, frame := range frames {
+		if frame.Direction != models.DirectionIncoming || frame.FrameType != models.FrameCall {
+			continue
+		}
+		r.register(ctx, frame.StationID)
+		step := Step{Request: frame, Recorded: answer(frames[i+1:], frame)}
+		r.expectTransactionID(step)
+
+		step.Replayed, step.Err = r.processor.Process(ctx, frame.StationID, frame.Payload)
+		enqueued, step.Notifications = r.outbox.since(enqueued)
+		steps = append(steps, step)
+	}
+	return steps
+}
+
+// register makes station known and accepted, as it was in the field.
+func (r *Replayer) register(ctx context.Context, stationID string) {
+	if _, err := r.stations.GetByID(ctx, stationID); err == nil {
+		return
+	}
+	_ = r.stations.Upsert(ctx, &models.Station{ID: stationID, RegistrationStatus: models.RegistrationAccepted, LastHeartbeat: time.Now().UTC()})
+}
+
+// expectTransactionID makes replayed StartTransaction assign recorded ID.
+func (r *Replayer) expectTransactionID(step Step) {
+	if step.Request.Action != protocol.ActionStartTransaction || step.Recorded == nil {
+		return
+	}
+	var frame []json.RawMessage
+	if err := json.Unmarshal(step.Recorded.Payload, &frame); err != nil || len(frame) < 3 {
+		return
+	}
+	var conf protocol.StartTransactionResponse
+	if err := json.Unmarshal(frame[2], &conf); err == nil && conf.TransactionID > 0 {
+		r.txIDs.expect(conf.TransactionID)
+	}
+}
+
+// Report writes human readable result of steps and returns number of mismatches.
+func Report(w io.Writer, steps []Step) int {
+	mismatches := 0
+	for i, step := range steps {
+		verdict := "MATCH"
+		if !step.Match() {
+			verdict = "DIFF"
+			mismatches++
+		}
+		fmt.Fprintf(w, "#%d %s %s %s [%s] %s\n", i+1, step.Request.CreatedAt.Format(time.RFC3339), step.Request.StationID, step.Request.Action, step.Request.UniqueID, verdict)
+		fmt.Fprintf(w, "  request:  %s\n", step.Request.Payload)
+		if step.Recorded != nil {
+			fmt.Fprintf(w, "  recorded: %s\n", step.Recorded.Payload)
+		} else {
+			fmt.Fprintf(w, "  recorded: <none>\n")
+		}
+		switch {
+		case step.Err != nil:
+			fmt.Fprintf(w, "  replayed: <error: %v>\n", step.Err)
+		case step.Replayed == nil:
+			fmt.Fprintf(w, "  replayed: <none>\n")
+		default:
+			fmt.Fprintf(w, "  replayed: %s\n", step.Replayed)
+		}
+		for _, event := range step.Notifications {
+			fmt.Fprintf(w, "  notify:   %s %s\n", event.EventType, event.Payload)
+		}
+	}
+	fmt.Fprintf(w, "%d calls replayed, %d differ\n", len(steps), mismatches)
+	return mismatches
+}
+
+// answer finds recorded reply to call among frames that follow it.
+func answer(frames []models.OCPPMessage, call models.OCPPMessage) *models.OCPPMessage {
+	for _, frame := range frames {
+		if frame.StationID == call.StationID && frame.UniqueID == call.UniqueID &&
+			frame.Direction == models.DirectionOutgoing && frame.FrameType != models.FrameCall {
+			reply := frame
+			return &reply
+		}
+	}
+	return nil
+}
+
+// normalize decodes frame and strips volatile fields.
+func normalize(frame []byte) (interface{}, error) {
+	var v interface{}
+	if err := json.Unmarshal(frame, &v); err != nil {
+		return nil, err
+	}
+	return strip(v), nil
+}
+
+func strip(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for key, value := range t {
+			if volatileFields[key] {
+				delete(t, key)
+				continue
+			}
+			t[key] = strip(value)
+		}
+	case []interface{}:
+		for i, value := range t {
+			t[i] = strip(value)
+		}
+	}
+	return v
+}
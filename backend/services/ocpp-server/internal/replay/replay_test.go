@@ -0,0 +1,156 @@
+package replay
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"drivepower/backend/services/ocpp-server/internal/models"
+	"drivepower/backend/services/ocpp-server/internal/ocpp/protocol"
+	"drivepower/backend/services/ocpp-server/internal/service"
+)
+
+// recorder builds message log of one station as the server would store it.
+type recorder struct {
+	stationID string
+	at        time.Time
+	frames    []models.OCPPMessage
+}
+
+func (r *recorder) add(direction, frameType, uniqueID, action, payload string) {
+	r.at = r.at.Add(time.Second)
+	r.frames = append(r.frames, models.OCPPMessage{
+		ID:        int64(len(r.frames) + 1),
+		StationID: r.stationID,
+		Direction: direction,
+		FrameType: frameType,
+		UniqueID:  uniqueID,
+		Action:    action,
+		Payload:   json.RawMessage(payload),
+		CreatedAt: r.at,
+	})
+}
+
+// exchange records incoming call and the answer sent to it.
+func (r *recorder) exchange(uniqueID, action, request, result string) {
+	r.add(models.DirectionIncoming, models.FrameCall, uniqueID, action, `[2,"`+uniqueID+`","`+action+`",`+request+`]`)
+	r.add(models.DirectionOutgoing, models.FrameCallResult, uniqueID, action, `[3,"`+uniqueID+`",`+result+`]`)
+}
+
+func TestRunReplaysConversation(t *testing.T) {
+	rec := &recorder{stationID: "CP-1", at: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)}
+	rec.exchange("1", protocol.ActionBootNotification,
+		`{"chargePointVendor":"Acme","chargePointModel":"AC22","firmwareVersion":"1.2.0"}`,
+		`{"currentTime":"2024-05-01T10:00:01Z","interval":300,"status":"Accepted"}`)
+	rec.exchange("2", protocol.ActionStartTransaction,
+		`{"connectorId":1,"idTag":"TAG-1","meterStart":1000,"timestamp":"2024-05-01T10:01:00Z"}`,
+		`{"idTagInfo":{"status":"Accepted"},"transactionId":4711}`)
+	// Server call in the middle of the conversation is not replayed.
+	rec.add(models.DirectionOutgoing, models.FrameCall, "srv-1", protocol.ActionReset, `[2,"srv-1","Reset",{"type":"Soft"}]`)
+	rec.add(models.DirectionIncoming, models.FrameCallResult, "srv-1", protocol.ActionReset, `[3,"srv-1",{"status":"Accepted"}]`)
+	rec.exchange("3", protocol.ActionStopTransaction,
+		`{"transactionId":4711,"meterStop":3500,"timestamp":"2024-05-01T11:00:00Z","reason":"Local"}`,
+		`{}`)
+
+	r, err := New(protocol.Subprotocol, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	steps := r.Run(context.Background(), rec.frames)
+	var report bytes.Buffer
+	if mismatches := Report(&report, steps); mismatches != 0 || len(steps) != 3 {
+		t.Fatalf("%d steps, %d mismatches:\n%s", len(steps), mismatches, report.String())
+	}
+
+	station, err := r.stations.GetByID(context.Background(), "CP-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if station.Vendor != "Acme" || station.Model != "AC22" || station.FirmwareVersion != "1.2.0" {
+		t.Fatalf("station = %+v, want boot data stored", station)
+	}
+
+	wantEvents := [][]string{
+		{service.EventDomain},
+		{service.EventDomain},
+		{service.EventSessionStop, service.EventDomain, service.EventBillingStopped},
+	}
+	for i, step := range steps {
+		var got []string
+		for _, event := range step.Notifications {
+			got = append(got, event.EventType)
+		}
+		if !equalStrings(got, wantEvents[i]) {
+			t.Fatalf("step %d (%s) notifications = %v, want %v", i+1, step.Request.Action, got, wantEvents[i])
+		}
+	}
+	var stop struct {
+		TransactionID string  `json:"transaction_id"`
+		EnergyKWh     float64 `json:"energy_kwh"`
+	}
+	if err := json.Unmarshal(steps[2].Notifications[0].Payload, &stop); err != nil {
+		t.Fatal(err)
+	}
+	if stop.TransactionID != "4711" || stop.EnergyKWh != 2.5 {
+		t.Fatalf("session stop = %+v, want transaction 4711 with 2.5 kWh", stop)
+	}
+}
+
+func TestRunReportsDifferences(t *testing.T) {
+	rec := &recorder{stationID: "CP-1", at: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)}
+	// Recorded answer came from a server that rejected the station.
+	rec.exchange("1", protocol.ActionBootNotification,
+		`{"chargePointVendor":"Acme","chargePointModel":"AC22"}`,
+		`{"currentTime":"2024-05-01T10:00:01Z","interval":60,"status":"Rejected"}`)
+	// Answer was never recorded.
+	rec.add(models.DirectionIncoming, models.FrameCall, "2", protocol.ActionHeartbeat, `[2,"2","Heartbeat",{}]`)
+	rec.add(models.DirectionIncoming, models.FrameCall, "3", protocol.ActionAuthorize, `[2,"3","Authorize",{}]`)
+	rec.add(models.DirectionOutgoing, models.FrameCallError, "3", protocol.ActionAuthorize, `[4,"3","OccurenceConstraintViolation","idTag is required",{}]`)
+
+	r, err := New(protocol.Subprotocol, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	steps := r.Run(context.Background(), rec.frames)
+	if len(steps) != 3 {
+		t.Fatalf("%d steps, want 3", len(steps))
+	}
+	if steps[0].Match() {
+		t.Fatalf("boot replayed as %s, want difference to recorded rejection", steps[0].Replayed)
+	}
+	if steps[1].Recorded != nil || steps[1].Match() {
+		t.Fatalf("heartbeat without recorded answer reported as match")
+	}
+	if !steps[2].Match() {
+		t.Fatalf("authorize replayed as %s, want recorded CALLERROR", steps[2].Replayed)
+	}
+	if mismatches := Report(&bytes.Buffer{}, steps); mismatches != 2 {
+		t.Fatalf("mismatches = %d, want 2", mismatches)
+	}
+}
+
+func TestNewRejectsUnknownSubprotocol(t *testing.T) {
+	if _, err := New("ocpp1.5", zap.NewNop()); err == nil {
+		t.Fatal("expected error for ocpp1.5")
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
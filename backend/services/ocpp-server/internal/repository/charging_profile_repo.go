@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"drivepower/backend/services/ocpp-server/internal/models"
+)
+
+var (
+	// ErrChargingProfileNotFound is returned when profile does not exist.
+	ErrChargingProfileNotFound = errors.New("charging profile not found")
+	// ErrChargingProfileConflict is returned when another profile has the same
+	// connector, purpose and stack level.
+	ErrChargingProfileConflict = errors.New("charging profile conflicts with existing one")
+)
+
+const uniqueViolation = "23505"
+
+const chargingProfileColumns = `id, station_id, connector_id, profile, status, last_error, created_at, updated_at`
+
+// ChargingProfileRepository stores smart charging profiles.
+type ChargingProfileRepository struct {
+	db *sql.DB
+}
+
+// NewChargingProfileRepository returns repository.
+func NewChargingProfileRepository(db *sql.DB) *ChargingProfileRepository {
+	return &ChargingProfileRepository{db: db}
+}
+
+// Create stores profile as pending and fills ID, which is also written to
+// profile as chargingProfileId.
+func (r *ChargingProfileRepository) Create(ctx context.Context, profile *models.ChargingProfile) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	const insert = `
+		INSERT INTO ocpp_charging_profiles (station_id, connector_id, purpose, stack_level, transaction_id, profile, status)
+		VALUES ($1, $2, $3, $4, $5, '{}', $6)
+		RETURNING id, created_at, updated_at
+	`
+	p := profile.Profile
+	err = tx.QueryRowContext(ctx, insert, profile.StationID, profile.ConnectorID, p.ChargingProfilePurpose, p.StackLevel, p.TransactionID, models.ProfilePending).
+		Scan(&profile.ID, &profile.CreatedAt, &profile.UpdatedAt)
+	if err != nil {
+		return conflict(err)
+	}
+	profile.Status = models.ProfilePending
+	profile.Profile.ChargingProfileID = int(profile.ID)
+
+	payload, err := json.Marshal(profile.Profile)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE ocpp_charging_profiles SET profile = $2 WHERE id = $1`, profile.ID, payload); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Update replaces connector and profile definition and marks it pending.
+func (r *ChargingProfileRepository) Update(ctx context.Context, profile *models.ChargingProfile) error {
+	profile.Profile.ChargingProfileID = int(profile.ID)
+	payload, err := json.Marshal(profile.Profile)
+	if err != nil {
+		return err
+	}
+	const query = `
+		UPDATE ocpp_charging_profiles
+		SET connector_id = $2, purpose = $3, stack_level = $4, transaction_id = $5, profile = $6,
+		    status = $7, last_error = '', updated_at = NOW()
+		WHERE id = $1
+		RETURNING station_id, created_at, updated_at
+	`
+	p := profile.Profile
+	err = r.db.QueryRowContext(ctx, query, profile.ID, profile.ConnectorID, p.ChargingProfilePurpose, p.StackLevel, p.TransactionID, payload, models.ProfilePending).
+		Scan(&profile.StationID, &profile.CreatedAt, &profile.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrChargingProfileNotFound
+	}
+	if err != nil {
+		return conflict(err)
+	}
+	profile.Status, profile.LastError = models.ProfilePending, ""
+	return nil
+}
+
+// SetStatus records outcome of sending profile to station.
+func (r *ChargingProfileRepository) SetStatus(ctx context.Context, id int64, status, lastError string) error {
+	const query = `
+		UPDATE ocpp_charging_profiles
+		SET status = $2, last_error = $3, updated_at = NOW()
+		WHERE id = $1
+	`
+	_, err := r.db.ExecContext(ctx, query, id, status, lastError)
+	return err
+}
+
+// Get returns profile by ID.
+func (r *ChargingProfileRepository) Get(ctx context.Context, id int64) (*models.ChargingProfile, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+chargingProfileColumns+` FROM ocpp_charging_profiles WHERE id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	profiles, err := scanChargingProfiles(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(profiles) == 0 {
+		return nil, ErrChargingProfileNotFound
+	}
+	return &profiles[0], nil
+}
+
+// List returns profiles of station (all stations when empty), optionally only in statuses.
+func (r *ChargingProfileRepository) List(ctx context.Context, stationID string, statuses ...string) ([]models.ChargingProfile, error) {
+	const query = `
+		SELECT ` + chargingProfileColumns + `
+		FROM ocpp_charging_profiles
+		WHERE ($1 = '' OR station_id = $1)
+		  AND (cardinality($2::TEXT[]) = 0 OR status = ANY($2::TEXT[]))
+		ORDER BY station_id, connector_id, id
+	`
+	if statuses == nil {
+		statuses = []string{}
+	}
+	rows, err := r.db.QueryContext(ctx, query, stationID, statuses)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanChargingProfiles(rows)
+}
+
+// Delete removes profile.
+func (r *ChargingProfileRepository) Delete(ctx context.Context, id int64) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM ocpp_charging_profiles WHERE id = $1`, id)
+	return err
+}
+
+func scanChargingProfiles(rows *sql.Rows) ([]models.ChargingProfile, error) {
+	var profiles []models.ChargingProfile
+	for rows.Next() {
+		var (
+			profile models.ChargingProfile
+			payload []byte
+		)
+		if err := rows.Scan(
+			&profile.ID,
+			&profile.StationID,
+			&profile.ConnectorID,
+			&payload,
+			&profile.Status,
+			&profile.LastError,
+			&profile.CreatedAt,
+			&profile.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(payload, &profile.Profile); err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, profile)
+	}
+	return profiles, rows.Err()
+}
+
+// conflict maps unique violation to ErrChargingProfileConflict.
+func conflict(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+		return ErrChargingProfileConflict
+	}
+	return err
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"drivepower/backend/services/ocpp-server/internal/models"
+)
+
+// CommandAuditRepository stores commands sent to stations.
+type CommandAuditRepository struct {
+	db *sql.DB
+}
+
+// NewCommandAuditRepository returns repository.
+func NewCommandAuditRepository(db *sql.DB) *CommandAuditRepository {
+	return &CommandAuditRepository{db: db}
+}
+
+// Insert stores finished command and fills its ID.
+func (r *CommandAuditRepository) Insert(ctx context.Context, entry *models.CommandAudit) error {
+	const query = `
+		INSERT INTO ocpp_command_audit (station_id, action, request, response, status, error, requested_by, created_at, completed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id
+	`
+	var (
+		response    []byte
+		requestedBy sql.NullInt64
+	)
+	if len(entry.Response) > 0 {
+		response = entry.Response
+	}
+	if entry.RequestedBy != 0 {
+		requestedBy = sql.NullInt64{Int64: entry.RequestedBy, Valid: true}
+	}
+	return r.db.QueryRowContext(ctx, query,
+		entry.StationID, entry.Action, []byte(entry.Request), response, entry.Status, entry.Error, requestedBy, entry.CreatedAt, entry.CompletedAt,
+	).Scan(&entry.ID)
+}
+
+// List returns commands, newest first; empty stationID lists all stations.
+func (r *CommandAuditRepository) List(ctx context.Context, stationID string, limit int) ([]models.CommandAudit, error) {
+	const query = `
+		SELECT id, station_id, action, request, response, status, error, requested_by, created_at, completed_at
+		FROM ocpp_command_audit
+		WHERE $1 = '' OR station_id = $1
+		ORDER BY id DESC
+		LIMIT $2
+	`
+	rows, err := r.db.QueryContext(ctx, query, stationID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []models.CommandAudit
+	for rows.Next() {
+		var (
+			entry       models.CommandAudit
+			request     []byte
+			response    []byte
+			requestedBy sql.NullInt64
+		)
+		if err := rows.Scan(
+			&entry.ID,
+			&entry.StationID,
+			&entry.Action,
+			&request,
+			&response,
+			&entry.Status,
+			&entry.Error,
+			&requestedBy,
+			&entry.CreatedAt,
+			&entry.CompletedAt,
+		); err != nil {
+			return nil, err
+		}
+		entry.Request = request
+		entry.Response = response
+		entry.RequestedBy = requestedBy.Int64
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
@@ -0,0 +1,203 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"drivepower/backend/services/ocpp-server/internal/models"
+)
+
+// ConfigRepository stores desired station configuration and sync results.
+type ConfigRepository struct {
+	db *sql.DB
+}
+
+// NewConfigRepository returns repository.
+func NewConfigRepository(db *sql.DB) *ConfigRepository {
+	return &ConfigRepository{db: db}
+}
+
+// ReplaceDesired replaces desired keys of station or group.
+func (r *ConfigRepository) ReplaceDesired(ctx context.Context, scope, target string, keys map[string]string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM ocpp_config_desired WHERE scope = $1 AND target = $2`, scope, target); err != nil {
+		return err
+	}
+	const query = `
+		INSERT INTO ocpp_config_desired (scope, target, key, value)
+		VALUES ($1, $2, $3, $4)
+	`
+	for key, value := range keys {
+		if _, err := tx.ExecContext(ctx, query, scope, target, key, value); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Desired returns desired keys stored for station or group.
+func (r *ConfigRepository) Desired(ctx context.Context, scope, target string) (map[string]string, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT key, value FROM ocpp_config_desired WHERE scope = $1 AND target = $2`, scope, target)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanKeyValues(rows)
+}
+
+// Resolve returns effective desired keys of station: keys of its group
+// overridden by keys of the station itself.
+func (r *ConfigRepository) Resolve(ctx context.Context, stationID string) (map[string]string, error) {
+	const query = `
+		SELECT DISTINCT ON (d.key) d.key, d.value
+		FROM ocpp_config_desired d
+		WHERE (d.scope = 'station' AND d.target = $1)
+		   OR (d.scope = 'group' AND d.target = (SELECT group_name FROM ocpp_config_groups WHERE station_id = $1))
+		ORDER BY d.key, d.scope = 'station' DESC
+	`
+	rows, err := r.db.QueryContext(ctx, query, stationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanKeyValues(rows)
+}
+
+// SetGroup puts station into group; empty group removes station from its group.
+func (r *ConfigRepository) SetGroup(ctx context.Context, stationID, group string) error {
+	if group == "" {
+		_, err := r.db.ExecContext(ctx, `DELETE FROM ocpp_config_groups WHERE station_id = $1`, stationID)
+		return err
+	}
+	const query = `
+		INSERT INTO ocpp_config_groups (station_id, group_name)
+		VALUES ($1, $2)
+		ON CONFLICT (station_id) DO UPDATE SET group_name = EXCLUDED.group_name, updated_at = NOW()
+	`
+	_, err := r.db.ExecContext(ctx, query, stationID, group)
+	return err
+}
+
+// Group returns group of station, empty when station has none.
+func (r *ConfigRepository) Group(ctx context.Context, stationID string) (string, error) {
+	var group string
+	err := r.db.QueryRowContext(ctx, `SELECT group_name FROM ocpp_config_groups WHERE station_id = $1`, stationID).Scan(&group)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return group, err
+}
+
+// GroupMembers returns stations of group.
+func (r *ConfigRepository) GroupMembers(ctx context.Context, group string) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT station_id FROM ocpp_config_groups WHERE group_name = $1 ORDER BY station_id`, group)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanStrings(rows)
+}
+
+// ConfiguredStations returns stations that have desired keys directly or via
+// group, or results of an earlier sync.
+func (r *ConfigRepository) ConfiguredStations(ctx context.Context) ([]string, error) {
+	const query = `
+		SELECT target FROM ocpp_config_desired WHERE scope = 'station'
+		UNION
+		SELECT g.station_id FROM ocpp_config_groups g
+		WHERE EXISTS (SELECT 1 FROM ocpp_config_desired d WHERE d.scope = 'group' AND d.target = g.group_name)
+		UNION
+		SELECT station_id FROM ocpp_config_reported
+		ORDER BY 1
+	`
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanStrings(rows)
+}
+
+// SaveStates replaces sync results of station.
+func (r *ConfigRepository) SaveStates(ctx context.Context, stationID string, states []models.ConfigKeyState) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM ocpp_config_reported WHERE station_id = $1`, stationID); err != nil {
+		return err
+	}
+	const query = `
+		INSERT INTO ocpp_config_reported (station_id, key, desired_value, reported_value, readonly, sync_status, error, synced_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	for _, state := range states {
+		if _, err := tx.ExecContext(ctx, query, stationID, state.Key, state.Desired, state.Reported, state.Readonly, state.Status, state.Error, state.SyncedAt); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// States returns sync results of station ordered by key.
+func (r *ConfigRepository) States(ctx context.Context, stationID string) ([]models.ConfigKeyState, error) {
+	const query = `
+		SELECT station_id, key, desired_value, reported_value, readonly, sync_status, error, synced_at
+		FROM ocpp_config_reported
+		WHERE station_id = $1
+		ORDER BY key
+	`
+	rows, err := r.db.QueryContext(ctx, query, stationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var states []models.ConfigKeyState
+	for rows.Next() {
+		var (
+			state    models.ConfigKeyState
+			reported sql.NullString
+		)
+		if err := rows.Scan(&state.StationID, &state.Key, &state.Desired, &reported, &state.Readonly, &state.Status, &state.Error, &state.SyncedAt); err != nil {
+			return nil, err
+		}
+		if reported.Valid {
+			state.Reported = &reported.String
+		}
+		states = append(states, state)
+	}
+	return states, rows.Err()
+}
+
+func scanKeyValues(rows *sql.Rows) (map[string]string, error) {
+	keys := make(map[string]string)
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, err
+		}
+		keys[key] = value
+	}
+	return keys, rows.Err()
+}
+
+func scanStrings(rows *sql.Rows) ([]string, error) {
+	var values []string
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, rows.Err()
+}
@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"drivepower/backend/services/ocpp-server/internal/models"
+)
+
+var (
+	// ErrDiagnosticsNotFound is returned when diagnostics request does not exist.
+	ErrDiagnosticsNotFound = errors.New("diagnostics not found")
+	// ErrDiagnosticsUploaded is returned when bundle of the request was already uploaded.
+	ErrDiagnosticsUploaded = errors.New("diagnostics already uploaded")
+)
+
+const diagnosticsColumns = `id, station_id, kind, log_type, token, status, status_detail, file_name, size, sha256,
+	requested_by, created_at, updated_at, uploaded_at`
+
+// DiagnosticsRepository stores log requests and uploaded bundles.
+type DiagnosticsRepository struct {
+	db *sql.DB
+}
+
+// NewDiagnosticsRepository returns repository.
+func NewDiagnosticsRepository(db *sql.DB) *DiagnosticsRepository {
+	return &DiagnosticsRepository{db: db}
+}
+
+// Create stores request and fills ID.
+func (r *DiagnosticsRepository) Create(ctx context.Context, d *models.Diagnostics) error {
+	const query = `
+		INSERT INTO ocpp_diagnostics (station_id, kind, log_type, token, status, requested_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, updated_at
+	`
+	return r.db.QueryRowContext(ctx, query, d.StationID, d.Kind, d.LogType, d.Token, d.Status, d.RequestedBy).
+		Scan(&d.ID, &d.CreatedAt, &d.UpdatedAt)
+}
+
+// SetStatus records station answer to the request. Request the station
+// already reported progress for keeps its status.
+func (r *DiagnosticsRepository) SetStatus(ctx context.Context, id int64, status, detail, fileName string) error {
+	const query = `
+		UPDATE ocpp_diagnostics
+		SET status = CASE WHEN status = $5 THEN $2 ELSE status END,
+		    status_detail = CASE WHEN status = $5 THEN $3 ELSE status_detail END,
+		    file_name = CASE WHEN file_name = '' THEN $4 ELSE file_name END,
+		    updated_at = NOW()
+		WHERE id = $1
+	`
+	_, err := r.db.ExecContext(ctx, query, id, status, detail, fileName, models.DiagnosticsRequested)
+	return err
+}
+
+// Get returns request by ID.
+func (r *DiagnosticsRepository) Get(ctx context.Context, id int64) (*models.Diagnostics, error) {
+	return r.getOne(ctx, `SELECT `+diagnosticsColumns+` FROM ocpp_diagnostics WHERE id = $1`, id)
+}
+
+// GetByToken returns request by its upload token.
+func (r *DiagnosticsRepository) GetByToken(ctx context.Context, token string) (*models.Diagnostics, error) {
+	return r.getOne(ctx, `SELECT `+diagnosticsColumns+` FROM ocpp_diagnostics WHERE token = $1`, token)
+}
+
+// List returns requests of station (all stations when empty), newest first.
+func (r *DiagnosticsRepository) List(ctx context.Context, stationID string, limit int) ([]models.Diagnostics, error) {
+	query := `
+		SELECT ` + diagnosticsColumns + `
+		FROM ocpp_diagnostics
+		WHERE $1 = '' OR station_id = $1
+		ORDER BY id DESC
+		LIMIT $2
+	`
+	rows, err := r.db.QueryContext(ctx, query, stationID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []models.Diagnostics
+	for rows.Next() {
+		d, err := scanDiagnostics(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *d)
+	}
+	return result, rows.Err()
+}
+
+// ReportStatus records upload progress reported by station and reports
+// whether request was found. Nil requestID (OCPP 1.6) addresses the latest
+// diagnostics request the station accepted. Status of received bundle is kept.
+func (r *DiagnosticsRepository) ReportStatus(ctx context.Context, stationID string, requestID *int, status, detail string) (bool, error) {
+	const query = `
+		UPDATE ocpp_diagnostics
+		SET status = CASE WHEN uploaded_at IS NULL OR $3 = 'uploaded' THEN $3 ELSE status END,
+		    status_detail = CASE WHEN uploaded_at IS NULL THEN $4 ELSE status_detail END,
+		    updated_at = NOW()
+		WHERE id = (
+			SELECT id FROM ocpp_diagnostics
+			WHERE station_id = $1
+			  AND (($2::BIGINT IS NULL AND kind = 'diagnostics' AND status NOT IN ('rejected', 'no_data'))
+			       OR id = $2::BIGINT)
+			ORDER BY id DESC
+			LIMIT 1
+		)
+	`
+	var id sql.NullInt64
+	if requestID != nil {
+		id = sql.NullInt64{Int64: int64(*requestID), Valid: true}
+	}
+	res, err := r.db.ExecContext(ctx, query, stationID, id, status, detail)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// StoreUpload records bundle received for request. Only the first upload is
+// accepted.
+func (r *DiagnosticsRepository) StoreUpload(ctx context.Context, id int64, fileName string, size int64, sha string, at time.Time) error {
+	const query = `
+		UPDATE ocpp_diagnostics
+		SET status = $6, file_name = $2, size = $3, sha256 = $4, uploaded_at = $5, updated_at = NOW()
+		WHERE id = $1 AND uploaded_at IS NULL
+	`
+	res, err := r.db.ExecContext(ctx, query, id, fileName, size, sha, at, models.DiagnosticsUploaded)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrDiagnosticsUploaded
+	}
+	return nil
+}
+
+func (r *DiagnosticsRepository) getOne(ctx context.Context, query string, arg interface{}) (*models.Diagnostics, error) {
+	d, err := scanDiagnostics(r.db.QueryRowContext(ctx, query, arg))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrDiagnosticsNotFound
+	}
+	return d, err
+}
+
+func scanDiagnostics(row rowScanner) (*models.Diagnostics, error) {
+	var (
+		d        models.Diagnostics
+		uploaded sql.NullTime
+	)
+	err := row.Scan(
+		&d.ID,
+		&d.StationID,
+		&d.Kind,
+		&d.LogType,
+		&d.Token,
+		&d.Status,
+		&d.StatusDetail,
+		&d.FileName,
+		&d.Size,
+		&d.SHA256,
+		&d.RequestedBy,
+		&d.CreatedAt,
+		&d.UpdatedAt,
+		&uploaded,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if uploaded.Valid {
+		d.UploadedAt = &uploaded.Time
+	}
+	return &d, nil
+}
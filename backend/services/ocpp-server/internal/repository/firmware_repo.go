@@ -0,0 +1,379 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"drivepower/backend/services/ocpp-server/internal/models"
+)
+
+var (
+	// ErrFirmwareArtifactNotFound is returned when firmware artifact does not exist.
+	ErrFirmwareArtifactNotFound = errors.New("firmware artifact not found")
+	// ErrFirmwareCampaignNotFound is returned when firmware campaign does not exist.
+	ErrFirmwareCampaignNotFound = errors.New("firmware campaign not found")
+	// ErrNoFirmwareTargets is returned when no station matches campaign vendor and model.
+	ErrNoFirmwareTargets = errors.New("no stations match firmware campaign")
+)
+
+const (
+	firmwareArtifactColumns = `id, version, file_name, url, size, sha256, created_at`
+	firmwareCampaignColumns = `id, name, artifact_id, vendor, model, batch_size, failure_threshold, current_batch,
+		status, created_by, created_at, updated_at, finished_at`
+)
+
+// FirmwareRepository stores firmware artifacts, campaigns and per-station progress.
+type FirmwareRepository struct {
+	db *sql.DB
+}
+
+// NewFirmwareRepository returns repository.
+func NewFirmwareRepository(db *sql.DB) *FirmwareRepository {
+	return &FirmwareRepository{db: db}
+}
+
+// NextArtifactID reserves artifact ID from sequence.
+func (r *FirmwareRepository) NextArtifactID(ctx context.Context) (int64, error) {
+	var id int64
+	err := r.db.QueryRowContext(ctx, `SELECT nextval('ocpp_firmware_artifacts_id_seq')`).Scan(&id)
+	return id, err
+}
+
+// CreateArtifact stores artifact under ID reserved by NextArtifactID, or
+// fills ID when it is zero.
+func (r *FirmwareRepository) CreateArtifact(ctx context.Context, artifact *models.FirmwareArtifact) error {
+	const query = `
+		INSERT INTO ocpp_firmware_artifacts (id, version, file_name, url, size, sha256)
+		VALUES (COALESCE(NULLIF($1, 0), nextval('ocpp_firmware_artifacts_id_seq')), $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`
+	return r.db.QueryRowContext(ctx, query, artifact.ID, artifact.Version, artifact.FileName, artifact.URL, artifact.Size, artifact.SHA256).
+		Scan(&artifact.ID, &artifact.CreatedAt)
+}
+
+// GetArtifact returns artifact by ID.
+func (r *FirmwareRepository) GetArtifact(ctx context.Context, id int64) (*models.FirmwareArtifact, error) {
+	query := `SELECT ` + firmwareArtifactColumns + ` FROM ocpp_firmware_artifacts WHERE id = $1`
+	var a models.FirmwareArtifact
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&a.ID, &a.Version, &a.FileName, &a.URL, &a.Size, &a.SHA256, &a.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrFirmwareArtifactNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// ListArtifacts returns artifacts, newest first.
+func (r *FirmwareRepository) ListArtifacts(ctx context.Context) ([]models.FirmwareArtifact, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+firmwareArtifactColumns+` FROM ocpp_firmware_artifacts ORDER BY id DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var artifacts []models.FirmwareArtifact
+	for rows.Next() {
+		var a models.FirmwareArtifact
+		if err := rows.Scan(&a.ID, &a.Version, &a.FileName, &a.URL, &a.Size, &a.SHA256, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		artifacts = append(artifacts, a)
+	}
+	return artifacts, rows.Err()
+}
+
+// CreateCampaign stores campaign together with its target stations: accepted
+// OCPP 1.6 stations of campaign vendor and model. Stations already running version are
+// recorded as up to date, the rest are split into batches of BatchSize.
+func (r *FirmwareRepository) CreateCampaign(ctx context.Context, campaign *models.FirmwareCampaign, version string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	const insert = `
+		INSERT INTO ocpp_firmware_campaigns (name, artifact_id, vendor, model, batch_size, failure_threshold, status, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, current_batch, created_at, updated_at
+	`
+	err = tx.QueryRowContext(ctx, insert,
+		campaign.Name,
+		campaign.ArtifactID,
+		campaign.Vendor,
+		campaign.Model,
+		campaign.BatchSize,
+		campaign.FailureThreshold,
+		models.CampaignRunning,
+		campaign.CreatedBy,
+	).Scan(&campaign.ID, &campaign.CurrentBatch, &campaign.CreatedAt, &campaign.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	campaign.Status = models.CampaignRunning
+
+	const targets = `
+		INSERT INTO ocpp_firmware_campaign_stations (campaign_id, station_id, batch, status, firmware_version)
+		SELECT $1, id,
+		       (ROW_NUMBER() OVER (PARTITION BY up_to_date ORDER BY id) - 1) / $4,
+		       CASE WHEN up_to_date THEN $6 ELSE $7 END,
+		       firmware_version
+		FROM (
+			SELECT id, COALESCE(firmware_version, '') AS firmware_version,
+			       COALESCE(firmware_version, '') = $5 AS up_to_date
+			FROM charging_stations
+			WHERE registration_status = 'accepted'
+			  AND ocpp_version = 'ocpp1.6'
+			  AND ($2 = '' OR vendor = $2)
+			  AND ($3 = '' OR model = $3)
+		) s
+	`
+	res, err := tx.ExecContext(ctx, targets, campaign.ID, campaign.Vendor, campaign.Model, campaign.BatchSize, version,
+		models.FirmwareUpToDate, models.FirmwarePending)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNoFirmwareTargets
+	}
+	return tx.Commit()
+}
+
+// GetCampaign returns campaign by ID.
+func (r *FirmwareRepository) GetCampaign(ctx context.Context, id int64) (*models.FirmwareCampaign, error) {
+	query := `SELECT ` + firmwareCampaignColumns + ` FROM ocpp_firmware_campaigns WHERE id = $1`
+	campaign, err := scanCampaign(r.db.QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrFirmwareCampaignNotFound
+	}
+	return campaign, err
+}
+
+// ListCampaigns returns campaigns in given states (all when empty), newest first.
+func (r *FirmwareRepository) ListCampaigns(ctx context.Context, statuses ...string) ([]models.FirmwareCampaign, error) {
+	query := `
+		SELECT ` + firmwareCampaignColumns + `
+		FROM ocpp_firmware_campaigns
+		WHERE cardinality($1::TEXT[]) = 0 OR status = ANY($1::TEXT[])
+		ORDER BY id DESC
+	`
+	if statuses == nil {
+		statuses = []string{}
+	}
+	rows, err := r.db.QueryContext(ctx, query, statuses)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var campaigns []models.FirmwareCampaign
+	for rows.Next() {
+		campaign, err := scanCampaign(rows)
+		if err != nil {
+			return nil, err
+		}
+		campaigns = append(campaigns, *campaign)
+	}
+	return campaigns, rows.Err()
+}
+
+// SetCampaignStatus moves campaign to status when it is in one of from states
+// and reports whether it did. Completed campaign gets finished_at.
+func (r *FirmwareRepository) SetCampaignStatus(ctx context.Context, id int64, status string, from ...string) (bool, error) {
+	const query = `
+		UPDATE ocpp_firmware_campaigns
+		SET status = $2,
+		    finished_at = CASE WHEN $2 = 'completed' THEN NOW() END,
+		    updated_at = NOW()
+		WHERE id = $1 AND status = ANY($3::TEXT[])
+	`
+	res, err := r.db.ExecContext(ctx, query, id, status, from)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// SetCurrentBatch records batch being rolled out.
+func (r *FirmwareRepository) SetCurrentBatch(ctx context.Context, id int64, batch int) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE ocpp_firmware_campaigns SET current_batch = $2, updated_at = NOW() WHERE id = $1`, id, batch)
+	return err
+}
+
+// CampaignStations returns progress of stations of campaign.
+func (r *FirmwareRepository) CampaignStations(ctx context.Context, id int64) ([]models.CampaignStation, error) {
+	const query = `
+		SELECT campaign_id, station_id, batch, status, error, firmware_version, updated_at
+		FROM ocpp_firmware_campaign_stations
+		WHERE campaign_id = $1
+		ORDER BY batch, station_id
+	`
+	rows, err := r.db.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stations []models.CampaignStation
+	for rows.Next() {
+		var s models.CampaignStation
+		if err := rows.Scan(&s.CampaignID, &s.StationID, &s.Batch, &s.Status, &s.Error, &s.FirmwareVersion, &s.UpdatedAt); err != nil {
+			return nil, err
+		}
+		stations = append(stations, s)
+	}
+	return stations, rows.Err()
+}
+
+// StationCounts returns number of campaign stations in each state.
+func (r *FirmwareRepository) StationCounts(ctx context.Context, id int64) (map[string]int, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT status, COUNT(*) FROM ocpp_firmware_campaign_stations WHERE campaign_id = $1 GROUP BY status`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var (
+			status string
+			n      int
+		)
+		if err := rows.Scan(&status, &n); err != nil {
+			return nil, err
+		}
+		counts[status] = n
+	}
+	return counts, rows.Err()
+}
+
+// NextBatch returns lowest batch that still has pending stations. Stations
+// left pending as offline are skipped until RetryOffline.
+func (r *FirmwareRepository) NextBatch(ctx context.Context, id int64) (int, bool, error) {
+	var batch sql.NullInt64
+	err := r.db.QueryRowContext(ctx, `SELECT MIN(batch) FROM ocpp_firmware_campaign_stations WHERE campaign_id = $1 AND status = $2 AND error = ''`, id, models.FirmwarePending).
+		Scan(&batch)
+	if err != nil {
+		return 0, false, err
+	}
+	return int(batch.Int64), batch.Valid, nil
+}
+
+// ClaimBatch moves pending stations of batch to sending and returns them.
+// Each station is claimed by one replica only.
+func (r *FirmwareRepository) ClaimBatch(ctx context.Context, id int64, batch int) ([]string, error) {
+	const query = `
+		UPDATE ocpp_firmware_campaign_stations
+		SET status = $3, updated_at = NOW()
+		WHERE campaign_id = $1 AND batch = $2 AND status = $4
+		RETURNING station_id
+	`
+	rows, err := r.db.QueryContext(ctx, query, id, batch, models.FirmwareSending, models.FirmwarePending)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanStrings(rows)
+}
+
+// FinishSending records outcome of UpdateFirmware unless the station already
+// reported progress with FirmwareStatusNotification.
+func (r *FirmwareRepository) FinishSending(ctx context.Context, id int64, stationID, status, errText string) error {
+	const query = `
+		UPDATE ocpp_firmware_campaign_stations
+		SET status = $3, error = $4, updated_at = NOW()
+		WHERE campaign_id = $1 AND station_id = $2 AND status = $5
+	`
+	_, err := r.db.ExecContext(ctx, query, id, stationID, status, errText, models.FirmwareSending)
+	return err
+}
+
+// RetryOffline makes station left pending as offline by unfinished campaigns
+// eligible for sending again.
+func (r *FirmwareRepository) RetryOffline(ctx context.Context, stationID string) (int, error) {
+	const query = `
+		UPDATE ocpp_firmware_campaign_stations s
+		SET error = '', updated_at = NOW()
+		FROM ocpp_firmware_campaigns c
+		WHERE c.id = s.campaign_id
+		  AND c.status <> $3
+		  AND s.station_id = $1
+		  AND s.status = $2
+		  AND s.error <> ''
+	`
+	res, err := r.db.ExecContext(ctx, query, stationID, models.FirmwarePending, models.CampaignCompleted)
+	if err != nil {
+		return 0, err
+	}
+	n, _ := res.RowsAffected()
+	return int(n), nil
+}
+
+// ReportStatus updates station that is being updated by unfinished campaign
+// and reports whether there was one.
+func (r *FirmwareRepository) ReportStatus(ctx context.Context, stationID, status, errText string) (bool, error) {
+	const query = `
+		UPDATE ocpp_firmware_campaign_stations s
+		SET status = $2, error = $3, updated_at = NOW()
+		FROM ocpp_firmware_campaigns c
+		WHERE c.id = s.campaign_id
+		  AND c.status <> $4
+		  AND s.station_id = $1
+		  AND s.status = ANY($5::TEXT[])
+	`
+	res, err := r.db.ExecContext(ctx, query, stationID, status, errText, models.CampaignCompleted, models.FirmwareInProgress)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// ExpireStations fails stations of campaign that made no progress since before.
+func (r *FirmwareRepository) ExpireStations(ctx context.Context, id int64, before time.Time) (int, error) {
+	const query = `
+		UPDATE ocpp_firmware_campaign_stations
+		SET status = $3, error = 'no progress reported', updated_at = NOW()
+		WHERE campaign_id = $1 AND status = ANY($4::TEXT[]) AND updated_at < $2
+	`
+	res, err := r.db.ExecContext(ctx, query, id, before, models.FirmwareFailed, models.FirmwareInProgress)
+	if err != nil {
+		return 0, err
+	}
+	n, _ := res.RowsAffected()
+	return int(n), nil
+}
+
+func scanCampaign(row rowScanner) (*models.FirmwareCampaign, error) {
+	var (
+		c        models.FirmwareCampaign
+		finished sql.NullTime
+	)
+	err := row.Scan(
+		&c.ID,
+		&c.Name,
+		&c.ArtifactID,
+		&c.Vendor,
+		&c.Model,
+		&c.BatchSize,
+		&c.FailureThreshold,
+		&c.CurrentBatch,
+		&c.Status,
+		&c.CreatedBy,
+		&c.CreatedAt,
+		&c.UpdatedAt,
+		&finished,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if finished.Valid {
+		c.FinishedAt = &finished.Time
+	}
+	return &c, nil
+}
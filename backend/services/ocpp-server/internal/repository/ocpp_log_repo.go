@@ -3,8 +3,37 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+
+	"drivepower/backend/services/ocpp-server/internal/models"
 )
 
+// messagePartitionPrefix names daily partitions of ocpp_messages (ocpp_messages_pYYYYMMDD).
+const messagePartitionPrefix = "ocpp_messages_p"
+
+// ErrMessageNotFound is returned when no logged frame matches.
+var ErrMessageNotFound = errors.New("ocpp message not found")
+
+// MessageFilter selects logged frames; zero fields are not applied.
+type MessageFilter struct {
+	StationID string
+	Action    string
+	Direction string
+	FrameType string
+	UniqueID  string
+	From      time.Time
+	To        time.Time
+	Limit     int
+}
+
+var messageColumns = []string{"station_id", "direction", "frame_type", "unique_id", "message_type", "payload", "created_at"}
+
 // OCPPLogRepository stores raw OCPP messages.
 type OCPPLogRepository struct {
 	db *sql.DB
@@ -15,13 +44,155 @@ func NewOCPPLogRepository(db *sql.DB) *OCPPLogRepository {
 	return &OCPPLogRepository{db: db}
 }
 
-// Save stores log entry.
-func (r *OCPPLogRepository) Save(ctx context.Context, stationID, direction, messageType string, payload []byte) error {
+// Insert writes batch of messages with COPY.
+func (r *OCPPLogRepository) Insert(ctx context.Context, messages []models.OCPPMessage) error {
+	conn, err := r.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return conn.Raw(func(driverConn interface{}) error {
+		pgxConn, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return fmt.Errorf("ocpp log: unexpected driver connection %T", driverConn)
+		}
+		_, err := pgxConn.Conn().CopyFrom(ctx, pgx.Identifier{"ocpp_messages"}, messageColumns,
+			pgx.CopyFromSlice(len(messages), func(i int) ([]interface{}, error) {
+				m := messages[i]
+				return []interface{}{m.StationID, m.Direction, m.FrameType, m.UniqueID, m.Action, []byte(m.Payload), m.CreatedAt}, nil
+			}),
+		)
+		return err
+	})
+}
+
+// Search returns frames matching filter, newest first.
+func (r *OCPPLogRepository) Search(ctx context.Context, filter MessageFilter) ([]models.OCPPMessage, error) {
+	var (
+		conditions []string
+		args       []interface{}
+	)
+	add := func(condition string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+	if filter.StationID != "" {
+		add("station_id = $%d", filter.StationID)
+	}
+	if filter.Action != "" {
+		add("message_type = $%d", filter.Action)
+	}
+	if filter.Direction != "" {
+		add("direction = $%d", filter.Direction)
+	}
+	if filter.FrameType != "" {
+		add("frame_type = $%d", filter.FrameType)
+	}
+	if filter.UniqueID != "" {
+		add("unique_id = $%d", filter.UniqueID)
+	}
+	if !filter.From.IsZero() {
+		add("created_at >= $%d", filter.From)
+	}
+	if !filter.To.IsZero() {
+		add("created_at < $%d", filter.To)
+	}
+
+	query := `SELECT id, station_id, direction, frame_type, unique_id, message_type, payload, created_at FROM ocpp_messages`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	args = append(args, filter.Limit)
+	query += fmt.Sprintf(" ORDER BY created_at DESC, id DESC LIMIT $%d", len(args))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanMessages(rows)
+}
+
+// Exchange returns frames of station carrying unique ID, oldest first.
+func (r *OCPPLogRepository) Exchange(ctx context.Context, stationID, uniqueID string) ([]models.OCPPMessage, error) {
 	const query = `
-		INSERT INTO ocpp_messages (station_id, direction, message_type, payload)
-		VALUES ($1, $2, $3, $4)
+		SELECT id, station_id, direction, frame_type, unique_id, message_type, payload, created_at
+		FROM ocpp_messages
+		WHERE station_id = $1 AND unique_id = $2
+		ORDER BY created_at, id
 	`
-	_, err := r.db.ExecContext(ctx, query, stationID, direction, messageType, payload)
-	return err
+	rows, err := r.db.QueryContext(ctx, query, stationID, uniqueID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanMessages(rows)
+}
+
+// EnsurePartitions creates daily partitions for days starting at from.
+func (r *OCPPLogRepository) EnsurePartitions(ctx context.Context, from time.Time, days int) error {
+	for i := 0; i < days; i++ {
+		day := from.UTC().AddDate(0, 0, i).Format(time.DateOnly)
+		if _, err := r.db.ExecContext(ctx, `SELECT ocpp_messages_ensure_partition($1::date)`, day); err != nil {
+			return fmt.Errorf("ensure partition %s: %w", day, err)
+		}
+	}
+	return nil
 }
 
+// DropPartitionsBefore drops daily partitions of days before day and returns their names.
+func (r *OCPPLogRepository) DropPartitionsBefore(ctx context.Context, day time.Time) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT c.relname
+		FROM pg_inherits i
+		JOIN pg_class c ON c.oid = i.inhrelid
+		JOIN pg_class p ON p.oid = i.inhparent
+		WHERE p.relname = 'ocpp_messages'
+	`)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	cutoff := day.UTC().Format("20060102")
+	var dropped []string
+	for _, name := range names {
+		suffix := strings.TrimPrefix(name, messagePartitionPrefix)
+		if suffix == name {
+			continue
+		}
+		if _, err := time.Parse("20060102", suffix); err != nil || suffix >= cutoff {
+			continue
+		}
+		if _, err := r.db.ExecContext(ctx, `DROP TABLE IF EXISTS `+pgx.Identifier{name}.Sanitize()); err != nil {
+			return dropped, fmt.Errorf("drop partition %s: %w", name, err)
+		}
+		dropped = append(dropped, name)
+	}
+	return dropped, nil
+}
+
+func scanMessages(rows *sql.Rows) ([]models.OCPPMessage, error) {
+	var messages []models.OCPPMessage
+	for rows.Next() {
+		var m models.OCPPMessage
+		if err := rows.Scan(&m.ID, &m.StationID, &m.Direction, &m.FrameType, &m.UniqueID, &m.Action, &m.Payload, &m.CreatedAt); err != nil {
+			return nil, err
+		}
+		messages = append(messages, m)
+	}
+	return messages, rows.Err()
+}
@@ -0,0 +1,203 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"drivepower/backend/services/ocpp-server/internal/models"
+)
+
+// ErrOutboxEventNotFound is returned when dead event to replay does not exist.
+var ErrOutboxEventNotFound = errors.New("outbox event not found")
+
+// OutboxRepository stores notifications awaiting delivery.
+type OutboxRepository struct {
+	db *sql.DB
+}
+
+// NewOutboxRepository returns repository.
+func NewOutboxRepository(db *sql.DB) *OutboxRepository {
+	return &OutboxRepository{db: db}
+}
+
+// Enqueue stores events atomically.
+func (r *OutboxRepository) Enqueue(ctx context.Context, events []models.OutboxEvent) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	const query = `
+		INSERT INTO ocpp_outbox (event_type, aggregate_id, payload)
+		VALUES ($1, $2, $3)
+	`
+	for _, event := range events {
+		if _, err := tx.ExecContext(ctx, query, event.EventType, event.AggregateID, []byte(event.Payload)); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// ClaimDue returns pending events whose retry time came and hides them from
+// other dispatchers for lease. Event waits while an older event of the same
+// aggregate is still pending.
+func (r *OutboxRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]models.OutboxEvent, error) {
+	const query = `
+		UPDATE ocpp_outbox
+		SET next_attempt_at = NOW() + make_interval(secs => $3),
+		    updated_at = NOW()
+		WHERE id IN (
+			SELECT o.id
+			FROM ocpp_outbox o
+			WHERE o.status = $1
+			  AND o.next_attempt_at <= NOW()
+			  AND NOT EXISTS (
+				SELECT 1 FROM ocpp_outbox prev
+				WHERE prev.aggregate_id = o.aggregate_id
+				  AND o.aggregate_id <> ''
+				  AND prev.status = $1
+				  AND prev.id < o.id
+			  )
+			ORDER BY o.id
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, event_type, aggregate_id, payload, status, attempts, last_error, next_attempt_at, created_at, updated_at
+	`
+	rows, err := r.db.QueryContext(ctx, query, models.OutboxPending, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanOutboxEvents(rows)
+}
+
+// MarkDelivered finishes event.
+func (r *OutboxRepository) MarkDelivered(ctx context.Context, id int64) error {
+	const query = `
+		UPDATE ocpp_outbox
+		SET status = $2, attempts = attempts + 1, last_error = '', updated_at = NOW()
+		WHERE id = $1
+	`
+	_, err := r.db.ExecContext(ctx, query, id, models.OutboxDelivered)
+	return err
+}
+
+// MarkFailed records failed attempt; status is pending with next retry time or dead.
+func (r *OutboxRepository) MarkFailed(ctx context.Context, id int64, status string, nextAttempt time.Time, lastError string) error {
+	const query = `
+		UPDATE ocpp_outbox
+		SET status = $2, attempts = attempts + 1, next_attempt_at = $3, last_error = $4, updated_at = NOW()
+		WHERE id = $1
+	`
+	_, err := r.db.ExecContext(ctx, query, id, status, nextAttempt, lastError)
+	return err
+}
+
+// AssignSession fills session_id of pending events of transaction that were
+// enqueued before the session was created.
+func (r *OutboxRepository) AssignSession(ctx context.Context, aggregateID string, sessionID int64) error {
+	const query = `
+		UPDATE ocpp_outbox
+		SET payload = jsonb_set(payload, '{session_id}', to_jsonb($2::BIGINT)),
+		    updated_at = NOW()
+		WHERE aggregate_id = $1
+		  AND status IN ($3, $4)
+		  AND payload ? 'session_id'
+		  AND (payload->>'session_id')::BIGINT = 0
+	`
+	_, err := r.db.ExecContext(ctx, query, aggregateID, sessionID, models.OutboxPending, models.OutboxDead)
+	return err
+}
+
+// ListByStatus returns events in status, newest first.
+func (r *OutboxRepository) ListByStatus(ctx context.Context, status string, limit int) ([]models.OutboxEvent, error) {
+	const query = `
+		SELECT id, event_type, aggregate_id, payload, status, attempts, last_error, next_attempt_at, created_at, updated_at
+		FROM ocpp_outbox
+		WHERE status = $1
+		ORDER BY id DESC
+		LIMIT $2
+	`
+	rows, err := r.db.QueryContext(ctx, query, status, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanOutboxEvents(rows)
+}
+
+// Replay moves dead event back to pending with fresh attempt budget.
+func (r *OutboxRepository) Replay(ctx context.Context, id int64) error {
+	const query = `
+		UPDATE ocpp_outbox
+		SET status = $2, attempts = 0, next_attempt_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND status = $3
+	`
+	res, err := r.db.ExecContext(ctx, query, id, models.OutboxPending, models.OutboxDead)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrOutboxEventNotFound
+	}
+	return nil
+}
+
+// ReplayAllDead moves every dead event back to pending and returns their count.
+func (r *OutboxRepository) ReplayAllDead(ctx context.Context) (int64, error) {
+	const query = `
+		UPDATE ocpp_outbox
+		SET status = $1, attempts = 0, next_attempt_at = NOW(), updated_at = NOW()
+		WHERE status = $2
+	`
+	res, err := r.db.ExecContext(ctx, query, models.OutboxPending, models.OutboxDead)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// DeleteDelivered removes delivered events older than before.
+func (r *OutboxRepository) DeleteDelivered(ctx context.Context, before time.Time) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM ocpp_outbox WHERE status = $1 AND updated_at < $2`, models.OutboxDelivered, before)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func scanOutboxEvents(rows *sql.Rows) ([]models.OutboxEvent, error) {
+	var events []models.OutboxEvent
+	for rows.Next() {
+		var (
+			event   models.OutboxEvent
+			payload []byte
+		)
+		if err := rows.Scan(
+			&event.ID,
+			&event.EventType,
+			&event.AggregateID,
+			&payload,
+			&event.Status,
+			&event.Attempts,
+			&event.LastError,
+			&event.NextAttemptAt,
+			&event.CreatedAt,
+			&event.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		event.Payload = payload
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
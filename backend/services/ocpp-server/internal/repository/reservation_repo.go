@@ -0,0 +1,217 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"drivepower/backend/services/ocpp-server/internal/models"
+)
+
+var (
+	// ErrReservationNotFound is returned when reservation does not exist.
+	ErrReservationNotFound = errors.New("reservation not found")
+	// ErrReservationConflict is returned when connector is already booked for
+	// part of the window.
+	ErrReservationConflict = errors.New("connector already reserved for this time")
+)
+
+const exclusionViolation = "23P01"
+
+const reservationColumns = `id, station_id, connector_id, user_id, id_tag, start_at, end_at, status, status_detail,
+	transaction_id, created_at, updated_at`
+
+// ReservationFilter narrows reservation listing; zero fields match any.
+type ReservationFilter struct {
+	StationID string
+	UserID    int64
+	Limit     int
+}
+
+// ReservationRepository stores connector bookings.
+type ReservationRepository struct {
+	db *sql.DB
+}
+
+// NewReservationRepository returns repository.
+func NewReservationRepository(db *sql.DB) *ReservationRepository {
+	return &ReservationRepository{db: db}
+}
+
+// Create stores reservation and fills ID. Overlapping open reservation of the
+// connector yields ErrReservationConflict.
+func (r *ReservationRepository) Create(ctx context.Context, reservation *models.Reservation) error {
+	const query = `
+		INSERT INTO ocpp_reservations (station_id, connector_id, user_id, id_tag, start_at, end_at, status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at, updated_at
+	`
+	err := r.db.QueryRowContext(ctx, query,
+		reservation.StationID,
+		reservation.ConnectorID,
+		reservation.UserID,
+		reservation.IdTag,
+		reservation.StartAt,
+		reservation.EndAt,
+		reservation.Status,
+	).Scan(&reservation.ID, &reservation.CreatedAt, &reservation.UpdatedAt)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == exclusionViolation {
+		return ErrReservationConflict
+	}
+	return err
+}
+
+// Get returns reservation by ID.
+func (r *ReservationRepository) Get(ctx context.Context, id int64) (*models.Reservation, error) {
+	query := `SELECT ` + reservationColumns + ` FROM ocpp_reservations WHERE id = $1`
+	reservation, err := scanReservation(r.db.QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrReservationNotFound
+	}
+	return reservation, err
+}
+
+// List returns reservations matching filter, latest window first.
+func (r *ReservationRepository) List(ctx context.Context, filter ReservationFilter) ([]models.Reservation, error) {
+	query := `
+		SELECT ` + reservationColumns + `
+		FROM ocpp_reservations
+		WHERE ($1 = '' OR station_id = $1)
+		  AND ($2 = 0 OR user_id = $2)
+		ORDER BY start_at DESC, id DESC
+		LIMIT $3
+	`
+	rows, err := r.db.QueryContext(ctx, query, filter.StationID, filter.UserID, filter.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanReservations(rows)
+}
+
+// SetStatus moves reservation to status when it is in one of from states and
+// reports whether it did.
+func (r *ReservationRepository) SetStatus(ctx context.Context, id int64, status, detail string, from ...string) (bool, error) {
+	const query = `
+		UPDATE ocpp_reservations
+		SET status = $2, status_detail = $3, updated_at = NOW()
+		WHERE id = $1 AND status = ANY($4::TEXT[])
+	`
+	res, err := r.db.ExecContext(ctx, query, id, status, detail, from)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// ClaimDue moves scheduled reservations whose window started by now to
+// reserving and returns them. Each reservation is claimed by one replica only.
+func (r *ReservationRepository) ClaimDue(ctx context.Context, now time.Time) ([]models.Reservation, error) {
+	query := `
+		UPDATE ocpp_reservations
+		SET status = $2, updated_at = NOW()
+		WHERE status = $3 AND start_at <= $1 AND end_at > $1
+		RETURNING ` + reservationColumns
+	rows, err := r.db.QueryContext(ctx, query, now, models.ReservationReserving, models.ReservationScheduled)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanReservations(rows)
+}
+
+// ExpireDue closes open reservations whose window ended by now: active ones
+// expire as no-show, the ones never delivered to station fail. Returns the
+// expired ones.
+func (r *ReservationRepository) ExpireDue(ctx context.Context, now time.Time) ([]models.Reservation, error) {
+	query := `
+		UPDATE ocpp_reservations
+		SET status = CASE WHEN status = $2 THEN $3 ELSE $4 END,
+		    status_detail = CASE WHEN status = $2 THEN 'no show' ELSE 'not delivered to station before window ended' END,
+		    updated_at = NOW()
+		WHERE status = ANY($5::TEXT[]) AND end_at <= $1
+		RETURNING ` + reservationColumns
+	rows, err := r.db.QueryContext(ctx, query, now, models.ReservationActive, models.ReservationExpired, models.ReservationFailed, models.ReservationOpen)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	all, err := scanReservations(rows)
+	if err != nil {
+		return nil, err
+	}
+	var expired []models.Reservation
+	for _, reservation := range all {
+		if reservation.Status == models.ReservationExpired {
+			expired = append(expired, reservation)
+		}
+	}
+	return expired, nil
+}
+
+// Consume marks active reservation used by transaction and reports whether
+// there was one. Nil reservationID matches reservation of connector and idTag.
+func (r *ReservationRepository) Consume(ctx context.Context, stationID string, connectorID int, idTag string, reservationID *int, transactionID string) (bool, error) {
+	const query = `
+		UPDATE ocpp_reservations
+		SET status = $6, transaction_id = $5, status_detail = '', updated_at = NOW()
+		WHERE id = (
+			SELECT id FROM ocpp_reservations
+			WHERE station_id = $1
+			  AND status = $7
+			  AND (id = $4::BIGINT OR ($4::BIGINT IS NULL AND connector_id = $2 AND id_tag = $3))
+			ORDER BY start_at
+			LIMIT 1
+		)
+	`
+	var id sql.NullInt64
+	if reservationID != nil {
+		id = sql.NullInt64{Int64: int64(*reservationID), Valid: true}
+	}
+	res, err := r.db.ExecContext(ctx, query, stationID, connectorID, idTag, id, transactionID, models.ReservationUsed, models.ReservationActive)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+func scanReservations(rows *sql.Rows) ([]models.Reservation, error) {
+	var reservations []models.Reservation
+	for rows.Next() {
+		reservation, err := scanReservation(rows)
+		if err != nil {
+			return nil, err
+		}
+		reservations = append(reservations, *reservation)
+	}
+	return reservations, rows.Err()
+}
+
+func scanReservation(row rowScanner) (*models.Reservation, error) {
+	var reservation models.Reservation
+	err := row.Scan(
+		&reservation.ID,
+		&reservation.StationID,
+		&reservation.ConnectorID,
+		&reservation.UserID,
+		&reservation.IdTag,
+		&reservation.StartAt,
+		&reservation.EndAt,
+		&reservation.Status,
+		&reservation.StatusDetail,
+		&reservation.TransactionID,
+		&reservation.CreatedAt,
+		&reservation.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &reservation, nil
+}
@@ -0,0 +1,295 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"drivepower/backend/services/ocpp-server/internal/models"
+)
+
+// ErrSiteNotFound is returned when site does not exist.
+var ErrSiteNotFound = errors.New("site not found")
+
+// SiteRepository stores sites, their stations and current allocations.
+type SiteRepository struct {
+	db *sql.DB
+}
+
+// NewSiteRepository returns repository.
+func NewSiteRepository(db *sql.DB) *SiteRepository {
+	return &SiteRepository{db: db}
+}
+
+// Save creates or updates site and replaces its stations. Stations are moved
+// from the site they belonged to.
+func (r *SiteRepository) Save(ctx context.Context, site *models.Site) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	const upsert = `
+		INSERT INTO ocpp_sites (id, name, max_current, min_current, connector_max_current, strategy)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (id) DO UPDATE SET
+			name = EXCLUDED.name,
+			max_current = EXCLUDED.max_current,
+			min_current = EXCLUDED.min_current,
+			connector_max_current = EXCLUDED.connector_max_current,
+			strategy = EXCLUDED.strategy,
+			updated_at = NOW()
+		RETURNING created_at, updated_at
+	`
+	err = tx.QueryRowContext(ctx, upsert, site.ID, site.Name, site.MaxCurrent, site.MinCurrent, site.ConnectorMaxCurrent, site.Strategy).
+		Scan(&site.CreatedAt, &site.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM ocpp_site_stations WHERE site_id = $1`, site.ID); err != nil {
+		return err
+	}
+	const assign = `
+		INSERT INTO ocpp_site_stations (station_id, site_id)
+		VALUES ($1, $2)
+		ON CONFLICT (station_id) DO UPDATE SET site_id = EXCLUDED.site_id
+	`
+	for _, stationID := range site.Stations {
+		if _, err := tx.ExecContext(ctx, assign, stationID, site.ID); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Get returns site with its stations.
+func (r *SiteRepository) Get(ctx context.Context, id string) (*models.Site, error) {
+	const query = `
+		SELECT id, name, max_current, min_current, connector_max_current, strategy, created_at, updated_at
+		FROM ocpp_sites
+		WHERE id = $1
+	`
+	var site models.Site
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&site.ID,
+		&site.Name,
+		&site.MaxCurrent,
+		&site.MinCurrent,
+		&site.ConnectorMaxCurrent,
+		&site.Strategy,
+		&site.CreatedAt,
+		&site.UpdatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrSiteNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if site.Stations, err = r.stations(ctx, id); err != nil {
+		return nil, err
+	}
+	return &site, nil
+}
+
+// List returns all sites with their stations.
+func (r *SiteRepository) List(ctx context.Context) ([]models.Site, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT id FROM ocpp_sites ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := scanStrings(rows)
+	rows.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	sites := make([]models.Site, 0, len(ids))
+	for _, id := range ids {
+		site, err := r.Get(ctx, id)
+		if errors.Is(err, ErrSiteNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		sites = append(sites, *site)
+	}
+	return sites, nil
+}
+
+func (r *SiteRepository) stations(ctx context.Context, siteID string) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT station_id FROM ocpp_site_stations WHERE site_id = $1 ORDER BY station_id`, siteID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	stations, err := scanStrings(rows)
+	if stations == nil {
+		stations = []string{}
+	}
+	return stations, err
+}
+
+// Delete removes site and its station assignments. Allocations are kept until
+// limits of its stations are released.
+func (r *SiteRepository) Delete(ctx context.Context, id string) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM ocpp_sites WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrSiteNotFound
+	}
+	return nil
+}
+
+// SiteOf returns site of station, empty when station belongs to none.
+func (r *SiteRepository) SiteOf(ctx context.Context, stationID string) (string, error) {
+	var siteID string
+	err := r.db.QueryRowContext(ctx, `SELECT site_id FROM ocpp_site_stations WHERE station_id = $1`, stationID).Scan(&siteID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return siteID, err
+}
+
+// Transactions returns ongoing transactions on stations of site, oldest first.
+func (r *SiteRepository) Transactions(ctx context.Context, siteID string) ([]models.Transaction, error) {
+	query := `
+		SELECT ` + transactionColumns + `
+		FROM ocpp_transactions
+		WHERE station_id IN (SELECT station_id FROM ocpp_site_stations WHERE site_id = $1)
+		ORDER BY started_at, transaction_id
+	`
+	rows, err := r.db.QueryContext(ctx, query, siteID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanTransactions(rows)
+}
+
+// SetUserTier stores priority tier of user.
+func (r *SiteRepository) SetUserTier(ctx context.Context, userID int64, tier int) error {
+	const query = `
+		INSERT INTO ocpp_user_tiers (user_id, tier)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET tier = EXCLUDED.tier, updated_at = NOW()
+	`
+	_, err := r.db.ExecContext(ctx, query, userID, tier)
+	return err
+}
+
+// UserTiers returns tiers of users; users without tier are omitted.
+func (r *SiteRepository) UserTiers(ctx context.Context, userIDs []int64) (map[int64]int, error) {
+	tiers := make(map[int64]int)
+	if len(userIDs) == 0 {
+		return tiers, nil
+	}
+	rows, err := r.db.QueryContext(ctx, `SELECT user_id, tier FROM ocpp_user_tiers WHERE user_id = ANY($1::BIGINT[])`, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			userID int64
+			tier   int
+		)
+		if err := rows.Scan(&userID, &tier); err != nil {
+			return nil, err
+		}
+		tiers[userID] = tier
+	}
+	return tiers, rows.Err()
+}
+
+// Allocations returns current allocations of site.
+func (r *SiteRepository) Allocations(ctx context.Context, siteID string) ([]models.SiteAllocation, error) {
+	const query = `
+		SELECT transaction_id, site_id, station_id, connector_id, measured_current, limit_current, unmanaged, updated_at
+		FROM ocpp_site_allocations
+		WHERE site_id = $1
+		ORDER BY station_id, connector_id
+	`
+	rows, err := r.db.QueryContext(ctx, query, siteID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var allocations []models.SiteAllocation
+	for rows.Next() {
+		var (
+			allocation models.SiteAllocation
+			measured   sql.NullFloat64
+			limit      sql.NullFloat64
+		)
+		if err := rows.Scan(
+			&allocation.TransactionID,
+			&allocation.SiteID,
+			&allocation.StationID,
+			&allocation.ConnectorID,
+			&measured,
+			&limit,
+			&allocation.Unmanaged,
+			&allocation.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		if measured.Valid {
+			allocation.MeasuredCurrent = &measured.Float64
+		}
+		if limit.Valid {
+			allocation.LimitCurrent = &limit.Float64
+		}
+		allocations = append(allocations, allocation)
+	}
+	return allocations, rows.Err()
+}
+
+// ReplaceAllocations replaces allocations of site; transactions that are over
+// are dropped.
+func (r *SiteRepository) ReplaceAllocations(ctx context.Context, siteID string, allocations []models.SiteAllocation) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM ocpp_site_allocations WHERE site_id = $1`, siteID); err != nil {
+		return err
+	}
+	const query = `
+		INSERT INTO ocpp_site_allocations (transaction_id, site_id, station_id, connector_id, measured_current, limit_current, unmanaged)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (transaction_id) DO UPDATE SET
+			site_id = EXCLUDED.site_id,
+			station_id = EXCLUDED.station_id,
+			connector_id = EXCLUDED.connector_id,
+			measured_current = EXCLUDED.measured_current,
+			limit_current = EXCLUDED.limit_current,
+			unmanaged = EXCLUDED.unmanaged,
+			updated_at = NOW()
+	`
+	for _, a := range allocations {
+		if _, err := tx.ExecContext(ctx, query, a.TransactionID, siteID, a.StationID, a.ConnectorID, a.MeasuredCurrent, a.LimitCurrent, a.Unmanaged); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// DeleteStationAllocations removes allocations of station and reports whether
+// there were any.
+func (r *SiteRepository) DeleteStationAllocations(ctx context.Context, stationID string) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM ocpp_site_allocations WHERE station_id = $1`, stationID)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
@@ -3,11 +3,15 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"time"
 
 	"drivepower/backend/services/ocpp-server/internal/models"
 )
 
+// ErrStationNotFound is returned when station is absent in registry.
+var ErrStationNotFound = errors.New("station not found")
+
 // StationRepository manages charging station persistence.
 type StationRepository struct {
 	db *sql.DB
@@ -18,22 +22,27 @@ func NewStationRepository(db *sql.DB) *StationRepository {
 	return &StationRepository{db: db}
 }
 
-// Upsert stores or updates station metadata.
+// Upsert stores or updates station metadata. Registration status is only set
+// on insert; later changes go through SetRegistration.
 func (r *StationRepository) Upsert(ctx context.Context, station *models.Station) error {
 	const query = `
-		INSERT INTO charging_stations (id, vendor, model, firmware_version, status, last_heartbeat, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())
+		INSERT INTO charging_stations (id, vendor, model, firmware_version, status, last_heartbeat, registration_status, ocpp_version, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW())
 		ON CONFLICT (id) DO UPDATE SET
 			vendor = EXCLUDED.vendor,
 			model = EXCLUDED.model,
 			firmware_version = EXCLUDED.firmware_version,
 			status = EXCLUDED.status,
 			last_heartbeat = EXCLUDED.last_heartbeat,
+			ocpp_version = EXCLUDED.ocpp_version,
 			updated_at = NOW()
 	`
 	if station.LastHeartbeat.IsZero() {
 		station.LastHeartbeat = time.Now().UTC()
 	}
+	if station.RegistrationStatus == "" {
+		station.RegistrationStatus = models.RegistrationAccepted
+	}
 	_, err := r.db.ExecContext(ctx, query,
 		station.ID,
 		station.Vendor,
@@ -41,10 +50,193 @@ func (r *StationRepository) Upsert(ctx context.Context, station *models.Station)
 		station.FirmwareVersion,
 		station.Status,
 		station.LastHeartbeat,
+		station.RegistrationStatus,
+		station.OCPPVersion,
 	)
 	return err
 }
 
+// GetByID returns station from registry.
+func (r *StationRepository) GetByID(ctx context.Context, stationID string) (*models.Station, error) {
+	const query = `
+		SELECT id, COALESCE(vendor, ''), COALESCE(model, ''), COALESCE(firmware_version, ''), status,
+		       last_heartbeat, registration_status, heartbeat_interval, ocpp_version, created_at, updated_at
+		FROM charging_stations
+		WHERE id = $1
+	`
+	station, err := scanStation(r.db.QueryRowContext(ctx, query, stationID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrStationNotFound
+	}
+	return station, err
+}
+
+// List returns stations, optionally filtered by registration status.
+func (r *StationRepository) List(ctx context.Context, registrationStatus string) ([]models.Station, error) {
+	const query = `
+		SELECT id, COALESCE(vendor, ''), COALESCE(model, ''), COALESCE(firmware_version, ''), status,
+		       last_heartbeat, registration_status, heartbeat_interval, ocpp_version, created_at, updated_at
+		FROM charging_stations
+		WHERE $1 = '' OR registration_status = $1
+		ORDER BY id
+	`
+	rows, err := r.db.QueryContext(ctx, query, registrationStatus)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stations []models.Station
+	for rows.Next() {
+		station, err := scanStation(rows)
+		if err != nil {
+			return nil, err
+		}
+		stations = append(stations, *station)
+	}
+	return stations, rows.Err()
+}
+
+// SetRegistration changes provisioning state and heartbeat interval (0 keeps server default).
+func (r *StationRepository) SetRegistration(ctx context.Context, stationID, registrationStatus string, heartbeatInterval int) error {
+	const query = `
+		UPDATE charging_stations
+		SET registration_status = $2,
+		    heartbeat_interval = $3,
+		    updated_at = NOW()
+		WHERE id = $1
+	`
+	res, err := r.db.ExecContext(ctx, query, stationID, registrationStatus, heartbeatInterval)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrStationNotFound
+	}
+	return nil
+}
+
+// TouchHeartbeat records station liveness. Offline station gets restoreStatus
+// back; returns true when the station was offline.
+func (r *StationRepository) TouchHeartbeat(ctx context.Context, stationID string, at time.Time, restoreStatus string) (bool, error) {
+	const query = `
+		WITH prev AS (
+			SELECT id, status FROM charging_stations WHERE id = $1 FOR UPDATE
+		)
+		UPDATE charging_stations c
+		SET last_heartbeat = $2,
+		    status = CASE WHEN prev.status = $4 THEN $3 ELSE c.status END,
+		    updated_at = NOW()
+		FROM prev
+		WHERE c.id = prev.id
+		RETURNING prev.status = $4
+	`
+	var wasOffline bool
+	err := r.db.QueryRowContext(ctx, query, stationID, at, restoreStatus, models.StatusOffline).Scan(&wasOffline)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, ErrStationNotFound
+	}
+	return wasOffline, err
+}
+
+// StaleStation is station marked offline by MarkStaleOffline.
+type StaleStation struct {
+	ID             string
+	PreviousStatus string
+	LastHeartbeat  time.Time
+}
+
+// MarkStaleOffline sets Offline status on stations that missed the given number
+// of heartbeat intervals (station's own interval or defaultInterval seconds).
+func (r *StationRepository) MarkStaleOffline(ctx context.Context, defaultInterval, missed int) ([]StaleStation, error) {
+	const query = `
+		WITH stale AS (
+			SELECT id, status
+			FROM charging_stations
+			WHERE status <> $1
+			  AND registration_status <> $2
+			  AND last_heartbeat < NOW() - make_interval(secs => $4 * CASE WHEN heartbeat_interval > 0 THEN heartbeat_interval ELSE $3 END)
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE charging_stations c
+		SET status = $1,
+		    updated_at = NOW()
+		FROM stale
+		WHERE c.id = stale.id
+		RETURNING c.id, stale.status, c.last_heartbeat
+	`
+	rows, err := r.db.QueryContext(ctx, query, models.StatusOffline, models.RegistrationDecommissioned, defaultInterval, missed)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stations []StaleStation
+	for rows.Next() {
+		var station StaleStation
+		if err := rows.Scan(&station.ID, &station.PreviousStatus, &station.LastHeartbeat); err != nil {
+			return nil, err
+		}
+		stations = append(stations, station)
+	}
+	return stations, rows.Err()
+}
+
+// ListOffline returns stations currently marked offline, longest silent first.
+func (r *StationRepository) ListOffline(ctx context.Context) ([]models.Station, error) {
+	const query = `
+		SELECT id, COALESCE(vendor, ''), COALESCE(model, ''), COALESCE(firmware_version, ''), status,
+		       last_heartbeat, registration_status, heartbeat_interval, ocpp_version, created_at, updated_at
+		FROM charging_stations
+		WHERE status = $1 AND registration_status <> $2
+		ORDER BY last_heartbeat
+	`
+	rows, err := r.db.QueryContext(ctx, query, models.StatusOffline, models.RegistrationDecommissioned)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stations []models.Station
+	for rows.Next() {
+		station, err := scanStation(rows)
+		if err != nil {
+			return nil, err
+		}
+		stations = append(stations, *station)
+	}
+	return stations, rows.Err()
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanStation(row rowScanner) (*models.Station, error) {
+	var station models.Station
+	err := row.Scan(
+		&station.ID,
+		&station.Vendor,
+		&station.Model,
+		&station.FirmwareVersion,
+		&station.Status,
+		&station.LastHeartbeat,
+		&station.RegistrationStatus,
+		&station.HeartbeatInterval,
+		&station.OCPPVersion,
+		&station.CreatedAt,
+		&station.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &station, nil
+}
+
 // UpdateStatus changes station status and heartbeat.
 func (r *StationRepository) UpdateStatus(ctx context.Context, stationID, status string) error {
 	const query = `
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+)
+
+// TransactionIDRepository hands out CSMS-assigned transaction IDs.
+type TransactionIDRepository struct {
+	db *sql.DB
+}
+
+// NewTransactionIDRepository ctor.
+func NewTransactionIDRepository(db *sql.DB) *TransactionIDRepository {
+	return &TransactionIDRepository{db: db}
+}
+
+// Next returns next transaction ID from sequence.
+func (r *TransactionIDRepository) Next(ctx context.Context) (int, error) {
+	var id int
+	err := r.db.QueryRowContext(ctx, `SELECT nextval('ocpp_transaction_id_seq')`).Scan(&id)
+	return id, err
+}
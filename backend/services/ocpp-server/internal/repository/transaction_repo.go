@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"drivepower/backend/services/ocpp-server/internal/models"
+)
+
+// TransactionRepository persists ongoing transactions.
+type TransactionRepository struct {
+	db *sql.DB
+}
+
+// NewTransactionRepository returns repository.
+func NewTransactionRepository(db *sql.DB) *TransactionRepository {
+	return &TransactionRepository{db: db}
+}
+
+// Save inserts or updates transaction; transaction seen again after expiry
+// becomes ongoing.
+func (r *TransactionRepository) Save(ctx context.Context, tx *models.Transaction) error {
+	const query = `
+		INSERT INTO ocpp_transactions (transaction_id, station_id, connector_id, session_id, meter_start, meter_last, id_tag, user_id, authorized, started_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW(), NOW())
+		ON CONFLICT (transaction_id) DO UPDATE SET
+			station_id = EXCLUDED.station_id,
+			connector_id = EXCLUDED.connector_id,
+			session_id = EXCLUDED.session_id,
+			meter_start = EXCLUDED.meter_start,
+			meter_last = GREATEST(ocpp_transactions.meter_last, EXCLUDED.meter_last),
+			id_tag = EXCLUDED.id_tag,
+			user_id = EXCLUDED.user_id,
+			authorized = EXCLUDED.authorized,
+			expired_at = NULL,
+			updated_at = NOW()
+	`
+	_, err := r.db.ExecContext(ctx, query,
+		tx.ID,
+		tx.StationID,
+		tx.ConnectorID,
+		tx.SessionID,
+		tx.MeterStart,
+		tx.MeterLast,
+		tx.IdTag,
+		tx.UserID,
+		tx.Authorized,
+	)
+	return err
+}
+
+// Touch records activity of transaction and its latest meter reading.
+func (r *TransactionRepository) Touch(ctx context.Context, transactionID string, meterLast int64) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE ocpp_transactions SET updated_at = NOW(), meter_last = GREATEST(meter_last, $2) WHERE transaction_id = $1`, transactionID, meterLast)
+	return err
+}
+
+// Delete removes finished transaction.
+func (r *TransactionRepository) Delete(ctx context.Context, transactionID string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM ocpp_transactions WHERE transaction_id = $1`, transactionID)
+	return err
+}
+
+// AssignSession sets session of transaction that started without one.
+func (r *TransactionRepository) AssignSession(ctx context.Context, transactionID string, sessionID int64) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE ocpp_transactions SET session_id = $2, updated_at = NOW() WHERE transaction_id = $1 AND session_id = 0`, transactionID, sessionID)
+	return err
+}
+
+// ExpireIdle marks transactions without activity since before as expired and
+// returns them. Expired transactions are hidden from ongoing ones; in a cluster
+// each is returned to one replica only.
+func (r *TransactionRepository) ExpireIdle(ctx context.Context, before time.Time) ([]models.Transaction, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		UPDATE ocpp_transactions SET expired_at = NOW()
+		WHERE expired_at IS NULL AND updated_at < $1
+		RETURNING `+transactionColumns, before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanTransactions(rows)
+}
+
+// Unexpire makes expired transaction ongoing again, e.g. when its close could
+// not be queued.
+func (r *TransactionRepository) Unexpire(ctx context.Context, transactionID string) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE ocpp_transactions SET expired_at = NULL WHERE transaction_id = $1`, transactionID)
+	return err
+}
+
+// DeleteExpired removes expired transaction once its close is delivered.
+func (r *TransactionRepository) DeleteExpired(ctx context.Context, transactionID string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM ocpp_transactions WHERE transaction_id = $1 AND expired_at IS NOT NULL`, transactionID)
+	return err
+}
+
+// IsExpired reports whether transaction is expired and its row not removed yet.
+func (r *TransactionRepository) IsExpired(ctx context.Context, transactionID string) (bool, error) {
+	var expired bool
+	err := r.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM ocpp_transactions WHERE transaction_id = $1 AND expired_at IS NOT NULL)`, transactionID).Scan(&expired)
+	return expired, err
+}
+
+// ListExpired returns IDs of expired transactions whose close is not delivered yet.
+func (r *TransactionRepository) ListExpired(ctx context.Context) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT transaction_id FROM ocpp_transactions WHERE expired_at IS NOT NULL`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// ErrTransactionNotFound is returned when transaction is not stored.
+var ErrTransactionNotFound = errors.New("transaction not found")
+
+const transactionColumns = `transaction_id, station_id, connector_id, session_id, meter_start, meter_last, id_tag, user_id, authorized, started_at, updated_at`
+
+// Get returns ongoing transaction by ID.
+func (r *TransactionRepository) Get(ctx context.Context, transactionID string) (*models.Transaction, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+transactionColumns+` FROM ocpp_transactions WHERE transaction_id = $1 AND expired_at IS NULL`, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	transactions, err := scanTransactions(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(transactions) == 0 {
+		return nil, ErrTransactionNotFound
+	}
+	return &transactions[0], nil
+}
+
+// List returns all ongoing transactions.
+func (r *TransactionRepository) List(ctx context.Context) ([]models.Transaction, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+transactionColumns+` FROM ocpp_transactions WHERE expired_at IS NULL`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanTransactions(rows)
+}
+
+// ListByStation returns ongoing transactions of station.
+func (r *TransactionRepository) ListByStation(ctx context.Context, stationID string) ([]models.Transaction, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+transactionColumns+` FROM ocpp_transactions WHERE station_id = $1 AND expired_at IS NULL`, stationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanTransactions(rows)
+}
+
+func scanTransactions(rows *sql.Rows) ([]models.Transaction, error) {
+	var transactions []models.Transaction
+	for rows.Next() {
+		var tx models.Transaction
+		if err := rows.Scan(
+			&tx.ID,
+			&tx.StationID,
+			&tx.ConnectorID,
+			&tx.SessionID,
+			&tx.MeterStart,
+			&tx.MeterLast,
+			&tx.IdTag,
+			&tx.UserID,
+			&tx.Authorized,
+			&tx.StartedAt,
+			&tx.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, tx)
+	}
+	return transactions, rows.Err()
+}
@@ -0,0 +1,124 @@
+// Package security implements OCPP security profiles for station connections.
+package security
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// OCPP security profiles.
+const (
+	// ProfileNone keeps legacy unauthenticated connections (development only).
+	ProfileNone = 0
+	// ProfileBasic is HTTP Basic authentication without TLS.
+	ProfileBasic = 1
+	// ProfileTLSBasic is TLS server with HTTP Basic authentication.
+	ProfileTLSBasic = 2
+	// ProfileTLSClientCert is mutual TLS, station identity taken from certificate CN.
+	ProfileTLSClientCert = 3
+)
+
+var (
+	// ErrUnauthorized is returned when credentials are missing or wrong.
+	ErrUnauthorized = errors.New("security: unauthorized")
+	// ErrTLSRequired is returned when profile requires TLS but connection is plain.
+	ErrTLSRequired = errors.New("security: tls required")
+	// ErrClientCertRequired is returned when profile requires client certificate.
+	ErrClientCertRequired = errors.New("security: client certificate required")
+)
+
+// StationCredentials holds security settings of single station.
+type StationCredentials struct {
+	Profile      int
+	PasswordHash string
+}
+
+// Authenticator checks websocket upgrade requests against station security profiles.
+type Authenticator struct {
+	defaultProfile int
+	stations       map[string]StationCredentials
+	logger         *zap.Logger
+}
+
+// NewAuthenticator builds authenticator; stations missing in map use defaultProfile.
+func NewAuthenticator(defaultProfile int, stations map[string]StationCredentials, logger *zap.Logger) (*Authenticator, error) {
+	if err := validateProfile(defaultProfile); err != nil {
+		return nil, err
+	}
+	for id, creds := range stations {
+		if err := validateProfile(creds.Profile); err != nil {
+			return nil, fmt.Errorf("station %s: %w", id, err)
+		}
+		if (creds.Profile == ProfileBasic || creds.Profile == ProfileTLSBasic) && creds.PasswordHash == "" {
+			return nil, fmt.Errorf("security: station %s: password hash is required for profile %d", id, creds.Profile)
+		}
+	}
+	return &Authenticator{
+		defaultProfile: defaultProfile,
+		stations:       stations,
+		logger:         logger,
+	}, nil
+}
+
+// Authenticate returns station identity of request. stationID is the identity
+// claimed in URL (may be empty for profile 3, where certificate CN is used).
+func (a *Authenticator) Authenticate(r *http.Request, stationID string) (string, error) {
+	certCN := ""
+	if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 && len(r.TLS.VerifiedChains[0]) > 0 {
+		certCN = r.TLS.VerifiedChains[0][0].Subject.CommonName
+	}
+	if stationID == "" {
+		stationID = certCN
+	}
+	if stationID == "" {
+		return "", nil
+	}
+	if certCN != "" && certCN != stationID {
+		a.logger.Warn("client certificate does not match station id",
+			zap.String("station_id", stationID),
+			zap.String("certificate_cn", certCN),
+		)
+		return "", ErrUnauthorized
+	}
+
+	creds, ok := a.stations[stationID]
+	if !ok {
+		creds = StationCredentials{Profile: a.defaultProfile}
+	}
+
+	switch creds.Profile {
+	case ProfileNone:
+		return stationID, nil
+	case ProfileBasic, ProfileTLSBasic:
+		if creds.Profile == ProfileTLSBasic && r.TLS == nil {
+			return "", ErrTLSRequired
+		}
+		username, password, ok := r.BasicAuth()
+		if !ok || username != stationID || creds.PasswordHash == "" {
+			return "", ErrUnauthorized
+		}
+		if err := bcrypt.CompareHashAndPassword([]byte(creds.PasswordHash), []byte(password)); err != nil {
+			return "", ErrUnauthorized
+		}
+		return stationID, nil
+	default:
+		if r.TLS == nil {
+			return "", ErrTLSRequired
+		}
+		if certCN == "" {
+			return "", ErrClientCertRequired
+		}
+		return stationID, nil
+	}
+}
+
+func validateProfile(profile int) error {
+	if profile < ProfileNone || profile > ProfileTLSClientCert {
+		return fmt.Errorf("security: unsupported profile %d", profile)
+	}
+	return nil
+}
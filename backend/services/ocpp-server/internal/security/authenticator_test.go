@@ -0,0 +1,73 @@
+package security
+
+import (
+	"crypto/tls"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestAuthenticateBasic(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth, err := NewAuthenticator(ProfileBasic, map[string]StationCredentials{
+		"CP-OPEN": {Profile: ProfileNone},
+		"CP-TLS":  {Profile: ProfileTLSBasic, PasswordHash: string(hash)},
+		"CP-1":    {Profile: ProfileBasic, PasswordHash: string(hash)},
+	}, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		stationID string
+		username  string
+		password  string
+		tls       bool
+		wantID    string
+		wantErr   error
+	}{
+		{name: "profile 0 without credentials", stationID: "CP-OPEN", wantID: "CP-OPEN"},
+		{name: "profile 1 good password", stationID: "CP-1", username: "CP-1", password: "secret", wantID: "CP-1"},
+		{name: "profile 1 wrong password", stationID: "CP-1", username: "CP-1", password: "wrong", wantErr: ErrUnauthorized},
+		{name: "profile 1 username of other station", stationID: "CP-1", username: "CP-2", password: "secret", wantErr: ErrUnauthorized},
+		{name: "profile 1 without credentials", stationID: "CP-1", wantErr: ErrUnauthorized},
+		{name: "default profile without hash", stationID: "CP-UNKNOWN", username: "CP-UNKNOWN", password: "secret", wantErr: ErrUnauthorized},
+		{name: "profile 2 without TLS", stationID: "CP-TLS", username: "CP-TLS", password: "secret", wantErr: ErrTLSRequired},
+		{name: "profile 2 over TLS", stationID: "CP-TLS", username: "CP-TLS", password: "secret", tls: true, wantID: "CP-TLS"},
+		{name: "profile 2 over TLS wrong password", stationID: "CP-TLS", username: "CP-TLS", password: "wrong", tls: true, wantErr: ErrUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/ocpp/"+tt.stationID, nil)
+			if tt.username != "" {
+				r.SetBasicAuth(tt.username, tt.password)
+			}
+			if tt.tls {
+				r.TLS = &tls.ConnectionState{HandshakeComplete: true}
+			}
+			id, err := auth.Authenticate(r, tt.stationID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if id != tt.wantID {
+				t.Fatalf("station id = %q, want %q", id, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestNewAuthenticatorRejectsMissingHash(t *testing.T) {
+	_, err := NewAuthenticator(ProfileNone, map[string]StationCredentials{
+		"CP-1": {Profile: ProfileBasic},
+	}, zap.NewNop())
+	if err == nil {
+		t.Fatal("expected error for profile 1 station without password hash")
+	}
+}
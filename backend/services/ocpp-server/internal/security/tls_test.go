@@ -0,0 +1,189 @@
+package security
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// testCA is a locally generated certificate authority.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T, name string) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue signs certificate for commonName; server certificates are valid for 127.0.0.1.
+func (ca *testCA) issue(t *testing.T, commonName string, server bool) (certPEM, keyPEM []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	if server {
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+		template.IPAddresses = []net.IP{net.IPv4(127, 0, 0, 1)}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func writeFile(t *testing.T, dir, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestClientCertificateProfile(t *testing.T) {
+	ca := newTestCA(t, "DrivePower Test CA")
+	foreign := newTestCA(t, "Foreign CA")
+
+	dir := t.TempDir()
+	serverCert, serverKey := ca.issue(t, "ocpp-server", true)
+	cfg, err := TLSConfig(
+		writeFile(t, dir, "server.crt", serverCert),
+		writeFile(t, dir, "server.key", serverKey),
+		writeFile(t, dir, "ca.crt", ca.pem),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	auth, err := NewAuthenticator(ProfileTLSClientCert, nil, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	type result struct {
+		id  string
+		err error
+	}
+	results := make(chan result, 1)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := auth.Authenticate(r, r.URL.Query().Get("id"))
+		results <- result{id: id, err: err}
+	}))
+	server.TLS = cfg
+	server.StartTLS()
+	defer server.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	certificate := func(ca *testCA, commonName string) []tls.Certificate {
+		certPEM, keyPEM := ca.issue(t, commonName, false)
+		cert, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return []tls.Certificate{cert}
+	}
+
+	tests := []struct {
+		name          string
+		stationID     string
+		certificates  []tls.Certificate
+		wantHandshake bool
+		wantID        string
+		wantErr       error
+	}{
+		{name: "certificate of station", stationID: "CP-1", certificates: certificate(ca, "CP-1"), wantHandshake: true, wantID: "CP-1"},
+		{name: "identity taken from certificate", certificates: certificate(ca, "CP-1"), wantHandshake: true, wantID: "CP-1"},
+		{name: "without client certificate", stationID: "CP-1", wantHandshake: true, wantErr: ErrClientCertRequired},
+		{name: "certificate of other station", stationID: "CP-1", certificates: certificate(ca, "CP-2"), wantHandshake: true, wantErr: ErrUnauthorized},
+		{name: "certificate of foreign CA", stationID: "CP-1", certificates: certificate(foreign, "CP-1")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientCfg := &tls.Config{RootCAs: roots}
+			if len(tt.certificates) > 0 {
+				// Presented even when server does not list its issuer.
+				clientCfg.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+					return &tt.certificates[0], nil
+				}
+			}
+			client := &http.Client{
+				Timeout:   5 * time.Second,
+				Transport: &http.Transport{TLSClientConfig: clientCfg},
+			}
+			resp, err := client.Get(server.URL + "/ocpp?id=" + tt.stationID)
+			if !tt.wantHandshake {
+				if err == nil {
+					resp.Body.Close()
+					t.Fatal("expected TLS handshake to fail")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+
+			got := <-results
+			if !errors.Is(got.err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", got.err, tt.wantErr)
+			}
+			if got.id != tt.wantID {
+				t.Fatalf("station id = %q, want %q", got.id, tt.wantID)
+			}
+		})
+	}
+}
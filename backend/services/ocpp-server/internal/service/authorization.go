@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"drivepower/backend/services/ocpp-server/internal/clients"
+	"drivepower/backend/services/ocpp-server/internal/ocpp/protocol"
+)
+
+// ErrIdTagNotAllowed is returned when id tag presented through the API is not
+// accepted or is registered to another user.
+var ErrIdTagNotAllowed = errors.New("id tag: not allowed")
+
+// idTagNotAllowed is ErrIdTagNotAllowed with reason shown to API clients.
+type idTagNotAllowed string
+
+func (e idTagNotAllowed) Error() string { return string(e) }
+
+func (e idTagNotAllowed) Is(target error) bool { return target == ErrIdTagNotAllowed }
+
+// Authorization is the resolved decision for id tag presented by station.
+type Authorization struct {
+	Status      string
+	UserID      int64
+	ExpiryDate  *time.Time
+	ParentIdTag string
+}
+
+// Accepted reports whether charging may proceed.
+func (a Authorization) Accepted() bool {
+	return a.Status == protocol.AuthorizationAccepted
+}
+
+// IdTagInfo converts decision to OCPP idTagInfo.
+func (a Authorization) IdTagInfo() protocol.IdTagInfo {
+	return protocol.IdTagInfo{
+		Status:      a.Status,
+		ExpiryDate:  a.ExpiryDate,
+		ParentIdTag: a.ParentIdTag,
+	}
+}
+
+// Authorizer resolves id tags to users through auth-service registry.
+type Authorizer struct {
+	auth    *clients.AuthClient
+	txStore *TransactionStore
+	logger  *zap.Logger
+}
+
+// NewAuthorizer builds Authorizer.
+func NewAuthorizer(auth *clients.AuthClient, txStore *TransactionStore, logger *zap.Logger) *Authorizer {
+	return &Authorizer{
+		auth:    auth,
+		txStore: txStore,
+		logger:  logger,
+	}
+}
+
+// Authorize returns registry decision for id tag. When registry is not
+// configured every tag is accepted without owner, matching legacy behaviour.
+func (a *Authorizer) Authorize(ctx context.Context, idTag string) (Authorization, error) {
+	idTag = strings.TrimSpace(idTag)
+	if idTag == "" {
+		return Authorization{Status: protocol.AuthorizationInvalid}, nil
+	}
+	if a.auth == nil || !a.auth.Enabled() {
+		return Authorization{Status: protocol.AuthorizationAccepted}, nil
+	}
+
+	info, err := a.auth.Authorize(ctx, idTag)
+	if err != nil {
+		return Authorization{}, err
+	}
+	return Authorization{
+		Status:      info.Status,
+		UserID:      info.UserID,
+		ExpiryDate:  info.ExpiryDate,
+		ParentIdTag: info.ParentIdTag,
+	}, nil
+}
+
+// AuthorizeFor resolves id tag used on behalf of user through the API: the tag
+// must be accepted and registered to that user. Without registry tags have no
+// owners and only their status is checked.
+func (a *Authorizer) AuthorizeFor(ctx context.Context, idTag string, userID int64) (Authorization, error) {
+	result, err := a.Authorize(ctx, idTag)
+	if err != nil {
+		return result, err
+	}
+	if !result.Accepted() {
+		return result, idTagNotAllowed("id_tag is " + result.Status)
+	}
+	if a.auth != nil && a.auth.Enabled() && result.UserID != userID {
+		return result, idTagNotAllowed("id_tag is not registered to user")
+	}
+	return result, nil
+}
+
+// AuthorizeStart is Authorize plus ConcurrentTx check for StartTransaction.
+func (a *Authorizer) AuthorizeStart(ctx context.Context, idTag string) (Authorization, error) {
+	result, err := a.Authorize(ctx, idTag)
+	if err != nil {
+		return result, err
+	}
+	if result.Accepted() && a.txStore.HasActiveIdTag(strings.TrimSpace(idTag)) {
+		result.Status = protocol.AuthorizationConcurrentTx
+	}
+	return result, nil
+}
@@ -0,0 +1,379 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"drivepower/backend/services/ocpp-server/internal/models"
+	"drivepower/backend/services/ocpp-server/internal/ocpp"
+	"drivepower/backend/services/ocpp-server/internal/ocpp/protocol"
+)
+
+const (
+	maxDailyDuration   = 24 * 60 * 60
+	maxWeeklyDuration  = 7 * maxDailyDuration
+	maxSchedulePeriods = 48
+)
+
+// ErrInvalidProfile is returned for charging profile that violates OCPP 1.6 rules.
+var ErrInvalidProfile = errors.New("charging profile: invalid")
+
+// invalidProfile is ErrInvalidProfile with reason shown to API clients.
+type invalidProfile string
+
+func (e invalidProfile) Error() string { return string(e) }
+
+func (e invalidProfile) Is(target error) bool { return target == ErrInvalidProfile }
+
+// ChargingProfileBackend stores charging profiles.
+type ChargingProfileBackend interface {
+	Create(ctx context.Context, profile *models.ChargingProfile) error
+	Update(ctx context.Context, profile *models.ChargingProfile) error
+	SetStatus(ctx context.Context, id int64, status, lastError string) error
+	Get(ctx context.Context, id int64) (*models.ChargingProfile, error)
+	List(ctx context.Context, stationID string, statuses ...string) ([]models.ChargingProfile, error)
+	Delete(ctx context.Context, id int64) error
+}
+
+// ChargingProfileInput describes profile to install on connector (0 for the
+// whole station).
+type ChargingProfileInput struct {
+	StationID   string
+	ConnectorID int
+	Profile     protocol.ChargingProfile
+	RequestedBy int64
+}
+
+// ChargingProfiles stores smart charging profiles and installs them on
+// stations with SetChargingProfile. Profiles that could not be delivered are
+// pushed again after the station boots.
+type ChargingProfiles struct {
+	repo     ChargingProfileBackend
+	commands *CommandService
+	txStore  *TransactionStore
+	delay    time.Duration
+	queue    *stationQueue
+	logger   *zap.Logger
+}
+
+// NewChargingProfiles builds service. Undelivered profiles are pushed delay
+// after station booted.
+func NewChargingProfiles(repo ChargingProfileBackend, commands *CommandService, txStore *TransactionStore, delay time.Duration, logger *zap.Logger) *ChargingProfiles {
+	p := &ChargingProfiles{
+		repo:     repo,
+		commands: commands,
+		txStore:  txStore,
+		delay:    delay,
+		logger:   logger,
+	}
+	p.queue = newStationQueue("charging profile resync", p.resync, logger)
+	return p
+}
+
+// StationBooted schedules delivery of profiles not yet installed on station.
+func (p *ChargingProfiles) StationBooted(stationID string) {
+	p.queue.schedule(stationID, p.delay)
+}
+
+// Start runs scheduled deliveries until ctx is done.
+func (p *ChargingProfiles) Start(ctx context.Context) {
+	p.queue.start(ctx)
+}
+
+// Create validates and stores profile, then sends it to station. Profile is
+// kept when the station is unreachable and delivered after its next boot.
+func (p *ChargingProfiles) Create(ctx context.Context, input ChargingProfileInput) (*models.ChargingProfile, error) {
+	input.StationID = strings.TrimSpace(input.StationID)
+	if input.StationID == "" {
+		return nil, invalidProfile("station_id is required")
+	}
+	if err := p.validate(ctx, input.StationID, input.ConnectorID, input.Profile); err != nil {
+		return nil, err
+	}
+
+	profile := &models.ChargingProfile{
+		StationID:   input.StationID,
+		ConnectorID: input.ConnectorID,
+		Profile:     input.Profile,
+	}
+	if err := p.repo.Create(ctx, profile); err != nil {
+		return nil, err
+	}
+	return profile, p.push(ctx, profile, input.RequestedBy)
+}
+
+// Update replaces profile definition and sends it to station under the same
+// chargingProfileId, so that the station replaces the installed one.
+func (p *ChargingProfiles) Update(ctx context.Context, id int64, input ChargingProfileInput) (*models.ChargingProfile, error) {
+	profile, err := p.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if input.StationID != "" && strings.TrimSpace(input.StationID) != profile.StationID {
+		return nil, invalidProfile("station of profile cannot be changed")
+	}
+	if err := p.validate(ctx, profile.StationID, input.ConnectorID, input.Profile); err != nil {
+		return nil, err
+	}
+
+	profile.ConnectorID = input.ConnectorID
+	profile.Profile = input.Profile
+	if err := p.repo.Update(ctx, profile); err != nil {
+		return nil, err
+	}
+	return profile, p.push(ctx, profile, input.RequestedBy)
+}
+
+// Delete clears profile on station and removes it. When station cannot be
+// reached the profile stays in clearing state, is returned and cleared after
+// the station boots; nil profile means it was removed.
+func (p *ChargingProfiles) Delete(ctx context.Context, id int64, requestedBy int64) (*models.ChargingProfile, error) {
+	profile, err := p.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if profile.Status == models.ProfileRejected {
+		// Station never installed it.
+		return nil, p.repo.Delete(ctx, id)
+	}
+	if err := p.repo.SetStatus(ctx, id, models.ProfileClearing, ""); err != nil {
+		return nil, err
+	}
+	profile.Status, profile.LastError = models.ProfileClearing, ""
+	cleared, err := p.clear(ctx, profile, requestedBy)
+	if err != nil || !cleared {
+		return profile, err
+	}
+	return nil, nil
+}
+
+// Get returns profile by ID.
+func (p *ChargingProfiles) Get(ctx context.Context, id int64) (*models.ChargingProfile, error) {
+	return p.repo.Get(ctx, id)
+}
+
+// List returns profiles of station; empty stationID lists all stations.
+func (p *ChargingProfiles) List(ctx context.Context, stationID string) ([]models.ChargingProfile, error) {
+	return p.repo.List(ctx, strings.TrimSpace(stationID))
+}
+
+// CompositeSchedule reads back the schedule the station computed from its
+// installed profiles.
+func (p *ChargingProfiles) CompositeSchedule(ctx context.Context, stationID string, request protocol.GetCompositeScheduleRequest, requestedBy int64) (protocol.GetCompositeScheduleResponse, error) {
+	return p.commands.GetCompositeSchedule(ctx, stationID, request, requestedBy)
+}
+
+// push sends profile and records the outcome in profile. Only storage errors
+// are returned: station failures leave the profile for the next delivery.
+func (p *ChargingProfiles) push(ctx context.Context, profile *models.ChargingProfile, requestedBy int64) error {
+	status, err := p.commands.SetChargingProfile(ctx, profile.StationID, profile.ConnectorID, profile.Profile, requestedBy)
+	var callErr *ocpp.CallError
+	switch {
+	case errors.As(err, &callErr), errors.Is(err, ErrUnsupportedVersion):
+		profile.Status, profile.LastError = models.ProfileFailed, err.Error()
+	case err != nil:
+		// Not connected or no answer: still pending, delivered after boot.
+		profile.Status, profile.LastError = models.ProfilePending, err.Error()
+	case status == protocol.CommandAccepted:
+		profile.Status, profile.LastError = models.ProfileInstalled, ""
+	case status == protocol.CommandRejected:
+		profile.Status, profile.LastError = models.ProfileRejected, ""
+	default:
+		profile.Status, profile.LastError = models.ProfileFailed, "unexpected status "+status
+	}
+	return p.repo.SetStatus(ctx, profile.ID, profile.Status, profile.LastError)
+}
+
+// clear sends ClearChargingProfile for profile and removes it once the
+// station no longer has it. Unknown means the station had nothing to clear.
+func (p *ChargingProfiles) clear(ctx context.Context, profile *models.ChargingProfile, requestedBy int64) (bool, error) {
+	id := int(profile.ID)
+	status, err := p.commands.ClearChargingProfile(ctx, profile.StationID, protocol.ClearChargingProfileRequest{ID: &id}, requestedBy)
+	if err != nil {
+		profile.LastError = err.Error()
+		return false, p.repo.SetStatus(ctx, profile.ID, models.ProfileClearing, profile.LastError)
+	}
+	if status != protocol.ClearProfileAccepted && status != protocol.ClearProfileUnknown {
+		profile.LastError = "unexpected status " + status
+		return false, p.repo.SetStatus(ctx, profile.ID, models.ProfileClearing, profile.LastError)
+	}
+	return true, p.repo.Delete(ctx, profile.ID)
+}
+
+// resync delivers pending and failed profiles of booted station and finishes
+// clearing. TxProfile of a transaction that is over is dropped, as stations
+// discard them when the transaction ends.
+func (p *ChargingProfiles) resync(ctx context.Context, stationID string) error {
+	profiles, err := p.repo.List(ctx, stationID, models.ProfilePending, models.ProfileFailed, models.ProfileClearing)
+	if err != nil {
+		return err
+	}
+	for i := range profiles {
+		profile := &profiles[i]
+		if profile.Status == models.ProfileClearing {
+			if _, err := p.clear(ctx, profile, 0); err != nil {
+				return err
+			}
+			continue
+		}
+		if profile.Profile.ChargingProfilePurpose == protocol.ProfilePurposeTx {
+			if active, err := p.transactionActive(ctx, stationID, profile.ConnectorID, profile.Profile.TransactionID); err != nil {
+				return err
+			} else if !active {
+				if err := p.repo.Delete(ctx, profile.ID); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if err := p.push(ctx, profile, 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validate checks profile against OCPP 1.6 smart charging rules; profiles are
+// accepted for OCPP 1.6 stations only.
+func (p *ChargingProfiles) validate(ctx context.Context, stationID string, connectorID int, profile protocol.ChargingProfile) error {
+	if err := validateChargingProfile(connectorID, profile); err != nil {
+		return err
+	}
+	if err := p.commands.requireOCPP16(ctx, stationID, protocol.ActionSetChargingProfile); err != nil {
+		return err
+	}
+	if profile.ChargingProfilePurpose != protocol.ProfilePurposeTx {
+		return nil
+	}
+	active, err := p.transactionActive(ctx, stationID, connectorID, profile.TransactionID)
+	if err != nil {
+		return err
+	}
+	if !active {
+		return invalidProfile("transactionId must reference active transaction on the connector")
+	}
+	return nil
+}
+
+func (p *ChargingProfiles) transactionActive(ctx context.Context, stationID string, connectorID int, transactionID *int) (bool, error) {
+	if transactionID == nil {
+		return false, nil
+	}
+	tx, ok, err := p.txStore.Lookup(ctx, strconv.Itoa(*transactionID))
+	if err != nil {
+		return false, err
+	}
+	return ok && tx.StationID == stationID && tx.ConnectorID == connectorID, nil
+}
+
+func validateChargingProfile(connectorID int, profile protocol.ChargingProfile) error {
+	if connectorID < 0 {
+		return invalidProfile("connector_id must not be negative")
+	}
+	if profile.StackLevel < 0 {
+		return invalidProfile("stackLevel must not be negative")
+	}
+
+	switch profile.ChargingProfilePurpose {
+	case protocol.ProfilePurposeChargePointMax:
+		if connectorID != 0 {
+			return invalidProfile("ChargePointMaxProfile can only be set on connector 0")
+		}
+	case protocol.ProfilePurposeTxDefault:
+	case protocol.ProfilePurposeTx:
+		if connectorID == 0 {
+			return invalidProfile("TxProfile requires connector_id greater than 0")
+		}
+		if profile.TransactionID == nil {
+			return invalidProfile("TxProfile requires transactionId")
+		}
+	default:
+		return invalidProfile("chargingProfilePurpose must be ChargePointMaxProfile, TxDefaultProfile or TxProfile")
+	}
+	if profile.TransactionID != nil && profile.ChargingProfilePurpose != protocol.ProfilePurposeTx {
+		return invalidProfile("transactionId is only allowed in TxProfile")
+	}
+
+	schedule := profile.ChargingSchedule
+	switch profile.ChargingProfileKind {
+	case protocol.ProfileKindAbsolute:
+		if schedule.StartSchedule == nil {
+			return invalidProfile("Absolute profile requires chargingSchedule.startSchedule")
+		}
+	case protocol.ProfileKindRecurring:
+		if profile.RecurrencyKind != protocol.RecurrencyDaily && profile.RecurrencyKind != protocol.RecurrencyWeekly {
+			return invalidProfile("Recurring profile requires recurrencyKind Daily or Weekly")
+		}
+		if schedule.StartSchedule == nil {
+			return invalidProfile("Recurring profile requires chargingSchedule.startSchedule")
+		}
+	case protocol.ProfileKindRelative:
+		if schedule.StartSchedule != nil {
+			return invalidProfile("Relative profile must not have chargingSchedule.startSchedule")
+		}
+	default:
+		return invalidProfile("chargingProfileKind must be Absolute, Recurring or Relative")
+	}
+	if profile.RecurrencyKind != "" && profile.ChargingProfileKind != protocol.ProfileKindRecurring {
+		return invalidProfile("recurrencyKind is only allowed in Recurring profile")
+	}
+	if profile.ValidFrom != nil && profile.ValidTo != nil && !profile.ValidFrom.Before(*profile.ValidTo) {
+		return invalidProfile("validFrom must be before validTo")
+	}
+	return validateChargingSchedule(profile, schedule)
+}
+
+func validateChargingSchedule(profile protocol.ChargingProfile, schedule protocol.ChargingSchedule) error {
+	if schedule.ChargingRateUnit != protocol.ChargingRateAmperes && schedule.ChargingRateUnit != protocol.ChargingRateWatts {
+		return invalidProfile("chargingRateUnit must be A or W")
+	}
+	if schedule.Duration != nil {
+		duration := *schedule.Duration
+		switch {
+		case duration <= 0:
+			return invalidProfile("chargingSchedule.duration must be positive")
+		case profile.RecurrencyKind == protocol.RecurrencyDaily && duration > maxDailyDuration:
+			return invalidProfile("Daily profile duration must not exceed 86400 seconds")
+		case profile.RecurrencyKind == protocol.RecurrencyWeekly && duration > maxWeeklyDuration:
+			return invalidProfile("Weekly profile duration must not exceed 604800 seconds")
+		}
+	}
+	if schedule.MinChargingRate != nil && (*schedule.MinChargingRate < 0 || !oneDecimal(*schedule.MinChargingRate)) {
+		return invalidProfile("minChargingRate must be non-negative with at most one decimal")
+	}
+
+	periods := schedule.ChargingSchedulePeriod
+	if len(periods) == 0 || len(periods) > maxSchedulePeriods {
+		return invalidProfile(fmt.Sprintf("chargingSchedulePeriod must have 1 to %d periods", maxSchedulePeriods))
+	}
+	if periods[0].StartPeriod != 0 {
+		return invalidProfile("first chargingSchedulePeriod must have startPeriod 0")
+	}
+	for i, period := range periods {
+		if i > 0 && period.StartPeriod <= periods[i-1].StartPeriod {
+			return invalidProfile("startPeriod values must be strictly increasing")
+		}
+		if schedule.Duration != nil && period.StartPeriod >= *schedule.Duration {
+			return invalidProfile("startPeriod must be within chargingSchedule.duration")
+		}
+		if period.Limit < 0 || !oneDecimal(period.Limit) {
+			return invalidProfile("limit must be non-negative with at most one decimal")
+		}
+		if period.NumberPhases != nil && (*period.NumberPhases < 1 || *period.NumberPhases > 3) {
+			return invalidProfile("numberPhases must be 1, 2 or 3")
+		}
+	}
+	return nil
+}
+
+// oneDecimal reports whether v has at most one decimal, as OCPP requires for limits.
+func oneDecimal(v float64) bool {
+	return math.Abs(v*10-math.Round(v*10)) < 1e-6
+}
@@ -0,0 +1,620 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"drivepower/backend/services/ocpp-server/internal/models"
+	"drivepower/backend/services/ocpp-server/internal/ocpp/protocol"
+	"drivepower/backend/services/ocpp-server/internal/ocpp/protocol/v201"
+)
+
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+	auditWriteTimeout = 5 * time.Second
+)
+
+var (
+	// ErrTransactionNotFound is returned when transaction is not tracked by the server.
+	ErrTransactionNotFound = errors.New("command: transaction not found")
+	// ErrInvalidCommand is returned for incomplete command input.
+	ErrInvalidCommand = errors.New("command: invalid input")
+	// ErrNotTransactionOwner is returned when user stops transaction of another user.
+	ErrNotTransactionOwner = errors.New("command: transaction belongs to another user")
+	// ErrOwnerUnknown is returned when transaction has no resolved owner (id tag
+	// was not linked to a user), so no user can prove it is theirs.
+	ErrOwnerUnknown = errors.New("command: transaction owner is unknown")
+	// ErrUnsupportedVersion is returned for command station's OCPP version does not support.
+	ErrUnsupportedVersion = errors.New("command: not supported by station OCPP version")
+)
+
+// invalidInput is ErrInvalidCommand with reason shown to API clients.
+type invalidInput string
+
+func (e invalidInput) Error() string { return string(e) }
+
+func (e invalidInput) Is(target error) bool { return target == ErrInvalidCommand }
+
+// unsupportedVersion is ErrUnsupportedVersion with reason shown to API clients.
+type unsupportedVersion string
+
+func (e unsupportedVersion) Error() string { return string(e) }
+
+func (e unsupportedVersion) Is(target error) bool { return target == ErrUnsupportedVersion }
+
+// RemoteStartInput describes remote start command; id tag must be registered
+// to the requesting user.
+type RemoteStartInput struct {
+	StationID   string
+	ConnectorID int
+	IdTag       string
+	RequestedBy int64
+}
+
+// ResetInput describes Reset command.
+type ResetInput struct {
+	StationID   string
+	Type        string
+	RequestedBy int64
+}
+
+// ChangeAvailabilityInput describes ChangeAvailability command; connector 0
+// addresses the whole station.
+type ChangeAvailabilityInput struct {
+	StationID   string
+	ConnectorID int
+	Type        string
+	RequestedBy int64
+}
+
+// UnlockConnectorInput describes UnlockConnector command.
+type UnlockConnectorInput struct {
+	StationID   string
+	ConnectorID int
+	RequestedBy int64
+}
+
+// TriggerMessageInput describes TriggerMessage command; nil ConnectorID
+// addresses the whole station.
+type TriggerMessageInput struct {
+	StationID        string
+	RequestedMessage string
+	ConnectorID      *int
+	RequestedBy      int64
+}
+
+// CommandAuditBackend stores commands sent to stations.
+type CommandAuditBackend interface {
+	Insert(ctx context.Context, entry *models.CommandAudit) error
+	List(ctx context.Context, stationID string, limit int) ([]models.CommandAudit, error)
+}
+
+// StationCaller sends CALL to station and waits for its answer; implemented by
+// ocpp.Caller for local stations and cluster.Node for the whole cluster.
+type StationCaller interface {
+	Call(ctx context.Context, stationID, action string, request, response interface{}) error
+}
+
+// CommandService sends CSMS-initiated commands to stations and records every
+// command with the station answer in audit.
+type CommandService struct {
+	caller     StationCaller
+	txStore    *TransactionStore
+	state      *StationState
+	stations   StationBackend
+	authorizer *Authorizer
+	audit      CommandAuditBackend
+	logger     *zap.Logger
+}
+
+// NewCommandService builds service. Stations registry tells which OCPP
+// version station speaks.
+func NewCommandService(caller StationCaller, txStore *TransactionStore, state *StationState, stations StationBackend, authorizer *Authorizer, audit CommandAuditBackend, logger *zap.Logger) *CommandService {
+	return &CommandService{
+		caller:     caller,
+		txStore:    txStore,
+		state:      state,
+		stations:   stations,
+		authorizer: authorizer,
+		audit:      audit,
+		logger:     logger,
+	}
+}
+
+// RemoteStart sends RemoteStartTransaction (RequestStartTransaction to OCPP
+// 2.0.1 station, connector_id is EVSE there) and returns station status.
+func (s *CommandService) RemoteStart(ctx context.Context, input RemoteStartInput) (string, error) {
+	input.StationID = strings.TrimSpace(input.StationID)
+	input.IdTag = strings.TrimSpace(input.IdTag)
+	if input.StationID == "" || input.IdTag == "" || input.ConnectorID < 0 {
+		return "", invalidInput("station_id and id_tag are required")
+	}
+	if _, err := s.authorizer.AuthorizeFor(ctx, input.IdTag, input.RequestedBy); err != nil {
+		return "", err
+	}
+	isV201, err := s.isV201(ctx, input.StationID)
+	if err != nil {
+		return "", err
+	}
+
+	var status string
+	if isV201 {
+		request := v201.RequestStartTransactionRequest{
+			IdToken:       v201.IdToken{IdToken: input.IdTag, Type: v201.IdTokenCentral},
+			RemoteStartID: remoteStartID(),
+		}
+		if input.ConnectorID > 0 {
+			evseID := input.ConnectorID
+			request.EvseID = &evseID
+		}
+		var resp v201.RequestStartTransactionResponse
+		err = s.call(ctx, input.RequestedBy, input.StationID, v201.ActionRequestStartTransaction, request, &resp)
+		status = resp.Status
+	} else {
+		var resp protocol.RemoteStartTransactionResponse
+		err = s.call(ctx, input.RequestedBy, input.StationID, protocol.ActionRemoteStartTransaction, protocol.RemoteStartTransactionRequest{
+			ConnectorID: input.ConnectorID,
+			IdTag:       input.IdTag,
+		}, &resp)
+		status = resp.Status
+	}
+	if err != nil {
+		return "", err
+	}
+
+	s.logger.Info("remote start answered",
+		zap.String("station_id", input.StationID),
+		zap.Int("connector_id", input.ConnectorID),
+		zap.String("status", status),
+	)
+	return status, nil
+}
+
+// RemoteStop looks up station of transaction and sends RemoteStopTransaction
+// (RequestStopTransaction to OCPP 2.0.1 station). Only the user the
+// transaction is charged to may stop it; transaction without resolved owner
+// cannot be stopped remotely by any user.
+func (s *CommandService) RemoteStop(ctx context.Context, transactionID string, requestedBy int64) (string, error) {
+	transactionID = strings.TrimSpace(transactionID)
+	if transactionID == "" {
+		return "", invalidInput("transaction_id is required")
+	}
+	txCtx, ok, err := s.txStore.Lookup(ctx, transactionID)
+	if err != nil {
+		return "", err
+	}
+	if !ok || txCtx.StationID == "" {
+		return "", ErrTransactionNotFound
+	}
+	if txCtx.UserID == 0 {
+		return "", ErrOwnerUnknown
+	}
+	if txCtx.UserID != requestedBy {
+		return "", ErrNotTransactionOwner
+	}
+
+	isV201, err := s.isV201(ctx, txCtx.StationID)
+	if err != nil {
+		return "", err
+	}
+
+	var status string
+	if isV201 {
+		var resp v201.RequestStopTransactionResponse
+		err = s.call(ctx, requestedBy, txCtx.StationID, v201.ActionRequestStopTransaction, v201.RequestStopTransactionRequest{
+			TransactionID: transactionID,
+		}, &resp)
+		status = resp.Status
+	} else {
+		txID, convErr := strconv.Atoi(transactionID)
+		if convErr != nil {
+			return "", invalidInput("transaction_id must be a number")
+		}
+		var resp protocol.RemoteStopTransactionResponse
+		err = s.call(ctx, requestedBy, txCtx.StationID, protocol.ActionRemoteStopTransaction, protocol.RemoteStopTransactionRequest{
+			TransactionID: txID,
+		}, &resp)
+		status = resp.Status
+	}
+	if err != nil {
+		return "", err
+	}
+
+	s.logger.Info("remote stop answered",
+		zap.String("station_id", txCtx.StationID),
+		zap.String("transaction_id", transactionID),
+		zap.String("status", status),
+	)
+	return status, nil
+}
+
+// Reset sends Reset and returns station status.
+func (s *CommandService) Reset(ctx context.Context, input ResetInput) (string, error) {
+	input.StationID = strings.TrimSpace(input.StationID)
+	if input.StationID == "" {
+		return "", invalidInput("station_id is required")
+	}
+	if input.Type != protocol.ResetHard && input.Type != protocol.ResetSoft {
+		return "", invalidInput("type must be Hard or Soft")
+	}
+	if err := s.requireOCPP16(ctx, input.StationID, protocol.ActionReset); err != nil {
+		return "", err
+	}
+
+	var resp protocol.ResetResponse
+	if err := s.call(ctx, input.RequestedBy, input.StationID, protocol.ActionReset, protocol.ResetRequest{Type: input.Type}, &resp); err != nil {
+		return "", err
+	}
+	s.logger.Info("reset answered",
+		zap.String("station_id", input.StationID),
+		zap.String("type", input.Type),
+		zap.String("status", resp.Status),
+	)
+	return resp.Status, nil
+}
+
+// ChangeAvailability sends ChangeAvailability and returns station status. A
+// Scheduled answer is kept in station state until the connector reports it.
+func (s *CommandService) ChangeAvailability(ctx context.Context, input ChangeAvailabilityInput) (string, error) {
+	input.StationID = strings.TrimSpace(input.StationID)
+	if input.StationID == "" || input.ConnectorID < 0 {
+		return "", invalidInput("station_id is required and connector_id must not be negative")
+	}
+	if input.Type != protocol.AvailabilityInoperative && input.Type != protocol.AvailabilityOperative {
+		return "", invalidInput("type must be Inoperative or Operative")
+	}
+	if err := s.requireOCPP16(ctx, input.StationID, protocol.ActionChangeAvailability); err != nil {
+		return "", err
+	}
+
+	var resp protocol.ChangeAvailabilityResponse
+	err := s.call(ctx, input.RequestedBy, input.StationID, protocol.ActionChangeAvailability, protocol.ChangeAvailabilityRequest{
+		ConnectorID: input.ConnectorID,
+		Type:        input.Type,
+	}, &resp)
+	if err != nil {
+		return "", err
+	}
+
+	switch resp.Status {
+	case protocol.CommandScheduled:
+		s.state.ScheduleAvailability(input.StationID, input.ConnectorID, input.Type)
+	case protocol.CommandAccepted:
+		s.state.ScheduleAvailability(input.StationID, input.ConnectorID, "")
+	}
+	s.logger.Info("change availability answered",
+		zap.String("station_id", input.StationID),
+		zap.Int("connector_id", input.ConnectorID),
+		zap.String("type", input.Type),
+		zap.String("status", resp.Status),
+	)
+	return resp.Status, nil
+}
+
+// UnlockConnector sends UnlockConnector and returns station status.
+func (s *CommandService) UnlockConnector(ctx context.Context, input UnlockConnectorInput) (string, error) {
+	input.StationID = strings.TrimSpace(input.StationID)
+	if input.StationID == "" || input.ConnectorID <= 0 {
+		return "", invalidInput("station_id and positive connector_id are required")
+	}
+	if err := s.requireOCPP16(ctx, input.StationID, protocol.ActionUnlockConnector); err != nil {
+		return "", err
+	}
+
+	var resp protocol.UnlockConnectorResponse
+	err := s.call(ctx, input.RequestedBy, input.StationID, protocol.ActionUnlockConnector, protocol.UnlockConnectorRequest{
+		ConnectorID: input.ConnectorID,
+	}, &resp)
+	if err != nil {
+		return "", err
+	}
+	s.logger.Info("unlock connector answered",
+		zap.String("station_id", input.StationID),
+		zap.Int("connector_id", input.ConnectorID),
+		zap.String("status", resp.Status),
+	)
+	return resp.Status, nil
+}
+
+// TriggerMessage sends TriggerMessage and returns station status.
+func (s *CommandService) TriggerMessage(ctx context.Context, input TriggerMessageInput) (string, error) {
+	input.StationID = strings.TrimSpace(input.StationID)
+	if input.StationID == "" {
+		return "", invalidInput("station_id is required")
+	}
+	if !containsString(protocol.TriggerableMessages, input.RequestedMessage) {
+		return "", invalidInput("requested_message must be one of " + strings.Join(protocol.TriggerableMessages, ", "))
+	}
+	if input.ConnectorID != nil && *input.ConnectorID <= 0 {
+		return "", invalidInput("connector_id must be positive")
+	}
+	if err := s.requireOCPP16(ctx, input.StationID, protocol.ActionTriggerMessage); err != nil {
+		return "", err
+	}
+
+	var resp protocol.TriggerMessageResponse
+	err := s.call(ctx, input.RequestedBy, input.StationID, protocol.ActionTriggerMessage, protocol.TriggerMessageRequest{
+		RequestedMessage: input.RequestedMessage,
+		ConnectorID:      input.ConnectorID,
+	}, &resp)
+	if err != nil {
+		return "", err
+	}
+	s.logger.Info("trigger message answered",
+		zap.String("station_id", input.StationID),
+		zap.String("requested_message", input.RequestedMessage),
+		zap.String("status", resp.Status),
+	)
+	return resp.Status, nil
+}
+
+// ClearCache sends ClearCache and returns station status. Request and
+// response are the same in OCPP 1.6 and 2.0.1.
+func (s *CommandService) ClearCache(ctx context.Context, stationID string, requestedBy int64) (string, error) {
+	stationID = strings.TrimSpace(stationID)
+	if stationID == "" {
+		return "", invalidInput("station_id is required")
+	}
+
+	var resp protocol.ClearCacheResponse
+	if err := s.call(ctx, requestedBy, stationID, protocol.ActionClearCache, protocol.ClearCacheRequest{}, &resp); err != nil {
+		return "", err
+	}
+	s.logger.Info("clear cache answered", zap.String("station_id", stationID), zap.String("status", resp.Status))
+	return resp.Status, nil
+}
+
+// GetConfiguration sends GetConfiguration for keys (all keys when empty).
+func (s *CommandService) GetConfiguration(ctx context.Context, stationID string, keys []string, requestedBy int64) (protocol.GetConfigurationResponse, error) {
+	var resp protocol.GetConfigurationResponse
+	stationID = strings.TrimSpace(stationID)
+	if stationID == "" {
+		return resp, invalidInput("station_id is required")
+	}
+	if err := s.requireOCPP16(ctx, stationID, protocol.ActionGetConfiguration); err != nil {
+		return resp, err
+	}
+	err := s.call(ctx, requestedBy, stationID, protocol.ActionGetConfiguration, protocol.GetConfigurationRequest{Key: keys}, &resp)
+	return resp, err
+}
+
+// ChangeConfiguration sends ChangeConfiguration and returns station status.
+func (s *CommandService) ChangeConfiguration(ctx context.Context, stationID, key, value string, requestedBy int64) (string, error) {
+	stationID = strings.TrimSpace(stationID)
+	if stationID == "" || key == "" {
+		return "", invalidInput("station_id and key are required")
+	}
+	if err := s.requireOCPP16(ctx, stationID, protocol.ActionChangeConfiguration); err != nil {
+		return "", err
+	}
+
+	var resp protocol.ChangeConfigurationResponse
+	err := s.call(ctx, requestedBy, stationID, protocol.ActionChangeConfiguration, protocol.ChangeConfigurationRequest{
+		Key:   key,
+		Value: value,
+	}, &resp)
+	if err != nil {
+		return "", err
+	}
+	s.logger.Info("change configuration answered",
+		zap.String("station_id", stationID),
+		zap.String("key", key),
+		zap.String("status", resp.Status),
+	)
+	return resp.Status, nil
+}
+
+// SetChargingProfile sends SetChargingProfile and returns station status.
+// Profile is expected to be validated by the caller.
+func (s *CommandService) SetChargingProfile(ctx context.Context, stationID string, connectorID int, profile protocol.ChargingProfile, requestedBy int64) (string, error) {
+	if err := s.requireOCPP16(ctx, stationID, protocol.ActionSetChargingProfile); err != nil {
+		return "", err
+	}
+
+	var resp protocol.SetChargingProfileResponse
+	err := s.call(ctx, requestedBy, stationID, protocol.ActionSetChargingProfile, protocol.SetChargingProfileRequest{
+		ConnectorID:        connectorID,
+		CsChargingProfiles: profile,
+	}, &resp)
+	if err != nil {
+		return "", err
+	}
+	s.logger.Info("set charging profile answered",
+		zap.String("station_id", stationID),
+		zap.Int("connector_id", connectorID),
+		zap.Int("charging_profile_id", profile.ChargingProfileID),
+		zap.String("status", resp.Status),
+	)
+	return resp.Status, nil
+}
+
+// ClearChargingProfile sends ClearChargingProfile and returns station status.
+func (s *CommandService) ClearChargingProfile(ctx context.Context, stationID string, request protocol.ClearChargingProfileRequest, requestedBy int64) (string, error) {
+	if err := s.requireOCPP16(ctx, stationID, protocol.ActionClearChargingProfile); err != nil {
+		return "", err
+	}
+
+	var resp protocol.ClearChargingProfileResponse
+	if err := s.call(ctx, requestedBy, stationID, protocol.ActionClearChargingProfile, request, &resp); err != nil {
+		return "", err
+	}
+	s.logger.Info("clear charging profile answered", zap.String("station_id", stationID), zap.String("status", resp.Status))
+	return resp.Status, nil
+}
+
+// GetCompositeSchedule asks station for schedule of connector (0 for the
+// grid connection of the whole station) for the next duration seconds.
+func (s *CommandService) GetCompositeSchedule(ctx context.Context, stationID string, request protocol.GetCompositeScheduleRequest, requestedBy int64) (protocol.GetCompositeScheduleResponse, error) {
+	var resp protocol.GetCompositeScheduleResponse
+	stationID = strings.TrimSpace(stationID)
+	if stationID == "" || request.ConnectorID < 0 || request.Duration <= 0 {
+		return resp, invalidInput("station_id and positive duration are required and connector_id must not be negative")
+	}
+	if request.ChargingRateUnit != "" && request.ChargingRateUnit != protocol.ChargingRateAmperes && request.ChargingRateUnit != protocol.ChargingRateWatts {
+		return resp, invalidInput("charging_rate_unit must be A or W")
+	}
+	if err := s.requireOCPP16(ctx, stationID, protocol.ActionGetCompositeSchedule); err != nil {
+		return resp, err
+	}
+	err := s.call(ctx, requestedBy, stationID, protocol.ActionGetCompositeSchedule, request, &resp)
+	return resp, err
+}
+
+// UpdateFirmware sends UpdateFirmware. The answer carries no status: progress
+// arrives later with FirmwareStatusNotification.
+func (s *CommandService) UpdateFirmware(ctx context.Context, stationID string, request protocol.UpdateFirmwareRequest, requestedBy int64) error {
+	var resp protocol.UpdateFirmwareResponse
+	if err := s.call(ctx, requestedBy, stationID, protocol.ActionUpdateFirmware, request, &resp); err != nil {
+		return err
+	}
+	s.logger.Info("update firmware accepted", zap.String("station_id", stationID), zap.String("location", request.Location))
+	return nil
+}
+
+// GetDiagnostics sends GetDiagnostics to OCPP 1.6 station and returns name of
+// file it will upload, empty when it has no diagnostics.
+func (s *CommandService) GetDiagnostics(ctx context.Context, stationID string, request protocol.GetDiagnosticsRequest, requestedBy int64) (string, error) {
+	var resp protocol.GetDiagnosticsResponse
+	if err := s.call(ctx, requestedBy, stationID, protocol.ActionGetDiagnostics, request, &resp); err != nil {
+		return "", err
+	}
+	s.logger.Info("get diagnostics answered", zap.String("station_id", stationID), zap.String("file_name", resp.FileName))
+	return resp.FileName, nil
+}
+
+// GetLog sends GetLog to OCPP 2.0.1 station.
+func (s *CommandService) GetLog(ctx context.Context, stationID string, request v201.GetLogRequest, requestedBy int64) (v201.GetLogResponse, error) {
+	var resp v201.GetLogResponse
+	if err := s.call(ctx, requestedBy, stationID, v201.ActionGetLog, request, &resp); err != nil {
+		return resp, err
+	}
+	s.logger.Info("get log answered",
+		zap.String("station_id", stationID),
+		zap.Int("request_id", request.RequestID),
+		zap.String("status", resp.Status),
+	)
+	return resp, nil
+}
+
+// ReserveNow sends ReserveNow and returns station status.
+func (s *CommandService) ReserveNow(ctx context.Context, stationID string, request protocol.ReserveNowRequest, requestedBy int64) (string, error) {
+	var resp protocol.ReserveNowResponse
+	if err := s.call(ctx, requestedBy, stationID, protocol.ActionReserveNow, request, &resp); err != nil {
+		return "", err
+	}
+	s.logger.Info("reserve now answered",
+		zap.String("station_id", stationID),
+		zap.Int("connector_id", request.ConnectorID),
+		zap.Int("reservation_id", request.ReservationID),
+		zap.String("status", resp.Status),
+	)
+	return resp.Status, nil
+}
+
+// CancelReservation sends CancelReservation and returns station status.
+func (s *CommandService) CancelReservation(ctx context.Context, stationID string, reservationID int, requestedBy int64) (string, error) {
+	var resp protocol.CancelReservationResponse
+	err := s.call(ctx, requestedBy, stationID, protocol.ActionCancelReservation, protocol.CancelReservationRequest{
+		ReservationID: reservationID,
+	}, &resp)
+	if err != nil {
+		return "", err
+	}
+	s.logger.Info("cancel reservation answered",
+		zap.String("station_id", stationID),
+		zap.Int("reservation_id", reservationID),
+		zap.String("status", resp.Status),
+	)
+	return resp.Status, nil
+}
+
+// Audit returns recorded commands, newest first; empty stationID lists all stations.
+func (s *CommandService) Audit(ctx context.Context, stationID string, limit int) ([]models.CommandAudit, error) {
+	if limit <= 0 {
+		limit = defaultAuditLimit
+	}
+	if limit > maxAuditLimit {
+		limit = maxAuditLimit
+	}
+	return s.audit.List(ctx, strings.TrimSpace(stationID), limit)
+}
+
+// call sends command and records it with the station answer. Audit failures
+// are logged and do not fail the command that already reached the station.
+func (s *CommandService) call(ctx context.Context, requestedBy int64, stationID, action string, request, response interface{}) error {
+	entry := models.CommandAudit{
+		StationID:   stationID,
+		Action:      action,
+		RequestedBy: requestedBy,
+		CreatedAt:   time.Now().UTC(),
+	}
+	err := s.caller.Call(ctx, stationID, action, request, response)
+	entry.CompletedAt = time.Now().UTC()
+	entry.Request, _ = json.Marshal(request)
+	if err != nil {
+		entry.Error = err.Error()
+	} else if payload, marshalErr := json.Marshal(response); marshalErr == nil {
+		entry.Response = payload
+		var answer struct {
+			Status string `json:"status"`
+		}
+		_ = json.Unmarshal(payload, &answer)
+		entry.Status = answer.Status
+	}
+
+	auditCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), auditWriteTimeout)
+	defer cancel()
+	if auditErr := s.audit.Insert(auditCtx, &entry); auditErr != nil {
+		s.logger.Error("failed to record command audit",
+			zap.String("station_id", stationID),
+			zap.String("action", action),
+			zap.Error(auditErr),
+		)
+	}
+	return err
+}
+
+// isV201 reports whether station last booted with OCPP 2.0.1.
+func (s *CommandService) isV201(ctx context.Context, stationID string) (bool, error) {
+	station, err := s.stations.GetByID(ctx, stationID)
+	if err != nil {
+		return false, err
+	}
+	return station.OCPPVersion == v201.Subprotocol, nil
+}
+
+// requireOCPP16 rejects command whose payload exists in OCPP 1.6 only when
+// station booted with 2.0.1.
+func (s *CommandService) requireOCPP16(ctx context.Context, stationID, action string) error {
+	isV201, err := s.isV201(ctx, stationID)
+	if err != nil {
+		return err
+	}
+	if isV201 {
+		return unsupportedVersion(action + " is supported for OCPP 1.6 stations only")
+	}
+	return nil
+}
+
+// remoteStartID returns remoteStartId for RequestStartTransaction; CSMS does
+// not correlate it, it only has to differ between requests.
+func remoteStartID() int {
+	return int(time.Now().UnixMilli() % math.MaxInt32)
+}
+
+func containsString(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
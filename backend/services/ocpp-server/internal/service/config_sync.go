@@ -0,0 +1,307 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"drivepower/backend/services/ocpp-server/internal/models"
+	"drivepower/backend/services/ocpp-server/internal/ocpp"
+	"drivepower/backend/services/ocpp-server/internal/ocpp/protocol"
+	"drivepower/backend/services/ocpp-server/internal/repository"
+)
+
+const (
+	maxConfigKeyLen   = 50
+	maxConfigValueLen = 500
+)
+
+// ErrInvalidConfig is returned for malformed desired configuration.
+var ErrInvalidConfig = errors.New("config: invalid desired configuration")
+
+// ConfigBackend stores desired configuration and sync results.
+type ConfigBackend interface {
+	ReplaceDesired(ctx context.Context, scope, target string, keys map[string]string) error
+	Desired(ctx context.Context, scope, target string) (map[string]string, error)
+	Resolve(ctx context.Context, stationID string) (map[string]string, error)
+	SetGroup(ctx context.Context, stationID, group string) error
+	Group(ctx context.Context, stationID string) (string, error)
+	GroupMembers(ctx context.Context, group string) ([]string, error)
+	ConfiguredStations(ctx context.Context) ([]string, error)
+	SaveStates(ctx context.Context, stationID string, states []models.ConfigKeyState) error
+	States(ctx context.Context, stationID string) ([]models.ConfigKeyState, error)
+}
+
+// ConfigSync keeps configuration keys of OCPP 1.6 stations equal to the
+// desired configuration: after BootNotification it reads keys with
+// GetConfiguration and pushes differences with ChangeConfiguration.
+type ConfigSync struct {
+	repo     ConfigBackend
+	commands *CommandService
+	delay    time.Duration
+	queue    *stationQueue
+	logger   *zap.Logger
+}
+
+// NewConfigSync builds sync. Station is synced delay after it booted, so that
+// BootNotification answer reaches it first.
+func NewConfigSync(repo ConfigBackend, commands *CommandService, delay time.Duration, logger *zap.Logger) *ConfigSync {
+	c := &ConfigSync{
+		repo:     repo,
+		commands: commands,
+		delay:    delay,
+		logger:   logger,
+	}
+	c.queue = newStationQueue("configuration sync", func(ctx context.Context, stationID string) error {
+		_, err := c.Sync(ctx, stationID)
+		if errors.Is(err, ErrUnsupportedVersion) {
+			// OCPP 2.0.1 members of a group keep their configuration.
+			return nil
+		}
+		return err
+	}, logger)
+	return c
+}
+
+// StationBooted schedules sync of station accepted by BootNotification.
+func (c *ConfigSync) StationBooted(stationID string) {
+	c.queue.schedule(stationID, c.delay)
+}
+
+// Start runs scheduled syncs until ctx is done.
+func (c *ConfigSync) Start(ctx context.Context) {
+	c.queue.start(ctx)
+}
+
+// Sync reads configuration of station, pushes keys that differ from desired
+// ones and stores the result. Station without desired keys is left untouched.
+// Only OCPP 1.6 stations can be synced.
+func (c *ConfigSync) Sync(ctx context.Context, stationID string) ([]models.ConfigKeyState, error) {
+	if err := c.commands.requireOCPP16(ctx, stationID, protocol.ActionGetConfiguration); err != nil {
+		return nil, err
+	}
+	desired, err := c.repo.Resolve(ctx, stationID)
+	if err != nil {
+		return nil, err
+	}
+	if len(desired) == 0 {
+		return nil, c.repo.SaveStates(ctx, stationID, nil)
+	}
+
+	current, err := c.commands.GetConfiguration(ctx, stationID, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	reported := make(map[string]protocol.KeyValue, len(current.ConfigurationKey))
+	for _, kv := range current.ConfigurationKey {
+		reported[kv.Key] = kv
+	}
+
+	keys := make([]string, 0, len(desired))
+	for key := range desired {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	states := make([]models.ConfigKeyState, 0, len(keys))
+	changed := 0
+	for _, key := range keys {
+		state := models.ConfigKeyState{StationID: stationID, Key: key, Desired: desired[key], SyncedAt: time.Now().UTC()}
+		kv, ok := reported[key]
+		switch {
+		case !ok:
+			state.Status = models.ConfigNotSupported
+		case kv.Value != nil && sameConfigValue(*kv.Value, state.Desired):
+			state.Reported, state.Readonly = kv.Value, kv.Readonly
+			state.Status = models.ConfigInSync
+		case kv.Readonly:
+			state.Reported, state.Readonly = kv.Value, true
+			state.Status = models.ConfigReadOnly
+		default:
+			state.Reported = kv.Value
+			if err := c.change(ctx, &state); err != nil {
+				return nil, err
+			}
+			changed++
+		}
+		states = append(states, state)
+	}
+
+	if err := c.repo.SaveStates(ctx, stationID, states); err != nil {
+		return nil, err
+	}
+	c.logger.Info("configuration synced",
+		zap.String("station_id", stationID),
+		zap.Int("keys", len(states)),
+		zap.Int("changed", changed),
+	)
+	return states, nil
+}
+
+// change pushes desired value of key. Station rejection is recorded in state;
+// only failures to reach the station are returned.
+func (c *ConfigSync) change(ctx context.Context, state *models.ConfigKeyState) error {
+	status, err := c.commands.ChangeConfiguration(ctx, state.StationID, state.Key, state.Desired, 0)
+	var callErr *ocpp.CallError
+	switch {
+	case errors.As(err, &callErr):
+		state.Status, state.Error = models.ConfigFailed, callErr.Error()
+		return nil
+	case err != nil:
+		return err
+	}
+
+	switch status {
+	case protocol.ConfigurationAccepted:
+		value := state.Desired
+		state.Reported = &value
+		state.Status = models.ConfigInSync
+	case protocol.ConfigurationRebootRequired:
+		// Value is applied after reboot; next BootNotification verifies it.
+		state.Status = models.ConfigRebootRequired
+	case protocol.ConfigurationRejected:
+		state.Status = models.ConfigRejected
+	case protocol.ConfigurationNotSupported:
+		state.Status = models.ConfigNotSupported
+	default:
+		state.Status, state.Error = models.ConfigFailed, "unexpected status "+status
+	}
+	return nil
+}
+
+// SetDesired replaces desired keys of station or group and schedules sync of
+// affected stations. Keys of single station are accepted for OCPP 1.6
+// stations only; OCPP 2.0.1 members of a group are skipped by sync.
+func (c *ConfigSync) SetDesired(ctx context.Context, scope, target string, keys map[string]string) error {
+	target = strings.TrimSpace(target)
+	if target == "" || (scope != models.ConfigScopeStation && scope != models.ConfigScopeGroup) {
+		return ErrInvalidConfig
+	}
+	for key, value := range keys {
+		if key == "" || len(key) > maxConfigKeyLen || len(value) > maxConfigValueLen {
+			return ErrInvalidConfig
+		}
+	}
+	if scope == models.ConfigScopeStation {
+		// Station that never booted may be configured ahead.
+		err := c.commands.requireOCPP16(ctx, target, protocol.ActionChangeConfiguration)
+		if err != nil && !errors.Is(err, repository.ErrStationNotFound) {
+			return err
+		}
+	}
+	if err := c.repo.ReplaceDesired(ctx, scope, target, keys); err != nil {
+		return err
+	}
+
+	stations := []string{target}
+	if scope == models.ConfigScopeGroup {
+		members, err := c.repo.GroupMembers(ctx, target)
+		if err != nil {
+			return err
+		}
+		stations = members
+	}
+	for _, stationID := range stations {
+		c.queue.schedule(stationID, 0)
+	}
+	return nil
+}
+
+// Desired returns desired keys stored for station or group.
+func (c *ConfigSync) Desired(ctx context.Context, scope, target string) (map[string]string, error) {
+	if scope != models.ConfigScopeStation && scope != models.ConfigScopeGroup {
+		return nil, ErrInvalidConfig
+	}
+	return c.repo.Desired(ctx, scope, strings.TrimSpace(target))
+}
+
+// SetGroup moves station to group (empty group removes it) and schedules its sync.
+func (c *ConfigSync) SetGroup(ctx context.Context, stationID, group string) error {
+	stationID = strings.TrimSpace(stationID)
+	if stationID == "" {
+		return ErrInvalidConfig
+	}
+	if err := c.repo.SetGroup(ctx, stationID, strings.TrimSpace(group)); err != nil {
+		return err
+	}
+	c.queue.schedule(stationID, 0)
+	return nil
+}
+
+// Drift reports keys that are not in sync with desired configuration: never
+// synced, changed since the last sync, or not applied by the station. Empty
+// stationID reports all configured stations; stations in sync are omitted.
+func (c *ConfigSync) Drift(ctx context.Context, stationID string) ([]models.ConfigDrift, error) {
+	stations := []string{strings.TrimSpace(stationID)}
+	if stations[0] == "" {
+		var err error
+		if stations, err = c.repo.ConfiguredStations(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	var report []models.ConfigDrift
+	for _, id := range stations {
+		drift, err := c.stationDrift(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if len(drift.Keys) > 0 {
+			report = append(report, drift)
+		}
+	}
+	return report, nil
+}
+
+func (c *ConfigSync) stationDrift(ctx context.Context, stationID string) (models.ConfigDrift, error) {
+	drift := models.ConfigDrift{StationID: stationID}
+	desired, err := c.repo.Resolve(ctx, stationID)
+	if err != nil {
+		return drift, err
+	}
+	if drift.Group, err = c.repo.Group(ctx, stationID); err != nil {
+		return drift, err
+	}
+	states, err := c.repo.States(ctx, stationID)
+	if err != nil {
+		return drift, err
+	}
+	synced := make(map[string]models.ConfigKeyState, len(states))
+	for _, state := range states {
+		synced[state.Key] = state
+	}
+
+	keys := make([]string, 0, len(desired))
+	for key := range desired {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		state, ok := synced[key]
+		if !ok || state.Desired != desired[key] {
+			state.StationID, state.Key, state.Desired = stationID, key, desired[key]
+			state.Status, state.Error = models.ConfigPending, ""
+		}
+		if state.Status != models.ConfigInSync {
+			drift.Keys = append(drift.Keys, state)
+		}
+	}
+	return drift, nil
+}
+
+// sameConfigValue compares values ignoring spaces around comma separated list items.
+func sameConfigValue(a, b string) bool {
+	return normalizeConfigValue(a) == normalizeConfigValue(b)
+}
+
+func normalizeConfigValue(value string) string {
+	items := strings.Split(value, ",")
+	for i, item := range items {
+		items[i] = strings.TrimSpace(item)
+	}
+	return strings.Join(items, ",")
+}
@@ -0,0 +1,324 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"drivepower/backend/services/ocpp-server/internal/models"
+	"drivepower/backend/services/ocpp-server/internal/ocpp/protocol"
+	"drivepower/backend/services/ocpp-server/internal/ocpp/protocol/v201"
+	"drivepower/backend/services/ocpp-server/internal/repository"
+)
+
+const (
+	defaultDiagnosticsLimit = 50
+	maxDiagnosticsLimit     = 500
+
+	// Upload retries requested from station in GetDiagnostics and GetLog.
+	diagnosticsRetries       = 3
+	diagnosticsRetryInterval = 60
+)
+
+var (
+	// ErrInvalidDiagnostics is returned for malformed diagnostics request or upload.
+	ErrInvalidDiagnostics = errors.New("diagnostics: invalid")
+	// ErrDiagnosticsTooLarge is returned when uploaded bundle exceeds size limit.
+	ErrDiagnosticsTooLarge = errors.New("diagnostics: bundle too large")
+)
+
+// invalidDiagnostics is ErrInvalidDiagnostics with reason shown to API clients.
+type invalidDiagnostics string
+
+func (e invalidDiagnostics) Error() string { return string(e) }
+
+func (e invalidDiagnostics) Is(target error) bool { return target == ErrInvalidDiagnostics }
+
+// DiagnosticsBackend stores log requests and uploaded bundles.
+type DiagnosticsBackend interface {
+	Create(ctx context.Context, d *models.Diagnostics) error
+	SetStatus(ctx context.Context, id int64, status, detail, fileName string) error
+	Get(ctx context.Context, id int64) (*models.Diagnostics, error)
+	GetByToken(ctx context.Context, token string) (*models.Diagnostics, error)
+	List(ctx context.Context, stationID string, limit int) ([]models.Diagnostics, error)
+	ReportStatus(ctx context.Context, stationID string, requestID *int, status, detail string) (bool, error)
+	StoreUpload(ctx context.Context, id int64, fileName string, size int64, sha string, at time.Time) error
+}
+
+// DiagnosticsInput describes log request. LogType applies to OCPP 2.0.1
+// stations only and defaults to DiagnosticsLog.
+type DiagnosticsInput struct {
+	StationID   string
+	LogType     string
+	StartTime   *time.Time
+	StopTime    *time.Time
+	RequestedBy int64
+}
+
+// DiagnosticsService asks stations for logs with GetDiagnostics (OCPP 1.6) or
+// GetLog (OCPP 2.0.1) and keeps bundles they upload. Every request gets its
+// own upload URL with random token, so stations need no credentials.
+type DiagnosticsService struct {
+	repo      DiagnosticsBackend
+	stations  StationBackend
+	commands  *CommandService
+	dir       string
+	publicURL string
+	maxUpload int64
+	logger    *zap.Logger
+}
+
+// NewDiagnosticsService builds service. Bundles are stored in dir and
+// uploaded by stations to publicURL.
+func NewDiagnosticsService(repo DiagnosticsBackend, stations StationBackend, commands *CommandService, dir, publicURL string, maxUpload int64, logger *zap.Logger) *DiagnosticsService {
+	return &DiagnosticsService{
+		repo:      repo,
+		stations:  stations,
+		commands:  commands,
+		dir:       dir,
+		publicURL: strings.TrimRight(publicURL, "/"),
+		maxUpload: maxUpload,
+		logger:    logger,
+	}
+}
+
+// Request sends log request matching station protocol. Command failures are
+// recorded on the request and returned.
+func (s *DiagnosticsService) Request(ctx context.Context, input DiagnosticsInput) (*models.Diagnostics, error) {
+	input.StationID = strings.TrimSpace(input.StationID)
+	if input.StationID == "" {
+		return nil, invalidDiagnostics("station_id is required")
+	}
+	if input.StartTime != nil && input.StopTime != nil && input.StopTime.Before(*input.StartTime) {
+		return nil, invalidDiagnostics("stop_time must not be before start_time")
+	}
+	if s.publicURL == "" {
+		return nil, invalidDiagnostics("diagnostics public URL is not configured")
+	}
+	station, err := s.stations.GetByID(ctx, input.StationID)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := newUploadToken()
+	if err != nil {
+		return nil, err
+	}
+	d := &models.Diagnostics{
+		StationID:   station.ID,
+		Kind:        models.DiagnosticsKindDiagnostics,
+		Token:       token,
+		Status:      models.DiagnosticsRequested,
+		RequestedBy: input.RequestedBy,
+	}
+	if station.OCPPVersion == v201.Subprotocol {
+		d.Kind = models.DiagnosticsKindLog
+		d.LogType = strings.TrimSpace(input.LogType)
+		if d.LogType == "" {
+			d.LogType = v201.LogDiagnostics
+		}
+		if d.LogType != v201.LogDiagnostics && d.LogType != v201.LogSecurity {
+			return nil, invalidDiagnostics("log_type must be DiagnosticsLog or SecurityLog")
+		}
+	}
+	if err := s.repo.Create(ctx, d); err != nil {
+		return nil, err
+	}
+
+	status, detail, fileName, err := s.send(ctx, d, input)
+	if err != nil {
+		status, detail = models.DiagnosticsFailed, err.Error()
+	}
+	if setErr := s.repo.SetStatus(ctx, d.ID, status, detail, fileName); setErr != nil {
+		s.logger.Error("failed to record diagnostics answer", zap.Int64("diagnostics_id", d.ID), zap.Error(setErr))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.Get(ctx, d.ID)
+}
+
+// send issues GetLog or GetDiagnostics and maps station answer to request state.
+func (s *DiagnosticsService) send(ctx context.Context, d *models.Diagnostics, input DiagnosticsInput) (string, string, string, error) {
+	retries, retryInterval := diagnosticsRetries, diagnosticsRetryInterval
+	location := s.publicURL + "/diagnostics/" + d.Token + "/"
+
+	if d.Kind == models.DiagnosticsKindLog {
+		resp, err := s.commands.GetLog(ctx, d.StationID, v201.GetLogRequest{
+			Log: v201.LogParameters{
+				RemoteLocation:  location,
+				OldestTimestamp: input.StartTime,
+				LatestTimestamp: input.StopTime,
+			},
+			LogType:       d.LogType,
+			RequestID:     int(d.ID),
+			Retries:       &retries,
+			RetryInterval: &retryInterval,
+		}, input.RequestedBy)
+		if err != nil {
+			return "", "", "", err
+		}
+		if resp.Status == v201.LogRejected {
+			return models.DiagnosticsRejected, resp.Status, "", nil
+		}
+		return models.DiagnosticsRequested, "", resp.Filename, nil
+	}
+
+	fileName, err := s.commands.GetDiagnostics(ctx, d.StationID, protocol.GetDiagnosticsRequest{
+		Location:      location,
+		Retries:       &retries,
+		RetryInterval: &retryInterval,
+		StartTime:     input.StartTime,
+		StopTime:      input.StopTime,
+	}, input.RequestedBy)
+	if err != nil {
+		return "", "", "", err
+	}
+	if fileName == "" {
+		return models.DiagnosticsNoData, "", "", nil
+	}
+	return models.DiagnosticsRequested, "", fileName, nil
+}
+
+// List returns requests of station (all stations when empty), newest first.
+func (s *DiagnosticsService) List(ctx context.Context, stationID string, limit int) ([]models.Diagnostics, error) {
+	if limit <= 0 {
+		limit = defaultDiagnosticsLimit
+	}
+	if limit > maxDiagnosticsLimit {
+		limit = maxDiagnosticsLimit
+	}
+	return s.repo.List(ctx, strings.TrimSpace(stationID), limit)
+}
+
+// Get returns request by ID.
+func (s *DiagnosticsService) Get(ctx context.Context, id int64) (*models.Diagnostics, error) {
+	return s.repo.Get(ctx, id)
+}
+
+// OpenFile opens uploaded bundle of request; requests without bundle are
+// reported as not found.
+func (s *DiagnosticsService) OpenFile(ctx context.Context, id int64) (*os.File, *models.Diagnostics, error) {
+	d, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if d.UploadedAt == nil {
+		return nil, nil, repository.ErrDiagnosticsNotFound
+	}
+	file, err := os.Open(s.bundlePath(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil, repository.ErrDiagnosticsNotFound
+	}
+	return file, d, err
+}
+
+// ReceiveUpload stores bundle uploaded by station to URL of token. fileName
+// is the name station used; the one announced in command answer is the fallback.
+func (s *DiagnosticsService) ReceiveUpload(ctx context.Context, token, fileName string, body io.Reader) (*models.Diagnostics, error) {
+	d, err := s.repo.GetByToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if d.UploadedAt != nil {
+		return nil, repository.ErrDiagnosticsUploaded
+	}
+	fileName = strings.TrimSpace(fileName)
+	if fileName == "" || fileName != filepath.Base(fileName) || strings.HasPrefix(fileName, ".") {
+		fileName = d.FileName
+	}
+	if fileName == "" {
+		fileName = fmt.Sprintf("%s-%d.log", d.StationID, d.ID)
+	}
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	tmp, err := os.CreateTemp(s.dir, "upload-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hash), io.LimitReader(body, s.maxUpload+1))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	if size == 0 {
+		return nil, invalidDiagnostics("diagnostics bundle is empty")
+	}
+	if size > s.maxUpload {
+		return nil, ErrDiagnosticsTooLarge
+	}
+
+	// File goes in place first: a stored upload must always have its bundle.
+	path := s.bundlePath(d.ID)
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return nil, err
+	}
+	if err := s.repo.StoreUpload(ctx, d.ID, fileName, size, hex.EncodeToString(hash.Sum(nil)), time.Now().UTC()); err != nil {
+		os.Remove(path)
+		return nil, err
+	}
+	s.logger.Info("diagnostics uploaded",
+		zap.Int64("diagnostics_id", d.ID),
+		zap.String("station_id", d.StationID),
+		zap.String("file_name", fileName),
+		zap.Int64("size", size),
+	)
+	return s.repo.Get(ctx, d.ID)
+}
+
+// DiagnosticsStatusChanged records DiagnosticsStatusNotification (nil
+// requestID) or LogStatusNotification of station. Idle is only sent on
+// TriggerMessage and is ignored.
+func (s *DiagnosticsService) DiagnosticsStatusChanged(ctx context.Context, stationID string, requestID *int, status string) error {
+	var state, detail string
+	switch status {
+	case protocol.DiagnosticsUploading:
+		state = models.DiagnosticsUploading
+	case protocol.DiagnosticsUploaded:
+		state = models.DiagnosticsUploaded
+	case protocol.DiagnosticsUploadFailed, v201.UploadFailure, v201.UploadBadMessage,
+		v201.UploadNotSupportedOperation, v201.UploadPermissionDenied:
+		state, detail = models.DiagnosticsFailed, status
+	default:
+		return nil
+	}
+	found, err := s.repo.ReportStatus(ctx, stationID, requestID, state, detail)
+	if err != nil {
+		return err
+	}
+	if !found {
+		s.logger.Debug("diagnostics status without request", zap.String("station_id", stationID), zap.String("status", status))
+	}
+	return nil
+}
+
+func (s *DiagnosticsService) bundlePath(id int64) string {
+	return filepath.Join(s.dir, strconv.FormatInt(id, 10))
+}
+
+// newUploadToken returns random token that authorizes upload of one bundle.
+func newUploadToken() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b[:]), nil
+}
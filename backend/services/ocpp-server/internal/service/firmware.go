@@ -0,0 +1,524 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"drivepower/backend/services/ocpp-server/internal/models"
+	"drivepower/backend/services/ocpp-server/internal/ocpp/protocol"
+	"drivepower/backend/services/ocpp-server/internal/repository"
+	"drivepower/backend/services/ocpp-server/internal/ws"
+)
+
+const (
+	defaultCampaignBatchSize = 10
+	defaultFailureThreshold  = 10
+
+	// Download retries requested from station in UpdateFirmware.
+	firmwareRetries       = 3
+	firmwareRetryInterval = 60
+)
+
+var (
+	// ErrInvalidFirmware is returned for malformed artifact or campaign.
+	ErrInvalidFirmware = errors.New("firmware: invalid")
+	// ErrCampaignState is returned when campaign cannot be paused or resumed in its state.
+	ErrCampaignState = errors.New("firmware campaign: not allowed in current state")
+)
+
+// invalidFirmware is ErrInvalidFirmware with reason shown to API clients.
+type invalidFirmware string
+
+func (e invalidFirmware) Error() string { return string(e) }
+
+func (e invalidFirmware) Is(target error) bool { return target == ErrInvalidFirmware }
+
+// FirmwareBackend stores firmware artifacts and campaigns.
+type FirmwareBackend interface {
+	NextArtifactID(ctx context.Context) (int64, error)
+	CreateArtifact(ctx context.Context, artifact *models.FirmwareArtifact) error
+	GetArtifact(ctx context.Context, id int64) (*models.FirmwareArtifact, error)
+	ListArtifacts(ctx context.Context) ([]models.FirmwareArtifact, error)
+	CreateCampaign(ctx context.Context, campaign *models.FirmwareCampaign, version string) error
+	GetCampaign(ctx context.Context, id int64) (*models.FirmwareCampaign, error)
+	ListCampaigns(ctx context.Context, statuses ...string) ([]models.FirmwareCampaign, error)
+	SetCampaignStatus(ctx context.Context, id int64, status string, from ...string) (bool, error)
+	SetCurrentBatch(ctx context.Context, id int64, batch int) error
+	CampaignStations(ctx context.Context, id int64) ([]models.CampaignStation, error)
+	StationCounts(ctx context.Context, id int64) (map[string]int, error)
+	NextBatch(ctx context.Context, id int64) (int, bool, error)
+	ClaimBatch(ctx context.Context, id int64, batch int) ([]string, error)
+	FinishSending(ctx context.Context, id int64, stationID, status, errText string) error
+	RetryOffline(ctx context.Context, stationID string) (int, error)
+	ReportStatus(ctx context.Context, stationID, status, errText string) (bool, error)
+	ExpireStations(ctx context.Context, id int64, before time.Time) (int, error)
+}
+
+// CampaignInput describes firmware rollout. Empty vendor or model matches any;
+// zero batch size and nil threshold take defaults.
+type CampaignInput struct {
+	Name             string
+	ArtifactID       int64
+	Vendor           string
+	Model            string
+	BatchSize        int
+	FailureThreshold *int
+	RequestedBy      int64
+}
+
+// FirmwareService keeps firmware artifacts and rolls them out to OCPP 1.6
+// stations in batches with UpdateFirmware. Next batch starts when every
+// station of the previous one installed the firmware or failed; campaign halts
+// when share of failed stations exceeds its threshold. Station that is offline
+// when its batch is sent stays pending and is retried after its next boot.
+type FirmwareService struct {
+	repo           FirmwareBackend
+	commands       *CommandService
+	dir            string
+	publicURL      string
+	maxUpload      int64
+	stationTimeout time.Duration
+	checkInterval  time.Duration
+	queue          *stationQueue
+	logger         *zap.Logger
+}
+
+// NewFirmwareService builds service. Uploaded images are stored in dir and
+// downloaded by stations from publicURL; station that reports no progress for
+// stationTimeout is failed.
+func NewFirmwareService(repo FirmwareBackend, commands *CommandService, dir, publicURL string, maxUpload int64, stationTimeout, checkInterval time.Duration, logger *zap.Logger) *FirmwareService {
+	s := &FirmwareService{
+		repo:           repo,
+		commands:       commands,
+		dir:            dir,
+		publicURL:      strings.TrimRight(publicURL, "/"),
+		maxUpload:      maxUpload,
+		stationTimeout: stationTimeout,
+		checkInterval:  checkInterval,
+		logger:         logger,
+	}
+	s.queue = newStationQueue("firmware retry", s.retryOffline, logger)
+	return s
+}
+
+// Upload stores firmware image read from body.
+func (s *FirmwareService) Upload(ctx context.Context, version, fileName string, body io.Reader) (*models.FirmwareArtifact, error) {
+	version, fileName = strings.TrimSpace(version), strings.TrimSpace(fileName)
+	if version == "" {
+		return nil, invalidFirmware("version is required")
+	}
+	if fileName == "" || fileName != filepath.Base(fileName) || strings.HasPrefix(fileName, ".") {
+		return nil, invalidFirmware("file_name must be plain file name")
+	}
+	if s.publicURL == "" {
+		return nil, invalidFirmware("firmware public URL is not configured, register artifact by url instead")
+	}
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	tmp, err := os.CreateTemp(s.dir, "upload-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hash), io.LimitReader(body, s.maxUpload+1))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	if size == 0 {
+		return nil, invalidFirmware("firmware image is empty")
+	}
+	if size > s.maxUpload {
+		return nil, invalidFirmware(fmt.Sprintf("firmware image exceeds %d bytes", s.maxUpload))
+	}
+
+	id, err := s.repo.NextArtifactID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// File goes in place first: a stored artifact must always have its image.
+	path := s.artifactPath(id)
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return nil, err
+	}
+	artifact := &models.FirmwareArtifact{
+		ID:       id,
+		Version:  version,
+		FileName: fileName,
+		Size:     size,
+		SHA256:   hex.EncodeToString(hash.Sum(nil)),
+	}
+	if err := s.repo.CreateArtifact(ctx, artifact); err != nil {
+		os.Remove(path)
+		return nil, err
+	}
+	s.locate(artifact)
+	s.logger.Info("firmware uploaded", zap.Int64("artifact_id", artifact.ID), zap.String("version", version), zap.Int64("size", size))
+	return artifact, nil
+}
+
+// Register stores firmware image hosted elsewhere.
+func (s *FirmwareService) Register(ctx context.Context, version, location string) (*models.FirmwareArtifact, error) {
+	version, location = strings.TrimSpace(version), strings.TrimSpace(location)
+	if version == "" {
+		return nil, invalidFirmware("version is required")
+	}
+	u, err := url.Parse(location)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, invalidFirmware("url must be absolute")
+	}
+
+	artifact := &models.FirmwareArtifact{
+		Version:  version,
+		FileName: filepath.Base(u.Path),
+		URL:      location,
+	}
+	if err := s.repo.CreateArtifact(ctx, artifact); err != nil {
+		return nil, err
+	}
+	s.locate(artifact)
+	return artifact, nil
+}
+
+// Artifacts returns known firmware images, newest first.
+func (s *FirmwareService) Artifacts(ctx context.Context) ([]models.FirmwareArtifact, error) {
+	artifacts, err := s.repo.ListArtifacts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range artifacts {
+		s.locate(&artifacts[i])
+	}
+	return artifacts, nil
+}
+
+// OpenArtifact opens uploaded image for download. Registered images and
+// mismatching file names are reported as not found.
+func (s *FirmwareService) OpenArtifact(ctx context.Context, id int64, fileName string) (*os.File, *models.FirmwareArtifact, error) {
+	artifact, err := s.repo.GetArtifact(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !artifact.Uploaded() || artifact.FileName != fileName {
+		return nil, nil, repository.ErrFirmwareArtifactNotFound
+	}
+	file, err := os.Open(s.artifactPath(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil, repository.ErrFirmwareArtifactNotFound
+	}
+	return file, artifact, err
+}
+
+// CreateCampaign validates input and starts rollout.
+func (s *FirmwareService) CreateCampaign(ctx context.Context, input CampaignInput) (*models.FirmwareCampaign, error) {
+	campaign := &models.FirmwareCampaign{
+		Name:             strings.TrimSpace(input.Name),
+		ArtifactID:       input.ArtifactID,
+		Vendor:           strings.TrimSpace(input.Vendor),
+		Model:            strings.TrimSpace(input.Model),
+		BatchSize:        input.BatchSize,
+		FailureThreshold: defaultFailureThreshold,
+		CreatedBy:        input.RequestedBy,
+	}
+	if campaign.Vendor == "" && campaign.Model == "" {
+		return nil, invalidFirmware("vendor or model is required")
+	}
+	if campaign.BatchSize == 0 {
+		campaign.BatchSize = defaultCampaignBatchSize
+	}
+	if campaign.BatchSize < 0 {
+		return nil, invalidFirmware("batch_size must be positive")
+	}
+	if input.FailureThreshold != nil {
+		campaign.FailureThreshold = *input.FailureThreshold
+	}
+	if campaign.FailureThreshold < 0 || campaign.FailureThreshold > 100 {
+		return nil, invalidFirmware("failure_threshold must be a percentage from 0 to 100")
+	}
+
+	artifact, err := s.repo.GetArtifact(ctx, input.ArtifactID)
+	if errors.Is(err, repository.ErrFirmwareArtifactNotFound) {
+		return nil, invalidFirmware("artifact_id does not reference firmware artifact")
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = s.repo.CreateCampaign(ctx, campaign, artifact.Version)
+	if errors.Is(err, repository.ErrNoFirmwareTargets) {
+		return nil, invalidFirmware("no accepted OCPP 1.6 stations match vendor and model")
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.logger.Info("firmware campaign started",
+		zap.Int64("campaign_id", campaign.ID),
+		zap.String("version", artifact.Version),
+		zap.String("vendor", campaign.Vendor),
+		zap.String("model", campaign.Model),
+	)
+	return campaign, nil
+}
+
+// Campaigns returns campaigns, newest first.
+func (s *FirmwareService) Campaigns(ctx context.Context) ([]models.FirmwareCampaign, error) {
+	return s.repo.ListCampaigns(ctx)
+}
+
+// Report returns campaign dashboard.
+func (s *FirmwareService) Report(ctx context.Context, id int64) (*models.CampaignReport, error) {
+	campaign, err := s.repo.GetCampaign(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	artifact, err := s.repo.GetArtifact(ctx, campaign.ArtifactID)
+	if err != nil {
+		return nil, err
+	}
+	s.locate(artifact)
+	stations, err := s.repo.CampaignStations(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.CampaignReport{
+		Campaign: *campaign,
+		Artifact: *artifact,
+		Counts:   make(map[string]int),
+		Stations: stations,
+	}
+	if report.Stations == nil {
+		report.Stations = []models.CampaignStation{}
+	}
+	for _, station := range stations {
+		report.Counts[station.Status]++
+	}
+	return report, nil
+}
+
+// Pause stops sending UpdateFirmware to further stations of campaign.
+// Stations already updating keep reporting progress.
+func (s *FirmwareService) Pause(ctx context.Context, id int64) (*models.FirmwareCampaign, error) {
+	return s.transition(ctx, id, models.CampaignPaused, models.CampaignRunning)
+}
+
+// Resume continues paused or halted campaign.
+func (s *FirmwareService) Resume(ctx context.Context, id int64) (*models.FirmwareCampaign, error) {
+	return s.transition(ctx, id, models.CampaignRunning, models.CampaignPaused, models.CampaignHalted)
+}
+
+func (s *FirmwareService) transition(ctx context.Context, id int64, status string, from ...string) (*models.FirmwareCampaign, error) {
+	changed, err := s.repo.SetCampaignStatus(ctx, id, status, from...)
+	if err != nil {
+		return nil, err
+	}
+	campaign, err := s.repo.GetCampaign(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !changed {
+		return nil, ErrCampaignState
+	}
+	return campaign, nil
+}
+
+// FirmwareStatusChanged records FirmwareStatusNotification of station in its
+// unfinished campaign. Idle is only sent on TriggerMessage and is ignored.
+func (s *FirmwareService) FirmwareStatusChanged(ctx context.Context, stationID, status string) error {
+	var state, errText string
+	switch status {
+	case protocol.FirmwareDownloading:
+		state = models.FirmwareDownloading
+	case protocol.FirmwareDownloaded:
+		state = models.FirmwareDownloaded
+	case protocol.FirmwareInstalling:
+		state = models.FirmwareInstalling
+	case protocol.FirmwareInstalled:
+		state = models.FirmwareInstalled
+	case protocol.FirmwareDownloadFailed, protocol.FirmwareInstallationFailed:
+		state, errText = models.FirmwareFailed, status
+	default:
+		return nil
+	}
+	found, err := s.repo.ReportStatus(ctx, stationID, state, errText)
+	if err != nil {
+		return err
+	}
+	if !found {
+		s.logger.Debug("firmware status outside of campaign", zap.String("station_id", stationID), zap.String("status", status))
+	}
+	return nil
+}
+
+// StationBooted schedules retry of station left pending as offline.
+func (s *FirmwareService) StationBooted(stationID string) {
+	s.queue.schedule(stationID, 0)
+}
+
+func (s *FirmwareService) retryOffline(ctx context.Context, stationID string) error {
+	n, err := s.repo.RetryOffline(ctx, stationID)
+	if n > 0 {
+		s.logger.Info("firmware update rescheduled after boot", zap.String("station_id", stationID), zap.Int("campaigns", n))
+	}
+	return err
+}
+
+// Start advances running campaigns until ctx is done.
+func (s *FirmwareService) Start(ctx context.Context) {
+	go s.queue.start(ctx)
+	ticker := time.NewTicker(s.checkInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			campaigns, err := s.repo.ListCampaigns(ctx, models.CampaignRunning)
+			if err != nil {
+				s.logger.Warn("failed to list firmware campaigns", zap.Error(err))
+				continue
+			}
+			for i := range campaigns {
+				if err := s.advance(ctx, &campaigns[i]); err != nil {
+					s.logger.Warn("firmware campaign step failed", zap.Int64("campaign_id", campaigns[i].ID), zap.Error(err))
+				}
+			}
+		}
+	}
+}
+
+// advance fails stations without progress, halts campaign over its failure
+// threshold and starts next batch once the current one is done. Pending
+// stations are not attempted and do not count toward the threshold; campaign
+// with offline stations left completes only after they were updated.
+func (s *FirmwareService) advance(ctx context.Context, campaign *models.FirmwareCampaign) error {
+	if s.stationTimeout > 0 {
+		expired, err := s.repo.ExpireStations(ctx, campaign.ID, time.Now().Add(-s.stationTimeout))
+		if err != nil {
+			return err
+		}
+		if expired > 0 {
+			s.logger.Warn("firmware update timed out", zap.Int64("campaign_id", campaign.ID), zap.Int("stations", expired))
+		}
+	}
+
+	counts, err := s.repo.StationCounts(ctx, campaign.ID)
+	if err != nil {
+		return err
+	}
+	attempted, inProgress := 0, 0
+	for status, n := range counts {
+		if status != models.FirmwarePending && status != models.FirmwareUpToDate {
+			attempted += n
+		}
+	}
+	for _, status := range models.FirmwareInProgress {
+		inProgress += counts[status]
+	}
+
+	failed := counts[models.FirmwareFailed]
+	if failed > 0 && failed*100 > campaign.FailureThreshold*attempted {
+		if halted, err := s.repo.SetCampaignStatus(ctx, campaign.ID, models.CampaignHalted, models.CampaignRunning); err != nil || !halted {
+			return err
+		}
+		s.logger.Warn("firmware campaign halted",
+			zap.Int64("campaign_id", campaign.ID),
+			zap.Int("failed", failed),
+			zap.Int("attempted", attempted),
+		)
+		return nil
+	}
+	if inProgress > 0 {
+		return nil
+	}
+
+	batch, ok, err := s.repo.NextBatch(ctx, campaign.ID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		if counts[models.FirmwarePending] > 0 {
+			// Only offline stations are left; wait for their boot.
+			return nil
+		}
+		if completed, err := s.repo.SetCampaignStatus(ctx, campaign.ID, models.CampaignCompleted, models.CampaignRunning); err != nil || !completed {
+			return err
+		}
+		s.logger.Info("firmware campaign completed", zap.Int64("campaign_id", campaign.ID), zap.Int("failed", failed))
+		return nil
+	}
+	if batch != campaign.CurrentBatch {
+		if err := s.repo.SetCurrentBatch(ctx, campaign.ID, batch); err != nil {
+			return err
+		}
+	}
+	return s.sendBatch(ctx, campaign, batch)
+}
+
+func (s *FirmwareService) sendBatch(ctx context.Context, campaign *models.FirmwareCampaign, batch int) error {
+	artifact, err := s.repo.GetArtifact(ctx, campaign.ArtifactID)
+	if err != nil {
+		return err
+	}
+	s.locate(artifact)
+	stations, err := s.repo.ClaimBatch(ctx, campaign.ID, batch)
+	if err != nil {
+		return err
+	}
+
+	retries, retryInterval := firmwareRetries, firmwareRetryInterval
+	for _, stationID := range stations {
+		err := s.commands.UpdateFirmware(ctx, stationID, protocol.UpdateFirmwareRequest{
+			Location:      artifact.Location,
+			Retries:       &retries,
+			RetrieveDate:  time.Now().UTC(),
+			RetryInterval: &retryInterval,
+		}, campaign.CreatedBy)
+
+		status, errText := models.FirmwareSent, ""
+		switch {
+		case errors.Is(err, ws.ErrStationNotConnected):
+			// Offline is not a failed update: keep station pending until it boots.
+			status, errText = models.FirmwarePending, "station not connected"
+		case err != nil:
+			status, errText = models.FirmwareFailed, err.Error()
+		}
+		if err := s.repo.FinishSending(ctx, campaign.ID, stationID, status, errText); err != nil {
+			return err
+		}
+	}
+	if len(stations) > 0 {
+		s.logger.Info("firmware batch sent", zap.Int64("campaign_id", campaign.ID), zap.Int("batch", batch), zap.Int("stations", len(stations)))
+	}
+	return nil
+}
+
+// locate fills location stations download artifact from.
+func (s *FirmwareService) locate(artifact *models.FirmwareArtifact) {
+	if !artifact.Uploaded() {
+		artifact.Location = artifact.URL
+		return
+	}
+	artifact.Location = s.publicURL + "/firmware/" + strconv.FormatInt(artifact.ID, 10) + "/" + url.PathEscape(artifact.FileName)
+}
+
+func (s *FirmwareService) artifactPath(id int64) string {
+	return filepath.Join(s.dir, strconv.FormatInt(id, 10))
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"drivepower/backend/services/ocpp-server/internal/models"
+	"drivepower/backend/services/ocpp-server/internal/ocpp/protocol"
+	"drivepower/backend/services/ocpp-server/internal/repository"
+)
+
+// AvailabilityEvent reports station going offline or coming back online.
+type AvailabilityEvent struct {
+	StationID string    `json:"station_id"`
+	Online    bool      `json:"online"`
+	LastSeen  time.Time `json:"last_seen"`
+	At        time.Time `json:"at"`
+}
+
+// LivenessMonitor records station liveness and marks silent stations offline.
+// Every inbound frame counts as liveness; last_heartbeat is written at most
+// once per check interval per station, Heartbeat writes it immediately.
+type LivenessMonitor struct {
+	repo          StationBackend
+	state         *StationState
+	heartbeat     time.Duration
+	missed        int
+	checkInterval time.Duration
+	logger        *zap.Logger
+
+	mu        sync.Mutex
+	persisted map[string]time.Time
+	previous  map[string]string
+	listeners []func(AvailabilityEvent)
+}
+
+// NewLivenessMonitor builds monitor. Station is offline after missed heartbeat
+// intervals (its own or default heartbeat); check runs every checkInterval.
+func NewLivenessMonitor(repo StationBackend, state *StationState, heartbeat time.Duration, missed int, checkInterval time.Duration, logger *zap.Logger) *LivenessMonitor {
+	if missed <= 0 {
+		missed = 3
+	}
+	if checkInterval <= 0 {
+		checkInterval = 30 * time.Second
+	}
+	return &LivenessMonitor{
+		repo:          repo,
+		state:         state,
+		heartbeat:     heartbeat,
+		missed:        missed,
+		checkInterval: checkInterval,
+		logger:        logger,
+		persisted:     make(map[string]time.Time),
+		previous:      make(map[string]string),
+	}
+}
+
+// Subscribe registers listener for availability events. Must be called before Start.
+func (m *LivenessMonitor) Subscribe(listener func(AvailabilityEvent)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.listeners = append(m.listeners, listener)
+}
+
+// Touch records inbound frame of station.
+func (m *LivenessMonitor) Touch(ctx context.Context, stationID string) {
+	m.touch(ctx, stationID, false)
+}
+
+// Heartbeat records Heartbeat message of station.
+func (m *LivenessMonitor) Heartbeat(ctx context.Context, stationID string) {
+	m.touch(ctx, stationID, true)
+}
+
+func (m *LivenessMonitor) touch(ctx context.Context, stationID string, force bool) {
+	now := time.Now().UTC()
+	m.mu.Lock()
+	if !force && now.Sub(m.persisted[stationID]) < m.checkInterval {
+		m.mu.Unlock()
+		return
+	}
+	m.persisted[stationID] = now
+	restore, ok := m.previous[stationID]
+	m.mu.Unlock()
+
+	if !ok {
+		restore = m.currentStatus(stationID)
+	}
+	wasOffline, err := m.repo.TouchHeartbeat(ctx, stationID, now, restore)
+	if err != nil {
+		if !errors.Is(err, repository.ErrStationNotFound) {
+			m.logger.Warn("failed to record station heartbeat", zap.String("station_id", stationID), zap.Error(err))
+		}
+		return
+	}
+	if !wasOffline {
+		return
+	}
+
+	m.mu.Lock()
+	delete(m.previous, stationID)
+	m.mu.Unlock()
+	m.state.UpdateStation(stationID, restore)
+	m.logger.Info("station back online", zap.String("station_id", stationID), zap.String("status", restore))
+	m.publish(AvailabilityEvent{StationID: stationID, Online: true, LastSeen: now, At: now})
+}
+
+// Start runs offline detection until ctx is cancelled.
+func (m *LivenessMonitor) Start(ctx context.Context) {
+	ticker := time.NewTicker(m.checkInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.check(ctx)
+		}
+	}
+}
+
+func (m *LivenessMonitor) check(ctx context.Context) {
+	stale, err := m.repo.MarkStaleOffline(ctx, int(m.heartbeat/time.Second), m.missed)
+	if err != nil {
+		m.logger.Warn("offline detection failed", zap.Error(err))
+		return
+	}
+	now := time.Now().UTC()
+	for _, station := range stale {
+		m.mu.Lock()
+		m.previous[station.ID] = station.PreviousStatus
+		delete(m.persisted, station.ID)
+		m.mu.Unlock()
+
+		m.state.UpdateStation(station.ID, models.StatusOffline)
+		m.logger.Warn("station went offline",
+			zap.String("station_id", station.ID),
+			zap.Time("last_heartbeat", station.LastHeartbeat),
+		)
+		m.publish(AvailabilityEvent{StationID: station.ID, Online: false, LastSeen: station.LastHeartbeat, At: now})
+	}
+}
+
+// Offline returns stations currently marked offline.
+func (m *LivenessMonitor) Offline(ctx context.Context) ([]models.Station, error) {
+	return m.repo.ListOffline(ctx)
+}
+
+// currentStatus returns status to restore when station comes back without
+// the monitor having seen it go offline (e.g. after restart).
+func (m *LivenessMonitor) currentStatus(stationID string) string {
+	if status := m.state.Status(stationID); status != "" && status != models.StatusOffline {
+		return status
+	}
+	return protocol.ConnectorAvailable
+}
+
+func (m *LivenessMonitor) publish(event AvailabilityEvent) {
+	m.mu.Lock()
+	listeners := m.listeners
+	m.mu.Unlock()
+	for _, listener := range listeners {
+		listener(event)
+	}
+}
@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"drivepower/backend/services/ocpp-server/internal/models"
+	"drivepower/backend/services/ocpp-server/internal/repository"
+)
+
+const (
+	messageLogWriteTimeout    = 10 * time.Second
+	messageLogReportEvery     = time.Minute
+	messageLogMaintainEvery   = time.Hour
+	messageLogPartitionsAhead = 3
+)
+
+// MessageLog records OCPP frames without blocking the message path: entries
+// are buffered and written in batches with COPY by a background writer. When
+// the buffer is full entries are dropped and counted. Daily partitions are
+// created ahead and dropped after retention.
+type MessageLog struct {
+	repo          *repository.OCPPLogRepository
+	batchSize     int
+	flushInterval time.Duration
+	retentionDays int
+	logger        *zap.Logger
+
+	entries chan models.OCPPMessage
+	dropped atomic.Int64
+
+	mu      sync.Mutex
+	pending []models.OCPPMessage
+	stopped chan struct{}
+}
+
+// NewMessageLog returns log buffering up to bufferSize entries. Retention of
+// zero keeps partitions forever.
+func NewMessageLog(repo *repository.OCPPLogRepository, bufferSize, batchSize int, flushInterval time.Duration, retentionDays int, logger *zap.Logger) *MessageLog {
+	if bufferSize <= 0 {
+		bufferSize = 10000
+	}
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+	if flushInterval <= 0 {
+		flushInterval = 500 * time.Millisecond
+	}
+	return &MessageLog{
+		repo:          repo,
+		batchSize:     batchSize,
+		flushInterval: flushInterval,
+		retentionDays: retentionDays,
+		logger:        logger,
+		entries:       make(chan models.OCPPMessage, bufferSize),
+		stopped:       make(chan struct{}),
+	}
+}
+
+// Record queues frame for writing; it never blocks.
+func (l *MessageLog) Record(message models.OCPPMessage) {
+	if message.CreatedAt.IsZero() {
+		message.CreatedAt = time.Now().UTC()
+	}
+	if !json.Valid(message.Payload) {
+		// Malformed frames are kept as JSON string, payload column is JSONB.
+		message.Payload, _ = json.Marshal(string(message.Payload))
+	}
+	select {
+	case l.entries <- message:
+	default:
+		l.dropped.Add(1)
+	}
+}
+
+// Dropped returns number of entries dropped since start.
+func (l *MessageLog) Dropped() int64 {
+	return l.dropped.Load()
+}
+
+// Start writes batches until ctx is cancelled; entries left are written by Flush.
+func (l *MessageLog) Start(ctx context.Context) {
+	defer close(l.stopped)
+	ticker := time.NewTicker(l.flushInterval)
+	defer ticker.Stop()
+	report := time.NewTicker(messageLogReportEvery)
+	defer report.Stop()
+
+	var (
+		batch    = make([]models.OCPPMessage, 0, l.batchSize)
+		reported int64
+	)
+	for {
+		select {
+		case <-ctx.Done():
+			l.mu.Lock()
+			l.pending = batch
+			l.mu.Unlock()
+			return
+		case message := <-l.entries:
+			batch = append(batch, message)
+			if len(batch) >= l.batchSize {
+				l.write(ctx, batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				l.write(ctx, batch)
+				batch = batch[:0]
+			}
+		case <-report.C:
+			if dropped := l.dropped.Load(); dropped != reported {
+				l.logger.Warn("ocpp message log entries dropped", zap.Int64("dropped_total", dropped), zap.Int64("dropped_since_last", dropped-reported))
+				reported = dropped
+			}
+		}
+	}
+}
+
+// Flush writes entries still buffered after Start returned.
+func (l *MessageLog) Flush(ctx context.Context) {
+	select {
+	case <-l.stopped:
+	case <-ctx.Done():
+		return
+	}
+	l.mu.Lock()
+	batch := l.pending
+	l.pending = nil
+	l.mu.Unlock()
+
+	written := 0
+	for {
+		select {
+		case message := <-l.entries:
+			batch = append(batch, message)
+			if len(batch) < l.batchSize {
+				continue
+			}
+		default:
+		}
+		if len(batch) == 0 {
+			break
+		}
+		l.write(ctx, batch)
+		written += len(batch)
+		batch = batch[:0]
+	}
+	l.logger.Info("ocpp message log flushed", zap.Int("written", written), zap.Int64("dropped_total", l.dropped.Load()))
+}
+
+// Search returns logged frames matching filter, newest first; limit is capped at 1000.
+func (l *MessageLog) Search(ctx context.Context, filter repository.MessageFilter) ([]models.OCPPMessage, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = 100
+	}
+	if filter.Limit > 1000 {
+		filter.Limit = 1000
+	}
+	return l.repo.Search(ctx, filter)
+}
+
+// Exchange returns call with unique ID and its reply. Stations may reuse IDs
+// after restart, so the latest call is taken.
+func (l *MessageLog) Exchange(ctx context.Context, stationID, uniqueID string) (models.OCPPExchange, error) {
+	frames, err := l.repo.Exchange(ctx, stationID, uniqueID)
+	if err != nil {
+		return models.OCPPExchange{}, err
+	}
+	request := -1
+	for i, frame := range frames {
+		if frame.FrameType == models.FrameCall {
+			request = i
+		}
+	}
+	if request < 0 {
+		return models.OCPPExchange{}, repository.ErrMessageNotFound
+	}
+	exchange := models.OCPPExchange{Request: frames[request]}
+	for _, frame := range frames[request+1:] {
+		if frame.Direction != exchange.Request.Direction && frame.FrameType != models.FrameCall {
+			reply := frame
+			exchange.Response = &reply
+			break
+		}
+	}
+	return exchange, nil
+}
+
+// StartMaintenance creates upcoming partitions and drops expired ones until ctx is cancelled.
+func (l *MessageLog) StartMaintenance(ctx context.Context) {
+	ticker := time.NewTicker(messageLogMaintainEvery)
+	defer ticker.Stop()
+	for {
+		l.maintain(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (l *MessageLog) maintain(ctx context.Context) {
+	now := time.Now().UTC()
+	if err := l.repo.EnsurePartitions(ctx, now, messageLogPartitionsAhead); err != nil {
+		l.logger.Warn("failed to create ocpp message partitions", zap.Error(err))
+	}
+	if l.retentionDays <= 0 {
+		return
+	}
+	dropped, err := l.repo.DropPartitionsBefore(ctx, now.AddDate(0, 0, -l.retentionDays))
+	if len(dropped) > 0 {
+		l.logger.Info("expired ocpp message partitions dropped", zap.Strings("partitions", dropped))
+	}
+	if err != nil {
+		l.logger.Warn("failed to drop expired ocpp message partitions", zap.Error(err))
+	}
+}
+
+// write stores batch; failed batch is counted as dropped, log must not stall the writer.
+func (l *MessageLog) write(ctx context.Context, batch []models.OCPPMessage) {
+	writeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), messageLogWriteTimeout)
+	defer cancel()
+	if err := l.repo.Insert(writeCtx, batch); err != nil {
+		l.dropped.Add(int64(len(batch)))
+		l.logger.Warn("failed to write ocpp message log batch", zap.Int("entries", len(batch)), zap.Error(err))
+	}
+}
@@ -0,0 +1,400 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"drivepower/backend/libs/events"
+	"drivepower/backend/services/ocpp-server/internal/clients"
+	"drivepower/backend/services/ocpp-server/internal/models"
+)
+
+// Outbox event types.
+const (
+	EventSessionStart   = "sessions.start"
+	EventSessionStop    = "sessions.stop"
+	EventBillingStopped = "billing.session_stopped"
+	EventBillingNoShow  = "billing.reservation_no_show"
+	EventMeterValue     = "telemetry.meter_value"
+	EventDomain         = "events.publish"
+)
+
+const (
+	outboxBatchSize  = 50
+	outboxLease      = time.Minute
+	outboxBaseDelay  = 2 * time.Second
+	outboxPoll       = time.Second
+	outboxRetention  = 7 * 24 * time.Hour
+	outboxCleanEvery = time.Hour
+)
+
+// DomainEvent describes event for the bus; Data is one of events.*Data payloads.
+type DomainEvent struct {
+	Type          string
+	StationID     string
+	TransactionID string
+	SessionID     int64
+	Data          interface{}
+}
+
+// OutboxMessage is notification to enqueue; AggregateID (transaction ID) keeps
+// events of one transaction in order.
+type OutboxMessage struct {
+	EventType   string
+	AggregateID string
+	Payload     interface{}
+}
+
+// OutboxBackend stores queued notifications.
+type OutboxBackend interface {
+	Enqueue(ctx context.Context, events []models.OutboxEvent) error
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]models.OutboxEvent, error)
+	MarkDelivered(ctx context.Context, id int64) error
+	MarkFailed(ctx context.Context, id int64, status string, nextAttempt time.Time, lastError string) error
+	AssignSession(ctx context.Context, aggregateID string, sessionID int64) error
+	ListByStatus(ctx context.Context, status string, limit int) ([]models.OutboxEvent, error)
+	Replay(ctx context.Context, id int64) error
+	ReplayAllDead(ctx context.Context) (int64, error)
+	DeleteDelivered(ctx context.Context, before time.Time) (int64, error)
+}
+
+// Outbox durably queues notifications for sessions, billing and telemetry
+// services and domain events for the event bus, and delivers them with retries
+// and exponential backoff. Events that exhaust attempts are dead-lettered until
+// replayed.
+type Outbox struct {
+	repo        OutboxBackend
+	sessions    *clients.SessionsClient
+	billing     *clients.BillingClient
+	telemetry   *clients.TelemetryClient
+	publisher   events.Publisher
+	txStore     *TransactionStore
+	maxAttempts int
+	maxDelay    time.Duration
+	logger      *zap.Logger
+
+	// dispatching serializes dispatcher loop and shutdown flush.
+	dispatching sync.Mutex
+}
+
+// NewOutbox builds outbox. Publisher is nil when event bus is disabled.
+func NewOutbox(
+	repo OutboxBackend,
+	sessions *clients.SessionsClient,
+	billing *clients.BillingClient,
+	telemetry *clients.TelemetryClient,
+	publisher events.Publisher,
+	txStore *TransactionStore,
+	maxAttempts int,
+	maxDelay time.Duration,
+	logger *zap.Logger,
+) *Outbox {
+	if maxAttempts <= 0 {
+		maxAttempts = 12
+	}
+	if maxDelay <= 0 {
+		maxDelay = 10 * time.Minute
+	}
+	return &Outbox{
+		repo:        repo,
+		sessions:    sessions,
+		billing:     billing,
+		telemetry:   telemetry,
+		publisher:   publisher,
+		txStore:     txStore,
+		maxAttempts: maxAttempts,
+		maxDelay:    maxDelay,
+		logger:      logger,
+	}
+}
+
+// Enqueue stores messages in one database transaction. Handlers call it before
+// answering the station, so a failure surfaces as CALLERROR and the station retries.
+// Callbacks of services without configured URL are not stored.
+func (o *Outbox) Enqueue(ctx context.Context, messages ...OutboxMessage) error {
+	queued := make([]models.OutboxEvent, 0, len(messages))
+	for _, msg := range messages {
+		if !o.targetEnabled(msg.EventType) {
+			continue
+		}
+		payload, err := json.Marshal(msg.Payload)
+		if err != nil {
+			return fmt.Errorf("outbox: encode %s: %w", msg.EventType, err)
+		}
+		queued = append(queued, models.OutboxEvent{
+			EventType:   msg.EventType,
+			AggregateID: msg.AggregateID,
+			Payload:     payload,
+		})
+	}
+	if len(queued) == 0 {
+		return nil
+	}
+	return o.repo.Enqueue(ctx, queued)
+}
+
+// Domain wraps domain events into outbox messages; with event bus disabled it
+// returns nothing. Transaction events are ordered by transaction, station
+// events by station.
+func (o *Outbox) Domain(domainEvents ...DomainEvent) []OutboxMessage {
+	if o.publisher == nil {
+		return nil
+	}
+	messages := make([]OutboxMessage, 0, len(domainEvents))
+	for _, de := range domainEvents {
+		event, err := events.New(de.Type, de.StationID, de.Data)
+		if err != nil {
+			o.logger.Error("failed to build domain event", zap.String("event_type", de.Type), zap.Error(err))
+			continue
+		}
+		event.TransactionID = de.TransactionID
+		event.SessionID = de.SessionID
+		aggregateID := de.TransactionID
+		if aggregateID == "" {
+			aggregateID = "station:" + de.StationID
+		}
+		messages = append(messages, OutboxMessage{EventType: EventDomain, AggregateID: aggregateID, Payload: event})
+	}
+	return messages
+}
+
+func (o *Outbox) targetEnabled(eventType string) bool {
+	switch eventType {
+	case EventSessionStart, EventSessionStop:
+		return o.sessions.Enabled()
+	case EventBillingStopped, EventBillingNoShow:
+		return o.billing.Enabled()
+	case EventMeterValue:
+		return o.telemetry.Enabled()
+	default:
+		return true
+	}
+}
+
+// List returns events in status (pending, delivered, dead).
+func (o *Outbox) List(ctx context.Context, status string, limit int) ([]models.OutboxEvent, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	return o.repo.ListByStatus(ctx, status, limit)
+}
+
+// Replay re-queues dead event.
+func (o *Outbox) Replay(ctx context.Context, id int64) error {
+	return o.repo.Replay(ctx, id)
+}
+
+// ReplayAll re-queues all dead events.
+func (o *Outbox) ReplayAll(ctx context.Context) (int64, error) {
+	return o.repo.ReplayAllDead(ctx)
+}
+
+// Start runs dispatcher until ctx is cancelled.
+func (o *Outbox) Start(ctx context.Context) {
+	ticker := time.NewTicker(outboxPoll)
+	defer ticker.Stop()
+	lastCleanup := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			o.dispatching.Lock()
+			o.dispatch(ctx)
+			o.dispatching.Unlock()
+			if time.Since(lastCleanup) >= outboxCleanEvery {
+				o.cleanup(ctx)
+				lastCleanup = time.Now()
+			}
+		}
+	}
+}
+
+// Flush delivers events that are due now; used on shutdown after stations are
+// disconnected, so notifications of their last messages are not left waiting
+// for the next start. Undelivered events stay queued.
+func (o *Outbox) Flush(ctx context.Context) {
+	o.dispatching.Lock()
+	defer o.dispatching.Unlock()
+	processed := o.dispatch(ctx)
+	o.logger.Info("outbox flushed", zap.Int("processed", processed))
+}
+
+// dispatch processes due events batch by batch and returns how many were processed.
+func (o *Outbox) dispatch(ctx context.Context) int {
+	processed := 0
+	for {
+		events, err := o.repo.ClaimDue(ctx, outboxBatchSize, outboxLease)
+		if err != nil {
+			if !errors.Is(err, context.Canceled) {
+				o.logger.Warn("outbox claim failed", zap.Error(err))
+			}
+			return processed
+		}
+		for _, event := range events {
+			o.process(ctx, event)
+		}
+		processed += len(events)
+		if len(events) < outboxBatchSize {
+			return processed
+		}
+	}
+}
+
+func (o *Outbox) process(ctx context.Context, event models.OutboxEvent) {
+	err := o.deliver(ctx, event)
+	if err == nil {
+		if err := o.repo.MarkDelivered(ctx, event.ID); err != nil {
+			o.logger.Warn("outbox mark delivered failed", zap.Int64("event_id", event.ID), zap.Error(err))
+		}
+		return
+	}
+
+	attempts := event.Attempts + 1
+	status, next := models.OutboxPending, time.Now().Add(o.backoff(attempts))
+	if attempts >= o.maxAttempts {
+		status = models.OutboxDead
+		o.logger.Error("outbox event dead-lettered",
+			zap.Int64("event_id", event.ID),
+			zap.String("event_type", event.EventType),
+			zap.String("aggregate_id", event.AggregateID),
+			zap.Int("attempts", attempts),
+			zap.Error(err),
+		)
+	} else {
+		o.logger.Warn("outbox delivery failed, will retry",
+			zap.Int64("event_id", event.ID),
+			zap.String("event_type", event.EventType),
+			zap.Int("attempts", attempts),
+			zap.Time("next_attempt_at", next),
+			zap.Error(err),
+		)
+	}
+	if err := o.repo.MarkFailed(ctx, event.ID, status, next, err.Error()); err != nil {
+		o.logger.Warn("outbox mark failed failed", zap.Int64("event_id", event.ID), zap.Error(err))
+	}
+}
+
+// backoff returns exponential delay for attempt (2s, 4s, 8s, ...) capped at maxDelay.
+func (o *Outbox) backoff(attempts int) time.Duration {
+	delay := outboxBaseDelay
+	for i := 1; i < attempts && delay < o.maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > o.maxDelay {
+		delay = o.maxDelay
+	}
+	return delay
+}
+
+func (o *Outbox) deliver(ctx context.Context, event models.OutboxEvent) error {
+	switch event.EventType {
+	case EventSessionStart:
+		var req clients.StartSessionRequest
+		if err := json.Unmarshal(event.Payload, &req); err != nil {
+			return err
+		}
+		sessionID, err := o.sessions.CreateFromOCPP(ctx, req)
+		if err != nil {
+			return err
+		}
+		o.sessionCreated(ctx, event.AggregateID, sessionID)
+		return nil
+	case EventSessionStop:
+		var req clients.StopSessionRequest
+		if err := json.Unmarshal(event.Payload, &req); err != nil {
+			return err
+		}
+		if err := o.sessions.CompleteFromOCPP(ctx, req); err != nil {
+			return err
+		}
+		if req.Reason == expiredStopReason {
+			if err := o.txStore.Closed(ctx, req.TransactionID); err != nil {
+				o.logger.Warn("failed to remove closed expired transaction", zap.String("transaction_id", req.TransactionID), zap.Error(err))
+			}
+		}
+		return nil
+	case EventBillingStopped:
+		var req clients.BillingStopRequest
+		if err := json.Unmarshal(event.Payload, &req); err != nil {
+			return err
+		}
+		if req.SessionID == 0 {
+			return o.missingSession("billing event")
+		}
+		return o.billing.NotifySessionStop(ctx, req)
+	case EventBillingNoShow:
+		var req clients.BillingNoShowRequest
+		if err := json.Unmarshal(event.Payload, &req); err != nil {
+			return err
+		}
+		return o.billing.NotifyReservationNoShow(ctx, req)
+	case EventMeterValue:
+		var req clients.MeterValueRequest
+		if err := json.Unmarshal(event.Payload, &req); err != nil {
+			return err
+		}
+		if req.SessionID == 0 {
+			return o.missingSession("meter value")
+		}
+		return o.telemetry.NotifyMeterValue(ctx, req)
+	case EventDomain:
+		var domainEvent events.Event
+		if err := json.Unmarshal(event.Payload, &domainEvent); err != nil {
+			return err
+		}
+		if domainEvent.SessionID == 0 && (domainEvent.Type == events.MeterSampled || domainEvent.Type == events.TransactionStopped) {
+			if err := o.missingSession(domainEvent.Type + " event"); err != nil {
+				return err
+			}
+		}
+		if o.publisher == nil {
+			// Bus was switched off after the event had been queued.
+			return nil
+		}
+		return o.publisher.Publish(ctx, domainEvent)
+	default:
+		return fmt.Errorf("outbox: unknown event type %q", event.EventType)
+	}
+}
+
+// missingSession decides about event whose transaction has no session: with
+// sessions-service disabled there is nothing to attach it to, otherwise the
+// session start has not been delivered yet and the event is retried.
+func (o *Outbox) missingSession(kind string) error {
+	if !o.sessions.Enabled() {
+		return nil
+	}
+	return fmt.Errorf("outbox: %s without session", kind)
+}
+
+// sessionCreated hands session ID to ongoing transaction and to its queued
+// events that were enqueued while the session did not exist yet.
+func (o *Outbox) sessionCreated(ctx context.Context, transactionID string, sessionID int64) {
+	if sessionID == 0 {
+		return
+	}
+	if err := o.repo.AssignSession(ctx, transactionID, sessionID); err != nil {
+		o.logger.Warn("outbox assign session failed", zap.String("transaction_id", transactionID), zap.Error(err))
+	}
+	if err := o.txStore.AssignSession(ctx, transactionID, sessionID); err != nil {
+		o.logger.Warn("failed to persist transaction session", zap.String("transaction_id", transactionID), zap.Error(err))
+	}
+}
+
+func (o *Outbox) cleanup(ctx context.Context) {
+	deleted, err := o.repo.DeleteDelivered(ctx, time.Now().Add(-outboxRetention))
+	if err != nil {
+		o.logger.Warn("outbox cleanup failed", zap.Error(err))
+		return
+	}
+	if deleted > 0 {
+		o.logger.Info("outbox delivered events removed", zap.Int64("count", deleted))
+	}
+}
@@ -0,0 +1,355 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"drivepower/backend/libs/events"
+	"drivepower/backend/services/ocpp-server/internal/clients"
+	"drivepower/backend/services/ocpp-server/internal/models"
+	"drivepower/backend/services/ocpp-server/internal/ocpp/protocol"
+	"drivepower/backend/services/ocpp-server/internal/ocpp/protocol/v201"
+	"drivepower/backend/services/ocpp-server/internal/repository"
+	"drivepower/backend/services/ocpp-server/internal/ws"
+)
+
+const (
+	defaultReservationLimit = 50
+	maxReservationLimit     = 500
+
+	// Booking may start slightly in the past to absorb client clock skew.
+	reservationStartSkew = time.Minute
+)
+
+var (
+	// ErrInvalidReservation is returned for malformed booking.
+	ErrInvalidReservation = errors.New("reservation: invalid")
+	// ErrReservationState is returned when reservation cannot be cancelled in its state.
+	ErrReservationState = errors.New("reservation: not allowed in current state")
+	// ErrReservationRejected is returned when station refuses ReserveNow.
+	ErrReservationRejected = errors.New("reservation: rejected by station")
+)
+
+// invalidReservation is ErrInvalidReservation with reason shown to API clients.
+type invalidReservation string
+
+func (e invalidReservation) Error() string { return string(e) }
+
+func (e invalidReservation) Is(target error) bool { return target == ErrInvalidReservation }
+
+// reservationRejected is ErrReservationRejected with station status.
+type reservationRejected string
+
+func (e reservationRejected) Error() string { return "station answered " + string(e) }
+
+func (e reservationRejected) Is(target error) bool { return target == ErrReservationRejected }
+
+// ReservationBackend stores connector bookings.
+type ReservationBackend interface {
+	Create(ctx context.Context, reservation *models.Reservation) error
+	Get(ctx context.Context, id int64) (*models.Reservation, error)
+	List(ctx context.Context, filter repository.ReservationFilter) ([]models.Reservation, error)
+	SetStatus(ctx context.Context, id int64, status, detail string, from ...string) (bool, error)
+	ClaimDue(ctx context.Context, now time.Time) ([]models.Reservation, error)
+	ExpireDue(ctx context.Context, now time.Time) ([]models.Reservation, error)
+	Consume(ctx context.Context, stationID string, connectorID int, idTag string, reservationID *int, transactionID string) (bool, error)
+}
+
+// BookingInput describes connector booking; nil StartAt books from now.
+type BookingInput struct {
+	StationID   string
+	ConnectorID int
+	IdTag       string
+	StartAt     *time.Time
+	EndAt       time.Time
+	UserID      int64
+}
+
+// ReservationService books connectors for time windows. Booking that starts
+// now is sent to station with ReserveNow right away, later ones when their
+// window starts; station releases the connector at the end of the window and
+// the reservation expires as no-show unless StartTransaction consumed it.
+type ReservationService struct {
+	repo          ReservationBackend
+	stations      StationBackend
+	commands      *CommandService
+	authorizer    *Authorizer
+	outbox        *Outbox
+	maxDuration   time.Duration
+	maxAdvance    time.Duration
+	checkInterval time.Duration
+	logger        *zap.Logger
+}
+
+// NewReservationService builds service. Bookings are limited to maxDuration
+// and must start within maxAdvance from now.
+func NewReservationService(repo ReservationBackend, stations StationBackend, commands *CommandService, authorizer *Authorizer, outbox *Outbox, maxDuration, maxAdvance, checkInterval time.Duration, logger *zap.Logger) *ReservationService {
+	return &ReservationService{
+		repo:          repo,
+		stations:      stations,
+		commands:      commands,
+		authorizer:    authorizer,
+		outbox:        outbox,
+		maxDuration:   maxDuration,
+		maxAdvance:    maxAdvance,
+		checkInterval: checkInterval,
+		logger:        logger,
+	}
+}
+
+// Book validates booking and stores it. Overlapping booking of the connector
+// yields repository.ErrReservationConflict; booking that starts now fails with
+// the ReserveNow error when station cannot take it.
+func (s *ReservationService) Book(ctx context.Context, input BookingInput) (*models.Reservation, error) {
+	now := time.Now().UTC()
+	reservation := &models.Reservation{
+		StationID:   strings.TrimSpace(input.StationID),
+		ConnectorID: input.ConnectorID,
+		UserID:      input.UserID,
+		IdTag:       strings.TrimSpace(input.IdTag),
+		StartAt:     now,
+		EndAt:       input.EndAt.UTC(),
+		Status:      models.ReservationScheduled,
+	}
+	if input.StartAt != nil {
+		reservation.StartAt = input.StartAt.UTC()
+	}
+	if reservation.StationID == "" || reservation.IdTag == "" || reservation.ConnectorID <= 0 {
+		return nil, invalidReservation("station_id, id_tag and positive connector_id are required")
+	}
+	if reservation.StartAt.Before(now.Add(-reservationStartSkew)) {
+		return nil, invalidReservation("start_time must not be in the past")
+	}
+	if reservation.StartAt.Before(now) {
+		reservation.StartAt = now
+	}
+	if !reservation.EndAt.After(reservation.StartAt) {
+		return nil, invalidReservation("end_time must be after start_time")
+	}
+	if s.maxDuration > 0 && reservation.EndAt.Sub(reservation.StartAt) > s.maxDuration {
+		return nil, invalidReservation("reservation must not be longer than " + s.maxDuration.String())
+	}
+	if s.maxAdvance > 0 && reservation.StartAt.Sub(now) > s.maxAdvance {
+		return nil, invalidReservation("reservation must start within " + s.maxAdvance.String())
+	}
+
+	station, err := s.stations.GetByID(ctx, reservation.StationID)
+	if err != nil {
+		return nil, err
+	}
+	if station.RegistrationStatus != models.RegistrationAccepted {
+		return nil, invalidReservation("station is not accepted")
+	}
+	if station.OCPPVersion == v201.Subprotocol {
+		return nil, invalidReservation("reservations are supported for OCPP 1.6 stations only")
+	}
+	if _, err := s.authorizer.AuthorizeFor(ctx, reservation.IdTag, input.UserID); err != nil {
+		return nil, err
+	}
+
+	startsNow := !reservation.StartAt.After(now)
+	if startsNow {
+		reservation.Status = models.ReservationReserving
+	}
+	if err := s.repo.Create(ctx, reservation); err != nil {
+		return nil, err
+	}
+	s.logger.Info("reservation booked",
+		zap.Int64("reservation_id", reservation.ID),
+		zap.String("station_id", reservation.StationID),
+		zap.Int("connector_id", reservation.ConnectorID),
+		zap.Time("start_at", reservation.StartAt),
+		zap.Time("end_at", reservation.EndAt),
+	)
+	if startsNow {
+		if err := s.reserve(ctx, reservation, false); err != nil {
+			return nil, err
+		}
+	}
+	return s.repo.Get(ctx, reservation.ID)
+}
+
+// List returns reservations matching filter, latest window first.
+func (s *ReservationService) List(ctx context.Context, filter repository.ReservationFilter) ([]models.Reservation, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultReservationLimit
+	}
+	if filter.Limit > maxReservationLimit {
+		filter.Limit = maxReservationLimit
+	}
+	filter.StationID = strings.TrimSpace(filter.StationID)
+	return s.repo.List(ctx, filter)
+}
+
+// Get returns reservation; non-zero userID restricts lookup to own reservations.
+func (s *ReservationService) Get(ctx context.Context, id, userID int64) (*models.Reservation, error) {
+	reservation, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if userID != 0 && reservation.UserID != userID {
+		return nil, repository.ErrReservationNotFound
+	}
+	return reservation, nil
+}
+
+// Cancel cancels open reservation; non-zero userID restricts it to own
+// reservations. Reservation held by station is released with
+// CancelReservation; when station cannot be reached it is cancelled anyway
+// and station releases connector at the end of the window.
+func (s *ReservationService) Cancel(ctx context.Context, id, userID, requestedBy int64) (*models.Reservation, error) {
+	reservation, err := s.Get(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch reservation.Status {
+	case models.ReservationScheduled:
+		cancelled, err := s.repo.SetStatus(ctx, id, models.ReservationCancelled, "", models.ReservationScheduled)
+		if err != nil {
+			return nil, err
+		}
+		if !cancelled {
+			return nil, ErrReservationState
+		}
+	case models.ReservationActive:
+		detail := ""
+		status, err := s.commands.CancelReservation(ctx, reservation.StationID, int(reservation.ID), requestedBy)
+		switch {
+		case err != nil:
+			detail = "station not notified: " + err.Error()
+			s.logger.Warn("cancel reservation not delivered", zap.Int64("reservation_id", id), zap.Error(err))
+		case status != protocol.CommandAccepted:
+			detail = "station answered " + status
+		}
+		cancelled, err := s.repo.SetStatus(ctx, id, models.ReservationCancelled, detail, models.ReservationActive)
+		if err != nil {
+			return nil, err
+		}
+		if !cancelled {
+			return nil, ErrReservationState
+		}
+	default:
+		return nil, ErrReservationState
+	}
+	s.logger.Info("reservation cancelled", zap.Int64("reservation_id", id), zap.String("station_id", reservation.StationID))
+	return s.repo.Get(ctx, id)
+}
+
+// ReservationUsed marks reservation consumed by transaction started on
+// station. Nil reservationID matches active reservation of connector and idTag.
+func (s *ReservationService) ReservationUsed(ctx context.Context, stationID string, connectorID int, idTag string, reservationID *int, transactionID string) error {
+	found, err := s.repo.Consume(ctx, stationID, connectorID, idTag, reservationID, transactionID)
+	if err != nil {
+		return err
+	}
+	if found {
+		s.logger.Info("reservation used", zap.String("station_id", stationID), zap.Int("connector_id", connectorID), zap.String("transaction_id", transactionID))
+	} else if reservationID != nil {
+		s.logger.Warn("transaction references unknown reservation", zap.String("station_id", stationID), zap.Int("reservation_id", *reservationID))
+	}
+	return nil
+}
+
+// Start delivers reservations whose window started and expires finished ones
+// until ctx is done.
+func (s *ReservationService) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.checkInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.expire(ctx)
+			due, err := s.repo.ClaimDue(ctx, time.Now().UTC())
+			if err != nil {
+				s.logger.Warn("failed to claim due reservations", zap.Error(err))
+				continue
+			}
+			for i := range due {
+				if err := s.reserve(ctx, &due[i], true); err != nil && !errors.Is(err, ErrReservationRejected) {
+					s.logger.Warn("reserve now failed", zap.Int64("reservation_id", due[i].ID), zap.Error(err))
+				}
+			}
+		}
+	}
+}
+
+// reserve sends ReserveNow for claimed reservation. With retry set an
+// unreachable station leaves reservation scheduled for the next check.
+func (s *ReservationService) reserve(ctx context.Context, reservation *models.Reservation, retry bool) error {
+	status, err := s.commands.ReserveNow(ctx, reservation.StationID, protocol.ReserveNowRequest{
+		ConnectorID:   reservation.ConnectorID,
+		ExpiryDate:    reservation.EndAt,
+		IdTag:         reservation.IdTag,
+		ReservationID: int(reservation.ID),
+	}, reservation.UserID)
+
+	next, detail := models.ReservationActive, ""
+	switch {
+	case err != nil && retry && errors.Is(err, ws.ErrStationNotConnected):
+		next, detail = models.ReservationScheduled, "station not connected"
+	case err != nil:
+		next, detail = models.ReservationFailed, err.Error()
+	case status != protocol.ReservationAccepted:
+		next, detail = models.ReservationFailed, "station answered "+status
+		err = reservationRejected(status)
+	}
+	if _, setErr := s.repo.SetStatus(ctx, reservation.ID, next, detail, models.ReservationReserving); setErr != nil {
+		return setErr
+	}
+	return err
+}
+
+// expire closes finished reservations and reports no-shows to billing.
+func (s *ReservationService) expire(ctx context.Context) {
+	expired, err := s.repo.ExpireDue(ctx, time.Now().UTC())
+	if err != nil {
+		s.logger.Warn("failed to expire reservations", zap.Error(err))
+		return
+	}
+	for _, reservation := range expired {
+		s.logger.Info("reservation expired without transaction",
+			zap.Int64("reservation_id", reservation.ID),
+			zap.String("station_id", reservation.StationID),
+			zap.Int64("user_id", reservation.UserID),
+		)
+		if reservation.UserID == 0 {
+			continue
+		}
+		if err := s.outbox.Enqueue(ctx, s.noShowMessages(reservation)...); err != nil {
+			s.logger.Error("failed to queue no-show notifications", zap.Int64("reservation_id", reservation.ID), zap.Error(err))
+		}
+	}
+}
+
+func (s *ReservationService) noShowMessages(reservation models.Reservation) []OutboxMessage {
+	aggregateID := "reservation:" + strconv.FormatInt(reservation.ID, 10)
+	messages := []OutboxMessage{{
+		EventType:   EventBillingNoShow,
+		AggregateID: aggregateID,
+		Payload: clients.BillingNoShowRequest{
+			ReservationID: reservation.ID,
+			UserID:        reservation.UserID,
+			StationID:     reservation.StationID,
+		},
+	}}
+	return append(messages, s.outbox.Domain(DomainEvent{
+		Type:      events.ReservationNoShow,
+		StationID: reservation.StationID,
+		Data: events.ReservationNoShowData{
+			ReservationID: reservation.ID,
+			ConnectorID:   reservation.ConnectorID,
+			UserID:        reservation.UserID,
+			IdTag:         reservation.IdTag,
+			StartAt:       reservation.StartAt,
+			ExpiredAt:     reservation.EndAt,
+		},
+	})...)
+}
@@ -0,0 +1,618 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"drivepower/backend/services/ocpp-server/internal/models"
+	"drivepower/backend/services/ocpp-server/internal/ocpp/protocol"
+	"drivepower/backend/services/ocpp-server/internal/repository"
+)
+
+const (
+	defaultSiteMinCurrent          = 6
+	defaultSiteConnectorMaxCurrent = 32
+
+	// Limits are sent as TxProfile with this stack level and chargingProfileId
+	// loadProfileIDBase+connectorId, so each push replaces the previous limit.
+	loadProfileStackLevel = 10
+	loadProfileIDBase     = 1_000_000_000
+
+	// loadHeadroom is current kept above measured draw of a vehicle that
+	// charges below its limit; unused rest is given to other transactions.
+	loadHeadroom = 2.0
+	// loadPushThreshold is the smallest limit change sent to station.
+	loadPushThreshold = 0.5
+
+	nominalVoltage = 230.0
+	nominalPhases  = 3.0
+)
+
+// ErrInvalidSite is returned for malformed site definition.
+var ErrInvalidSite = errors.New("site: invalid")
+
+// invalidSite is ErrInvalidSite with reason shown to API clients.
+type invalidSite string
+
+func (e invalidSite) Error() string { return string(e) }
+
+func (e invalidSite) Is(target error) bool { return target == ErrInvalidSite }
+
+// SiteBackend stores sites and their current allocations.
+type SiteBackend interface {
+	Save(ctx context.Context, site *models.Site) error
+	Get(ctx context.Context, id string) (*models.Site, error)
+	List(ctx context.Context) ([]models.Site, error)
+	Delete(ctx context.Context, id string) error
+	SiteOf(ctx context.Context, stationID string) (string, error)
+	Transactions(ctx context.Context, siteID string) ([]models.Transaction, error)
+	SetUserTier(ctx context.Context, userID int64, tier int) error
+	UserTiers(ctx context.Context, userIDs []int64) (map[int64]int, error)
+	Allocations(ctx context.Context, siteID string) ([]models.SiteAllocation, error)
+	ReplaceAllocations(ctx context.Context, siteID string, allocations []models.SiteAllocation) error
+	DeleteStationAllocations(ctx context.Context, stationID string) (bool, error)
+}
+
+// SiteInput describes site; nil currents and empty strategy take defaults.
+type SiteInput struct {
+	ID                  string
+	Name                string
+	MaxCurrent          float64
+	MinCurrent          *float64
+	ConnectorMaxCurrent *float64
+	Strategy            string
+	Stations            []string
+}
+
+// measurement is current drawn by transaction reported in MeterValues.
+type measurement struct {
+	stationID string
+	amps      float64
+}
+
+// SitePowerManager keeps active transactions of a site below its grid
+// capacity. On transaction start and stop and on new current measurements it
+// splits the capacity among transactions and pushes the limits to OCPP 1.6
+// stations as TxProfile. Transactions that cannot be limited keep current
+// reserved for them out of the capacity.
+type SitePowerManager struct {
+	repo     SiteBackend
+	commands *CommandService
+	state    *StationState
+	delay    time.Duration
+	queue    *stationQueue
+	logger   *zap.Logger
+
+	mu       sync.Mutex
+	measured map[string]measurement
+	locks    map[string]*sync.Mutex
+}
+
+// NewSitePowerManager builds manager. Changes are applied delay after they
+// happen, so that bursts of events cause one recalculation.
+func NewSitePowerManager(repo SiteBackend, commands *CommandService, state *StationState, delay time.Duration, logger *zap.Logger) *SitePowerManager {
+	m := &SitePowerManager{
+		repo:     repo,
+		commands: commands,
+		state:    state,
+		delay:    delay,
+		logger:   logger,
+		measured: make(map[string]measurement),
+		locks:    make(map[string]*sync.Mutex),
+	}
+	m.queue = newStationQueue("site load balancing", m.stationChanged, logger)
+	return m
+}
+
+// TransactionsChanged schedules recalculation of site of station after a
+// transaction started or stopped.
+func (m *SitePowerManager) TransactionsChanged(stationID string) {
+	m.queue.schedule(stationID, m.delay)
+}
+
+// MeterSampled records current drawn by transaction and schedules
+// recalculation. Power is converted to current at nominal three-phase voltage
+// when station does not report Current.Import.
+func (m *SitePowerManager) MeterSampled(stationID, transactionID string, values []protocol.MeterValue) {
+	for i := len(values) - 1; i >= 0; i-- {
+		amps, ok := values[i].CurrentImportAmps()
+		if !ok {
+			var watts float64
+			if watts, ok = values[i].PowerImportWatts(); ok {
+				amps = watts / (nominalVoltage * nominalPhases)
+			}
+		}
+		if ok {
+			m.mu.Lock()
+			m.measured[transactionID] = measurement{stationID: stationID, amps: amps}
+			m.mu.Unlock()
+			m.queue.schedule(stationID, m.delay)
+			return
+		}
+	}
+}
+
+// Start runs scheduled recalculations until ctx is done.
+func (m *SitePowerManager) Start(ctx context.Context) {
+	m.queue.start(ctx)
+}
+
+// SaveSite creates or replaces site and rebalances it. Stations removed from
+// the site get their limits cleared.
+func (m *SitePowerManager) SaveSite(ctx context.Context, input SiteInput) (*models.Site, error) {
+	site, err := siteOf(input)
+	if err != nil {
+		return nil, err
+	}
+	var removed []string
+	previous, err := m.repo.Get(ctx, site.ID)
+	switch {
+	case err == nil:
+		removed = missing(previous.Stations, site.Stations)
+	case !errors.Is(err, repository.ErrSiteNotFound):
+		return nil, err
+	}
+	if err := m.repo.Save(ctx, site); err != nil {
+		return nil, err
+	}
+	// Any station of the site triggers rebalancing of the whole site.
+	if len(site.Stations) > 0 {
+		removed = append(removed, site.Stations[0])
+	}
+	for _, stationID := range removed {
+		m.queue.schedule(stationID, 0)
+	}
+	return site, nil
+}
+
+// DeleteSite removes site and clears limits of its stations.
+func (m *SitePowerManager) DeleteSite(ctx context.Context, id string) error {
+	site, err := m.repo.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := m.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	for _, stationID := range site.Stations {
+		m.queue.schedule(stationID, 0)
+	}
+	return nil
+}
+
+// Site returns site by ID.
+func (m *SitePowerManager) Site(ctx context.Context, id string) (*models.Site, error) {
+	return m.repo.Get(ctx, id)
+}
+
+// Sites returns all sites.
+func (m *SitePowerManager) Sites(ctx context.Context) ([]models.Site, error) {
+	return m.repo.List(ctx)
+}
+
+// Allocations returns current limits of active transactions of site.
+func (m *SitePowerManager) Allocations(ctx context.Context, siteID string) ([]models.SiteAllocation, error) {
+	if _, err := m.repo.Get(ctx, siteID); err != nil {
+		return nil, err
+	}
+	return m.repo.Allocations(ctx, siteID)
+}
+
+// SetUserTier sets priority tier of user used by the priority strategy; it
+// applies from the next recalculation.
+func (m *SitePowerManager) SetUserTier(ctx context.Context, userID int64, tier int) error {
+	if userID <= 0 {
+		return invalidSite("user id must be positive")
+	}
+	return m.repo.SetUserTier(ctx, userID, tier)
+}
+
+// stationChanged rebalances site of station, or releases limits of station
+// that no longer belongs to a site.
+func (m *SitePowerManager) stationChanged(ctx context.Context, stationID string) error {
+	siteID, err := m.repo.SiteOf(ctx, stationID)
+	if err != nil {
+		return err
+	}
+	if siteID == "" {
+		return m.release(ctx, stationID)
+	}
+	return m.Rebalance(ctx, siteID)
+}
+
+// Rebalance splits capacity of site among its active transactions and pushes
+// limits that changed. Current of unmanaged transactions is reserved first.
+func (m *SitePowerManager) Rebalance(ctx context.Context, siteID string) error {
+	lock := m.siteLock(siteID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	site, err := m.repo.Get(ctx, siteID)
+	if err != nil {
+		return err
+	}
+	transactions, err := m.repo.Transactions(ctx, siteID)
+	if err != nil {
+		return err
+	}
+	current, err := m.repo.Allocations(ctx, siteID)
+	if err != nil {
+		return err
+	}
+	previous := make(map[string]models.SiteAllocation, len(current))
+	for _, allocation := range current {
+		previous[allocation.TransactionID] = allocation
+	}
+
+	userIDs := make([]int64, 0, len(transactions))
+	for _, tx := range transactions {
+		if tx.UserID != 0 {
+			userIDs = append(userIDs, tx.UserID)
+		}
+	}
+	tiers, err := m.repo.UserTiers(ctx, userIDs)
+	if err != nil {
+		return err
+	}
+
+	loads := make([]*siteLoad, 0, len(transactions))
+	allocations := make([]models.SiteAllocation, 0, len(transactions))
+	var unmanaged []models.SiteAllocation
+	reserved := 0.0
+	versions := make(map[string]bool)
+	for _, tx := range transactions {
+		allocation := previous[tx.ID]
+		allocation.TransactionID, allocation.SiteID = tx.ID, siteID
+		allocation.StationID, allocation.ConnectorID = tx.StationID, tx.ConnectorID
+		if amps, ok := m.takeMeasurement(tx.ID); ok {
+			allocation.MeasuredCurrent = &amps
+		}
+		txID, limitable, err := m.limitable(ctx, tx, versions)
+		if err != nil {
+			return err
+		}
+		if !limitable {
+			reserve := reservation(site, allocation)
+			allocation.LimitCurrent, allocation.Unmanaged = &reserve, true
+			reserved += reserve
+			unmanaged = append(unmanaged, allocation)
+			continue
+		}
+		if allocation.Unmanaged {
+			allocation.LimitCurrent, allocation.Unmanaged = nil, false
+		}
+		allocations = append(allocations, allocation)
+		loads = append(loads, &siteLoad{
+			transactionID: txID,
+			tier:          tiers[tx.UserID],
+			startedAt:     tx.StartedAt,
+			ceiling:       m.ceiling(site, allocation),
+		})
+	}
+
+	// Samples of transactions that are over are not needed.
+	for _, stationID := range site.Stations {
+		m.dropMeasurements(stationID)
+	}
+
+	allocateCurrent(site, math.Max(site.MaxCurrent-reserved, 0), loads)
+
+	// Decreases go first and increases only when all of them succeeded, so a
+	// failed push never leaves the site above its capacity. Transaction without
+	// limit counts as decrease.
+	var decreases, increases []int
+	for i, load := range loads {
+		previousLimit := allocations[i].LimitCurrent
+		switch {
+		case previousLimit != nil && math.Abs(*previousLimit-load.limit) < loadPushThreshold:
+		case previousLimit != nil && load.limit > *previousLimit:
+			increases = append(increases, i)
+		default:
+			decreases = append(decreases, i)
+		}
+	}
+	pushed, failed := 0, 0
+	push := func(i int) {
+		allocation, load := &allocations[i], loads[i]
+		if err := m.push(ctx, allocation, load.transactionID, load.limit); err != nil {
+			m.logger.Warn("failed to push site limit",
+				zap.String("site_id", siteID),
+				zap.String("station_id", allocation.StationID),
+				zap.Int("connector_id", allocation.ConnectorID),
+				zap.Error(err),
+			)
+			failed++
+			return
+		}
+		limit := load.limit
+		allocation.LimitCurrent = &limit
+		pushed++
+	}
+	for _, i := range decreases {
+		push(i)
+	}
+	if failed > 0 && len(increases) > 0 {
+		// Previous limits of the skipped transactions stay recorded, so the
+		// next rebalance retries them.
+		m.logger.Warn("site limit increases skipped after failed decrease",
+			zap.String("site_id", siteID),
+			zap.Int("failed", failed),
+			zap.Int("skipped", len(increases)),
+		)
+	} else {
+		for _, i := range increases {
+			push(i)
+		}
+	}
+
+	if err := m.repo.ReplaceAllocations(ctx, siteID, append(allocations, unmanaged...)); err != nil {
+		return err
+	}
+	m.logger.Info("site rebalanced",
+		zap.String("site_id", siteID),
+		zap.Int("transactions", len(allocations)),
+		zap.Int("unmanaged", len(unmanaged)),
+		zap.Float64("reserved_current", reserved),
+		zap.Int("pushed", pushed),
+	)
+	return nil
+}
+
+// limitable reports whether transaction can be limited with TxProfile: only
+// OCPP 1.6 transactions (integer ID) on a known connector can. versions caches
+// station OCPP version for one rebalance.
+func (m *SitePowerManager) limitable(ctx context.Context, tx models.Transaction, versions map[string]bool) (int, bool, error) {
+	txID, err := strconv.Atoi(tx.ID)
+	if err != nil || tx.ConnectorID <= 0 {
+		return 0, false, nil
+	}
+	isV201, ok := versions[tx.StationID]
+	if !ok {
+		isV201, err = m.commands.isV201(ctx, tx.StationID)
+		if errors.Is(err, repository.ErrStationNotFound) {
+			isV201, err = false, nil
+		}
+		if err != nil {
+			return 0, false, err
+		}
+		versions[tx.StationID] = isV201
+	}
+	return txID, !isV201, nil
+}
+
+// reservation returns current kept for transaction that cannot be limited:
+// measured draw plus headroom, connector maximum until it is measured.
+func reservation(site *models.Site, allocation models.SiteAllocation) float64 {
+	if allocation.MeasuredCurrent != nil {
+		return math.Min(site.ConnectorMaxCurrent, *allocation.MeasuredCurrent+loadHeadroom)
+	}
+	return site.ConnectorMaxCurrent
+}
+
+// ceiling returns the most current transaction can use: connector maximum,
+// or measured draw plus headroom when the vehicle charges below its limit.
+func (m *SitePowerManager) ceiling(site *models.Site, allocation models.SiteAllocation) float64 {
+	ceiling := site.ConnectorMaxCurrent
+	if m.state.ConnectorStatus(allocation.StationID, allocation.ConnectorID) == protocol.ConnectorSuspendedEV {
+		return site.MinCurrent
+	}
+	if allocation.MeasuredCurrent != nil && allocation.LimitCurrent != nil && *allocation.MeasuredCurrent < *allocation.LimitCurrent-loadHeadroom {
+		ceiling = math.Min(ceiling, *allocation.MeasuredCurrent+loadHeadroom)
+	}
+	return math.Max(ceiling, site.MinCurrent)
+}
+
+// push sends limit as TxProfile of transaction.
+func (m *SitePowerManager) push(ctx context.Context, allocation *models.SiteAllocation, transactionID int, limit float64) error {
+	profile := protocol.ChargingProfile{
+		ChargingProfileID:      loadProfileIDBase + allocation.ConnectorID,
+		TransactionID:          &transactionID,
+		StackLevel:             loadProfileStackLevel,
+		ChargingProfilePurpose: protocol.ProfilePurposeTx,
+		ChargingProfileKind:    protocol.ProfileKindRelative,
+		ChargingSchedule: protocol.ChargingSchedule{
+			ChargingRateUnit:       protocol.ChargingRateAmperes,
+			ChargingSchedulePeriod: []protocol.ChargingSchedulePeriod{{StartPeriod: 0, Limit: limit}},
+		},
+	}
+	status, err := m.commands.SetChargingProfile(ctx, allocation.StationID, allocation.ConnectorID, profile, 0)
+	if err != nil {
+		return err
+	}
+	if status != protocol.CommandAccepted {
+		return errors.New("station answered " + status)
+	}
+	return nil
+}
+
+// release clears limits of station that left its site.
+func (m *SitePowerManager) release(ctx context.Context, stationID string) error {
+	m.dropMeasurements(stationID)
+	limited, err := m.repo.DeleteStationAllocations(ctx, stationID)
+	if err != nil || !limited {
+		return err
+	}
+	stackLevel := loadProfileStackLevel
+	_, err = m.commands.ClearChargingProfile(ctx, stationID, protocol.ClearChargingProfileRequest{
+		ChargingProfilePurpose: protocol.ProfilePurposeTx,
+		StackLevel:             &stackLevel,
+	}, 0)
+	return err
+}
+
+func (m *SitePowerManager) takeMeasurement(transactionID string) (float64, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	sample, ok := m.measured[transactionID]
+	delete(m.measured, transactionID)
+	return sample.amps, ok
+}
+
+func (m *SitePowerManager) dropMeasurements(stationID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for transactionID, sample := range m.measured {
+		if sample.stationID == stationID {
+			delete(m.measured, transactionID)
+		}
+	}
+}
+
+func (m *SitePowerManager) siteLock(siteID string) *sync.Mutex {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	lock, ok := m.locks[siteID]
+	if !ok {
+		lock = &sync.Mutex{}
+		m.locks[siteID] = lock
+	}
+	return lock
+}
+
+// siteLoad is active transaction competing for site capacity.
+type siteLoad struct {
+	transactionID int
+	tier          int
+	startedAt     time.Time
+	ceiling       float64
+	limit         float64
+}
+
+// allocateCurrent splits capacity (site maximum less reserved current) and sets
+// limit of every load. Each transaction first gets the minimum current in
+// strategy order while capacity lasts (the rest is paused with 0 A); remaining
+// capacity is then shared: equally among all, by tier with equal shares within
+// a tier, or in order of arrival.
+func allocateCurrent(site *models.Site, capacity float64, loads []*siteLoad) {
+	loads = append([]*siteLoad(nil), loads...)
+	sort.SliceStable(loads, func(i, j int) bool {
+		if site.Strategy == models.SiteStrategyPriority && loads[i].tier != loads[j].tier {
+			return loads[i].tier > loads[j].tier
+		}
+		return loads[i].startedAt.Before(loads[j].startedAt)
+	})
+
+	remaining := capacity
+	for _, load := range loads {
+		load.limit = 0
+		if remaining >= site.MinCurrent {
+			load.limit = site.MinCurrent
+			remaining -= site.MinCurrent
+		}
+	}
+
+	for start := 0; start < len(loads); {
+		end := start + 1
+		switch site.Strategy {
+		case models.SiteStrategyEqual:
+			end = len(loads)
+		case models.SiteStrategyPriority:
+			for end < len(loads) && loads[end].tier == loads[start].tier {
+				end++
+			}
+		}
+		remaining = shareCurrent(loads[start:end], remaining)
+		start = end
+	}
+
+	for _, load := range loads {
+		load.limit = math.Floor(load.limit*10) / 10
+	}
+}
+
+// shareCurrent splits capacity equally among running loads up to their
+// ceilings and returns what is left.
+func shareCurrent(loads []*siteLoad, capacity float64) float64 {
+	var open []*siteLoad
+	for _, load := range loads {
+		if load.limit > 0 && load.limit < load.ceiling {
+			open = append(open, load)
+		}
+	}
+	for capacity > 0.01 && len(open) > 0 {
+		share := capacity / float64(len(open))
+		next := open[:0]
+		for _, load := range open {
+			add := math.Min(share, load.ceiling-load.limit)
+			load.limit += add
+			capacity -= add
+			if load.ceiling-load.limit > 0.01 {
+				next = append(next, load)
+			}
+		}
+		if len(next) == len(open) {
+			break
+		}
+		open = next
+	}
+	return capacity
+}
+
+func siteOf(input SiteInput) (*models.Site, error) {
+	site := &models.Site{
+		ID:                  strings.TrimSpace(input.ID),
+		Name:                strings.TrimSpace(input.Name),
+		MaxCurrent:          input.MaxCurrent,
+		MinCurrent:          defaultSiteMinCurrent,
+		ConnectorMaxCurrent: defaultSiteConnectorMaxCurrent,
+		Strategy:            input.Strategy,
+		Stations:            []string{},
+	}
+	if input.MinCurrent != nil {
+		site.MinCurrent = *input.MinCurrent
+	}
+	if input.ConnectorMaxCurrent != nil {
+		site.ConnectorMaxCurrent = *input.ConnectorMaxCurrent
+	}
+	if site.Strategy == "" {
+		site.Strategy = models.SiteStrategyEqual
+	}
+
+	switch {
+	case site.ID == "":
+		return nil, invalidSite("site id is required")
+	case site.MaxCurrent <= 0 || !oneDecimal(site.MaxCurrent):
+		return nil, invalidSite("max_current must be positive with at most one decimal")
+	case site.MinCurrent < 0 || !oneDecimal(site.MinCurrent):
+		return nil, invalidSite("min_current must be non-negative with at most one decimal")
+	case site.ConnectorMaxCurrent <= 0 || site.ConnectorMaxCurrent < site.MinCurrent || !oneDecimal(site.ConnectorMaxCurrent):
+		return nil, invalidSite("connector_max_current must be positive, not below min_current, with at most one decimal")
+	}
+	switch site.Strategy {
+	case models.SiteStrategyEqual, models.SiteStrategyPriority, models.SiteStrategyFirstCome:
+	default:
+		return nil, invalidSite("strategy must be equal, priority or first_come")
+	}
+
+	seen := make(map[string]bool, len(input.Stations))
+	for _, stationID := range input.Stations {
+		stationID = strings.TrimSpace(stationID)
+		if stationID == "" {
+			return nil, invalidSite("station id must not be empty")
+		}
+		if !seen[stationID] {
+			seen[stationID] = true
+			site.Stations = append(site.Stations, stationID)
+		}
+	}
+	return site, nil
+}
+
+// missing returns values of before that are not in after.
+func missing(before, after []string) []string {
+	var result []string
+	for _, v := range before {
+		if !containsString(after, v) {
+			result = append(result, v)
+		}
+	}
+	return result
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"drivepower/backend/libs/events"
+	"drivepower/backend/services/ocpp-server/internal/ws"
+)
+
+// stationEventsQueue bounds station events waiting to be written to outbox.
+const stationEventsQueue = 1024
+
+// StationEvents publishes station connection and availability changes on the
+// event bus through the outbox. Listeners only queue events and a background
+// loop writes them, so socket handling never waits for the database.
+type StationEvents struct {
+	outbox *Outbox
+	queue  chan []OutboxMessage
+	logger *zap.Logger
+}
+
+// NewStationEvents builds publisher.
+func NewStationEvents(outbox *Outbox, logger *zap.Logger) *StationEvents {
+	return &StationEvents{
+		outbox: outbox,
+		queue:  make(chan []OutboxMessage, stationEventsQueue),
+		logger: logger,
+	}
+}
+
+// OnConnection is ws.ConnectionListener publishing StationConnected and
+// StationDisconnected.
+func (p *StationEvents) OnConnection(event ws.ConnectionEvent) {
+	eventType := events.StationConnected
+	if event.Type == ws.EventDisconnected {
+		eventType = events.StationDisconnected
+	}
+	p.push(DomainEvent{
+		Type:      eventType,
+		StationID: event.StationID,
+		Data: events.StationConnectionData{
+			RemoteAddr:  event.RemoteAddr,
+			Subprotocol: event.Subprotocol,
+			Reason:      event.Reason,
+			At:          event.At,
+		},
+	})
+}
+
+// OnAvailability is LivenessMonitor listener publishing
+// StationAvailabilityChanged.
+func (p *StationEvents) OnAvailability(event AvailabilityEvent) {
+	p.push(DomainEvent{
+		Type:      events.StationAvailabilityChanged,
+		StationID: event.StationID,
+		Data: events.StationAvailabilityData{
+			Online:   event.Online,
+			LastSeen: event.LastSeen,
+			At:       event.At,
+		},
+	})
+}
+
+// Start writes queued events to outbox until ctx is done.
+func (p *StationEvents) Start(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case messages := <-p.queue:
+			p.enqueue(ctx, messages)
+		}
+	}
+}
+
+// Flush writes events still queued, e.g. disconnects of stations closed
+// during shutdown.
+func (p *StationEvents) Flush(ctx context.Context) {
+	for {
+		select {
+		case messages := <-p.queue:
+			p.enqueue(ctx, messages)
+		default:
+			return
+		}
+	}
+}
+
+func (p *StationEvents) push(domainEvent DomainEvent) {
+	messages := p.outbox.Domain(domainEvent)
+	if len(messages) == 0 {
+		return
+	}
+	select {
+	case p.queue <- messages:
+	default:
+		p.logger.Warn("station events queue full, event dropped",
+			zap.String("event_type", domainEvent.Type),
+			zap.String("station_id", domainEvent.StationID),
+		)
+	}
+}
+
+func (p *StationEvents) enqueue(ctx context.Context, messages []OutboxMessage) {
+	enqueueCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+	if err := p.outbox.Enqueue(enqueueCtx, messages...); err != nil {
+		p.logger.Error("failed to queue station event", zap.Error(err))
+	}
+}
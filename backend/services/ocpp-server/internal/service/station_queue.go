@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	stationQueueWorkers = 8
+	stationQueueTimeout = 2 * time.Minute
+)
+
+// stationQueue runs work sending commands to station outside its read loop,
+// e.g. after BootNotification was answered. Repeated requests for the same
+// station before it is due are merged.
+type stationQueue struct {
+	name   string
+	run    func(ctx context.Context, stationID string) error
+	logger *zap.Logger
+
+	mu  sync.Mutex
+	due map[string]time.Time
+}
+
+func newStationQueue(name string, run func(ctx context.Context, stationID string) error, logger *zap.Logger) *stationQueue {
+	return &stationQueue{
+		name:   name,
+		run:    run,
+		logger: logger,
+		due:    make(map[string]time.Time),
+	}
+}
+
+func (q *stationQueue) schedule(stationID string, delay time.Duration) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.due[stationID] = time.Now().Add(delay)
+}
+
+// start runs due work with bounded concurrency until ctx is done.
+func (q *stationQueue) start(ctx context.Context) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	slots := make(chan struct{}, stationQueueWorkers)
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, stationID := range q.takeDue(time.Now()) {
+				select {
+				case slots <- struct{}{}:
+				case <-ctx.Done():
+					return
+				}
+				wg.Add(1)
+				go func(stationID string) {
+					defer wg.Done()
+					defer func() { <-slots }()
+					runCtx, cancel := context.WithTimeout(ctx, stationQueueTimeout)
+					defer cancel()
+					if err := q.run(runCtx, stationID); err != nil {
+						q.logger.Warn(q.name+" failed", zap.String("station_id", stationID), zap.Error(err))
+					}
+				}(stationID)
+			}
+		}
+	}
+}
+
+func (q *stationQueue) takeDue(now time.Time) []string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var stations []string
+	for stationID, at := range q.due {
+		if !at.After(now) {
+			stations = append(stations, stationID)
+			delete(q.due, stationID)
+		}
+	}
+	return stations
+}
@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"drivepower/backend/services/ocpp-server/internal/models"
+	"drivepower/backend/services/ocpp-server/internal/ocpp/protocol"
+	"drivepower/backend/services/ocpp-server/internal/repository"
+)
+
+// Policies for stations missing in registry.
+const (
+	PolicyReject  = "reject"
+	PolicyPending = "pending"
+	PolicyAccept  = "accept"
+)
+
+// BootDecision is BootNotification answer for station.
+type BootDecision struct {
+	Status   string
+	Interval int
+}
+
+// RegistrationEvent reports station approved or decommissioned by operator.
+type RegistrationEvent struct {
+	StationID          string
+	RegistrationStatus string
+}
+
+// StationBackend persists station registry and liveness state.
+type StationBackend interface {
+	Upsert(ctx context.Context, station *models.Station) error
+	GetByID(ctx context.Context, stationID string) (*models.Station, error)
+	List(ctx context.Context, registrationStatus string) ([]models.Station, error)
+	SetRegistration(ctx context.Context, stationID, registrationStatus string, heartbeatInterval int) error
+	TouchHeartbeat(ctx context.Context, stationID string, at time.Time, restoreStatus string) (bool, error)
+	MarkStaleOffline(ctx context.Context, defaultInterval, missed int) ([]repository.StaleStation, error)
+	ListOffline(ctx context.Context) ([]models.Station, error)
+	UpdateStatus(ctx context.Context, stationID, status string) error
+}
+
+// StationRegistry decides whether station may register and which messages it may send.
+type StationRegistry struct {
+	repo         StationBackend
+	policy       string
+	heartbeat    time.Duration
+	pendingRetry time.Duration
+	logger       *zap.Logger
+
+	mu        sync.RWMutex
+	statuses  map[string]string
+	listeners []func(context.Context, RegistrationEvent)
+}
+
+// NewStationRegistry builds registry.
+func NewStationRegistry(repo StationBackend, policy string, heartbeat, pendingRetry time.Duration, logger *zap.Logger) *StationRegistry {
+	return &StationRegistry{
+		repo:         repo,
+		policy:       policy,
+		heartbeat:    heartbeat,
+		pendingRetry: pendingRetry,
+		logger:       logger,
+		statuses:     make(map[string]string),
+	}
+}
+
+// Subscribe registers listener for registration changes. Must be called before
+// serving requests.
+func (r *StationRegistry) Subscribe(listener func(context.Context, RegistrationEvent)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.listeners = append(r.listeners, listener)
+}
+
+// Forget drops cached registration status of station, e.g. after another
+// replica changed it; next message reads it from backend.
+func (r *StationRegistry) Forget(stationID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.statuses, stationID)
+}
+
+// Boot applies registration policy to BootNotification and stores station metadata.
+func (r *StationRegistry) Boot(ctx context.Context, station *models.Station) (BootDecision, error) {
+	existing, err := r.repo.GetByID(ctx, station.ID)
+	switch {
+	case errors.Is(err, repository.ErrStationNotFound):
+		switch r.policy {
+		case PolicyAccept:
+			station.RegistrationStatus = models.RegistrationAccepted
+		case PolicyPending:
+			station.RegistrationStatus = models.RegistrationPending
+		default:
+			r.logger.Warn("unknown station rejected", zap.String("station_id", station.ID))
+			return r.decision(""), nil
+		}
+		r.logger.Info("new station registered",
+			zap.String("station_id", station.ID),
+			zap.String("registration_status", station.RegistrationStatus),
+		)
+	case err != nil:
+		return BootDecision{}, err
+	default:
+		station.RegistrationStatus = existing.RegistrationStatus
+		station.HeartbeatInterval = existing.HeartbeatInterval
+		if existing.RegistrationStatus == models.RegistrationDecommissioned {
+			r.remember(station.ID, existing.RegistrationStatus)
+			return r.decision(existing.RegistrationStatus), nil
+		}
+	}
+
+	if err := r.repo.Upsert(ctx, station); err != nil {
+		return BootDecision{}, err
+	}
+	r.remember(station.ID, station.RegistrationStatus)
+
+	decision := r.decision(station.RegistrationStatus)
+	if decision.Status == protocol.RegistrationAccepted && station.HeartbeatInterval > 0 {
+		decision.Interval = station.HeartbeatInterval
+	}
+	return decision, nil
+}
+
+// Allow reports whether station may send action. Until registration is
+// accepted only BootNotification is allowed, as required by OCPP.
+func (r *StationRegistry) Allow(ctx context.Context, stationID, action string) bool {
+	if action == protocol.ActionBootNotification {
+		return true
+	}
+
+	r.mu.RLock()
+	status, ok := r.statuses[stationID]
+	r.mu.RUnlock()
+	if ok {
+		return status == models.RegistrationAccepted
+	}
+
+	station, err := r.repo.GetByID(ctx, stationID)
+	if err != nil {
+		if !errors.Is(err, repository.ErrStationNotFound) {
+			r.logger.Warn("station registry lookup failed", zap.String("station_id", stationID), zap.Error(err))
+		}
+		return false
+	}
+	r.remember(stationID, station.RegistrationStatus)
+	return station.RegistrationStatus == models.RegistrationAccepted
+}
+
+// Approve accepts station, creating registry entry when station was never seen
+// (pre-provisioning). heartbeatInterval of 0 keeps server default.
+func (r *StationRegistry) Approve(ctx context.Context, stationID string, heartbeatInterval int) error {
+	err := r.repo.SetRegistration(ctx, stationID, models.RegistrationAccepted, heartbeatInterval)
+	if errors.Is(err, repository.ErrStationNotFound) {
+		err = r.repo.Upsert(ctx, &models.Station{
+			ID:                 stationID,
+			Status:             protocol.ConnectorUnavailable,
+			RegistrationStatus: models.RegistrationAccepted,
+		})
+		if err == nil {
+			err = r.repo.SetRegistration(ctx, stationID, models.RegistrationAccepted, heartbeatInterval)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	r.remember(stationID, models.RegistrationAccepted)
+	r.logger.Info("station approved", zap.String("station_id", stationID))
+	r.publish(ctx, RegistrationEvent{StationID: stationID, RegistrationStatus: models.RegistrationAccepted})
+	return nil
+}
+
+// Decommission blocks station from further communication.
+func (r *StationRegistry) Decommission(ctx context.Context, stationID string) error {
+	if err := r.repo.SetRegistration(ctx, stationID, models.RegistrationDecommissioned, 0); err != nil {
+		return err
+	}
+	r.remember(stationID, models.RegistrationDecommissioned)
+	r.logger.Info("station decommissioned", zap.String("station_id", stationID))
+	r.publish(ctx, RegistrationEvent{StationID: stationID, RegistrationStatus: models.RegistrationDecommissioned})
+	return nil
+}
+
+// List returns registry entries filtered by registration status (empty for all).
+func (r *StationRegistry) List(ctx context.Context, registrationStatus string) ([]models.Station, error) {
+	return r.repo.List(ctx, registrationStatus)
+}
+
+func (r *StationRegistry) decision(registrationStatus string) BootDecision {
+	switch registrationStatus {
+	case models.RegistrationAccepted:
+		return BootDecision{Status: protocol.RegistrationAccepted, Interval: int(r.heartbeat.Seconds())}
+	case models.RegistrationPending:
+		return BootDecision{Status: protocol.RegistrationPending, Interval: int(r.pendingRetry.Seconds())}
+	default:
+		return BootDecision{Status: protocol.RegistrationRejected, Interval: int(r.pendingRetry.Seconds())}
+	}
+}
+
+func (r *StationRegistry) publish(ctx context.Context, event RegistrationEvent) {
+	r.mu.RLock()
+	listeners := r.listeners
+	r.mu.RUnlock()
+	for _, listener := range listeners {
+		listener(ctx, event)
+	}
+}
+
+func (r *StationRegistry) remember(stationID, registrationStatus string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.statuses[stationID] = registrationStatus
+}
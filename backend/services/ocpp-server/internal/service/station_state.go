@@ -1,16 +1,25 @@
 package service
 
-import "sync"
+import (
+	"sync"
+
+	"drivepower/backend/services/ocpp-server/internal/ocpp/protocol"
+)
 
 // ConnectorState holds minimal connector info.
 type ConnectorState struct {
 	Status string
+	// ScheduledAvailability is ChangeAvailability type the station accepted as
+	// Scheduled; it is cleared once the connector reports matching status.
+	ScheduledAvailability string
 }
 
 // StationRuntimeState keeps runtime info per station.
 type StationRuntimeState struct {
-	Status     string
-	Connectors map[int]ConnectorState
+	Status string
+	// ScheduledAvailability is pending ChangeAvailability of connector 0.
+	ScheduledAvailability string
+	Connectors            map[int]ConnectorState
 }
 
 // StationState keeps track of in-memory station data for quick lookups.
@@ -30,24 +39,84 @@ func NewStationState() *StationState {
 func (s *StationState) UpdateStation(stationID, status string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	state, ok := s.stations[stationID]
-	if !ok {
-		state = &StationRuntimeState{Connectors: make(map[int]ConnectorState)}
-		s.stations[stationID] = state
-	}
+	state := s.station(stationID)
 	state.Status = status
+	if availabilityReached(state.ScheduledAvailability, status) {
+		state.ScheduledAvailability = ""
+	}
 }
 
 // UpdateConnector updates connector-level status.
 func (s *StationState) UpdateConnector(stationID string, connectorID int, status string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	state := s.station(stationID)
+	connector := state.Connectors[connectorID]
+	connector.Status = status
+	if availabilityReached(connector.ScheduledAvailability, status) {
+		connector.ScheduledAvailability = ""
+	}
+	state.Connectors[connectorID] = connector
+}
+
+// ScheduleAvailability remembers ChangeAvailability answered with Scheduled;
+// an empty availability drops pending change (e.g. superseded by accepted one).
+func (s *StationState) ScheduleAvailability(stationID string, connectorID int, availability string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state := s.station(stationID)
+	if connectorID == 0 {
+		state.ScheduledAvailability = availability
+		return
+	}
+	connector := state.Connectors[connectorID]
+	connector.ScheduledAvailability = availability
+	state.Connectors[connectorID] = connector
+}
+
+// ScheduledAvailability returns pending availability change of connector (0 for station).
+func (s *StationState) ScheduledAvailability(stationID string, connectorID int) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	state, ok := s.stations[stationID]
+	if !ok {
+		return ""
+	}
+	if connectorID == 0 {
+		return state.ScheduledAvailability
+	}
+	return state.Connectors[connectorID].ScheduledAvailability
+}
+
+// station returns state of station, creating it; caller holds write lock.
+func (s *StationState) station(stationID string) *StationRuntimeState {
 	state, ok := s.stations[stationID]
 	if !ok {
 		state = &StationRuntimeState{Connectors: make(map[int]ConnectorState)}
 		s.stations[stationID] = state
 	}
-	state.Connectors[connectorID] = ConnectorState{Status: status}
+	return state
+}
+
+// availabilityReached reports whether reported status completes scheduled change.
+func availabilityReached(scheduled, status string) bool {
+	switch scheduled {
+	case protocol.AvailabilityInoperative:
+		return status == protocol.ConnectorUnavailable
+	case protocol.AvailabilityOperative:
+		return status == protocol.ConnectorAvailable
+	}
+	return false
+}
+
+// Status returns last known station status.
+func (s *StationState) Status(stationID string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if state, ok := s.stations[stationID]; ok {
+		return state.Status
+	}
+	return ""
 }
 
 // Snapshot returns a copy of current state map.
@@ -57,8 +126,9 @@ func (s *StationState) Snapshot() map[string]StationRuntimeState {
 	result := make(map[string]StationRuntimeState, len(s.stations))
 	for id, st := range s.stations {
 		copyState := StationRuntimeState{
-			Status:     st.Status,
-			Connectors: make(map[int]ConnectorState, len(st.Connectors)),
+			Status:                st.Status,
+			ScheduledAvailability: st.ScheduledAvailability,
+			Connectors:            make(map[int]ConnectorState, len(st.Connectors)),
 		}
 		for cid, conn := range st.Connectors {
 			copyState.Connectors[cid] = conn
@@ -68,3 +138,13 @@ func (s *StationState) Snapshot() map[string]StationRuntimeState {
 	return result
 }
 
+
+// ConnectorStatus returns last known status of connector.
+func (s *StationState) ConnectorStatus(stationID string, connectorID int) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if state, ok := s.stations[stationID]; ok {
+		return state.Connectors[connectorID].Status
+	}
+	return ""
+}
@@ -1,44 +1,418 @@
 package service
 
-import "sync"
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"drivepower/backend/libs/events"
+	"drivepower/backend/services/ocpp-server/internal/clients"
+	"drivepower/backend/services/ocpp-server/internal/models"
+	"drivepower/backend/services/ocpp-server/internal/repository"
+)
+
+// touchInterval limits how often transaction activity is written to backend.
+const touchInterval = 5 * time.Minute
+
+// expiredStopReason is stop reason of transactions closed on expiry.
+const expiredStopReason = "Expired"
 
 // TransactionContext keeps runtime info for a transaction.
 type TransactionContext struct {
-	SessionID int64
-	MeterStart int64
+	SessionID   int64
+	MeterStart  int64
+	MeterLast   int64
+	StationID   string
+	ConnectorID int
+	IdTag       string
+	UserID      int64
+	Authorized  bool
+}
+
+// TransactionBackend persists transaction contexts so they survive restarts.
+type TransactionBackend interface {
+	Save(ctx context.Context, tx *models.Transaction) error
+	Touch(ctx context.Context, transactionID string, meterLast int64) error
+	Delete(ctx context.Context, transactionID string) error
+	ExpireIdle(ctx context.Context, before time.Time) ([]models.Transaction, error)
+	Unexpire(ctx context.Context, transactionID string) error
+	DeleteExpired(ctx context.Context, transactionID string) error
+	IsExpired(ctx context.Context, transactionID string) (bool, error)
+	ListExpired(ctx context.Context) ([]string, error)
+	AssignSession(ctx context.Context, transactionID string, sessionID int64) error
+	Get(ctx context.Context, transactionID string) (*models.Transaction, error)
+	List(ctx context.Context) ([]models.Transaction, error)
+	ListByStation(ctx context.Context, stationID string) ([]models.Transaction, error)
 }
 
-// TransactionStore stores contexts by transaction ID.
+// TransactionStore keeps contexts by transaction ID in memory and writes them
+// through to backend. Reads are served from memory. In a cluster the store is
+// shared: session assigned on another node is picked up from backend.
 type TransactionStore struct {
-	mu   sync.RWMutex
-	data map[string]TransactionContext
+	backend TransactionBackend
+	ttl     time.Duration
+	shared  bool
+	logger  *zap.Logger
+
+	mu       sync.RWMutex
+	data     map[string]TransactionContext
+	activity map[string]time.Time
 }
 
-// NewTransactionStore returns initialized store.
-func NewTransactionStore() *TransactionStore {
+// NewTransactionStore returns store. Transactions without activity for ttl are
+// considered abandoned and dropped; shared is set when replicas run in a cluster.
+func NewTransactionStore(backend TransactionBackend, ttl time.Duration, shared bool, logger *zap.Logger) *TransactionStore {
 	return &TransactionStore{
-		data: make(map[string]TransactionContext),
+		backend:  backend,
+		ttl:      ttl,
+		shared:   shared,
+		logger:   logger,
+		data:     make(map[string]TransactionContext),
+		activity: make(map[string]time.Time),
 	}
 }
 
-// Set stores context for transaction.
-func (s *TransactionStore) Set(txID string, ctx TransactionContext) {
+// Restore loads persisted transactions and adds active sessions from
+// sessions-service that are missing locally (e.g. started before persistence).
+// Sessions of expired transactions whose close is still queued are skipped.
+func (s *TransactionStore) Restore(ctx context.Context, sessions *clients.SessionsClient) error {
+	persisted, err := s.backend.List(ctx)
+	if err != nil {
+		return err
+	}
+	s.load(persisted)
+	expiredIDs, err := s.backend.ListExpired(ctx)
+	if err != nil {
+		return err
+	}
+	expired := make(map[string]bool, len(expiredIDs))
+	for _, id := range expiredIDs {
+		expired[id] = true
+	}
+
+	active, err := sessions.ListActive(ctx)
+	if err != nil {
+		s.logger.Warn("failed to fetch active sessions, restored persisted transactions only", zap.Error(err))
+		active = nil
+	}
+	recovered := 0
+	for _, session := range active {
+		if _, ok := s.Get(session.TransactionID); ok || expired[session.TransactionID] {
+			continue
+		}
+		// Sessions are only opened for accepted id tags.
+		err := s.Set(ctx, session.TransactionID, TransactionContext{
+			SessionID:   session.ID,
+			MeterStart:  session.MeterStart,
+			StationID:   session.StationID,
+			ConnectorID: session.ConnectorID,
+			UserID:      session.UserID,
+			Authorized:  true,
+		})
+		if err != nil {
+			s.logger.Warn("failed to persist recovered transaction", zap.String("transaction_id", session.TransactionID), zap.Error(err))
+		}
+		recovered++
+	}
+
+	s.logger.Info("transactions restored",
+		zap.Int("persisted", len(persisted)),
+		zap.Int("recovered_from_sessions", recovered),
+		zap.Int("expired_pending_close", len(expiredIDs)),
+	)
+	return nil
+}
+
+// LoadStation reads persisted transactions of station into memory. Used when
+// station connects to this node after being served by another one.
+func (s *TransactionStore) LoadStation(ctx context.Context, stationID string) error {
+	persisted, err := s.backend.ListByStation(ctx, stationID)
+	if err != nil {
+		return err
+	}
+	s.load(persisted)
+	return nil
+}
+
+// EvictStation drops transactions of station from memory; persisted state is kept.
+func (s *TransactionStore) EvictStation(stationID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, tx := range s.data {
+		if tx.StationID == stationID {
+			delete(s.data, id)
+			delete(s.activity, id)
+		}
+	}
+}
+
+// Lookup returns context from memory or, for transactions of stations served
+// by another node, from backend. In shared store an authorized transaction
+// still waiting for session is re-read, as another node may have assigned it.
+func (s *TransactionStore) Lookup(ctx context.Context, txID string) (TransactionContext, bool, error) {
+	cached, ok := s.Get(txID)
+	if ok && !(s.shared && cached.Authorized && cached.SessionID == 0) {
+		return cached, true, nil
+	}
+	persisted, err := s.backend.Get(ctx, txID)
+	if errors.Is(err, repository.ErrTransactionNotFound) {
+		return cached, ok, nil
+	}
+	if err != nil {
+		return TransactionContext{}, false, err
+	}
+	tx := contextOf(*persisted)
+	if ok {
+		s.mu.Lock()
+		s.data[txID] = tx
+		s.mu.Unlock()
+	}
+	return tx, true, nil
+}
+
+// AssignSession records session created for transaction after it started.
+func (s *TransactionStore) AssignSession(ctx context.Context, txID string, sessionID int64) error {
+	s.mu.Lock()
+	if tx, ok := s.data[txID]; ok && tx.SessionID == 0 {
+		tx.SessionID = sessionID
+		s.data[txID] = tx
+	}
+	s.mu.Unlock()
+	return s.backend.AssignSession(ctx, txID, sessionID)
+}
+
+func (s *TransactionStore) load(transactions []models.Transaction) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.data[txID] = ctx
+	for _, tx := range transactions {
+		s.data[tx.ID] = contextOf(tx)
+		s.activity[tx.ID] = tx.UpdatedAt
+	}
+}
+
+func contextOf(tx models.Transaction) TransactionContext {
+	return TransactionContext{
+		SessionID:   tx.SessionID,
+		MeterStart:  tx.MeterStart,
+		MeterLast:   tx.MeterLast,
+		StationID:   tx.StationID,
+		ConnectorID: tx.ConnectorID,
+		IdTag:       tx.IdTag,
+		UserID:      tx.UserID,
+		Authorized:  tx.Authorized,
+	}
+}
+
+// Set stores context for transaction.
+func (s *TransactionStore) Set(ctx context.Context, txID string, tx TransactionContext) error {
+	s.mu.Lock()
+	s.data[txID] = tx
+	s.activity[txID] = time.Now()
+	s.mu.Unlock()
+
+	return s.backend.Save(ctx, &models.Transaction{
+		ID:          txID,
+		StationID:   tx.StationID,
+		ConnectorID: tx.ConnectorID,
+		SessionID:   tx.SessionID,
+		MeterStart:  tx.MeterStart,
+		MeterLast:   tx.MeterLast,
+		IdTag:       tx.IdTag,
+		UserID:      tx.UserID,
+		Authorized:  tx.Authorized,
+	})
 }
 
 // Get returns context and bool.
 func (s *TransactionStore) Get(txID string) (TransactionContext, bool) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	ctx, ok := s.data[txID]
-	return ctx, ok
+	tx, ok := s.data[txID]
+	return tx, ok
+}
+
+// Touch records activity (e.g. meter values) so transaction is not expired;
+// meterWh is latest energy register reading, 0 when samples had none.
+func (s *TransactionStore) Touch(ctx context.Context, txID string, meterWh int64) error {
+	now := time.Now()
+	s.mu.Lock()
+	tx, known := s.data[txID]
+	if known && meterWh > tx.MeterLast {
+		tx.MeterLast = meterWh
+		s.data[txID] = tx
+	}
+	last, ok := s.activity[txID]
+	if !ok || now.Sub(last) < touchInterval {
+		s.mu.Unlock()
+		return nil
+	}
+	s.activity[txID] = now
+	meterLast := tx.MeterLast
+	s.mu.Unlock()
+	return s.backend.Touch(ctx, txID, meterLast)
 }
 
 // Delete removes transaction context.
-func (s *TransactionStore) Delete(txID string) {
+func (s *TransactionStore) Delete(ctx context.Context, txID string) error {
 	s.mu.Lock()
-	defer s.mu.Unlock()
 	delete(s.data, txID)
+	delete(s.activity, txID)
+	s.mu.Unlock()
+	return s.backend.Delete(ctx, txID)
+}
+
+// HasActiveIdTag reports whether id tag already has ongoing authorized transaction.
+func (s *TransactionStore) HasActiveIdTag(idTag string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, tx := range s.data {
+		if tx.Authorized && tx.IdTag == idTag {
+			return true
+		}
+	}
+	return false
+}
+
+// FindByConnector returns ongoing transaction on station connector (EVSE for OCPP 2.0.1).
+func (s *TransactionStore) FindByConnector(stationID string, connectorID int) (string, TransactionContext, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for txID, tx := range s.data {
+		if tx.StationID == stationID && tx.ConnectorID == connectorID {
+			return txID, tx, true
+		}
+	}
+	return "", TransactionContext{}, false
+}
+
+// StartExpiry periodically closes abandoned transactions until ctx is
+// cancelled: their session is stopped through outbox with the last known meter
+// reading.
+func (s *TransactionStore) StartExpiry(ctx context.Context, outbox *Outbox) {
+	if s.ttl <= 0 {
+		return
+	}
+	ticker := time.NewTicker(s.ttl / 12)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.expire(ctx, outbox)
+		}
+	}
+}
+
+func (s *TransactionStore) expire(ctx context.Context, outbox *Outbox) {
+	expired, err := s.backend.ExpireIdle(ctx, time.Now().Add(-s.ttl))
+	if err != nil {
+		s.logger.Warn("transaction expiry failed", zap.Error(err))
+		return
+	}
+	for _, tx := range expired {
+		s.mu.Lock()
+		if cached, ok := s.data[tx.ID]; ok && cached.MeterLast > tx.MeterLast {
+			tx.MeterLast = cached.MeterLast
+		}
+		s.mu.Unlock()
+
+		if err := s.closeExpired(ctx, outbox, tx); err != nil {
+			s.logger.Error("failed to queue close of expired transaction", zap.String("transaction_id", tx.ID), zap.Error(err))
+			// Expired again on next check.
+			if err := s.backend.Unexpire(ctx, tx.ID); err != nil {
+				s.logger.Warn("failed to unexpire transaction", zap.String("transaction_id", tx.ID), zap.Error(err))
+			}
+			continue
+		}
+
+		s.mu.Lock()
+		delete(s.data, tx.ID)
+		delete(s.activity, tx.ID)
+		s.mu.Unlock()
+		s.logger.Warn("abandoned transaction expired",
+			zap.String("transaction_id", tx.ID),
+			zap.String("station_id", tx.StationID),
+			zap.Int("connector_id", tx.ConnectorID),
+			zap.Int64("session_id", tx.SessionID),
+			zap.Int64("meter_last", tx.MeterLast),
+		)
+	}
+}
+
+// closeExpired queues session stop, billing and TransactionStopped for expired
+// transaction, ending it at its last activity. Transaction row is removed once
+// sessions-service accepts the stop (see Closed), right away when there is no
+// session to close.
+func (s *TransactionStore) closeExpired(ctx context.Context, outbox *Outbox, tx models.Transaction) error {
+	if !tx.Authorized {
+		return s.backend.DeleteExpired(ctx, tx.ID)
+	}
+	if err := outbox.Enqueue(ctx, expiredCloseMessages(outbox, tx)...); err != nil {
+		return err
+	}
+	if outbox.targetEnabled(EventSessionStop) {
+		return nil
+	}
+	return s.backend.DeleteExpired(ctx, tx.ID)
+}
+
+func expiredCloseMessages(outbox *Outbox, tx models.Transaction) []OutboxMessage {
+	meterStop := tx.MeterStart
+	if tx.MeterLast > meterStop {
+		meterStop = tx.MeterLast
+	}
+	energyKWh := float64(meterStop-tx.MeterStart) / 1000.0
+	endTime := tx.UpdatedAt.UTC()
+
+	messages := []OutboxMessage{
+		{
+			EventType:   EventSessionStop,
+			AggregateID: tx.ID,
+			Payload: clients.StopSessionRequest{
+				TransactionID: tx.ID,
+				MeterStop:     meterStop,
+				Reason:        expiredStopReason,
+				EnergyKWh:     energyKWh,
+				EndTime:       endTime,
+			},
+		},
+		{
+			EventType:   EventBillingStopped,
+			AggregateID: tx.ID,
+			Payload: clients.BillingStopRequest{
+				SessionID: tx.SessionID,
+				UserID:    tx.UserID,
+				EnergyKWh: energyKWh,
+			},
+		},
+	}
+	return append(messages, outbox.Domain(DomainEvent{
+		Type:          events.TransactionStopped,
+		StationID:     tx.StationID,
+		TransactionID: tx.ID,
+		SessionID:     tx.SessionID,
+		Data: events.TransactionStoppedData{
+			ConnectorID: tx.ConnectorID,
+			UserID:      tx.UserID,
+			MeterStop:   meterStop,
+			EnergyKWh:   energyKWh,
+			Reason:      expiredStopReason,
+			StoppedAt:   endTime,
+		},
+	})...)
+}
+
+// Expired reports whether transaction was closed on expiry and its close is
+// still pending delivery. Such transaction is already stopped downstream.
+func (s *TransactionStore) Expired(ctx context.Context, txID string) (bool, error) {
+	return s.backend.IsExpired(ctx, txID)
+}
+
+// Closed removes expired transaction after its session stop was delivered.
+func (s *TransactionStore) Closed(ctx context.Context, txID string) error {
+	return s.backend.DeleteExpired(ctx, txID)
 }
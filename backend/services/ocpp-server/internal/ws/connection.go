@@ -2,12 +2,20 @@ package ws
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/gorilla/websocket"
 	"go.uber.org/zap"
 )
 
+var (
+	// ErrSendBufferFull is returned when outgoing queue of connection is full.
+	ErrSendBufferFull = errors.New("ws: send buffer full")
+	// ErrConnectionClosed is returned when connection is already closed.
+	ErrConnectionClosed = errors.New("ws: connection closed")
+)
+
 // MessageProcessor handles raw OCPP messages.
 type MessageProcessor interface {
 	Process(ctx context.Context, stationID string, raw []byte) ([]byte, error)
@@ -16,23 +24,29 @@ type MessageProcessor interface {
 // Connection represents active station WebSocket connection.
 type Connection struct {
 	stationID    string
+	subprotocol  string
 	ws           *websocket.Conn
 	send         chan []byte
 	logger       *zap.Logger
 	processor    MessageProcessor
 	writeTimeout time.Duration
-	onClose      func(stationID string)
+	remoteAddr   string
+	connectedAt  time.Time
+	onClose      func(conn *Connection)
 }
 
 // NewConnection builds connection wrapper.
-func NewConnection(stationID string, ws *websocket.Conn, processor MessageProcessor, writeTimeout time.Duration, logger *zap.Logger, onClose func(string)) *Connection {
+func NewConnection(stationID, subprotocol string, ws *websocket.Conn, processor MessageProcessor, writeTimeout time.Duration, logger *zap.Logger, onClose func(*Connection)) *Connection {
 	return &Connection{
 		stationID:    stationID,
+		subprotocol:  subprotocol,
 		ws:           ws,
 		send:         make(chan []byte, 16),
 		logger:       logger,
 		processor:    processor,
 		writeTimeout: writeTimeout,
+		remoteAddr:   ws.RemoteAddr().String(),
+		connectedAt:  time.Now().UTC(),
 		onClose:      onClose,
 	}
 }
@@ -42,6 +56,21 @@ func (c *Connection) StationID() string {
 	return c.stationID
 }
 
+// Subprotocol returns OCPP version negotiated for connection.
+func (c *Connection) Subprotocol() string {
+	return c.subprotocol
+}
+
+// RemoteAddr returns network address of station.
+func (c *Connection) RemoteAddr() string {
+	return c.remoteAddr
+}
+
+// ConnectedAt returns time the connection was established.
+func (c *Connection) ConnectedAt() time.Time {
+	return c.connectedAt
+}
+
 // Start launches read/write pumps.
 func (c *Connection) Start(ctx context.Context) {
 	go c.writePump(ctx)
@@ -71,6 +100,11 @@ func (c *Connection) readPump(ctx context.Context) {
 		}
 
 		response, err := c.processor.Process(ctx, c.stationID, message)
+		if errors.Is(err, ErrDraining) {
+			// Not answered: station repeats the message after reconnecting.
+			c.logger.Debug("message refused while draining", zap.String("station_id", c.stationID))
+			continue
+		}
 		if err != nil {
 			c.logger.Warn("failed to process message", zap.String("station_id", c.stationID), zap.Error(err))
 			continue
@@ -106,16 +140,19 @@ func (c *Connection) writePump(ctx context.Context) {
 }
 
 // Send enqueues a message for writing.
-func (c *Connection) Send(msg []byte) {
+func (c *Connection) Send(msg []byte) (err error) {
 	defer func() {
 		if r := recover(); r != nil {
 			c.logger.Warn("attempted to send on closed channel", zap.String("station_id", c.stationID))
+			err = ErrConnectionClosed
 		}
 	}()
 	select {
 	case c.send <- msg:
+		return nil
 	default:
 		c.logger.Warn("dropping outgoing message, buffer full", zap.String("station_id", c.stationID))
+		return ErrSendBufferFull
 	}
 }
 
@@ -124,6 +161,27 @@ func (c *Connection) Ping() error {
 	return c.write(websocket.PingMessage, []byte("ping"))
 }
 
+// Close sends close frame with code and reason and drops the socket; the read
+// pump then exits and runs cleanup.
+func (c *Connection) Close(code int, reason string) {
+	deadline := time.Now().Add(c.writeTimeout)
+	if err := c.ws.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), deadline); err != nil {
+		c.logger.Debug("failed to send close frame", zap.String("station_id", c.stationID), zap.Error(err))
+	}
+	_ = c.ws.Close()
+}
+
+func (c *Connection) event(eventType, reason string) ConnectionEvent {
+	return ConnectionEvent{
+		Type:        eventType,
+		StationID:   c.stationID,
+		RemoteAddr:  c.remoteAddr,
+		Subprotocol: c.subprotocol,
+		Reason:      reason,
+		At:          time.Now().UTC(),
+	}
+}
+
 func (c *Connection) write(messageType int, data []byte) error {
 	c.ws.SetWriteDeadline(time.Now().Add(c.writeTimeout))
 	return c.ws.WriteMessage(messageType, data)
@@ -133,6 +191,6 @@ func (c *Connection) cleanup() {
 	close(c.send)
 	_ = c.ws.Close()
 	if c.onClose != nil {
-		c.onClose(c.stationID)
+		c.onClose(c)
 	}
 }
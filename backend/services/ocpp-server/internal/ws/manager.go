@@ -2,40 +2,253 @@ package ws
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrStationNotConnected is returned when station has no active connection.
+	ErrStationNotConnected = errors.New("ws: station not connected")
+	// ErrDraining is returned for station CALLs arriving after drain has started.
+	ErrDraining = errors.New("ws: server is draining")
+)
+
+// drainPoll and drainReport pace waiting and progress logging during drain.
+const (
+	drainPoll   = 50 * time.Millisecond
+	drainReport = time.Second
+)
+
+// messageTypeCall is OCPP-J message type of station requests.
+const messageTypeCall = 2
+
+// Connection event types.
+const (
+	EventConnected    = "connected"
+	EventDisconnected = "disconnected"
+)
+
+// Disconnect reasons.
+const (
+	ReasonClosed   = "closed"
+	ReasonReplaced = "replaced"
 )
 
-// Manager tracks station connections.
+// ConnectionEvent describes station connection going online or offline.
+type ConnectionEvent struct {
+	Type        string    `json:"type"`
+	StationID   string    `json:"station_id"`
+	RemoteAddr  string    `json:"remote_addr"`
+	Subprotocol string    `json:"subprotocol"`
+	Reason      string    `json:"reason,omitempty"`
+	At          time.Time `json:"at"`
+}
+
+// ConnectionListener receives connection events. Listeners are called
+// synchronously and must not block.
+type ConnectionListener func(ConnectionEvent)
+
+// Manager tracks station connections and messages being processed on them.
 type Manager struct {
-	mu          sync.RWMutex
-	connections map[string]*Connection
+	mu           sync.RWMutex
+	connections  map[string]*Connection
+	listeners    []ConnectionListener
 	pingInterval time.Duration
+	logger       *zap.Logger
+
+	draining atomic.Bool
+	inflight atomic.Int64
 }
 
 // NewManager builds connection manager.
-func NewManager(pingInterval time.Duration) *Manager {
+func NewManager(pingInterval time.Duration, logger *zap.Logger) *Manager {
 	if pingInterval <= 0 {
 		pingInterval = 30 * time.Second
 	}
 	return &Manager{
-		connections: make(map[string]*Connection),
+		connections:  make(map[string]*Connection),
 		pingInterval: pingInterval,
+		logger:       logger,
 	}
 }
 
-// Add registers new connection.
-func (m *Manager) Add(conn *Connection) {
+// Subscribe registers listener for connection events. Must be called before Start.
+func (m *Manager) Subscribe(listener ConnectionListener) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	m.listeners = append(m.listeners, listener)
+}
+
+// Add registers new connection. Previous connection of the same station (a
+// reconnect before the old socket timed out) is closed and reported as disconnected.
+func (m *Manager) Add(conn *Connection) {
+	m.mu.Lock()
+	previous, replaced := m.connections[conn.StationID()]
 	m.connections[conn.StationID()] = conn
+	m.mu.Unlock()
+
+	if replaced && previous != conn {
+		previous.Close(websocket.ClosePolicyViolation, "replaced by new connection")
+		m.publish(previous.event(EventDisconnected, ReasonReplaced))
+	}
+	m.publish(conn.event(EventConnected, ""))
 }
 
-// Remove removes connection.
-func (m *Manager) Remove(stationID string) {
-	m.mu.Lock();
-	defer m.mu.Unlock()
-	delete(m.connections, stationID)
+// Remove removes connection if it is still the active one of its station.
+// Returns false when the connection was already replaced.
+func (m *Manager) Remove(conn *Connection) bool {
+	m.mu.Lock()
+	current, ok := m.connections[conn.StationID()]
+	if !ok || current != conn {
+		m.mu.Unlock()
+		return false
+	}
+	delete(m.connections, conn.StationID())
+	m.mu.Unlock()
+
+	m.publish(conn.event(EventDisconnected, ReasonClosed))
+	return true
+}
+
+// Get returns active connection of station.
+func (m *Manager) Get(stationID string) (*Connection, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	conn, ok := m.connections[stationID]
+	return conn, ok
+}
+
+// Stations returns IDs of stations connected to this process.
+func (m *Manager) Stations() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	ids := make([]string, 0, len(m.connections))
+	for id := range m.connections {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// Disconnect closes connection of station with close code and reason. The
+// read loop then removes it and reports it as disconnected.
+func (m *Manager) Disconnect(stationID string, code int, reason string) bool {
+	conn, ok := m.Get(stationID)
+	if !ok {
+		return false
+	}
+	conn.Close(code, reason)
+	return true
+}
+
+// Draining reports whether manager stopped accepting stations and messages.
+func (m *Manager) Draining() bool {
+	return m.draining.Load()
+}
+
+// Drain stops accepting new connections and incoming messages and waits until
+// messages already being processed are answered. Connections stay open.
+func (m *Manager) Drain(ctx context.Context) error {
+	m.draining.Store(true)
+	m.logger.Info("draining station connections",
+		zap.Int("connections", len(m.Stations())),
+		zap.Int64("in_flight", m.inflight.Load()),
+	)
+	err := m.wait(ctx, func() int64 { return m.inflight.Load() }, "waiting for in-flight messages")
+	if err != nil {
+		m.logger.Warn("drain interrupted, in-flight messages abandoned", zap.Int64("in_flight", m.inflight.Load()))
+		return err
+	}
+	m.logger.Info("in-flight messages finished")
+	return nil
+}
+
+// CloseAll sends close frame to every station and waits until their
+// connections are cleaned up.
+func (m *Manager) CloseAll(ctx context.Context, code int, reason string) error {
+	stations := m.Stations()
+	for _, id := range stations {
+		m.Disconnect(id, code, reason)
+	}
+	err := m.wait(ctx, func() int64 { return int64(len(m.Stations())) }, "waiting for connections to close")
+	if err != nil {
+		m.logger.Warn("connections left open", zap.Int("connections", len(m.Stations())))
+		return err
+	}
+	m.logger.Info("station connections closed", zap.Int("closed", len(stations)))
+	return nil
+}
+
+// track wraps processor so that its calls are counted for drain and station
+// CALLs are refused once drain has started. Replies to CSMS calls still pass,
+// so pending callers complete instead of waiting for their timeout.
+func (m *Manager) track(processor MessageProcessor) MessageProcessor {
+	return trackedProcessor{manager: m, processor: processor}
+}
+
+type trackedProcessor struct {
+	manager   *Manager
+	processor MessageProcessor
+}
+
+func (p trackedProcessor) Process(ctx context.Context, stationID string, raw []byte) ([]byte, error) {
+	// Counted before the check so Drain cannot miss a message that got past it.
+	p.manager.inflight.Add(1)
+	defer p.manager.inflight.Add(-1)
+	if p.manager.draining.Load() && isCall(raw) {
+		return nil, ErrDraining
+	}
+	return p.processor.Process(ctx, stationID, raw)
+}
+
+// isCall reports whether frame is OCPP-J CALL (message type 2). Malformed
+// frames count as CALL.
+func isCall(raw []byte) bool {
+	var frame []json.RawMessage
+	if err := json.Unmarshal(raw, &frame); err != nil || len(frame) == 0 {
+		return true
+	}
+	var messageType int
+	if err := json.Unmarshal(frame[0], &messageType); err != nil {
+		return true
+	}
+	return messageType == messageTypeCall
+}
+
+// wait polls remaining until it reaches zero, logging progress every drainReport.
+func (m *Manager) wait(ctx context.Context, remaining func() int64, message string) error {
+	ticker := time.NewTicker(drainPoll)
+	defer ticker.Stop()
+	lastReport := time.Now()
+	for {
+		left := remaining()
+		if left == 0 {
+			return nil
+		}
+		if time.Since(lastReport) >= drainReport {
+			m.logger.Info(message, zap.Int64("remaining", left))
+			lastReport = time.Now()
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// SendTo enqueues raw frame for station connection.
+func (m *Manager) SendTo(stationID string, frame []byte) error {
+	conn, ok := m.Get(stationID)
+	if !ok {
+		return ErrStationNotConnected
+	}
+	return conn.Send(frame)
 }
 
 // Start begins ping loop to keep connections active.
@@ -55,3 +268,12 @@ func (m *Manager) Start(ctx context.Context) {
 		}
 	}
 }
+
+func (m *Manager) publish(event ConnectionEvent) {
+	m.mu.RLock()
+	listeners := m.listeners
+	m.mu.RUnlock()
+	for _, listener := range listeners {
+		listener(event)
+	}
+}
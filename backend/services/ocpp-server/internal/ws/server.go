@@ -3,43 +3,96 @@ package ws
 import (
 	"context"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gorilla/websocket"
 	"go.uber.org/zap"
 )
 
+// Authenticator resolves and verifies station identity of upgrade request.
+type Authenticator interface {
+	Authenticate(r *http.Request, stationID string) (string, error)
+}
+
+// Subprotocol binds websocket subprotocol (OCPP version) to its message processor.
+type Subprotocol struct {
+	Name      string
+	Processor MessageProcessor
+}
+
 // Server upgrades HTTP connections to WebSockets for OCPP.
 type Server struct {
 	manager      *Manager
-	processor    MessageProcessor
+	processors   map[string]MessageProcessor
+	fallback     string
+	auth         Authenticator
 	logger       *zap.Logger
 	writeTimeout time.Duration
 	upgrader     websocket.Upgrader
 }
 
-// NewServer builds ws server.
-func NewServer(manager *Manager, processor MessageProcessor, writeTimeout time.Duration, logger *zap.Logger) *Server {
+// NewServer builds ws server. Subprotocols are listed in server preference
+// order; stations that request no subprotocol are served by fallback.
+// Origin is checked by upgrader default: stations send none, browsers must be same-origin.
+func NewServer(manager *Manager, subprotocols []Subprotocol, fallback string, auth Authenticator, writeTimeout time.Duration, logger *zap.Logger) *Server {
+	names := make([]string, 0, len(subprotocols))
+	processors := make(map[string]MessageProcessor, len(subprotocols))
+	for _, sp := range subprotocols {
+		names = append(names, sp.Name)
+		processors[sp.Name] = manager.track(sp.Processor)
+	}
 	return &Server{
-		manager:   manager,
-		processor: processor,
-		logger:    logger,
+		manager:      manager,
+		processors:   processors,
+		fallback:     fallback,
+		auth:         auth,
+		logger:       logger,
 		writeTimeout: writeTimeout,
 		upgrader: websocket.Upgrader{
 			ReadBufferSize:  1024,
 			WriteBufferSize: 1024,
-			CheckOrigin: func(r *http.Request) bool {
-				return true
-			},
+			Subprotocols:    names,
 		},
 	}
 }
 
-// HandleWS is HTTP handler for /ocpp/ws endpoint.
+// HandleWS is HTTP handler for /ocpp/{chargePointId} and legacy /ocpp/ws?station_id= endpoints.
 func (s *Server) HandleWS(w http.ResponseWriter, r *http.Request) {
-	stationID := r.URL.Query().Get("station_id")
+	if s.manager.Draining() {
+		// Station retries and reaches another replica or the restarted one.
+		w.Header().Set("Retry-After", "5")
+		http.Error(w, "service restarting", http.StatusServiceUnavailable)
+		return
+	}
+
+	stationID := r.PathValue("id")
+	if stationID == "" {
+		stationID = r.URL.Query().Get("station_id")
+	}
+	if s.auth != nil {
+		authenticated, err := s.auth.Authenticate(r, stationID)
+		if err != nil {
+			s.logger.Warn("station authentication failed", zap.String("station_id", stationID), zap.Error(err))
+			w.Header().Set("WWW-Authenticate", `Basic realm="ocpp"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		stationID = authenticated
+	}
 	if stationID == "" {
-		http.Error(w, "station_id is required", http.StatusBadRequest)
+		http.Error(w, "station id is required", http.StatusBadRequest)
+		return
+	}
+	if !ValidStationID(stationID) {
+		http.Error(w, "invalid station id", http.StatusBadRequest)
+		return
+	}
+
+	requested := websocket.Subprotocols(r)
+	if len(requested) > 0 && s.negotiate(requested) == "" {
+		s.logger.Warn("unsupported ocpp subprotocol", zap.String("station_id", stationID), zap.Strings("requested", requested))
+		http.Error(w, "unsupported subprotocol", http.StatusBadRequest)
 		return
 	}
 
@@ -49,13 +102,46 @@ func (s *Server) HandleWS(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	subprotocol := conn.Subprotocol()
+	if subprotocol == "" {
+		subprotocol = s.fallback
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
-	connection := NewConnection(stationID, conn, s.processor, s.writeTimeout, s.logger, func(id string) {
-		s.manager.Remove(id)
+	connection := NewConnection(stationID, subprotocol, conn, s.processors[subprotocol], s.writeTimeout, s.logger, func(c *Connection) {
+		s.manager.Remove(c)
 		cancel()
 	})
 	s.manager.Add(connection)
 
 	go connection.Start(ctx)
-	s.logger.Info("station connected", zap.String("station_id", stationID))
+}
+
+// ValidStationID reports whether id is valid OCPP charge point identity:
+// 1-48 characters from RFC 3986 "unreserved" set plus *=:+|@.
+func ValidStationID(id string) bool {
+	if id == "" || len(id) > 48 {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case strings.ContainsRune("-._~*=:+|@", c):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// negotiate returns first supported subprotocol (server preference) requested by station.
+func (s *Server) negotiate(requested []string) string {
+	for _, name := range s.upgrader.Subprotocols {
+		for _, candidate := range requested {
+			if candidate == name {
+				return name
+			}
+		}
+	}
+	return ""
 }
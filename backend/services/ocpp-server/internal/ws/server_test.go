@@ -0,0 +1,87 @@
+package ws
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// recordingAuth accepts any station and remembers the identity it was given.
+type recordingAuth struct {
+	stationID string
+	called    bool
+}
+
+func (a *recordingAuth) Authenticate(_ *http.Request, stationID string) (string, error) {
+	a.stationID, a.called = stationID, true
+	return stationID, nil
+}
+
+func TestHandleWSStationID(t *testing.T) {
+	tests := []struct {
+		name        string
+		target      string
+		wantID      string
+		wantInvalid bool
+	}{
+		{name: "path", target: "/ocpp/CP-001", wantID: "CP-001"},
+		{name: "percent-decoded path", target: "/ocpp/CP%7C01%40site", wantID: "CP|01@site"},
+		{name: "legacy query", target: "/ocpp/ws?station_id=CP-002", wantID: "CP-002"},
+		{name: "legacy endpoint without id", target: "/ocpp/ws", wantID: "", wantInvalid: true},
+		{name: "48 characters", target: "/ocpp/" + strings.Repeat("a", 48), wantID: strings.Repeat("a", 48)},
+		{name: "49 characters", target: "/ocpp/" + strings.Repeat("a", 49), wantID: strings.Repeat("a", 49), wantInvalid: true},
+		{name: "space", target: "/ocpp/CP%2001", wantID: "CP 01", wantInvalid: true},
+		{name: "encoded slash", target: "/ocpp/CP%2F01", wantID: "CP/01", wantInvalid: true},
+		{name: "non-ASCII", target: "/ocpp/CP-%C3%A9", wantID: "CP-é", wantInvalid: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auth := &recordingAuth{}
+			server := NewServer(NewManager(time.Minute, zap.NewNop()), nil, "ocpp1.6", auth, time.Second, zap.NewNop())
+			mux := http.NewServeMux()
+			mux.HandleFunc("/ocpp/ws", server.HandleWS)
+			mux.HandleFunc("/ocpp/{id}", server.HandleWS)
+
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			if !auth.called || auth.stationID != tt.wantID {
+				t.Fatalf("station id = %q (called %v), want %q", auth.stationID, auth.called, tt.wantID)
+			}
+			// Valid identity reaches the websocket upgrade, which rejects a plain request.
+			rejected := rec.Code == http.StatusBadRequest && strings.Contains(rec.Body.String(), "station id")
+			if rejected != tt.wantInvalid {
+				t.Fatalf("response %d %q, want invalid = %v", rec.Code, rec.Body.String(), tt.wantInvalid)
+			}
+		})
+	}
+}
+
+func TestValidStationID(t *testing.T) {
+	tests := []struct {
+		id   string
+		want bool
+	}{
+		{"CP-001", true},
+		{"a", true},
+		{"-._~*=:+|@", true},
+		{strings.Repeat("Z", 48), true},
+		{"", false},
+		{strings.Repeat("Z", 49), false},
+		{"CP 001", false},
+		{"CP/001", false},
+		{"CP#1", false},
+		{"CP?1", false},
+		{"CP%1", false},
+		{"СР-001", false},
+	}
+	for _, tt := range tests {
+		if got := ValidStationID(tt.id); got != tt.want {
+			t.Errorf("ValidStationID(%q) = %v, want %v", tt.id, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,2 @@
+-- OCPP 1.6 transaction IDs are integers assigned by the central system.
+CREATE SEQUENCE IF NOT EXISTS ocpp_transaction_id_seq AS INTEGER START WITH 1;
@@ -0,0 +1,7 @@
+-- Provisioning state of station: pending -> accepted -> decommissioned.
+-- Stations registered before the registry existed stay accepted.
+ALTER TABLE charging_stations
+    ADD COLUMN IF NOT EXISTS registration_status TEXT NOT NULL DEFAULT 'accepted',
+    ADD COLUMN IF NOT EXISTS heartbeat_interval INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_charging_stations_registration_status ON charging_stations(registration_status);
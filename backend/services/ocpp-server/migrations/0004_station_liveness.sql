@@ -0,0 +1,3 @@
+-- Liveness monitor scans stations by heartbeat age and lists the ones that are down.
+CREATE INDEX IF NOT EXISTS idx_charging_stations_last_heartbeat ON charging_stations(last_heartbeat);
+CREATE INDEX IF NOT EXISTS idx_charging_stations_status ON charging_stations(status);
@@ -0,0 +1,17 @@
+-- Ongoing transactions; rows are removed on StopTransaction/TransactionEvent(Ended)
+-- or when no activity was seen for the configured TTL.
+CREATE TABLE IF NOT EXISTS ocpp_transactions (
+    transaction_id TEXT PRIMARY KEY,
+    station_id TEXT NOT NULL,
+    connector_id INTEGER NOT NULL DEFAULT 0,
+    session_id BIGINT NOT NULL DEFAULT 0,
+    meter_start BIGINT NOT NULL DEFAULT 0,
+    id_tag TEXT NOT NULL DEFAULT '',
+    user_id BIGINT NOT NULL DEFAULT 0,
+    authorized BOOLEAN NOT NULL DEFAULT FALSE,
+    started_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_ocpp_transactions_station ON ocpp_transactions(station_id, connector_id);
+CREATE INDEX IF NOT EXISTS idx_ocpp_transactions_updated_at ON ocpp_transactions(updated_at);
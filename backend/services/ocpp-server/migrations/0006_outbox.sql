@@ -0,0 +1,18 @@
+-- Notifications for sessions/billing/telemetry. Written before the station gets
+-- its answer and delivered by dispatcher with retries; events of the same
+-- aggregate (transaction) are delivered in order.
+CREATE TABLE IF NOT EXISTS ocpp_outbox (
+    id BIGSERIAL PRIMARY KEY,
+    event_type TEXT NOT NULL,
+    aggregate_id TEXT NOT NULL DEFAULT '',
+    payload JSONB NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_ocpp_outbox_due ON ocpp_outbox(status, next_attempt_at);
+CREATE INDEX IF NOT EXISTS idx_ocpp_outbox_aggregate ON ocpp_outbox(aggregate_id, id) WHERE status = 'pending';
@@ -0,0 +1,58 @@
+-- OCPP message log partitioned by day (UTC). Partitions are created ahead by
+-- ocpp-server and dropped after retention period. Existing rows are moved.
+BEGIN;
+
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM pg_class WHERE relname = 'ocpp_messages' AND relkind = 'r') THEN
+        ALTER TABLE ocpp_messages RENAME TO ocpp_messages_legacy;
+        ALTER SEQUENCE IF EXISTS ocpp_messages_id_seq RENAME TO ocpp_messages_legacy_id_seq;
+    END IF;
+END $$;
+
+CREATE TABLE IF NOT EXISTS ocpp_messages (
+    id BIGSERIAL,
+    station_id TEXT NOT NULL,
+    direction TEXT NOT NULL,
+    frame_type TEXT NOT NULL DEFAULT '',
+    unique_id TEXT NOT NULL DEFAULT '',
+    message_type TEXT NOT NULL DEFAULT '',
+    payload JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (id, created_at)
+) PARTITION BY RANGE (created_at);
+
+CREATE INDEX IF NOT EXISTS idx_ocpp_messages_station ON ocpp_messages(station_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_ocpp_messages_unique_id ON ocpp_messages(unique_id) WHERE unique_id <> '';
+
+-- ocpp_messages_ensure_partition creates partition for UTC day and returns its name.
+CREATE OR REPLACE FUNCTION ocpp_messages_ensure_partition(day DATE) RETURNS TEXT AS $$
+DECLARE
+    name TEXT := 'ocpp_messages_p' || to_char(day, 'YYYYMMDD');
+BEGIN
+    EXECUTE format(
+        'CREATE TABLE IF NOT EXISTS %I PARTITION OF ocpp_messages FOR VALUES FROM (%L) TO (%L)',
+        name,
+        day::timestamp AT TIME ZONE 'UTC',
+        (day + 1)::timestamp AT TIME ZONE 'UTC'
+    );
+    RETURN name;
+END;
+$$ LANGUAGE plpgsql;
+
+SELECT ocpp_messages_ensure_partition((NOW() AT TIME ZONE 'UTC')::date + n) FROM generate_series(0, 2) AS n;
+
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM pg_class WHERE relname = 'ocpp_messages_legacy' AND relkind = 'r') THEN
+        PERFORM ocpp_messages_ensure_partition(day)
+        FROM (SELECT DISTINCT (created_at AT TIME ZONE 'UTC')::date AS day FROM ocpp_messages_legacy) AS days;
+
+        INSERT INTO ocpp_messages (station_id, direction, message_type, payload, created_at)
+        SELECT station_id, direction, message_type, payload, created_at FROM ocpp_messages_legacy;
+
+        DROP TABLE ocpp_messages_legacy;
+    END IF;
+END $$;
+
+COMMIT;
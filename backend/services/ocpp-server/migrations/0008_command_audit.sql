@@ -0,0 +1,16 @@
+-- CSMS-initiated commands sent to stations together with the station answer,
+-- kept for support investigations.
+CREATE TABLE IF NOT EXISTS ocpp_command_audit (
+    id BIGSERIAL PRIMARY KEY,
+    station_id TEXT NOT NULL,
+    action TEXT NOT NULL,
+    request JSONB NOT NULL,
+    response JSONB,
+    status TEXT NOT NULL DEFAULT '',
+    error TEXT NOT NULL DEFAULT '',
+    requested_by BIGINT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_ocpp_command_audit_station ON ocpp_command_audit(station_id, created_at DESC);
@@ -0,0 +1,33 @@
+-- Desired configuration keys of stations and station groups. Station keys
+-- override keys of the group the station belongs to.
+CREATE TABLE IF NOT EXISTS ocpp_config_desired (
+    scope TEXT NOT NULL CHECK (scope IN ('station', 'group')),
+    target TEXT NOT NULL,
+    key TEXT NOT NULL,
+    value TEXT NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (scope, target, key)
+);
+
+-- Configuration group of station.
+CREATE TABLE IF NOT EXISTS ocpp_config_groups (
+    station_id TEXT PRIMARY KEY,
+    group_name TEXT NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_ocpp_config_groups_group ON ocpp_config_groups(group_name);
+
+-- Result of last sync per desired key: value reported by GetConfiguration and
+-- outcome of ChangeConfiguration.
+CREATE TABLE IF NOT EXISTS ocpp_config_reported (
+    station_id TEXT NOT NULL,
+    key TEXT NOT NULL,
+    desired_value TEXT NOT NULL,
+    reported_value TEXT,
+    readonly BOOLEAN NOT NULL DEFAULT FALSE,
+    sync_status TEXT NOT NULL,
+    error TEXT NOT NULL DEFAULT '',
+    synced_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (station_id, key)
+);
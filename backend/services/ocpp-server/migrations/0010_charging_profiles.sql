@@ -0,0 +1,20 @@
+-- Smart charging profiles installed by CSMS. Profile of the same purpose and
+-- stack level on the same connector (and transaction for TxProfile) would
+-- replace each other on the station, so it is unique here too.
+CREATE TABLE IF NOT EXISTS ocpp_charging_profiles (
+    id BIGSERIAL PRIMARY KEY,
+    station_id TEXT NOT NULL,
+    connector_id INTEGER NOT NULL,
+    purpose TEXT NOT NULL,
+    stack_level INTEGER NOT NULL,
+    transaction_id INTEGER,
+    profile JSONB NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_ocpp_charging_profiles_slot
+    ON ocpp_charging_profiles(station_id, connector_id, purpose, stack_level, COALESCE(transaction_id, 0));
+CREATE INDEX IF NOT EXISTS idx_ocpp_charging_profiles_station ON ocpp_charging_profiles(station_id, status);
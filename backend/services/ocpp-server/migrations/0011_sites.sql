@@ -0,0 +1,40 @@
+-- Sites share one grid connection; current of their active transactions is
+-- limited so that the site stays below max_current (A per phase).
+CREATE TABLE IF NOT EXISTS ocpp_sites (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL DEFAULT '',
+    max_current DOUBLE PRECISION NOT NULL CHECK (max_current > 0),
+    min_current DOUBLE PRECISION NOT NULL DEFAULT 6,
+    connector_max_current DOUBLE PRECISION NOT NULL DEFAULT 32,
+    strategy TEXT NOT NULL DEFAULT 'equal' CHECK (strategy IN ('equal', 'priority', 'first_come')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Station belongs to at most one site.
+CREATE TABLE IF NOT EXISTS ocpp_site_stations (
+    station_id TEXT PRIMARY KEY,
+    site_id TEXT NOT NULL REFERENCES ocpp_sites(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_ocpp_site_stations_site ON ocpp_site_stations(site_id);
+
+-- Priority tier of user for the priority strategy; higher tier is served first.
+CREATE TABLE IF NOT EXISTS ocpp_user_tiers (
+    user_id BIGINT PRIMARY KEY,
+    tier INTEGER NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Last measured current and limit pushed as TxProfile per active transaction.
+CREATE TABLE IF NOT EXISTS ocpp_site_allocations (
+    transaction_id TEXT PRIMARY KEY,
+    site_id TEXT NOT NULL,
+    station_id TEXT NOT NULL,
+    connector_id INTEGER NOT NULL,
+    measured_current DOUBLE PRECISION,
+    limit_current DOUBLE PRECISION,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_ocpp_site_allocations_site ON ocpp_site_allocations(site_id);
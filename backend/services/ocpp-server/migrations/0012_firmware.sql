@@ -0,0 +1,44 @@
+-- Firmware images. Uploaded images are stored on disk of ocpp-server and
+-- served under /firmware/{id}/{file_name}; registered ones have external url.
+CREATE TABLE IF NOT EXISTS ocpp_firmware_artifacts (
+    id BIGSERIAL PRIMARY KEY,
+    version TEXT NOT NULL,
+    file_name TEXT NOT NULL DEFAULT '',
+    url TEXT NOT NULL DEFAULT '',
+    size BIGINT NOT NULL DEFAULT 0,
+    sha256 TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Rollout of artifact to stations of vendor and/or model, batch by batch.
+CREATE TABLE IF NOT EXISTS ocpp_firmware_campaigns (
+    id BIGSERIAL PRIMARY KEY,
+    name TEXT NOT NULL DEFAULT '',
+    artifact_id BIGINT NOT NULL REFERENCES ocpp_firmware_artifacts(id),
+    vendor TEXT NOT NULL DEFAULT '',
+    model TEXT NOT NULL DEFAULT '',
+    batch_size INTEGER NOT NULL CHECK (batch_size > 0),
+    failure_threshold INTEGER NOT NULL CHECK (failure_threshold BETWEEN 0 AND 100),
+    current_batch INTEGER NOT NULL DEFAULT 0,
+    status TEXT NOT NULL DEFAULT 'running' CHECK (status IN ('running', 'paused', 'halted', 'completed')),
+    created_by BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_ocpp_firmware_campaigns_status ON ocpp_firmware_campaigns(status);
+
+-- Progress of each targeted station; firmware_version is the version before update.
+CREATE TABLE IF NOT EXISTS ocpp_firmware_campaign_stations (
+    campaign_id BIGINT NOT NULL REFERENCES ocpp_firmware_campaigns(id) ON DELETE CASCADE,
+    station_id TEXT NOT NULL,
+    batch INTEGER NOT NULL,
+    status TEXT NOT NULL,
+    error TEXT NOT NULL DEFAULT '',
+    firmware_version TEXT NOT NULL DEFAULT '',
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (campaign_id, station_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_ocpp_firmware_campaign_stations_station ON ocpp_firmware_campaign_stations(station_id, status);
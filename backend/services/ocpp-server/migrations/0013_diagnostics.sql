@@ -0,0 +1,24 @@
+-- Subprotocol of last BootNotification; decides which command requests logs.
+ALTER TABLE charging_stations
+    ADD COLUMN IF NOT EXISTS ocpp_version TEXT NOT NULL DEFAULT '';
+
+-- Log requests (GetDiagnostics / GetLog) and bundles uploaded by stations to
+-- /diagnostics/{token}. Files are stored on disk of ocpp-server.
+CREATE TABLE IF NOT EXISTS ocpp_diagnostics (
+    id BIGSERIAL PRIMARY KEY,
+    station_id TEXT NOT NULL,
+    kind TEXT NOT NULL CHECK (kind IN ('diagnostics', 'log')),
+    log_type TEXT NOT NULL DEFAULT '',
+    token TEXT NOT NULL UNIQUE,
+    status TEXT NOT NULL DEFAULT 'requested',
+    status_detail TEXT NOT NULL DEFAULT '',
+    file_name TEXT NOT NULL DEFAULT '',
+    size BIGINT NOT NULL DEFAULT 0,
+    sha256 TEXT NOT NULL DEFAULT '',
+    requested_by BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    uploaded_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_ocpp_diagnostics_station ON ocpp_diagnostics(station_id, created_at DESC);
@@ -0,0 +1,30 @@
+-- Connector bookings. id is reservationId of ReserveNow; open bookings of one
+-- connector must not overlap.
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+CREATE TABLE IF NOT EXISTS ocpp_reservations (
+    id BIGSERIAL PRIMARY KEY,
+    station_id TEXT NOT NULL,
+    connector_id INTEGER NOT NULL CHECK (connector_id > 0),
+    user_id BIGINT NOT NULL DEFAULT 0,
+    id_tag TEXT NOT NULL,
+    start_at TIMESTAMPTZ NOT NULL,
+    end_at TIMESTAMPTZ NOT NULL,
+    status TEXT NOT NULL DEFAULT 'scheduled'
+        CHECK (status IN ('scheduled', 'reserving', 'active', 'used', 'cancelled', 'expired', 'failed')),
+    status_detail TEXT NOT NULL DEFAULT '',
+    transaction_id TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (end_at > start_at),
+    CONSTRAINT ocpp_reservations_no_overlap EXCLUDE USING gist (
+        station_id WITH =,
+        connector_id WITH =,
+        tstzrange(start_at, end_at) WITH &&
+    ) WHERE (status IN ('scheduled', 'reserving', 'active'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_ocpp_reservations_open ON ocpp_reservations(status, start_at)
+    WHERE status IN ('scheduled', 'reserving', 'active');
+CREATE INDEX IF NOT EXISTS idx_ocpp_reservations_user ON ocpp_reservations(user_id, id DESC);
+CREATE INDEX IF NOT EXISTS idx_ocpp_reservations_station ON ocpp_reservations(station_id, start_at DESC);
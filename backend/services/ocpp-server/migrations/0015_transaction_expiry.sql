@@ -0,0 +1,6 @@
+-- Last energy register reading (Wh) of ongoing transaction, used to close it on
+-- expiry. Expired transaction is kept until its session close is delivered, so
+-- restart does not revive it from sessions-service active sessions.
+ALTER TABLE ocpp_transactions
+    ADD COLUMN IF NOT EXISTS meter_last BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS expired_at TIMESTAMPTZ;
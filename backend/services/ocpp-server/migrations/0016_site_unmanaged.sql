@@ -0,0 +1,4 @@
+-- Transactions that cannot be limited (OCPP 2.0.1, unknown connector) keep
+-- their current reserved in limit_current and are marked unmanaged.
+ALTER TABLE ocpp_site_allocations
+    ADD COLUMN IF NOT EXISTS unmanaged BOOLEAN NOT NULL DEFAULT FALSE;
@@ -7,8 +7,10 @@ import (
 	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 
+	"drivepower/backend/libs/events"
 	libredis "drivepower/backend/libs/redis"
 	"drivepower/backend/services/sessions-service/internal/config"
+	"drivepower/backend/services/sessions-service/internal/consumer"
 	"drivepower/backend/services/sessions-service/internal/db"
 	httpserver "drivepower/backend/services/sessions-service/internal/http"
 	"drivepower/backend/services/sessions-service/internal/http/handlers"
@@ -20,6 +22,7 @@ import (
 // App wires sessions-service dependencies.
 type App struct {
 	server      *httpserver.Server
+	consumer    *consumer.Consumer
 	db          *sql.DB
 	redisClient *redis.Client
 	logger      *zap.Logger
@@ -52,22 +55,37 @@ func New(cfg *config.Config, logger *zap.Logger) (*App, error) {
 		ActiveSessions: handlers.NewActiveSessionsHandler(sessionsService),
 		SessionStart:   ocppHandler.HandleSessionStart,
 		SessionStop:    ocppHandler.HandleSessionStop,
+		OCPPActive:     ocppHandler.HandleActiveSessions,
+		OCPPSession:    ocppHandler.HandleSessionByTransaction,
 		Health:         handlers.NewHealthHandler(),
 	}
 
 	router := httpserver.NewRouter(routes)
 	server := httpserver.NewServer(cfg.HTTPAddress(), router, logger)
 
+	var eventConsumer *consumer.Consumer
+	if cfg.Events.Enabled {
+		eventConsumer = consumer.NewConsumer(events.NewRedisBus(redisClient, cfg.Events.Stream, logger), sessionsService, logger)
+	}
+
 	return &App{
 		server:      server,
+		consumer:    eventConsumer,
 		db:          sqlDB,
 		redisClient: redisClient,
 		logger:      logger,
 	}, nil
 }
 
-// Run starts HTTP server.
+// Run starts event consumer (when enabled) and HTTP server.
 func (a *App) Run(ctx context.Context) error {
+	if a.consumer != nil {
+		go func() {
+			if err := a.consumer.Run(ctx); err != nil {
+				a.logger.Error("event consumer stopped", zap.Error(err))
+			}
+		}()
+	}
 	return a.server.Run(ctx)
 }
 
@@ -23,6 +23,10 @@ type Config struct {
 		DB       int    `yaml:"db" env:"SESSIONS_REDIS_DB"`
 		TTL      int    `yaml:"ttlSeconds" env:"SESSIONS_REDIS_TTL"`
 	} `yaml:"redis"`
+	Events struct {
+		Enabled bool   `yaml:"enabled" env:"SESSIONS_EVENTS_ENABLED"`
+		Stream  string `yaml:"stream" env:"SESSIONS_EVENTS_STREAM"`
+	} `yaml:"events"`
 }
 
 // Load reads configuration via shared helper.
@@ -0,0 +1,70 @@
+// Package consumer subscribes sessions-service to domain events of the charging network.
+package consumer
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"drivepower/backend/libs/events"
+	"drivepower/backend/services/sessions-service/internal/service"
+)
+
+// Group is consumer group of sessions-service on the event bus.
+const Group = "sessions-service"
+
+// Consumer opens and completes sessions from transaction events.
+type Consumer struct {
+	bus    events.Subscriber
+	svc    *service.SessionsService
+	logger *zap.Logger
+}
+
+// NewConsumer builds consumer.
+func NewConsumer(bus events.Subscriber, svc *service.SessionsService, logger *zap.Logger) *Consumer {
+	return &Consumer{
+		bus:    bus,
+		svc:    svc,
+		logger: logger,
+	}
+}
+
+// Run consumes events until ctx is cancelled.
+func (c *Consumer) Run(ctx context.Context) error {
+	return c.bus.Subscribe(ctx, Group, c.handle, events.TransactionStarted, events.TransactionStopped)
+}
+
+func (c *Consumer) handle(ctx context.Context, event events.Event) error {
+	if event.TransactionID == "" {
+		c.logger.Warn("transaction event without transaction id", zap.String("event_id", event.ID), zap.String("event_type", event.Type))
+		return nil
+	}
+	switch event.Type {
+	case events.TransactionStarted:
+		var data events.TransactionStartedData
+		if err := event.Decode(&data); err != nil {
+			return err
+		}
+		_, err := c.svc.StartSessionFromOCPP(ctx, service.StartSessionInput{
+			UserID:        data.UserID,
+			StationID:     event.StationID,
+			ConnectorID:   data.ConnectorID,
+			TransactionID: event.TransactionID,
+			MeterStart:    data.MeterStart,
+			StartTime:     data.StartedAt,
+		})
+		return err
+	case events.TransactionStopped:
+		var data events.TransactionStoppedData
+		if err := event.Decode(&data); err != nil {
+			return err
+		}
+		// Session may not exist yet if start is still in flight; the error makes the bus retry.
+		return c.svc.StopSessionFromOCPP(ctx, service.StopSessionInput{
+			TransactionID: event.TransactionID,
+			EndTime:       data.StoppedAt,
+			EnergyKWh:     data.EnergyKWh,
+		})
+	}
+	return nil
+}
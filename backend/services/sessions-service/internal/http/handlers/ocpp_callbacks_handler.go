@@ -2,11 +2,14 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"time"
 
 	"go.uber.org/zap"
 
+	"drivepower/backend/services/sessions-service/internal/models"
+	"drivepower/backend/services/sessions-service/internal/repository"
 	"drivepower/backend/services/sessions-service/internal/service"
 )
 
@@ -24,11 +27,15 @@ func NewOCPPCallbacksHandler(svc *service.SessionsService, logger *zap.Logger) *
 	}
 }
 
+// maxActiveSessions bounds active session listing for OCPP server.
+const maxActiveSessions = 10000
+
 type sessionStartRequest struct {
 	UserID        int64     `json:"user_id"`
 	StationID     string    `json:"station_id"`
 	ConnectorID   int       `json:"connector_id"`
 	TransactionID string    `json:"transaction_id"`
+	MeterStart    int64     `json:"meter_start"`
 	StartTime     time.Time `json:"start_time"`
 }
 
@@ -55,6 +62,7 @@ func (h *OCPPCallbacksHandler) HandleSessionStart(w http.ResponseWriter, r *http
 		StationID:     req.StationID,
 		ConnectorID:   req.ConnectorID,
 		TransactionID: req.TransactionID,
+		MeterStart:    req.MeterStart,
 		StartTime:     req.StartTime,
 	})
 	if err != nil {
@@ -65,6 +73,43 @@ func (h *OCPPCallbacksHandler) HandleSessionStart(w http.ResponseWriter, r *http
 	writeJSON(w, http.StatusAccepted, map[string]interface{}{"status": "ok", "session_id": session.ID})
 }
 
+// HandleActiveSessions handles GET /internal/ocpp/active-sessions, used by
+// OCPP server to rebuild transaction contexts after restart.
+func (h *OCPPCallbacksHandler) HandleActiveSessions(w http.ResponseWriter, r *http.Request) {
+	sessions, err := h.svc.GetActiveSessions(r.Context(), maxActiveSessions)
+	if err != nil {
+		h.logger.Error("list active sessions failed", zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to fetch active sessions")
+		return
+	}
+	if sessions == nil {
+		sessions = []models.Session{}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"sessions": sessions})
+}
+
+// HandleSessionByTransaction handles GET /internal/ocpp/session?transaction_id=,
+// used by event consumers to resolve session of transaction events published
+// before the session was opened.
+func (h *OCPPCallbacksHandler) HandleSessionByTransaction(w http.ResponseWriter, r *http.Request) {
+	transactionID := r.URL.Query().Get("transaction_id")
+	if transactionID == "" {
+		writeError(w, http.StatusBadRequest, "transaction_id is required")
+		return
+	}
+	session, err := h.svc.GetSessionByTransaction(r.Context(), transactionID)
+	if errors.Is(err, repository.ErrSessionNotFound) {
+		writeError(w, http.StatusNotFound, "session not found")
+		return
+	}
+	if err != nil {
+		h.logger.Error("get session by transaction failed", zap.Error(err))
+		writeError(w, http.StatusInternalServerError, "failed to fetch session")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"session": session})
+}
+
 // HandleSessionStop handles POST /internal/ocpp/session-stop.
 func (h *OCPPCallbacksHandler) HandleSessionStop(w http.ResponseWriter, r *http.Request) {
 	var req sessionStopRequest
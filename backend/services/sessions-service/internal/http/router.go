@@ -8,6 +8,8 @@ type Routes struct {
 	ActiveSessions   http.HandlerFunc
 	SessionStart     http.HandlerFunc
 	SessionStop      http.HandlerFunc
+	OCPPActive       http.HandlerFunc
+	OCPPSession      http.HandlerFunc
 	Health           http.HandlerFunc
 }
 
@@ -26,6 +28,12 @@ func NewRouter(routes Routes) http.Handler {
 	if routes.SessionStop != nil {
 		mux.Handle("/internal/ocpp/session-stop", method(http.MethodPost, routes.SessionStop))
 	}
+	if routes.OCPPActive != nil {
+		mux.Handle("/internal/ocpp/active-sessions", method(http.MethodGet, routes.OCPPActive))
+	}
+	if routes.OCPPSession != nil {
+		mux.Handle("/internal/ocpp/session", method(http.MethodGet, routes.OCPPSession))
+	}
 	if routes.Health != nil {
 		mux.Handle("/health", method(http.MethodGet, routes.Health))
 	}
@@ -12,6 +12,7 @@ type Session struct {
 	StartTime   time.Time `db:"start_time" json:"start_time"`
 	EndTime     time.Time `db:"end_time" json:"end_time"`
 	EnergyKWh   float64   `db:"energy_kwh" json:"energy_kwh"`
+	MeterStart  int64     `db:"meter_start" json:"meter_start"`
 	Transaction string    `db:"transaction_id" json:"transaction_id"`
 	CreatedAt   time.Time `db:"created_at" json:"created_at"`
 	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
@@ -20,18 +20,20 @@ func NewSessionRepository(db *sql.DB) *SessionRepository {
 }
 
 // StartSession either creates a new session or updates existing by transaction id.
+// A late duplicate start does not reopen completed session.
 func (r *SessionRepository) StartSession(ctx context.Context, session *models.Session) (*models.Session, error) {
 	const query = `
-		INSERT INTO charging_sessions (user_id, station_id, connector_id, status, start_time, transaction_id, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())
+		INSERT INTO charging_sessions (user_id, station_id, connector_id, status, start_time, meter_start, transaction_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), NOW())
 		ON CONFLICT (transaction_id) DO UPDATE SET
 			user_id = EXCLUDED.user_id,
 			station_id = EXCLUDED.station_id,
 			connector_id = EXCLUDED.connector_id,
-			status = EXCLUDED.status,
+			status = CASE WHEN charging_sessions.status = 'completed' THEN charging_sessions.status ELSE EXCLUDED.status END,
 			start_time = EXCLUDED.start_time,
+			meter_start = EXCLUDED.meter_start,
 			updated_at = NOW()
-		RETURNING id, created_at, updated_at
+		RETURNING id, status, created_at, updated_at
 	`
 	err := r.db.QueryRowContext(ctx, query,
 		session.UserID,
@@ -39,15 +41,17 @@ func (r *SessionRepository) StartSession(ctx context.Context, session *models.Se
 		session.ConnectorID,
 		session.Status,
 		session.StartTime,
+		session.MeterStart,
 		session.Transaction,
-	).Scan(&session.ID, &session.CreatedAt, &session.UpdatedAt)
+	).Scan(&session.ID, &session.Status, &session.CreatedAt, &session.UpdatedAt)
 	if err != nil {
 		return nil, err
 	}
 	return session, nil
 }
 
-// CompleteSession finalizes session by transaction id.
+// CompleteSession finalizes session by transaction id. Session that is already
+// completed is kept as is, so a repeated stop does not overwrite its energy.
 func (r *SessionRepository) CompleteSession(ctx context.Context, transactionID string, endTime time.Time, energy float64, status string) error {
 	const query = `
 		UPDATE charging_sessions
@@ -55,7 +59,7 @@ func (r *SessionRepository) CompleteSession(ctx context.Context, transactionID s
 		    energy_kwh = $3,
 		    status = $4,
 		    updated_at = NOW()
-		WHERE transaction_id = $1
+		WHERE transaction_id = $1 AND status <> 'completed'
 	`
 	result, err := r.db.ExecContext(ctx, query, transactionID, endTime, energy, status)
 	if err != nil {
@@ -66,7 +70,13 @@ func (r *SessionRepository) CompleteSession(ctx context.Context, transactionID s
 		return err
 	}
 	if affected == 0 {
-		return sql.ErrNoRows
+		var exists bool
+		if err := r.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM charging_sessions WHERE transaction_id = $1)`, transactionID).Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			return sql.ErrNoRows
+		}
 	}
 	return nil
 }
@@ -77,7 +87,7 @@ func (r *SessionRepository) GetSessionsByUser(ctx context.Context, userID int64,
 		limit = 50
 	}
 	const query = `
-		SELECT id, user_id, station_id, connector_id, status, start_time, end_time, energy_kwh, transaction_id, created_at, updated_at
+		SELECT id, user_id, station_id, connector_id, status, start_time, end_time, energy_kwh, meter_start, transaction_id, created_at, updated_at
 		FROM charging_sessions
 		WHERE user_id = $1
 		ORDER BY start_time DESC
@@ -88,43 +98,41 @@ func (r *SessionRepository) GetSessionsByUser(ctx context.Context, userID int64,
 		return nil, err
 	}
 	defer rows.Close()
+	return scanSessions(rows)
+}
 
-	var sessions []models.Session
-	for rows.Next() {
-		var s models.Session
-		if err := rows.Scan(
-			&s.ID,
-			&s.UserID,
-			&s.StationID,
-			&s.ConnectorID,
-			&s.Status,
-			&s.StartTime,
-			&s.EndTime,
-			&s.EnergyKWh,
-			&s.Transaction,
-			&s.CreatedAt,
-			&s.UpdatedAt,
-		); err != nil {
-			return nil, err
-		}
-		sessions = append(sessions, s)
+// ErrSessionNotFound indicates missing transaction.
+var ErrSessionNotFound = errors.New("session not found")
+
+// GetByTransactionID returns session of OCPP transaction.
+func (r *SessionRepository) GetByTransactionID(ctx context.Context, transactionID string) (*models.Session, error) {
+	const query = `
+		SELECT id, user_id, station_id, connector_id, status, start_time, end_time, energy_kwh, meter_start, transaction_id, created_at, updated_at
+		FROM charging_sessions
+		WHERE transaction_id = $1
+	`
+	rows, err := r.db.QueryContext(ctx, query, transactionID)
+	if err != nil {
+		return nil, err
 	}
-	if err := rows.Err(); err != nil {
+	defer rows.Close()
+	sessions, err := scanSessions(rows)
+	if err != nil {
 		return nil, err
 	}
-	return sessions, nil
+	if len(sessions) == 0 {
+		return nil, ErrSessionNotFound
+	}
+	return &sessions[0], nil
 }
 
-// ErrSessionNotFound indicates missing transaction.
-var ErrSessionNotFound = errors.New("session not found")
-
 // GetActiveSessions returns currently active sessions.
 func (r *SessionRepository) GetActiveSessions(ctx context.Context, limit int) ([]models.Session, error) {
 	if limit <= 0 {
 		limit = 50
 	}
 	const query = `
-		SELECT id, user_id, station_id, connector_id, status, start_time, end_time, energy_kwh, transaction_id, created_at, updated_at
+		SELECT id, user_id, station_id, connector_id, status, start_time, end_time, energy_kwh, meter_start, transaction_id, created_at, updated_at
 		FROM charging_sessions
 		WHERE status = 'active'
 		ORDER BY start_time DESC
@@ -135,10 +143,17 @@ func (r *SessionRepository) GetActiveSessions(ctx context.Context, limit int) ([
 		return nil, err
 	}
 	defer rows.Close()
+	return scanSessions(rows)
+}
 
+// scanSessions reads session rows; end_time of active session is NULL and stays zero.
+func scanSessions(rows *sql.Rows) ([]models.Session, error) {
 	var sessions []models.Session
 	for rows.Next() {
-		var s models.Session
+		var (
+			s       models.Session
+			endTime sql.NullTime
+		)
 		if err := rows.Scan(
 			&s.ID,
 			&s.UserID,
@@ -146,14 +161,16 @@ func (r *SessionRepository) GetActiveSessions(ctx context.Context, limit int) ([
 			&s.ConnectorID,
 			&s.Status,
 			&s.StartTime,
-			&s.EndTime,
+			&endTime,
 			&s.EnergyKWh,
+			&s.MeterStart,
 			&s.Transaction,
 			&s.CreatedAt,
 			&s.UpdatedAt,
 		); err != nil {
 			return nil, err
 		}
+		s.EndTime = endTime.Time
 		sessions = append(sessions, s)
 	}
 	if err := rows.Err(); err != nil {
@@ -32,6 +32,7 @@ type StartSessionInput struct {
 	StationID     string
 	ConnectorID   int
 	TransactionID string
+	MeterStart    int64
 	StartTime     time.Time
 }
 
@@ -66,6 +67,7 @@ func (s *SessionsService) StartSessionFromOCPP(ctx context.Context, input StartS
 		ConnectorID: input.ConnectorID,
 		Status:      SessionStatusActive,
 		StartTime:   input.StartTime.UTC(),
+		MeterStart:  input.MeterStart,
 		Transaction: input.TransactionID,
 	}
 
@@ -74,7 +76,7 @@ func (s *SessionsService) StartSessionFromOCPP(ctx context.Context, input StartS
 		return nil, err
 	}
 
-	if s.activeStore != nil {
+	if s.activeStore != nil && session.Status == SessionStatusActive {
 		cacheErr := s.activeStore.Save(ctx, redisstore.ActiveSession{
 			SessionID:     session.ID,
 			TransactionID: input.TransactionID,
@@ -112,6 +114,11 @@ func (s *SessionsService) GetSessionsByUser(ctx context.Context, userID int64, l
 	return s.repo.GetSessionsByUser(ctx, userID, limit)
 }
 
+// GetSessionByTransaction returns session of OCPP transaction.
+func (s *SessionsService) GetSessionByTransaction(ctx context.Context, transactionID string) (*models.Session, error) {
+	return s.repo.GetByTransactionID(ctx, transactionID)
+}
+
 // GetActiveSessions returns currently running sessions.
 func (s *SessionsService) GetActiveSessions(ctx context.Context, limit int) ([]models.Session, error) {
 	return s.repo.GetActiveSessions(ctx, limit)
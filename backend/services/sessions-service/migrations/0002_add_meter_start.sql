@@ -0,0 +1,3 @@
+-- Meter reading at transaction start, needed by OCPP server to rebuild transactions after restart.
+ALTER TABLE charging_sessions
+    ADD COLUMN IF NOT EXISTS meter_start BIGINT NOT NULL DEFAULT 0;
@@ -4,9 +4,14 @@ import (
 	"context"
 	"database/sql"
 
+	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 
+	"drivepower/backend/libs/events"
+	libredis "drivepower/backend/libs/redis"
+	"drivepower/backend/services/telemetry-service/internal/clients"
 	"drivepower/backend/services/telemetry-service/internal/config"
+	"drivepower/backend/services/telemetry-service/internal/consumer"
 	"drivepower/backend/services/telemetry-service/internal/db"
 	httpserver "drivepower/backend/services/telemetry-service/internal/http"
 	"drivepower/backend/services/telemetry-service/internal/http/handlers"
@@ -16,9 +21,11 @@ import (
 
 // App wires telemetry service dependencies.
 type App struct {
-	server *httpserver.Server
-	db     *sql.DB
-	logger *zap.Logger
+	server   *httpserver.Server
+	consumer *consumer.Consumer
+	db       *sql.DB
+	redis    *redis.Client
+	logger   *zap.Logger
 }
 
 // New constructs application components.
@@ -40,15 +47,38 @@ func New(cfg *config.Config, logger *zap.Logger) (*App, error) {
 	router := httpserver.NewRouter(routes)
 	server := httpserver.NewServer(cfg.HTTPAddress(), router, logger)
 
+	var (
+		redisClient   *redis.Client
+		eventConsumer *consumer.Consumer
+	)
+	if cfg.Events.Enabled {
+		redisClient, err = libredis.NewRedisClient(cfg.Redis.Addr, cfg.Redis.Password)
+		if err != nil {
+			sqlDB.Close()
+			return nil, err
+		}
+		sessionsClient := clients.NewSessionsClient(cfg.Services.SessionsURL)
+		eventConsumer = consumer.NewConsumer(events.NewRedisBus(redisClient, cfg.Events.Stream, logger), telemetryService, sessionsClient, logger)
+	}
+
 	return &App{
-		server: server,
-		db:     sqlDB,
-		logger: logger,
+		server:   server,
+		consumer: eventConsumer,
+		db:       sqlDB,
+		redis:    redisClient,
+		logger:   logger,
 	}, nil
 }
 
-// Run starts serving HTTP requests.
+// Run starts event consumer (when enabled) and HTTP server.
 func (a *App) Run(ctx context.Context) error {
+	if a.consumer != nil {
+		go func() {
+			if err := a.consumer.Run(ctx); err != nil {
+				a.logger.Error("event consumer stopped", zap.Error(err))
+			}
+		}()
+	}
 	return a.server.Run(ctx)
 }
 
@@ -59,5 +89,10 @@ func (a *App) Close() {
 			a.logger.Warn("failed to close db", zap.Error(err))
 		}
 	}
+	if a.redis != nil {
+		if err := a.redis.Close(); err != nil {
+			a.logger.Warn("failed to close redis", zap.Error(err))
+		}
+	}
 }
 
@@ -0,0 +1,66 @@
+// Package clients holds HTTP clients of services telemetry-service depends on.
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// ErrSessionsDisabled is returned when sessions-service URL is not configured.
+var ErrSessionsDisabled = errors.New("sessions-service url not configured")
+
+// SessionsClient looks up sessions in sessions-service.
+type SessionsClient struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewSessionsClient builds client; empty baseURL disables lookups.
+func NewSessionsClient(baseURL string) *SessionsClient {
+	return &SessionsClient{
+		baseURL: baseURL,
+		client: &http.Client{
+			Timeout: 5 * time.Second,
+		},
+	}
+}
+
+// SessionIDByTransaction returns ID of session opened for OCPP transaction.
+func (c *SessionsClient) SessionIDByTransaction(ctx context.Context, transactionID string) (int64, error) {
+	if c.baseURL == "" {
+		return 0, ErrSessionsDisabled
+	}
+	endpoint := fmt.Sprintf("%s/internal/ocpp/session?transaction_id=%s", c.baseURL, url.QueryEscape(transactionID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return 0, fmt.Errorf("session of transaction %s not found", transactionID)
+	}
+	if resp.StatusCode >= 300 {
+		return 0, fmt.Errorf("sessions lookup non-success status %d", resp.StatusCode)
+	}
+
+	var payload struct {
+		Session struct {
+			ID int64 `json:"id"`
+		} `json:"session"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return 0, err
+	}
+	return payload.Session.ID, nil
+}
@@ -16,6 +16,17 @@ type Config struct {
 	Database struct {
 		DSN string `yaml:"dsn" env:"TELEMETRY_POSTGRES_DSN"`
 	} `yaml:"database"`
+	Redis struct {
+		Addr     string `yaml:"addr" env:"TELEMETRY_REDIS_ADDR"`
+		Password string `yaml:"password" env:"TELEMETRY_REDIS_PASSWORD"`
+	} `yaml:"redis"`
+	Events struct {
+		Enabled bool   `yaml:"enabled" env:"TELEMETRY_EVENTS_ENABLED"`
+		Stream  string `yaml:"stream" env:"TELEMETRY_EVENTS_STREAM"`
+	} `yaml:"events"`
+	Services struct {
+		SessionsURL string `yaml:"sessions_url" env:"SESSIONS_SERVICE_URL"`
+	} `yaml:"services"`
 }
 
 // Load configuration using shared helper.
@@ -35,6 +46,12 @@ func Load() (*Config, error) {
 	if strings.TrimSpace(cfg.Database.DSN) == "" {
 		return nil, errors.New("config: database dsn required")
 	}
+	if cfg.Events.Enabled && strings.TrimSpace(cfg.Redis.Addr) == "" {
+		return nil, errors.New("config: redis addr required when events are enabled")
+	}
+	if cfg.Events.Enabled && strings.TrimSpace(cfg.Services.SessionsURL) == "" {
+		return nil, errors.New("config: sessions url required when events are enabled")
+	}
 	return cfg, nil
 }
 
@@ -0,0 +1,78 @@
+// Package consumer subscribes telemetry-service to domain events of the charging network.
+package consumer
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"drivepower/backend/libs/events"
+	"drivepower/backend/services/telemetry-service/internal/clients"
+	"drivepower/backend/services/telemetry-service/internal/service"
+)
+
+// Group is consumer group of telemetry-service on the event bus.
+const Group = "telemetry-service"
+
+// Consumer stores meter samples of ongoing transactions.
+type Consumer struct {
+	bus      events.Subscriber
+	service  *service.TelemetryService
+	sessions *clients.SessionsClient
+	logger   *zap.Logger
+}
+
+// NewConsumer builds consumer.
+func NewConsumer(bus events.Subscriber, service *service.TelemetryService, sessions *clients.SessionsClient, logger *zap.Logger) *Consumer {
+	return &Consumer{
+		bus:      bus,
+		service:  service,
+		sessions: sessions,
+		logger:   logger,
+	}
+}
+
+// Run consumes events until ctx is cancelled.
+func (c *Consumer) Run(ctx context.Context) error {
+	return c.bus.Subscribe(ctx, Group, c.handle, events.MeterSampled)
+}
+
+func (c *Consumer) handle(ctx context.Context, event events.Event) error {
+	var data events.MeterSampledData
+	if err := event.Decode(&data); err != nil {
+		return err
+	}
+	sessionID, err := c.sessionID(ctx, event)
+	if err != nil {
+		return err
+	}
+	// A redelivered reading does not change session energy (max - min).
+	return c.service.StoreMeterValue(ctx, service.MeterValueInput{
+		SessionID:   sessionID,
+		StationID:   event.StationID,
+		ConnectorID: data.ConnectorID,
+		MeterValue:  data.Value,
+		Unit:        data.Unit,
+		Timestamp:   data.SampledAt,
+	})
+}
+
+// sessionID returns session of transaction event. Events published while the
+// session start was still in flight carry none, so it is looked up in
+// sessions-service; an error makes the bus retry the event.
+func (c *Consumer) sessionID(ctx context.Context, event events.Event) (int64, error) {
+	if event.SessionID != 0 {
+		return event.SessionID, nil
+	}
+	sessionID, err := c.sessions.SessionIDByTransaction(ctx, event.TransactionID)
+	if err != nil {
+		c.logger.Warn("failed to resolve session of event",
+			zap.String("event_id", event.ID),
+			zap.String("event_type", event.Type),
+			zap.String("transaction_id", event.TransactionID),
+			zap.Error(err),
+		)
+		return 0, err
+	}
+	return sessionID, nil
+}
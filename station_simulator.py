@@ -38,13 +38,30 @@ def random_tx_id() -> str:
     return f"TX-{suffix}"
 
 
+def energy_sample(kwh: float) -> List[Dict[str, Any]]:
+    return [
+        {
+            "timestamp": iso_now(),
+            "sampledValue": [
+                {
+                    "value": str(int(kwh * 1000)),
+                    "measurand": "Energy.Active.Import.Register",
+                    "unit": "Wh",
+                    "context": "Sample.Periodic",
+                    "location": "Outlet",
+                }
+            ],
+        }
+    ]
+
+
 @dataclass
 class ConnectorState:
     connector_id: int
     status: str = "Available"
     current_energy_kwh: float = 0.0
     active_session: bool = False
-    transaction_id: Optional[str] = None
+    transaction_id: Optional[int] = None
     energy_task: Optional[asyncio.Task] = None
 
 
@@ -72,6 +89,8 @@ class StationSimulator:
         self.stop_event = threading.Event()
         self.auto_start = False
         self.sim_speed = 1.0  # multiplier for energy growth
+        # StartTransaction unique id -> connector id, transactionId comes in CALLRESULT
+        self.pending_starts: Dict[str, int] = {}
 
     def set_config(
         self,
@@ -162,15 +181,39 @@ class StationSimulator:
             data = json.loads(msg)
         except json.JSONDecodeError:
             return
-        if not isinstance(data, dict):
+        if not isinstance(data, list) or len(data) < 3:
             return
-        msg_type = data.get("messageType")
-        if msg_type == "RemoteStartTransaction":
-            connector_id = int(data.get("connectorId", 1))
-            self.run_coro(self._start_session(connector_id))
-        elif msg_type == "RemoteStopTransaction":
-            connector_id = int(data.get("connectorId", 1))
-            self.run_coro(self._stop_session(connector_id))
+        # CALLRESULT to our StartTransaction carries CSMS-assigned transactionId
+        if data[0] == 3:
+            connector = self._get_connector(self.pending_starts.pop(data[1], 0))
+            if connector and connector.active_session:
+                connector.transaction_id = data[2].get("transactionId")
+                status = data[2].get("idTagInfo", {}).get("status")
+                self.log(f"Transaction {connector.transaction_id} on connector {connector.connector_id}: {status}")
+            return
+        # Only CSMS-initiated CALL frames need an answer: [2, uniqueId, action, payload]
+        if len(data) < 4 or data[0] != 2:
+            return
+        unique_id, action, payload = data[1], data[2], data[3] or {}
+        if action == "RemoteStartTransaction":
+            connector = self._get_connector(int(payload.get("connectorId", 1)))
+            accepted = connector is not None and not connector.active_session
+            self.run_coro(self._send_result(unique_id, {"status": "Accepted" if accepted else "Rejected"}))
+            if accepted:
+                self.run_coro(self._start_session(connector.connector_id))
+        elif action == "RemoteStopTransaction":
+            tx_id = int(payload.get("transactionId", 0))
+            connector = next((c for c in self.connectors if c.transaction_id == tx_id), None)
+            self.run_coro(self._send_result(unique_id, {"status": "Accepted" if connector else "Rejected"}))
+            if connector:
+                self.run_coro(self._stop_session(connector.connector_id))
+        else:
+            self.run_coro(self._send_frame([4, unique_id, "NotSupported", f"{action} is not supported", {}]))
+
+    async def _send_result(self, unique_id: str, payload: Dict[str, Any]):
+        frame = [3, unique_id, payload]
+        await self._send_frame(frame)
+        self.log(f"SEND: {frame}")
 
     async def _heartbeat_loop(self):
         while self.connected and self.ws and not self.stop_event.is_set():
@@ -180,7 +223,7 @@ class StationSimulator:
                     2,
                     random_tx_id(),
                     "Heartbeat",
-                    {"timestamp": iso_now()},
+                    {},
                 ]
                 await self._send_frame(frame)
                 self.log(f"SEND: {frame}")
@@ -191,7 +234,6 @@ class StationSimulator:
             random_tx_id(),
             "BootNotification",
             {
-                "stationId": self.station_id,
                 "chargePointVendor": "SimVendor",
                 "chargePointModel": "SimModel",
             },
@@ -206,8 +248,8 @@ class StationSimulator:
                 random_tx_id(),
                 "StatusNotification",
                 {
-                    "stationId": self.station_id,
                     "connectorId": c.connector_id,
+                    "errorCode": "NoError",
                     "status": status,
                     "timestamp": iso_now(),
                 },
@@ -231,21 +273,20 @@ class StationSimulator:
         connector = self._get_connector(connector_id)
         if not connector or connector.active_session:
             return
-        tx_id = random_tx_id()
         connector.active_session = True
-        connector.transaction_id = tx_id
+        connector.transaction_id = None
         connector.status = "Charging"
         connector.current_energy_kwh = 0.0
         self.publish_state(self.connectors)
 
+        unique_id = random_tx_id()
+        self.pending_starts[unique_id] = connector_id
         frame = [
             2,
-            random_tx_id(),
+            unique_id,
             "StartTransaction",
             {
-                "stationId": self.station_id,
                 "connectorId": connector_id,
-                "transactionId": tx_id,
                 "idTag": "TAG-001",
                 "meterStart": int(connector.current_energy_kwh * 1000),
                 "timestamp": iso_now(),
@@ -265,13 +306,12 @@ class StationSimulator:
             connector.energy_task = None
 
         payload_body = {
-            "stationId": self.station_id,
-            "connectorId": connector_id,
             "transactionId": connector.transaction_id,
             "idTag": "TAG-001",
             "meterStop": int(connector.current_energy_kwh * 1000),
             "timestamp": iso_now(),
             "reason": "Local",
+            "transactionData": energy_sample(connector.current_energy_kwh),
         }
         connector.active_session = False
         connector.transaction_id = None
@@ -295,11 +335,9 @@ class StationSimulator:
                         random_tx_id(),
                         "MeterValues",
                         {
-                            "stationId": self.station_id,
                             "connectorId": connector.connector_id,
                             "transactionId": connector.transaction_id,
-                            "meterValue": round(connector.current_energy_kwh, 3),
-                            "timestamp": iso_now(),
+                            "meterValue": energy_sample(connector.current_energy_kwh),
                         },
                     ]
                     await self._send_frame(frame)